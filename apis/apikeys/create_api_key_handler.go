@@ -0,0 +1,66 @@
+package apis
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func CreateAPIKeyHandler(apiKeySvc svc.APIKeyService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwCreateAPIKeyHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		userID, ok := ctx.Locals("userID").(string)
+		if !ok || userID == "" {
+			return throwCreateAPIKeyHandlerError(ctx, fiber.StatusUnauthorized, &models.ErrorResponse{
+				MessageID: messages.ERR_UNAUTHORIZED.Code,
+				Message:   messages.ERR_UNAUTHORIZED.Text,
+				Exception: "userID not found in context",
+			})
+		}
+
+		var payload models.CreateAPIKeyPayload
+		if err := ctx.BodyParser(&payload); err != nil {
+			return throwCreateAPIKeyHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: fmt.Sprintf("Error parsing request body: %s", err.Error()),
+			})
+		}
+
+		if payload.Name == "" || payload.Organization == "" || payload.UserID == "" || len(payload.Permissions) == 0 {
+			return throwCreateAPIKeyHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: "Missing required fields in payload",
+			})
+		}
+
+		statusCode, keyResp, errResp := apiKeySvc.CreateAPIKey(ctx.Context(), userID, payload)
+		if errResp != nil {
+			return throwCreateAPIKeyHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_API_KEY_CREATE_SUCCESS.Code,
+				messages.INFO_API_KEY_CREATE_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(keyResp)
+	}
+}
+
+func throwCreateAPIKeyHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
@@ -0,0 +1,40 @@
+package apis
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func ListAPIKeysHandler(apiKeySvc svc.APIKeyService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwListAPIKeysHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		statusCode, keysResp, errResp := apiKeySvc.ListAPIKeys(ctx.Context())
+		if errResp != nil {
+			return throwListAPIKeysHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_API_KEY_FETCH_SUCCESS.Code,
+				messages.INFO_API_KEY_FETCH_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(keysResp)
+	}
+}
+
+func throwListAPIKeysHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
@@ -0,0 +1,49 @@
+package apis
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func ListAuditLogsHandler(auditSvc svc.AuditService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwListAuditLogsHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		var filter models.AuditLogFilter
+		if err := ctx.QueryParser(&filter); err != nil {
+			return throwListAuditLogsHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: fmt.Sprintf("Error parsing query: %s", err.Error()),
+			})
+		}
+
+		statusCode, auditResp, errResp := auditSvc.ListAuditLogs(ctx.Context(), filter)
+		if errResp != nil {
+			return throwListAuditLogsHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_AUDIT_FETCH_SUCCESS.Code,
+				messages.INFO_AUDIT_FETCH_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(auditResp)
+	}
+}
+
+func throwListAuditLogsHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
@@ -0,0 +1,40 @@
+package apis
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func VerifyAuditChainHandler(auditSvc svc.AuditService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwVerifyAuditChainHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		statusCode, verifyResp, errResp := auditSvc.VerifyAuditChain(ctx.Context())
+		if errResp != nil {
+			return throwVerifyAuditChainHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s: valid=%t", messages.INFO_AUDIT_VERIFY_SUCCESS.Code,
+				messages.INFO_AUDIT_VERIFY_SUCCESS.Text, verifyResp.Valid))
+
+		return ctx.Status(statusCode).JSON(verifyResp)
+	}
+}
+
+func throwVerifyAuditChainHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
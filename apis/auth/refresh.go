@@ -40,6 +40,14 @@ func RefreshAccessTokenHandler(authSvc svc.AuthService, userSvc svc.UserService)
 			})
 		}
 
+		if user.Status == models.UserStatusSuspended {
+			return throwRefreshTokenHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_ACCOUNT_SUSPENDED.Code,
+				Message:   messages.ERR_ACCOUNT_SUSPENDED.Text,
+				Exception: "refresh rejected for suspended account",
+			})
+		}
+
 		accessToken, err := authSvc.GenerateAccessToken(claims.UserID, user.Email, user.Role)
 		if err != nil {
 			return throwRefreshTokenHandlerError(ctx, fiber.StatusInternalServerError, &models.ErrorResponse{
@@ -0,0 +1,32 @@
+package branches
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func GetBranchByIDHandler(branchSvc svc.BranchService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		statusCode, branchResp, errResp := branchSvc.GetBranch(ctx.Context(), ctx.Params("id"))
+		if errResp != nil {
+			return throwGetBranchByIDHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_BRANCH_FETCH_SUCCESS.Code,
+				messages.INFO_BRANCH_FETCH_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(branchResp)
+	}
+}
+
+func throwGetBranchByIDHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
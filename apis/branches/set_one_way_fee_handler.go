@@ -0,0 +1,49 @@
+package branches
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func SetOneWayFeeHandler(branchSvc svc.BranchService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwSetOneWayFeeHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		var payload models.SetOneWayFeePayload
+		if err := ctx.BodyParser(&payload); err != nil {
+			return throwSetOneWayFeeHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: fmt.Sprintf("Error parsing request body: %s", err.Error()),
+			})
+		}
+
+		statusCode, feeResp, errResp := branchSvc.SetOneWayFee(ctx.Context(), ctx.Params("id"), payload)
+		if errResp != nil {
+			return throwSetOneWayFeeHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_ONE_WAY_FEE_UPDATE_SUCCESS.Code,
+				messages.INFO_ONE_WAY_FEE_UPDATE_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(feeResp)
+	}
+}
+
+func throwSetOneWayFeeHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
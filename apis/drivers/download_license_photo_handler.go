@@ -0,0 +1,44 @@
+package drivers
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func DownloadLicensePhotoHandler(driverSvc svc.DriverService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwDownloadLicensePhotoHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		statusCode, content, errResp := driverSvc.OpenLicensePhoto(ctx.Context(), ctx.Params("id"))
+		if errResp != nil {
+			return throwDownloadLicensePhotoHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_DRIVER_PROFILE_FETCH_SUCCESS.Code,
+				messages.INFO_DRIVER_PROFILE_FETCH_SUCCESS.Text))
+
+		ctx.Set(fiber.HeaderContentType, content.ContentType)
+		ctx.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+		ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", content.FileName))
+		// fasthttp closes the body once it has been written out.
+		return ctx.Status(statusCode).SendStream(content.Body)
+	}
+}
+
+func throwDownloadLicensePhotoHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
@@ -0,0 +1,43 @@
+package drivers
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetDriverInvitationsHandler lists the open rentals the caller has been
+// named on as an additional driver.
+func GetDriverInvitationsHandler(driverSvc svc.DriverService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		userID, ok := ctx.Locals("userID").(string)
+		if !ok || userID == "" {
+			return throwGetDriverInvitationsHandlerError(ctx, fiber.StatusUnauthorized, &models.ErrorResponse{
+				MessageID: messages.ERR_UNAUTHORIZED.Code,
+				Message:   messages.ERR_UNAUTHORIZED.Text,
+				Exception: "userID not found in context",
+			})
+		}
+
+		statusCode, invitationsResp, errResp := driverSvc.ListDriverInvitations(ctx.Context(), userID)
+		if errResp != nil {
+			return throwGetDriverInvitationsHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_DRIVER_INVITATION_FETCH_SUCCESS.Code,
+				messages.INFO_DRIVER_INVITATION_FETCH_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(invitationsResp)
+	}
+}
+
+func throwGetDriverInvitationsHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
@@ -0,0 +1,41 @@
+package drivers
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func GetMyDriverProfileHandler(driverSvc svc.DriverService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		userID, ok := ctx.Locals("userID").(string)
+		if !ok || userID == "" {
+			return throwGetMyDriverProfileHandlerError(ctx, fiber.StatusUnauthorized, &models.ErrorResponse{
+				MessageID: messages.ERR_UNAUTHORIZED.Code,
+				Message:   messages.ERR_UNAUTHORIZED.Text,
+				Exception: "userID not found in context",
+			})
+		}
+
+		statusCode, profileResp, errResp := driverSvc.GetDriverProfile(ctx.Context(), userID)
+		if errResp != nil {
+			return throwGetMyDriverProfileHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_DRIVER_PROFILE_FETCH_SUCCESS.Code,
+				messages.INFO_DRIVER_PROFILE_FETCH_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(profileResp)
+	}
+}
+
+func throwGetMyDriverProfileHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
@@ -0,0 +1,32 @@
+package drivers
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func GetVehicleClassesHandler(driverSvc svc.DriverService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		statusCode, classesResp, errResp := driverSvc.ListVehicleClasses(ctx.Context())
+		if errResp != nil {
+			return throwGetVehicleClassesHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_VEHICLE_CLASS_FETCH_SUCCESS.Code,
+				messages.INFO_VEHICLE_CLASS_FETCH_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(classesResp)
+	}
+}
+
+func throwGetVehicleClassesHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
@@ -0,0 +1,50 @@
+package drivers
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListDriverProfilesHandler is the verification queue, e.g. ?status=pending.
+func ListDriverProfilesHandler(driverSvc svc.DriverService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwListDriverProfilesHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		var filter models.DriverProfileFilter
+		if err := ctx.QueryParser(&filter); err != nil {
+			return throwListDriverProfilesHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: fmt.Sprintf("Error parsing query: %s", err.Error()),
+			})
+		}
+
+		statusCode, profilesResp, errResp := driverSvc.ListDriverProfiles(ctx.Context(), filter)
+		if errResp != nil {
+			return throwListDriverProfilesHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_DRIVER_PROFILE_FETCH_SUCCESS.Code,
+				messages.INFO_DRIVER_PROFILE_FETCH_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(profilesResp)
+	}
+}
+
+func throwListDriverProfilesHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
@@ -0,0 +1,52 @@
+package drivers
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PutMyDriverProfileHandler saves the caller's licence details. Changing them
+// sends the profile back for verification.
+func PutMyDriverProfileHandler(driverSvc svc.DriverService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		userID, ok := ctx.Locals("userID").(string)
+		if !ok || userID == "" {
+			return throwPutMyDriverProfileHandlerError(ctx, fiber.StatusUnauthorized, &models.ErrorResponse{
+				MessageID: messages.ERR_UNAUTHORIZED.Code,
+				Message:   messages.ERR_UNAUTHORIZED.Text,
+				Exception: "userID not found in context",
+			})
+		}
+
+		var payload models.DriverProfilePayload
+		if err := ctx.BodyParser(&payload); err != nil {
+			return throwPutMyDriverProfileHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: fmt.Sprintf("Error parsing request body: %s", err.Error()),
+			})
+		}
+
+		statusCode, profileResp, errResp := driverSvc.SaveDriverProfile(ctx.Context(), userID, payload)
+		if errResp != nil {
+			return throwPutMyDriverProfileHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_DRIVER_PROFILE_UPDATE_SUCCESS.Code,
+				messages.INFO_DRIVER_PROFILE_UPDATE_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(profileResp)
+	}
+}
+
+func throwPutMyDriverProfileHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
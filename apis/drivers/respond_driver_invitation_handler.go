@@ -0,0 +1,52 @@
+package drivers
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RespondDriverInvitationHandler lets a named driver accept ({"accept": true})
+// or decline being added to a rental.
+func RespondDriverInvitationHandler(driverSvc svc.DriverService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		userID, ok := ctx.Locals("userID").(string)
+		if !ok || userID == "" {
+			return throwRespondDriverInvitationHandlerError(ctx, fiber.StatusUnauthorized, &models.ErrorResponse{
+				MessageID: messages.ERR_UNAUTHORIZED.Code,
+				Message:   messages.ERR_UNAUTHORIZED.Text,
+				Exception: "userID not found in context",
+			})
+		}
+
+		var payload models.RespondDriverInvitationPayload
+		if err := ctx.BodyParser(&payload); err != nil {
+			return throwRespondDriverInvitationHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: fmt.Sprintf("Error parsing request body: %s", err.Error()),
+			})
+		}
+
+		statusCode, invitationResp, errResp := driverSvc.RespondToInvitation(ctx.Context(), ctx.Params("id"), userID, payload)
+		if errResp != nil {
+			return throwRespondDriverInvitationHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_DRIVER_INVITATION_RESPONDED.Code,
+				messages.INFO_DRIVER_INVITATION_RESPONDED.Text))
+
+		return ctx.Status(statusCode).JSON(invitationResp)
+	}
+}
+
+func throwRespondDriverInvitationHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
@@ -0,0 +1,53 @@
+package drivers
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ReviewDriverProfileHandler verifies a driver ({"approved": true}) or
+// rejects them with a reason the customer will see.
+func ReviewDriverProfileHandler(driverSvc svc.DriverService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwReviewDriverProfileHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		reviewerID, _ := ctx.Locals("userID").(string)
+
+		var payload models.ReviewDriverProfilePayload
+		if err := ctx.BodyParser(&payload); err != nil {
+			return throwReviewDriverProfileHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: fmt.Sprintf("Error parsing request body: %s", err.Error()),
+			})
+		}
+
+		statusCode, profileResp, errResp := driverSvc.ReviewDriverProfile(ctx.Context(), ctx.Params("id"), reviewerID, payload)
+		if errResp != nil {
+			return throwReviewDriverProfileHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_DRIVER_PROFILE_REVIEW_SUCCESS.Code,
+				messages.INFO_DRIVER_PROFILE_REVIEW_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(profileResp)
+	}
+}
+
+func throwReviewDriverProfileHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
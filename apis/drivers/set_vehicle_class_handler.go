@@ -0,0 +1,51 @@
+package drivers
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetVehicleClassHandler sets the driver age rules and young driver
+// surcharge for a vehicle category.
+func SetVehicleClassHandler(driverSvc svc.DriverService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwSetVehicleClassHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		var payload models.VehicleClassPayload
+		if err := ctx.BodyParser(&payload); err != nil {
+			return throwSetVehicleClassHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: fmt.Sprintf("Error parsing request body: %s", err.Error()),
+			})
+		}
+
+		statusCode, classResp, errResp := driverSvc.SetVehicleClass(ctx.Context(), ctx.Params("category"), payload)
+		if errResp != nil {
+			return throwSetVehicleClassHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_VEHICLE_CLASS_UPDATE_SUCCESS.Code,
+				messages.INFO_VEHICLE_CLASS_UPDATE_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(classResp)
+	}
+}
+
+func throwSetVehicleClassHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
@@ -0,0 +1,79 @@
+package drivers
+
+import (
+	"fmt"
+	"io"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// UploadLicensePhotoHandler accepts a multipart form with a "file" part
+// holding a JPEG, PNG or PDF scan of the caller's licence.
+func UploadLicensePhotoHandler(driverSvc svc.DriverService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		userID, ok := ctx.Locals("userID").(string)
+		if !ok || userID == "" {
+			return throwUploadLicensePhotoHandlerError(ctx, fiber.StatusUnauthorized, &models.ErrorResponse{
+				MessageID: messages.ERR_UNAUTHORIZED.Code,
+				Message:   messages.ERR_UNAUTHORIZED.Text,
+				Exception: "userID not found in context",
+			})
+		}
+
+		fileHeader, err := ctx.FormFile("file")
+		if err != nil {
+			return throwUploadLicensePhotoHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: fmt.Sprintf("Error reading multipart file: %s", err.Error()),
+			})
+		}
+		if fileHeader.Size > svc.MaxDocumentBytes {
+			return throwUploadLicensePhotoHandlerError(ctx, fiber.StatusRequestEntityTooLarge, &models.ErrorResponse{
+				MessageID: messages.ERR_MEDIA_TOO_LARGE.Code,
+				Message:   messages.ERR_MEDIA_TOO_LARGE.Text,
+				Exception: fmt.Sprintf("license photos are limited to %d MiB", svc.MaxDocumentBytes>>20),
+			})
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			return throwUploadLicensePhotoHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: err.Error(),
+			})
+		}
+		defer file.Close()
+
+		data, err := io.ReadAll(io.LimitReader(file, svc.MaxDocumentBytes+1))
+		if err != nil {
+			return throwUploadLicensePhotoHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: err.Error(),
+			})
+		}
+
+		statusCode, profileResp, errResp := driverSvc.UploadLicensePhoto(ctx.Context(), userID, data)
+		if errResp != nil {
+			return throwUploadLicensePhotoHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_LICENSE_PHOTO_UPLOAD_SUCCESS.Code,
+				messages.INFO_LICENSE_PHOTO_UPLOAD_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(profileResp)
+	}
+}
+
+func throwUploadLicensePhotoHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
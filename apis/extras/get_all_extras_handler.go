@@ -0,0 +1,47 @@
+package extras
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetAllExtrasHandler lists the extras on offer. With ?branch_id= and a
+// start_date/end_date window each stocked extra also reports how many are
+// still free at that branch.
+func GetAllExtrasHandler(extraSvc svc.ExtraService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		var filter models.ExtraFilter
+		if err := ctx.QueryParser(&filter); err != nil {
+			return throwGetAllExtrasHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: fmt.Sprintf("Error parsing query: %s", err.Error()),
+			})
+		}
+		if role, _ := ctx.Locals("role").(string); role != "admin" {
+			filter.IncludeInactive = false
+		}
+
+		statusCode, extrasResp, errResp := extraSvc.ListExtras(ctx.Context(), filter)
+		if errResp != nil {
+			return throwGetAllExtrasHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_EXTRA_FETCH_SUCCESS.Code,
+				messages.INFO_EXTRA_FETCH_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(extrasResp)
+	}
+}
+
+func throwGetAllExtrasHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
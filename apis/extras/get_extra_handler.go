@@ -0,0 +1,32 @@
+package extras
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func GetExtraHandler(extraSvc svc.ExtraService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		statusCode, extraResp, errResp := extraSvc.GetExtra(ctx.Context(), ctx.Params("id"))
+		if errResp != nil {
+			return throwGetExtraHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_EXTRA_FETCH_SUCCESS.Code,
+				messages.INFO_EXTRA_FETCH_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(extraResp)
+	}
+}
+
+func throwGetExtraHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
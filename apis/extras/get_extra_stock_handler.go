@@ -0,0 +1,40 @@
+package extras
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func GetExtraStockHandler(extraSvc svc.ExtraService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwGetExtraStockHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		statusCode, stockResp, errResp := extraSvc.ListExtraStock(ctx.Context(), ctx.Params("id"))
+		if errResp != nil {
+			return throwGetExtraStockHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_EXTRA_FETCH_SUCCESS.Code,
+				messages.INFO_EXTRA_FETCH_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(stockResp)
+	}
+}
+
+func throwGetExtraStockHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
@@ -0,0 +1,50 @@
+package extras
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetExtraStockHandler sets how many units of an extra a branch holds.
+func SetExtraStockHandler(extraSvc svc.ExtraService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwSetExtraStockHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		var payload models.SetExtraStockPayload
+		if err := ctx.BodyParser(&payload); err != nil {
+			return throwSetExtraStockHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: fmt.Sprintf("Error parsing request body: %s", err.Error()),
+			})
+		}
+
+		statusCode, stockResp, errResp := extraSvc.SetExtraStock(ctx.Context(), ctx.Params("id"), payload)
+		if errResp != nil {
+			return throwSetExtraStockHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_EXTRA_STOCK_SET_SUCCESS.Code,
+				messages.INFO_EXTRA_STOCK_SET_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(stockResp)
+	}
+}
+
+func throwSetExtraStockHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
@@ -0,0 +1,54 @@
+package inspections
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func GetRentalChargesHandler(rentalSvc svc.RentalService, inspectionSvc svc.InspectionService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		userID, ok := ctx.Locals("userID").(string)
+		if !ok || userID == "" {
+			return throwGetRentalChargesHandlerError(ctx, fiber.StatusUnauthorized, &models.ErrorResponse{
+				MessageID: messages.ERR_UNAUTHORIZED.Code,
+				Message:   messages.ERR_UNAUTHORIZED.Text,
+				Exception: "userID not found in context",
+			})
+		}
+
+		statusCode, rentalResp, errResp := rentalSvc.GetRental(ctx.Context(), ctx.Params("id"))
+		if errResp != nil {
+			return throwGetRentalChargesHandlerError(ctx, statusCode, errResp)
+		}
+
+		if role, _ := ctx.Locals("role").(string); role != "admin" && rentalResp.UserID.String() != userID {
+			return throwGetRentalChargesHandlerError(ctx, fiber.StatusNotFound, &models.ErrorResponse{
+				MessageID: messages.ERR_RENTAL_NOT_FOUND.Code,
+				Message:   messages.ERR_RENTAL_NOT_FOUND.Text,
+				Exception: "rental not found",
+			})
+		}
+
+		statusCode, chargesResp, errResp := inspectionSvc.ListRentalCharges(ctx.Context(), ctx.Params("id"))
+		if errResp != nil {
+			return throwGetRentalChargesHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_CHARGES_FETCH_SUCCESS.Code,
+				messages.INFO_CHARGES_FETCH_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(chargesResp)
+	}
+}
+
+func throwGetRentalChargesHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
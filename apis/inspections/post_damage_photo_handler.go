@@ -0,0 +1,82 @@
+package inspections
+
+import (
+	"fmt"
+	"io"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PostDamagePhotoHandler attaches a photo, sent as the "file" part of a
+// multipart form, to a damage item recorded during an inspection.
+func PostDamagePhotoHandler(mediaSvc svc.MediaService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwPostDamagePhotoHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		userID, _ := ctx.Locals("userID").(string)
+
+		fileHeader, err := ctx.FormFile("file")
+		if err != nil {
+			return throwPostDamagePhotoHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: fmt.Sprintf("Error reading multipart file: %s", err.Error()),
+			})
+		}
+
+		limit := int64(svc.MaxPhotoBytes)
+		if fileHeader.Size > limit {
+			return throwPostDamagePhotoHandlerError(ctx, fiber.StatusRequestEntityTooLarge, &models.ErrorResponse{
+				MessageID: messages.ERR_MEDIA_TOO_LARGE.Code,
+				Message:   messages.ERR_MEDIA_TOO_LARGE.Text,
+				Exception: fmt.Sprintf("photo uploads are limited to %d MiB", limit>>20),
+			})
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			return throwPostDamagePhotoHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: err.Error(),
+			})
+		}
+		defer file.Close()
+
+		data, err := io.ReadAll(io.LimitReader(file, limit+1))
+		if err != nil {
+			return throwPostDamagePhotoHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: err.Error(),
+			})
+		}
+
+		statusCode, mediaResp, errResp := mediaSvc.AttachDamagePhoto(ctx.Context(), ctx.Params("id"), userID, fileHeader.Filename, data)
+		if errResp != nil {
+			return throwPostDamagePhotoHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_MEDIA_UPLOAD_SUCCESS.Code,
+				messages.INFO_MEDIA_UPLOAD_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(mediaResp)
+	}
+}
+
+func throwPostDamagePhotoHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
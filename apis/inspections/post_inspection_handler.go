@@ -0,0 +1,51 @@
+package inspections
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func PostInspectionHandler(inspectionSvc svc.InspectionService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwPostInspectionHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		userID, _ := ctx.Locals("userID").(string)
+
+		var payload models.CreateInspectionPayload
+		if err := ctx.BodyParser(&payload); err != nil {
+			return throwPostInspectionHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: fmt.Sprintf("Error parsing request body: %s", err.Error()),
+			})
+		}
+
+		statusCode, inspectionResp, errResp := inspectionSvc.CreateInspection(ctx.Context(), ctx.Params("id"), userID, payload)
+		if errResp != nil {
+			return throwPostInspectionHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_INSPECTION_CREATE_SUCCESS.Code,
+				messages.INFO_INSPECTION_CREATE_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(inspectionResp)
+	}
+}
+
+func throwPostInspectionHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
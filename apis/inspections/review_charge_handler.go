@@ -0,0 +1,49 @@
+package inspections
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func ReviewChargeHandler(inspectionSvc svc.InspectionService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwReviewChargeHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		var payload models.ReviewChargePayload
+		if err := ctx.BodyParser(&payload); err != nil {
+			return throwReviewChargeHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: fmt.Sprintf("Error parsing request body: %s", err.Error()),
+			})
+		}
+
+		statusCode, chargeResp, errResp := inspectionSvc.ReviewCharge(ctx.Context(), ctx.Params("id"), payload)
+		if errResp != nil {
+			return throwReviewChargeHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_CHARGE_REVIEW_SUCCESS.Code,
+				messages.INFO_CHARGE_REVIEW_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(chargeResp)
+	}
+}
+
+func throwReviewChargeHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
@@ -0,0 +1,51 @@
+package invoices
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetInvoiceHandler returns an invoice or credit note as JSON, or as a PDF
+// when asked for with ?format=pdf or an Accept: application/pdf header.
+func GetInvoiceHandler(invoiceSvc svc.InvoiceService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		userID, ok := ctx.Locals("userID").(string)
+		if !ok || userID == "" {
+			return throwGetInvoiceHandlerError(ctx, fiber.StatusUnauthorized, &models.ErrorResponse{
+				MessageID: messages.ERR_UNAUTHORIZED.Code,
+				Message:   messages.ERR_UNAUTHORIZED.Text,
+				Exception: "userID not found in context",
+			})
+		}
+
+		statusCode, invoiceResp, errResp := invoiceSvc.GetInvoice(ctx.Context(), ctx.Params("id"))
+		if errResp != nil {
+			return throwGetInvoiceHandlerError(ctx, statusCode, errResp)
+		}
+
+		if role, _ := ctx.Locals("role").(string); role != "admin" && invoiceResp.UserID.String() != userID {
+			return throwGetInvoiceHandlerError(ctx, fiber.StatusNotFound, &models.ErrorResponse{
+				MessageID: messages.ERR_INVOICE_NOT_FOUND.Code,
+				Message:   messages.ERR_INVOICE_NOT_FOUND.Text,
+				Exception: "invoice not found",
+			})
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_INVOICE_FETCH_SUCCESS.Code,
+				messages.INFO_INVOICE_FETCH_SUCCESS.Text))
+
+		return sendInvoice(ctx, statusCode, invoiceResp)
+	}
+}
+
+func throwGetInvoiceHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
@@ -0,0 +1,61 @@
+package invoices
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetRentalInvoiceHandler returns the rental's invoice as JSON, or as a PDF
+// when asked for with ?format=pdf or an Accept: application/pdf header.
+func GetRentalInvoiceHandler(invoiceSvc svc.InvoiceService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		userID, ok := ctx.Locals("userID").(string)
+		if !ok || userID == "" {
+			return throwGetRentalInvoiceHandlerError(ctx, fiber.StatusUnauthorized, &models.ErrorResponse{
+				MessageID: messages.ERR_UNAUTHORIZED.Code,
+				Message:   messages.ERR_UNAUTHORIZED.Text,
+				Exception: "userID not found in context",
+			})
+		}
+
+		statusCode, invoiceResp, errResp := invoiceSvc.GetRentalInvoice(ctx.Context(), ctx.Params("id"))
+		if errResp != nil {
+			return throwGetRentalInvoiceHandlerError(ctx, statusCode, errResp)
+		}
+
+		if role, _ := ctx.Locals("role").(string); role != "admin" && invoiceResp.UserID.String() != userID {
+			return throwGetRentalInvoiceHandlerError(ctx, fiber.StatusNotFound, &models.ErrorResponse{
+				MessageID: messages.ERR_INVOICE_NOT_FOUND.Code,
+				Message:   messages.ERR_INVOICE_NOT_FOUND.Text,
+				Exception: "invoice not found",
+			})
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_INVOICE_FETCH_SUCCESS.Code,
+				messages.INFO_INVOICE_FETCH_SUCCESS.Text))
+
+		return sendInvoice(ctx, statusCode, invoiceResp)
+	}
+}
+
+// sendInvoice writes the invoice in the format the client asked for.
+func sendInvoice(ctx *fiber.Ctx, statusCode int, invoice *models.InvoiceResponse) error {
+	if ctx.Query("format") != "pdf" && ctx.Accepts(fiber.MIMEApplicationJSON, "application/pdf") != "application/pdf" {
+		return ctx.Status(statusCode).JSON(invoice)
+	}
+	ctx.Set(fiber.HeaderContentType, "application/pdf")
+	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", invoice.Number+".pdf"))
+	return ctx.Status(statusCode).Send(svc.RenderInvoicePDF(*invoice))
+}
+
+func throwGetRentalInvoiceHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
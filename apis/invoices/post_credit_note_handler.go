@@ -0,0 +1,49 @@
+package invoices
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func PostCreditNoteHandler(invoiceSvc svc.InvoiceService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwPostCreditNoteHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		var payload models.CreateCreditNotePayload
+		if err := ctx.BodyParser(&payload); err != nil {
+			return throwPostCreditNoteHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: fmt.Sprintf("Error parsing request body: %s", err.Error()),
+			})
+		}
+
+		statusCode, creditNoteResp, errResp := invoiceSvc.CreateCreditNote(ctx.Context(), ctx.Params("id"), payload)
+		if errResp != nil {
+			return throwPostCreditNoteHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_CREDIT_NOTE_CREATE_SUCCESS.Code,
+				messages.INFO_CREDIT_NOTE_CREATE_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(creditNoteResp)
+	}
+}
+
+func throwPostCreditNoteHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
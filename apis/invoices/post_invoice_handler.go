@@ -0,0 +1,40 @@
+package invoices
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func PostInvoiceHandler(invoiceSvc svc.InvoiceService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwPostInvoiceHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		statusCode, invoiceResp, errResp := invoiceSvc.IssueInvoice(ctx.Context(), ctx.Params("id"))
+		if errResp != nil {
+			return throwPostInvoiceHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_INVOICE_ISSUE_SUCCESS.Code,
+				messages.INFO_INVOICE_ISSUE_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(invoiceResp)
+	}
+}
+
+func throwPostInvoiceHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
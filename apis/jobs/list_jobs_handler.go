@@ -0,0 +1,51 @@
+package jobs
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListJobsHandler lists background jobs, newest first, optionally filtered by
+// ?status= and ?kind=.
+func ListJobsHandler(jobSvc svc.JobService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwListJobsHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		var filter models.JobFilter
+		if err := ctx.QueryParser(&filter); err != nil {
+			return throwListJobsHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: fmt.Sprintf("Error parsing query: %s", err.Error()),
+			})
+		}
+
+		statusCode, jobsResp, errResp := jobSvc.ListJobs(ctx.Context(), filter)
+		if errResp != nil {
+			return throwListJobsHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_JOBS_FETCH_SUCCESS.Code,
+				messages.INFO_JOBS_FETCH_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(jobsResp)
+	}
+}
+
+func throwListJobsHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
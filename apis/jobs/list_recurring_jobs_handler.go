@@ -0,0 +1,40 @@
+package jobs
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func ListRecurringJobsHandler(jobSvc svc.JobService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwListRecurringJobsHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		statusCode, recurringResp, errResp := jobSvc.ListRecurringJobs(ctx.Context())
+		if errResp != nil {
+			return throwListRecurringJobsHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_RECURRING_JOBS_FETCH_SUCCESS.Code,
+				messages.INFO_RECURRING_JOBS_FETCH_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(recurringResp)
+	}
+}
+
+func throwListRecurringJobsHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
@@ -0,0 +1,42 @@
+package jobs
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RetryJobHandler requeues a dead job once whatever made it fail has been
+// fixed.
+func RetryJobHandler(jobSvc svc.JobService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwRetryJobHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		statusCode, jobResp, errResp := jobSvc.RetryJob(ctx.Context(), ctx.Params("id"))
+		if errResp != nil {
+			return throwRetryJobHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_JOB_RETRY_SUCCESS.Code,
+				messages.INFO_JOB_RETRY_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(jobResp)
+	}
+}
+
+func throwRetryJobHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
@@ -0,0 +1,49 @@
+package maintenance
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func CompleteMaintenanceHandler(maintenanceSvc svc.MaintenanceService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwCompleteMaintenanceHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		var payload models.CompleteMaintenancePayload
+		if err := ctx.BodyParser(&payload); err != nil {
+			return throwCompleteMaintenanceHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: fmt.Sprintf("Error parsing request body: %s", err.Error()),
+			})
+		}
+
+		statusCode, maintenanceResp, errResp := maintenanceSvc.CompleteMaintenance(ctx.Context(), ctx.Params("id"), payload)
+		if errResp != nil {
+			return throwCompleteMaintenanceHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_MAINTENANCE_COMPLETE_SUCCESS.Code,
+				messages.INFO_MAINTENANCE_COMPLETE_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(maintenanceResp)
+	}
+}
+
+func throwCompleteMaintenanceHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
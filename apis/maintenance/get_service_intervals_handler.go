@@ -0,0 +1,40 @@
+package maintenance
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func GetServiceIntervalsHandler(maintenanceSvc svc.MaintenanceService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwGetServiceIntervalsHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		statusCode, intervalsResp, errResp := maintenanceSvc.ListServiceIntervals(ctx.Context(), ctx.Params("id"))
+		if errResp != nil {
+			return throwGetServiceIntervalsHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_MAINTENANCE_FETCH_SUCCESS.Code,
+				messages.INFO_MAINTENANCE_FETCH_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(intervalsResp)
+	}
+}
+
+func throwGetServiceIntervalsHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
@@ -0,0 +1,51 @@
+package maintenance
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListMaintenanceHandler lists maintenance across the fleet, e.g.
+// ?status=pending for service tasks still waiting for a slot.
+func ListMaintenanceHandler(maintenanceSvc svc.MaintenanceService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwListMaintenanceHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		var filter models.MaintenanceFilter
+		if err := ctx.QueryParser(&filter); err != nil {
+			return throwListMaintenanceHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: fmt.Sprintf("Error parsing query: %s", err.Error()),
+			})
+		}
+
+		statusCode, maintenanceResp, errResp := maintenanceSvc.ListMaintenance(ctx.Context(), filter)
+		if errResp != nil {
+			return throwListMaintenanceHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_MAINTENANCE_FETCH_SUCCESS.Code,
+				messages.INFO_MAINTENANCE_FETCH_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(maintenanceResp)
+	}
+}
+
+func throwListMaintenanceHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
@@ -0,0 +1,51 @@
+package maintenance
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func PostMaintenanceHandler(maintenanceSvc svc.MaintenanceService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwPostMaintenanceHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		userID, _ := ctx.Locals("userID").(string)
+
+		var payload models.CreateMaintenancePayload
+		if err := ctx.BodyParser(&payload); err != nil {
+			return throwPostMaintenanceHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: fmt.Sprintf("Error parsing request body: %s", err.Error()),
+			})
+		}
+
+		statusCode, maintenanceResp, errResp := maintenanceSvc.CreateMaintenance(ctx.Context(), ctx.Params("id"), userID, payload)
+		if errResp != nil {
+			return throwPostMaintenanceHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_MAINTENANCE_CREATE_SUCCESS.Code,
+				messages.INFO_MAINTENANCE_CREATE_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(maintenanceResp)
+	}
+}
+
+func throwPostMaintenanceHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
@@ -0,0 +1,43 @@
+package notifications
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetNotificationPreferencesHandler returns which notifications the caller gets on each channel
+// and in which language.
+func GetNotificationPreferencesHandler(notificationSvc svc.NotificationService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		userID, ok := ctx.Locals("userID").(string)
+		if !ok || userID == "" {
+			return throwGetNotificationPreferencesHandlerError(ctx, fiber.StatusUnauthorized, &models.ErrorResponse{
+				MessageID: messages.ERR_UNAUTHORIZED.Code,
+				Message:   messages.ERR_UNAUTHORIZED.Text,
+				Exception: "userID not found in context",
+			})
+		}
+
+		statusCode, prefsResp, errResp := notificationSvc.GetNotificationPreferences(ctx.Context(), userID)
+		if errResp != nil {
+			return throwGetNotificationPreferencesHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_NOTIFICATION_PREFERENCES_FETCH_SUCCESS.Code,
+				messages.INFO_NOTIFICATION_PREFERENCES_FETCH_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(prefsResp)
+	}
+}
+
+func throwGetNotificationPreferencesHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
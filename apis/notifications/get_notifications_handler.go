@@ -0,0 +1,51 @@
+package notifications
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetNotificationsHandler lists the notifications sent to the caller, newest first.
+func GetNotificationsHandler(notificationSvc svc.NotificationService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		userID, ok := ctx.Locals("userID").(string)
+		if !ok || userID == "" {
+			return throwGetNotificationsHandlerError(ctx, fiber.StatusUnauthorized, &models.ErrorResponse{
+				MessageID: messages.ERR_UNAUTHORIZED.Code,
+				Message:   messages.ERR_UNAUTHORIZED.Text,
+				Exception: "userID not found in context",
+			})
+		}
+
+		var filter models.NotificationFilter
+		if err := ctx.QueryParser(&filter); err != nil {
+			return throwGetNotificationsHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: fmt.Sprintf("Error parsing query: %s", err.Error()),
+			})
+		}
+
+		statusCode, notificationsResp, errResp := notificationSvc.ListNotifications(ctx.Context(), userID, filter)
+		if errResp != nil {
+			return throwGetNotificationsHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_NOTIFICATIONS_FETCH_SUCCESS.Code,
+				messages.INFO_NOTIFICATIONS_FETCH_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(notificationsResp)
+	}
+}
+
+func throwGetNotificationsHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
@@ -0,0 +1,52 @@
+package notifications
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PutNotificationPreferencesHandler updates the caller's locale, phone number and the
+// notification kinds wanted by email and by SMS. Omitted fields are kept.
+func PutNotificationPreferencesHandler(notificationSvc svc.NotificationService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		userID, ok := ctx.Locals("userID").(string)
+		if !ok || userID == "" {
+			return throwPutNotificationPreferencesHandlerError(ctx, fiber.StatusUnauthorized, &models.ErrorResponse{
+				MessageID: messages.ERR_UNAUTHORIZED.Code,
+				Message:   messages.ERR_UNAUTHORIZED.Text,
+				Exception: "userID not found in context",
+			})
+		}
+
+		var payload models.UpdateNotificationPreferencesPayload
+		if err := ctx.BodyParser(&payload); err != nil {
+			return throwPutNotificationPreferencesHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: fmt.Sprintf("Error parsing request body: %s", err.Error()),
+			})
+		}
+
+		statusCode, prefsResp, errResp := notificationSvc.UpdateNotificationPreferences(ctx.Context(), userID, payload)
+		if errResp != nil {
+			return throwPutNotificationPreferencesHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_NOTIFICATION_PREFERENCES_UPDATE_SUCCESS.Code,
+				messages.INFO_NOTIFICATION_PREFERENCES_UPDATE_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(prefsResp)
+	}
+}
+
+func throwPutNotificationPreferencesHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
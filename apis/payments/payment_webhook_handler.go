@@ -0,0 +1,36 @@
+package payments
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	"vehix/core/payments"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PaymentWebhookHandler receives asynchronous events from the payment
+// gateway. It sits outside the auth middleware; the signature header is what
+// authenticates the caller.
+func PaymentWebhookHandler(paymentSvc svc.PaymentService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		statusCode, errResp := paymentSvc.HandleWebhook(ctx.Context(), ctx.Body(), ctx.Get(payments.SignatureHeader))
+		if errResp != nil {
+			return throwPaymentWebhookHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_PAYMENT_WEBHOOK_PROCESSED.Code,
+				messages.INFO_PAYMENT_WEBHOOK_PROCESSED.Text))
+
+		return ctx.SendStatus(statusCode)
+	}
+}
+
+func throwPaymentWebhookHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
@@ -0,0 +1,49 @@
+package promos
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func UpdatePromoCodeHandler(promoSvc svc.PromoService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwUpdatePromoCodeHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		var payload models.UpdatePromoCodePayload
+		if err := ctx.BodyParser(&payload); err != nil {
+			return throwUpdatePromoCodeHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: fmt.Sprintf("Error parsing request body: %s", err.Error()),
+			})
+		}
+
+		statusCode, promoResp, errResp := promoSvc.UpdatePromoCode(ctx.Context(), ctx.Params("id"), &payload)
+		if errResp != nil {
+			return throwUpdatePromoCodeHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_PROMO_UPDATE_SUCCESS.Code,
+				messages.INFO_PROMO_UPDATE_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(promoResp)
+	}
+}
+
+func throwUpdatePromoCodeHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
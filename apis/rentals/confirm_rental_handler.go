@@ -0,0 +1,65 @@
+package rentals
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ConfirmRentalHandler pays for a held rental with the payment_token in the
+// body, turning the hold into a confirmed booking.
+func ConfirmRentalHandler(rentalSvc svc.RentalService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		userID, ok := ctx.Locals("userID").(string)
+		if !ok || userID == "" {
+			return throwConfirmRentalHandlerError(ctx, fiber.StatusUnauthorized, &models.ErrorResponse{
+				MessageID: messages.ERR_UNAUTHORIZED.Code,
+				Message:   messages.ERR_UNAUTHORIZED.Text,
+				Exception: "userID not found in context",
+			})
+		}
+
+		var payload models.ConfirmRentalPayload
+		if err := ctx.BodyParser(&payload); err != nil {
+			return throwConfirmRentalHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: fmt.Sprintf("Error parsing request body: %s", err.Error()),
+			})
+		}
+
+		statusCode, rentalResp, errResp := rentalSvc.GetRental(ctx.Context(), ctx.Params("id"))
+		if errResp != nil {
+			return throwConfirmRentalHandlerError(ctx, statusCode, errResp)
+		}
+
+		if role, _ := ctx.Locals("role").(string); role != "admin" && rentalResp.UserID.String() != userID {
+			return throwConfirmRentalHandlerError(ctx, fiber.StatusNotFound, &models.ErrorResponse{
+				MessageID: messages.ERR_RENTAL_NOT_FOUND.Code,
+				Message:   messages.ERR_RENTAL_NOT_FOUND.Text,
+				Exception: "rental not found",
+			})
+		}
+
+		statusCode, rentalResp, errResp = rentalSvc.ConfirmRental(ctx.Context(), ctx.Params("id"), payload)
+		if errResp != nil {
+			return throwConfirmRentalHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_RENTAL_CONFIRM_SUCCESS.Code,
+				messages.INFO_RENTAL_CONFIRM_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(rentalResp)
+	}
+}
+
+func throwConfirmRentalHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
@@ -1,7 +1,56 @@
 package rentals
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
 
-func DeleteRentalHandler(c *fiber.Ctx) error {
-	return c.SendString("DeleteRentalHandler called, ID: " + c.Params("id"))
+	"github.com/gofiber/fiber/v2"
+)
+
+// DeleteRentalHandler cancels the rental. Rentals are never removed so that
+// the booking history stays available for invoicing and audit.
+func DeleteRentalHandler(rentalSvc svc.RentalService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		userID, ok := ctx.Locals("userID").(string)
+		if !ok || userID == "" {
+			return throwDeleteRentalHandlerError(ctx, fiber.StatusUnauthorized, &models.ErrorResponse{
+				MessageID: messages.ERR_UNAUTHORIZED.Code,
+				Message:   messages.ERR_UNAUTHORIZED.Text,
+				Exception: "userID not found in context",
+			})
+		}
+
+		statusCode, rentalResp, errResp := rentalSvc.GetRental(ctx.Context(), ctx.Params("id"))
+		if errResp != nil {
+			return throwDeleteRentalHandlerError(ctx, statusCode, errResp)
+		}
+
+		if role, _ := ctx.Locals("role").(string); role != "admin" && rentalResp.UserID.String() != userID {
+			return throwDeleteRentalHandlerError(ctx, fiber.StatusNotFound, &models.ErrorResponse{
+				MessageID: messages.ERR_RENTAL_NOT_FOUND.Code,
+				Message:   messages.ERR_RENTAL_NOT_FOUND.Text,
+				Exception: "rental not found",
+			})
+		}
+
+		statusCode, rentalResp, errResp = rentalSvc.CancelRental(ctx.Context(), ctx.Params("id"))
+		if errResp != nil {
+			return throwDeleteRentalHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_RENTAL_CANCEL_SUCCESS.Code,
+				messages.INFO_RENTAL_CANCEL_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(rentalResp)
+	}
+}
+
+func throwDeleteRentalHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
 }
@@ -1,7 +1,54 @@
 package rentals
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
 
-func GetAllRentalsHandler(ctx *fiber.Ctx) error {
-	return ctx.SendString("GetAllRentalsHandler called")
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetAllRentalsHandler lists every rental for admins (optionally filtered by
+// ?user_id=, ?vehicle_id=, ?overdue=true and ?blocked=true) and only the
+// caller's own rentals for everyone else.
+func GetAllRentalsHandler(rentalSvc svc.RentalService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		userID, ok := ctx.Locals("userID").(string)
+		if !ok || userID == "" {
+			return throwGetAllRentalsHandlerError(ctx, fiber.StatusUnauthorized, &models.ErrorResponse{
+				MessageID: messages.ERR_UNAUTHORIZED.Code,
+				Message:   messages.ERR_UNAUTHORIZED.Text,
+				Exception: "userID not found in context",
+			})
+		}
+
+		filter := models.RentalFilter{UserID: userID}
+		if role, _ := ctx.Locals("role").(string); role == "admin" {
+			filter = models.RentalFilter{
+				UserID:    ctx.Query("user_id"),
+				VehicleID: ctx.Query("vehicle_id"),
+				Overdue:   ctx.QueryBool("overdue"),
+				Blocked:   ctx.QueryBool("blocked"),
+			}
+		}
+
+		statusCode, rentalsResp, errResp := rentalSvc.ListRentals(ctx.Context(), filter)
+		if errResp != nil {
+			return throwGetAllRentalsHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_RENTAL_FETCH_SUCCESS.Code,
+				messages.INFO_RENTAL_FETCH_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(rentalsResp)
+	}
+}
+
+func throwGetAllRentalsHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
 }
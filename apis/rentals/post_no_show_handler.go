@@ -0,0 +1,43 @@
+package rentals
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PostNoShowHandler is used by staff when a customer never collected the
+// vehicle. The rental is cancelled and the no-show fee from its cancellation
+// policy is charged.
+func PostNoShowHandler(rentalSvc svc.RentalService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwPostNoShowHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		statusCode, rentalResp, errResp := rentalSvc.MarkNoShow(ctx.Context(), ctx.Params("id"))
+		if errResp != nil {
+			return throwPostNoShowHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_RENTAL_NO_SHOW_SUCCESS.Code,
+				messages.INFO_RENTAL_NO_SHOW_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(rentalResp)
+	}
+}
+
+func throwPostNoShowHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
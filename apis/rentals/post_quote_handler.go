@@ -0,0 +1,60 @@
+package rentals
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PostQuoteHandler prices a booking request exactly as POST /v1/rentals
+// would, without reserving the vehicle or charging the card.
+func PostQuoteHandler(rentalSvc svc.RentalService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		userID, ok := ctx.Locals("userID").(string)
+		if !ok || userID == "" {
+			return throwPostQuoteHandlerError(ctx, fiber.StatusUnauthorized, &models.ErrorResponse{
+				MessageID: messages.ERR_UNAUTHORIZED.Code,
+				Message:   messages.ERR_UNAUTHORIZED.Text,
+				Exception: "userID not found in context",
+			})
+		}
+
+		var payload models.CreateRentalPayload
+		if err := ctx.BodyParser(&payload); err != nil {
+			return throwPostQuoteHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: fmt.Sprintf("Error parsing request body: %s", err.Error()),
+			})
+		}
+
+		if payload.VehicleID == "" || payload.StartDate.IsZero() || payload.EndDate.IsZero() {
+			return throwPostQuoteHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: "Missing required fields in payload",
+			})
+		}
+
+		statusCode, quoteResp, errResp := rentalSvc.QuoteRental(ctx.Context(), userID, payload)
+		if errResp != nil {
+			return throwPostQuoteHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_RENTAL_QUOTE_SUCCESS.Code,
+				messages.INFO_RENTAL_QUOTE_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(quoteResp)
+	}
+}
+
+func throwPostQuoteHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
@@ -1,7 +1,58 @@
 package rentals
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
 
-func PostRentalHandler(ctx *fiber.Ctx) error {
-	return ctx.SendString("PostRentalHandler called; ID: " + ctx.Params("id"))
+	"github.com/gofiber/fiber/v2"
+)
+
+func PostRentalHandler(rentalSvc svc.RentalService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		userID, ok := ctx.Locals("userID").(string)
+		if !ok || userID == "" {
+			return throwPostRentalHandlerError(ctx, fiber.StatusUnauthorized, &models.ErrorResponse{
+				MessageID: messages.ERR_UNAUTHORIZED.Code,
+				Message:   messages.ERR_UNAUTHORIZED.Text,
+				Exception: "userID not found in context",
+			})
+		}
+
+		var payload models.CreateRentalPayload
+		if err := ctx.BodyParser(&payload); err != nil {
+			return throwPostRentalHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: fmt.Sprintf("Error parsing request body: %s", err.Error()),
+			})
+		}
+
+		if payload.VehicleID == "" || payload.StartDate.IsZero() || payload.EndDate.IsZero() {
+			return throwPostRentalHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: "Missing required fields in payload",
+			})
+		}
+
+		statusCode, rentalResp, errResp := rentalSvc.CreateRental(ctx.Context(), userID, payload)
+		if errResp != nil {
+			return throwPostRentalHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_RENTAL_CREATE_SUCCESS.Code,
+				messages.INFO_RENTAL_CREATE_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(rentalResp)
+	}
+}
+
+func throwPostRentalHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
 }
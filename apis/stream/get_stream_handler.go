@@ -0,0 +1,119 @@
+package stream
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"time"
+	"vehix/core/events"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// GetStreamHandler streams rental and vehicle changes as server-sent events.
+// Each event's id is the event ID, its name the event type and its data the
+// event envelope. Reconnecting clients send Last-Event-ID to catch up.
+// Filter with ?branch_id and ?types.
+func GetStreamHandler(streamSvc svc.StreamService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		userID, ok := ctx.Locals("userID").(string)
+		if !ok || userID == "" {
+			return throwGetStreamHandlerError(ctx, fiber.StatusUnauthorized, &models.ErrorResponse{
+				MessageID: messages.ERR_UNAUTHORIZED.Code,
+				Message:   messages.ERR_UNAUTHORIZED.Text,
+				Exception: "userID not found in context",
+			})
+		}
+		role, _ := ctx.Locals("role").(string)
+
+		var filter models.StreamFilter
+		if err := ctx.QueryParser(&filter); err != nil {
+			return throwGetStreamHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: fmt.Sprintf("Error parsing query: %s", err.Error()),
+			})
+		}
+		if filter.LastEventID == "" {
+			filter.LastEventID = ctx.Get("Last-Event-ID")
+		}
+
+		statusCode, stream, errResp := streamSvc.OpenStream(ctx.Context(), userID, role, filter)
+		if errResp != nil {
+			return throwGetStreamHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_STREAM_OPENED.Code,
+				messages.INFO_STREAM_OPENED.Text))
+
+		ctx.Set(fiber.HeaderContentType, "text/event-stream")
+		ctx.Set(fiber.HeaderCacheControl, "no-cache")
+		ctx.Set(fiber.HeaderConnection, "keep-alive")
+		ctx.Set("X-Accel-Buffering", "no") // nginx would otherwise hold events back
+		ctx.Status(statusCode).Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			defer stream.Close()
+			defer logger.Info(fmt.Sprintf("[%s] %s", messages.INFO_STREAM_CLOSED.Code, messages.INFO_STREAM_CLOSED.Text))
+
+			// A write error means the client has gone.
+			send := func(msg events.Message) error {
+				data, err := json.Marshal(msg)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", msg.ID, msg.Type, data)
+				return w.Flush()
+			}
+
+			fmt.Fprint(w, "retry: 3000\n\n")
+			if err := w.Flush(); err != nil {
+				return
+			}
+			sent := map[uuid.UUID]bool{}
+			for _, msg := range stream.Backlog {
+				sent[msg.ID] = true
+				if err := send(msg); err != nil {
+					return
+				}
+			}
+
+			heartbeat := time.NewTicker(stream.Heartbeat)
+			defer heartbeat.Stop()
+			expiry := time.NewTimer(stream.MaxDuration)
+			defer expiry.Stop()
+			for {
+				select {
+				case msg, ok := <-stream.Events:
+					if !ok {
+						return
+					}
+					if sent[msg.ID] {
+						continue
+					}
+					if err := send(msg); err != nil {
+						return
+					}
+				case <-heartbeat.C:
+					fmt.Fprint(w, ": ping\n\n")
+					if err := w.Flush(); err != nil {
+						return
+					}
+				case <-expiry.C:
+					return
+				}
+			}
+		})
+		return nil
+	}
+}
+
+func throwGetStreamHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
@@ -0,0 +1,42 @@
+package telematics
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetTelematicsDevicesHandler lists a vehicle's telematics units, revoked
+// ones included, with when each last reported.
+func GetTelematicsDevicesHandler(telematicsSvc svc.TelematicsService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwGetTelematicsDevicesHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		statusCode, devicesResp, errResp := telematicsSvc.ListDevices(ctx.Context(), ctx.Params("id"))
+		if errResp != nil {
+			return throwGetTelematicsDevicesHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_TELEMATICS_DEVICES_FETCH_SUCCESS.Code,
+				messages.INFO_TELEMATICS_DEVICES_FETCH_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(devicesResp)
+	}
+}
+
+func throwGetTelematicsDevicesHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
@@ -0,0 +1,52 @@
+package telematics
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PostTelematicsDeviceHandler registers a telematics unit for a vehicle and
+// returns the token it signs in with. The token is not shown again.
+func PostTelematicsDeviceHandler(telematicsSvc svc.TelematicsService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwPostTelematicsDeviceHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		var payload models.CreateTelematicsDevicePayload
+		if err := ctx.BodyParser(&payload); err != nil {
+			return throwPostTelematicsDeviceHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: fmt.Sprintf("Error parsing request body: %s", err.Error()),
+			})
+		}
+
+		userID, _ := ctx.Locals("userID").(string)
+		statusCode, deviceResp, errResp := telematicsSvc.CreateDevice(ctx.Context(), ctx.Params("id"), userID, payload)
+		if errResp != nil {
+			return throwPostTelematicsDeviceHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_TELEMATICS_DEVICE_CREATE_SUCCESS.Code,
+				messages.INFO_TELEMATICS_DEVICE_CREATE_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(deviceResp)
+	}
+}
+
+func throwPostTelematicsDeviceHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
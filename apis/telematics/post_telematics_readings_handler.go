@@ -0,0 +1,54 @@
+package telematics
+
+import (
+	"fmt"
+	"strings"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PostTelematicsReadingsHandler accepts a batch of readings from a telematics
+// unit, which signs in with its device token as a bearer token rather than
+// as a user.
+func PostTelematicsReadingsHandler(telematicsSvc svc.TelematicsService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		token, ok := strings.CutPrefix(ctx.Get(fiber.HeaderAuthorization), "Bearer ")
+		if !ok || token == "" {
+			return throwPostTelematicsReadingsHandlerError(ctx, fiber.StatusUnauthorized, &models.ErrorResponse{
+				MessageID: messages.ERR_INVALID_TELEMATICS_DEVICE.Code,
+				Message:   messages.ERR_INVALID_TELEMATICS_DEVICE.Text,
+				Exception: "device token missing from Authorization header",
+			})
+		}
+
+		var payload models.TelematicsBatchPayload
+		if err := ctx.BodyParser(&payload); err != nil {
+			return throwPostTelematicsReadingsHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: fmt.Sprintf("Error parsing request body: %s", err.Error()),
+			})
+		}
+
+		statusCode, batchResp, errResp := telematicsSvc.IngestReadings(ctx.Context(), token, payload)
+		if errResp != nil {
+			return throwPostTelematicsReadingsHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s: %d accepted, %d duplicate, %d rejected", messages.INFO_TELEMATICS_READINGS_INGESTED.Code,
+				messages.INFO_TELEMATICS_READINGS_INGESTED.Text, batchResp.Accepted, batchResp.Duplicates, len(batchResp.Rejected)))
+
+		return ctx.Status(statusCode).JSON(batchResp)
+	}
+}
+
+func throwPostTelematicsReadingsHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
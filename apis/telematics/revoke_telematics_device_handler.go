@@ -0,0 +1,40 @@
+package telematics
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func RevokeTelematicsDeviceHandler(telematicsSvc svc.TelematicsService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwRevokeTelematicsDeviceHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		statusCode, errResp := telematicsSvc.RevokeDevice(ctx.Context(), ctx.Params("id"))
+		if errResp != nil {
+			return throwRevokeTelematicsDeviceHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_TELEMATICS_DEVICE_REVOKE_SUCCESS.Code,
+				messages.INFO_TELEMATICS_DEVICE_REVOKE_SUCCESS.Text))
+
+		return ctx.SendStatus(statusCode)
+	}
+}
+
+func throwRevokeTelematicsDeviceHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
@@ -0,0 +1,48 @@
+package apis
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	user "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func AdminDeleteUserHandler(userSvc user.UserService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwAdminDeleteUserHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		if actorID, _ := ctx.Locals("userID").(string); actorID == ctx.Params("id") {
+			return throwAdminDeleteUserHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_SELF_MANAGEMENT.Code,
+				Message:   messages.ERR_SELF_MANAGEMENT.Text,
+				Exception: "use DELETE /v1/me to delete your own account",
+			})
+		}
+
+		statusCode, errResp := userSvc.DeleteUser(ctx.Context(), ctx.Params("id"))
+		if errResp != nil {
+			return throwAdminDeleteUserHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_USER_DELETE_SUCCESS.Code,
+				messages.INFO_USER_DELETE_SUCCESS.Text))
+
+		return ctx.SendStatus(statusCode)
+	}
+}
+
+func throwAdminDeleteUserHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
@@ -0,0 +1,56 @@
+package apis
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	user "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func AdminUpdateUserHandler(userSvc user.UserService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwAdminUpdateUserHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		var payload models.UpdateUserPayload
+		if err := ctx.BodyParser(&payload); err != nil {
+			return throwAdminUpdateUserHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: err.Error(),
+			})
+		}
+
+		if payload.Password != nil {
+			return throwAdminUpdateUserHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: "admins cannot set passwords; use POST /v1/users/:id/password-reset",
+			})
+		}
+
+		statusCode, userResp, errResp := userSvc.UpdateUser(ctx.Context(), ctx.Params("id"), &payload)
+		if errResp != nil {
+			return throwAdminUpdateUserHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_USER_UPDATE_SUCCESS.Code,
+				messages.INFO_USER_UPDATE_SUCCESS.Text))
+		return ctx.Status(statusCode).JSON(userResp)
+	}
+}
+
+func throwAdminUpdateUserHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
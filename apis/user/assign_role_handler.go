@@ -0,0 +1,56 @@
+package apis
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	user "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func AssignRoleHandler(userSvc user.UserService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwAssignRoleHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		if actorID, _ := ctx.Locals("userID").(string); actorID == ctx.Params("id") {
+			return throwAssignRoleHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_SELF_MANAGEMENT.Code,
+				Message:   messages.ERR_SELF_MANAGEMENT.Text,
+				Exception: "admins cannot change their own role",
+			})
+		}
+
+		var payload models.AssignRolePayload
+		if err := ctx.BodyParser(&payload); err != nil {
+			return throwAssignRoleHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: fmt.Sprintf("Error parsing request body: %s", err.Error()),
+			})
+		}
+
+		statusCode, userResp, errResp := userSvc.AssignRole(ctx.Context(), ctx.Params("id"), payload.Role)
+		if errResp != nil {
+			return throwAssignRoleHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_USER_ROLE_UPDATE_SUCCESS.Code,
+				messages.INFO_USER_ROLE_UPDATE_SUCCESS.Text))
+		return ctx.Status(statusCode).JSON(userResp)
+	}
+}
+
+func throwAssignRoleHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
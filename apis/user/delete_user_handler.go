@@ -28,8 +28,8 @@ func DeleteUserHandler(userSvc user.UserService) fiber.Handler {
 		}
 
 		logger.Info(
-			fmt.Sprintf("[%s] %s", messages.INFO_USER_FETCH_SUCCESS.Code,
-				messages.INFO_USER_FETCH_SUCCESS.Text))
+			fmt.Sprintf("[%s] %s", messages.INFO_USER_DELETE_SUCCESS.Code,
+				messages.INFO_USER_DELETE_SUCCESS.Text))
 
 		return ctx.SendStatus(statusCode)
 	}
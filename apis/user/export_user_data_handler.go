@@ -0,0 +1,42 @@
+package apis
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	user "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func ExportUserDataHandler(userSvc user.UserService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		userID, ok := ctx.Locals("userID").(string)
+		if !ok || userID == "" {
+			return throwExportUserDataHandlerError(ctx, fiber.StatusUnauthorized, &models.ErrorResponse{
+				MessageID: messages.ERR_UNAUTHORIZED.Code,
+				Message:   messages.ERR_UNAUTHORIZED.Text,
+				Exception: "userID not found in context",
+			})
+		}
+
+		statusCode, exportResp, errResp := userSvc.ExportUserData(ctx.Context(), userID)
+		if errResp != nil {
+			return throwExportUserDataHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_USER_EXPORT_SUCCESS.Code,
+				messages.INFO_USER_EXPORT_SUCCESS.Text))
+
+		ctx.Attachment(fmt.Sprintf("vehix-export-%s.json", userID))
+		return ctx.Status(statusCode).JSON(exportResp)
+	}
+}
+
+func throwExportUserDataHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
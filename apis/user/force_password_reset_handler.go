@@ -0,0 +1,39 @@
+package apis
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	user "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func ForcePasswordResetHandler(userSvc user.UserService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwForcePasswordResetHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		statusCode, userResp, errResp := userSvc.ForcePasswordReset(ctx.Context(), ctx.Params("id"))
+		if errResp != nil {
+			return throwForcePasswordResetHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_USER_PASSWORD_RESET_SUCCESS.Code,
+				messages.INFO_USER_PASSWORD_RESET_SUCCESS.Text))
+		return ctx.Status(statusCode).JSON(userResp)
+	}
+}
+
+func throwForcePasswordResetHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
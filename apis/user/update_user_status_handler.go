@@ -0,0 +1,63 @@
+package apis
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	user "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// UpdateUserStatusHandler suspends or reactivates the account depending on the
+// status it was registered with.
+func UpdateUserStatusHandler(userSvc user.UserService, status string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwUpdateUserStatusHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		if actorID, _ := ctx.Locals("userID").(string); actorID == ctx.Params("id") {
+			return throwUpdateUserStatusHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_SELF_MANAGEMENT.Code,
+				Message:   messages.ERR_SELF_MANAGEMENT.Text,
+				Exception: "admins cannot change their own account status",
+			})
+		}
+
+		var payload models.UpdateUserStatusPayload
+		if len(ctx.Body()) > 0 {
+			if err := ctx.BodyParser(&payload); err != nil {
+				return throwUpdateUserStatusHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+					MessageID: messages.ERR_BAD_REQUEST.Code,
+					Message:   messages.ERR_BAD_REQUEST.Text,
+					Exception: fmt.Sprintf("Error parsing request body: %s", err.Error()),
+				})
+			}
+		}
+
+		statusCode, userResp, errResp := userSvc.UpdateUserStatus(ctx.Context(), ctx.Params("id"), status, payload.Reason)
+		if errResp != nil {
+			return throwUpdateUserStatusHandlerError(ctx, statusCode, errResp)
+		}
+
+		msg := messages.INFO_USER_REACTIVATE_SUCCESS
+		if status == models.UserStatusSuspended {
+			msg = messages.INFO_USER_SUSPEND_SUCCESS
+		}
+		logger.Info(fmt.Sprintf("[%s] %s", msg.Code, msg.Text))
+
+		return ctx.Status(statusCode).JSON(userResp)
+	}
+}
+
+func throwUpdateUserStatusHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
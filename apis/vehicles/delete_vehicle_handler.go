@@ -1,7 +1,40 @@
 package vehicles
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
 
-func DeleteVehicleHandler(ctx *fiber.Ctx) error {
-	return ctx.SendString("DeleteVehicleHandler called, ID: " + ctx.Params("id"))
+	"github.com/gofiber/fiber/v2"
+)
+
+func DeleteVehicleHandler(vehicleSvc svc.VehicleService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwDeleteVehicleHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		statusCode, errResp := vehicleSvc.DeleteVehicle(ctx.Context(), ctx.Params("id"))
+		if errResp != nil {
+			return throwDeleteVehicleHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_VEHICLE_DELETE_SUCCESS.Code,
+				messages.INFO_VEHICLE_DELETE_SUCCESS.Text))
+
+		return ctx.SendStatus(statusCode)
+	}
+}
+
+func throwDeleteVehicleHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
 }
@@ -0,0 +1,41 @@
+package vehicles
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DownloadVehicleMediaHandler streams the stored file, or its thumbnail with
+// ?variant=thumbnail.
+func DownloadVehicleMediaHandler(mediaSvc svc.MediaService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		role, _ := ctx.Locals("role").(string)
+		thumbnail := ctx.Query("variant") == "thumbnail"
+
+		statusCode, content, errResp := mediaSvc.OpenMedia(ctx.Context(), ctx.Params("id"), ctx.Params("mediaID"), thumbnail, role == "admin")
+		if errResp != nil {
+			return throwDownloadVehicleMediaHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_MEDIA_FETCH_SUCCESS.Code,
+				messages.INFO_MEDIA_FETCH_SUCCESS.Text))
+
+		ctx.Set(fiber.HeaderContentType, content.ContentType)
+		ctx.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+		ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", content.FileName))
+		// fasthttp closes the body once it has been written out.
+		return ctx.Status(statusCode).SendStream(content.Body)
+	}
+}
+
+func throwDownloadVehicleMediaHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
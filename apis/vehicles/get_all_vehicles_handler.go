@@ -1,7 +1,48 @@
 package vehicles
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
 
-func GetAllVehiclesHandler(ctx *fiber.Ctx) error {
-	return ctx.SendString("GetAllVehiclesHandler called")
+	"github.com/gofiber/fiber/v2"
+)
+
+func GetAllVehiclesHandler(vehicleSvc svc.VehicleService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		var filter models.VehicleFilter
+		if err := ctx.QueryParser(&filter); err != nil {
+			return throwGetAllVehiclesHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: fmt.Sprintf("Error parsing query: %s", err.Error()),
+			})
+		}
+
+		statusCode, vehiclesResp, errResp := vehicleSvc.ListVehicles(ctx.Context(), filter)
+		if errResp != nil {
+			return throwGetAllVehiclesHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_VEHICLE_FETCH_SUCCESS.Code,
+				messages.INFO_VEHICLE_FETCH_SUCCESS.Text))
+
+		// A rented vehicle's position is its customer's; only staff see it.
+		if role, _ := ctx.Locals("role").(string); role != "admin" {
+			for i := range *vehiclesResp {
+				(*vehiclesResp)[i].Position = nil
+			}
+		}
+
+		return ctx.Status(statusCode).JSON(vehiclesResp)
+	}
+}
+
+func throwGetAllVehiclesHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
 }
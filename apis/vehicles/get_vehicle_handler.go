@@ -1,7 +1,37 @@
 package vehicles
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
 
-func GetVehicleByIDHandler(ctx *fiber.Ctx) error {
-	return ctx.SendString("GetVehicleByIDHandler called, ID: " + ctx.Params("id"))
+	"github.com/gofiber/fiber/v2"
+)
+
+func GetVehicleByIDHandler(vehicleSvc svc.VehicleService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		statusCode, vehicleResp, errResp := vehicleSvc.GetVehicle(ctx.Context(), ctx.Params("id"))
+		if errResp != nil {
+			return throwGetVehicleByIDHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_VEHICLE_FETCH_SUCCESS.Code,
+				messages.INFO_VEHICLE_FETCH_SUCCESS.Text))
+
+		// A rented vehicle's position is its customer's; only staff see it.
+		if role, _ := ctx.Locals("role").(string); role != "admin" {
+			vehicleResp.Position = nil
+		}
+
+		return ctx.Status(statusCode).JSON(vehicleResp)
+	}
+}
+
+func throwGetVehicleByIDHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
 }
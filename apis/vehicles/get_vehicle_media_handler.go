@@ -0,0 +1,37 @@
+package vehicles
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetVehicleMediaHandler lists a vehicle's media. Registration and insurance
+// documents and damage photos are only listed for admins; everyone else sees
+// photos.
+func GetVehicleMediaHandler(mediaSvc svc.MediaService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		role, _ := ctx.Locals("role").(string)
+
+		statusCode, mediaResp, errResp := mediaSvc.ListMedia(ctx.Context(), ctx.Params("id"), role == "admin")
+		if errResp != nil {
+			return throwGetVehicleMediaHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_MEDIA_FETCH_SUCCESS.Code,
+				messages.INFO_MEDIA_FETCH_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(mediaResp)
+	}
+}
+
+func throwGetVehicleMediaHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
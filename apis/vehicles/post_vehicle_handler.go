@@ -1,7 +1,57 @@
 package vehicles
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
 
-func PostVehiclesHandler(ctx *fiber.Ctx) error {
-	return ctx.SendString("PostVehiclesHandler called")
+	"github.com/gofiber/fiber/v2"
+)
+
+func PostVehiclesHandler(vehicleSvc svc.VehicleService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwPostVehiclesHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		var payload models.CreateVehiclePayload
+		if err := ctx.BodyParser(&payload); err != nil {
+			return throwPostVehiclesHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: fmt.Sprintf("Error parsing request body: %s", err.Error()),
+			})
+		}
+
+		if payload.Make == "" || payload.Model == "" || payload.Year == 0 || payload.VIN == "" || payload.LicensePlate == "" {
+			return throwPostVehiclesHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: "Missing required fields in payload",
+			})
+		}
+
+		statusCode, vehicleResp, errResp := vehicleSvc.CreateVehicle(ctx.Context(), payload)
+		if errResp != nil {
+			return throwPostVehiclesHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_VEHICLE_CREATE_SUCCESS.Code,
+				messages.INFO_VEHICLE_CREATE_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(vehicleResp)
+	}
+}
+
+func throwPostVehiclesHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
 }
@@ -1,7 +1,49 @@
 package vehicles
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
 
-func UpdateVehicleHandler(ctx *fiber.Ctx) error {
-	return ctx.SendString("UpdateVehicleHandler called, ID: " + ctx.Params("id"))
+	"github.com/gofiber/fiber/v2"
+)
+
+func UpdateVehicleHandler(vehicleSvc svc.VehicleService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		if role, ok := ctx.Locals("role").(string); role != "admin" || !ok {
+			return throwUpdateVehicleHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "user does not have admin privileges",
+			})
+		}
+
+		var payload models.UpdateVehiclePayload
+		if err := ctx.BodyParser(&payload); err != nil {
+			return throwUpdateVehicleHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: err.Error(),
+			})
+		}
+
+		statusCode, vehicleResp, errResp := vehicleSvc.UpdateVehicle(ctx.Context(), ctx.Params("id"), &payload)
+		if errResp != nil {
+			return throwUpdateVehicleHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_VEHICLE_UPDATE_SUCCESS.Code,
+				messages.INFO_VEHICLE_UPDATE_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(vehicleResp)
+	}
+}
+
+func throwUpdateVehicleHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
 }
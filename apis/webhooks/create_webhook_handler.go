@@ -0,0 +1,71 @@
+package webhooks
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateWebhookHandler subscribes the calling API client to events. The
+// response carries the signing secret, which is not shown again.
+func CreateWebhookHandler(webhookSvc svc.WebhookService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		// Webhooks belong to API clients. Admins signed in as themselves can
+		// manage every client's.
+		apiKeyID, _ := ctx.Locals("apiKeyID").(string)
+		if role, _ := ctx.Locals("role").(string); apiKeyID == "" && role != "admin" {
+			return throwCreateWebhookHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "webhooks are managed with an API key",
+			})
+		}
+
+		userID, ok := ctx.Locals("userID").(string)
+		if !ok || userID == "" {
+			return throwCreateWebhookHandlerError(ctx, fiber.StatusUnauthorized, &models.ErrorResponse{
+				MessageID: messages.ERR_UNAUTHORIZED.Code,
+				Message:   messages.ERR_UNAUTHORIZED.Text,
+				Exception: "userID not found in context",
+			})
+		}
+
+		var payload models.CreateWebhookPayload
+		if err := ctx.BodyParser(&payload); err != nil {
+			return throwCreateWebhookHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: fmt.Sprintf("Error parsing request body: %s", err.Error()),
+			})
+		}
+
+		if payload.URL == "" || (apiKeyID == "" && payload.APIKeyID == "") {
+			return throwCreateWebhookHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: "Missing required fields in payload",
+			})
+		}
+
+		statusCode, webhookResp, errResp := webhookSvc.CreateWebhook(ctx.Context(), apiKeyID, userID, payload)
+		if errResp != nil {
+			return throwCreateWebhookHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_WEBHOOK_CREATE_SUCCESS.Code,
+				messages.INFO_WEBHOOK_CREATE_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(webhookResp)
+	}
+}
+
+func throwCreateWebhookHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
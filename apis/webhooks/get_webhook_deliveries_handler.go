@@ -0,0 +1,54 @@
+package webhooks
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetWebhookDeliveriesHandler lists a webhook's deliveries, newest first,
+// with the outcome of each one's latest attempt. Filter with ?status.
+func GetWebhookDeliveriesHandler(webhookSvc svc.WebhookService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		// Webhooks belong to API clients. Admins signed in as themselves can
+		// manage every client's.
+		apiKeyID, _ := ctx.Locals("apiKeyID").(string)
+		if role, _ := ctx.Locals("role").(string); apiKeyID == "" && role != "admin" {
+			return throwGetWebhookDeliveriesHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "webhooks are managed with an API key",
+			})
+		}
+
+		var filter models.WebhookDeliveryFilter
+		if err := ctx.QueryParser(&filter); err != nil {
+			return throwGetWebhookDeliveriesHandlerError(ctx, fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: fmt.Sprintf("Error parsing query: %s", err.Error()),
+			})
+		}
+
+		statusCode, deliveriesResp, errResp := webhookSvc.ListWebhookDeliveries(ctx.Context(), ctx.Params("id"), apiKeyID, filter)
+		if errResp != nil {
+			return throwGetWebhookDeliveriesHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_WEBHOOK_DELIVERIES_FETCH_SUCCESS.Code,
+				messages.INFO_WEBHOOK_DELIVERIES_FETCH_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(deliveriesResp)
+	}
+}
+
+func throwGetWebhookDeliveriesHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
@@ -0,0 +1,44 @@
+package webhooks
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PingWebhookHandler queues a test delivery to the webhook's URL.
+func PingWebhookHandler(webhookSvc svc.WebhookService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		// Webhooks belong to API clients. Admins signed in as themselves can
+		// manage every client's.
+		apiKeyID, _ := ctx.Locals("apiKeyID").(string)
+		if role, _ := ctx.Locals("role").(string); apiKeyID == "" && role != "admin" {
+			return throwPingWebhookHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "webhooks are managed with an API key",
+			})
+		}
+
+		statusCode, deliveryResp, errResp := webhookSvc.PingWebhook(ctx.Context(), ctx.Params("id"), apiKeyID)
+		if errResp != nil {
+			return throwPingWebhookHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_WEBHOOK_PING_SUCCESS.Code,
+				messages.INFO_WEBHOOK_PING_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(deliveryResp)
+	}
+}
+
+func throwPingWebhookHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
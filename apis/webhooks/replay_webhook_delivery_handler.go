@@ -0,0 +1,45 @@
+package webhooks
+
+import (
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	svc "vehix/core/service"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ReplayWebhookDeliveryHandler sends a past delivery again, e.g. after the
+// receiver has been fixed.
+func ReplayWebhookDeliveryHandler(webhookSvc svc.WebhookService) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+
+		// Webhooks belong to API clients. Admins signed in as themselves can
+		// manage every client's.
+		apiKeyID, _ := ctx.Locals("apiKeyID").(string)
+		if role, _ := ctx.Locals("role").(string); apiKeyID == "" && role != "admin" {
+			return throwReplayWebhookDeliveryHandlerError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+				MessageID: messages.ERR_FORBIDDEN.Code,
+				Message:   messages.ERR_FORBIDDEN.Text,
+				Exception: "webhooks are managed with an API key",
+			})
+		}
+
+		statusCode, deliveryResp, errResp := webhookSvc.ReplayWebhookDelivery(ctx.Context(), ctx.Params("id"), ctx.Params("deliveryID"), apiKeyID)
+		if errResp != nil {
+			return throwReplayWebhookDeliveryHandlerError(ctx, statusCode, errResp)
+		}
+
+		logger.Info(
+			fmt.Sprintf("[%s] %s", messages.INFO_WEBHOOK_REPLAY_SUCCESS.Code,
+				messages.INFO_WEBHOOK_REPLAY_SUCCESS.Text))
+
+		return ctx.Status(statusCode).JSON(deliveryResp)
+	}
+}
+
+func throwReplayWebhookDeliveryHandlerError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
+	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
+	return ctx.Status(statusCode).JSON(errResp)
+}
@@ -0,0 +1,123 @@
+package database
+
+import (
+	"vehix/models"
+
+	"gorm.io/gorm"
+)
+
+// Migrate brings the schema up to date. AutoMigrate only ever adds, so changes
+// it cannot express (dropping superseded indexes, triggers) live here too.
+func Migrate(db *gorm.DB) error {
+	err := db.AutoMigrate(
+		&models.User{},
+		&models.Branch{},
+		&models.OneWayFee{},
+		&models.Vehicle{},
+		&models.VehicleMedia{},
+		&models.MaintenanceWindow{},
+		&models.ServiceInterval{},
+		&models.PromoCode{},
+		&models.DriverProfile{},
+		&models.VehicleClass{},
+		&models.Rental{},
+		&models.Extra{},
+		&models.ExtraStock{},
+		&models.RentalExtra{},
+		&models.RentalDriver{},
+		&models.RentalModification{},
+		&models.Job{},
+		&models.RecurringJob{},
+		&models.OutboxEvent{},
+		&models.WebhookSubscription{},
+		&models.WebhookDelivery{},
+		&models.NotificationPreference{},
+		&models.Notification{},
+		&models.TelematicsDevice{},
+		&models.TelematicsReading{},
+		&models.Inspection{},
+		&models.DamageItem{},
+		&models.RentalCharge{},
+		&models.PaymentEvent{},
+		&models.Invoice{},
+		&models.InvoiceLine{},
+		&models.InvoiceSequence{},
+		&models.APIKey{},
+		&models.AuditLog{},
+	)
+	if err != nil {
+		return err
+	}
+
+	// The email index became partial so soft-deleted accounts don't block
+	// re-registration with the same address.
+	if db.Migrator().HasIndex(&models.User{}, "idx_users_email") {
+		if err := db.Migrator().DropIndex(&models.User{}, "idx_users_email"); err != nil {
+			return err
+		}
+	}
+
+	// Issued invoices are legal documents; like the audit trail they can only
+	// be added to. Corrections go through credit notes.
+	if err := db.Exec(`
+		CREATE OR REPLACE FUNCTION invoices_immutable() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'issued invoices cannot be changed';
+		END;
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS invoices_no_update ON invoices;
+		CREATE TRIGGER invoices_no_update BEFORE UPDATE OR DELETE ON invoices
+			FOR EACH ROW EXECUTE FUNCTION invoices_immutable();
+
+		DROP TRIGGER IF EXISTS invoice_lines_no_update ON invoice_lines;
+		CREATE TRIGGER invoice_lines_no_update BEFORE UPDATE OR DELETE ON invoice_lines
+			FOR EACH ROW EXECUTE FUNCTION invoices_immutable();
+	`).Error; err != nil {
+		return err
+	}
+
+	// Readings arrive in time order, so a BRIN index makes pruning by time
+	// cheap at a fraction of a B-tree's size.
+	if err := db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_telematics_readings_recorded_brin
+			ON telematics_readings USING brin (recorded_at);
+	`).Error; err != nil {
+		return err
+	}
+
+	// Every new outbox row is signalled to the API replicas, which push it
+	// to their open event streams. The relay still publishes it to brokers.
+	if err := db.Exec(`
+		CREATE OR REPLACE FUNCTION outbox_events_notify() RETURNS trigger AS $$
+		BEGIN
+			PERFORM pg_notify('outbox_events', NEW.id::text);
+			RETURN NEW;
+		END;
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS outbox_events_notify ON outbox_events;
+		CREATE TRIGGER outbox_events_notify AFTER INSERT ON outbox_events
+			FOR EACH ROW EXECUTE FUNCTION outbox_events_notify();
+	`).Error; err != nil {
+		return err
+	}
+
+	// The audit trail is append-only at the database level as well, so even a
+	// compromised service account can't quietly rewrite it.
+	return db.Exec(`
+		CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'audit_logs is append-only';
+		END;
+		$$ LANGUAGE plpgsql;
+
+		DROP TRIGGER IF EXISTS audit_logs_no_update ON audit_logs;
+		CREATE TRIGGER audit_logs_no_update BEFORE UPDATE OR DELETE ON audit_logs
+			FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only();
+
+		DROP TRIGGER IF EXISTS audit_logs_no_truncate ON audit_logs;
+		CREATE TRIGGER audit_logs_no_truncate BEFORE TRUNCATE ON audit_logs
+			FOR EACH STATEMENT EXECUTE FUNCTION audit_logs_append_only();
+	`).Error
+}
@@ -0,0 +1,148 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"time"
+	"vehix/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Event types published to other services. Names are part of the contract
+// with consumers; add new ones rather than changing these.
+const (
+	UserRegistered  = "user.registered"
+	UserDeleted     = "user.deleted"
+	RentalCreated   = "rental.created"
+	RentalModified  = "rental.modified"
+	RentalCancelled = "rental.cancelled"
+	RentalReturned  = "rental.returned"
+	RentalOverdue   = "rental.overdue"
+	RentalBlocked   = "rental.blocked"
+	VehicleRetired  = "vehicle.retired"
+	VehicleAdded    = "vehicle.added"
+	VehicleUpdated  = "vehicle.updated"
+)
+
+// Types lists every event type, for validating subscriptions.
+var Types = []string{UserRegistered, UserDeleted, RentalCreated, RentalModified, RentalCancelled, RentalReturned, RentalOverdue, RentalBlocked, VehicleRetired, VehicleAdded, VehicleUpdated}
+
+// Message is what publishers put on the wire. Delivery is at least once, so
+// consumers should ignore IDs they have already handled.
+type Message struct {
+	ID            uuid.UUID      `json:"id"`
+	Type          string         `json:"type"`
+	AggregateType string         `json:"aggregate_type"`
+	AggregateID   string         `json:"aggregate_id"`
+	OccurredAt    time.Time      `json:"occurred_at"`
+	Data          models.JSONMap `json:"data"`
+}
+
+// Publisher hands messages to a broker. Publish returns only once the broker
+// has accepted the message; an error means it may or may not have been.
+type Publisher interface {
+	Publish(ctx context.Context, msg Message) error
+	Close() error
+}
+
+// Fanout hands each message to every publisher in turn. A failure retries
+// the message for all of them, so each still sees it at least once.
+type Fanout []Publisher
+
+func (f Fanout) Publish(ctx context.Context, msg Message) error {
+	for _, p := range f {
+		if err := p.Publish(ctx, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f Fanout) Close() error {
+	var errs []error
+	for _, p := range f {
+		errs = append(errs, p.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// Record adds an event to the outbox. Pass the transaction making the change
+// so the event is only published if the change commits.
+func Record(tx *gorm.DB, eventType, aggregateType, aggregateID string, data any) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	payload := models.JSONMap{}
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return err
+	}
+
+	return tx.Create(&models.OutboxEvent{
+		Type:          eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       payload,
+		NextAttemptAt: time.Now(),
+	}).Error
+}
+
+// FromOutbox is the message published for an outbox row.
+func FromOutbox(e *models.OutboxEvent) Message {
+	return Message{
+		ID:            e.EventID,
+		Type:          e.Type,
+		AggregateType: e.AggregateType,
+		AggregateID:   e.AggregateID,
+		OccurredAt:    e.CreatedAt,
+		Data:          e.Payload,
+	}
+}
+
+// Connect builds the publisher selected by EVENT_PUBLISHER ("stdout", the
+// default, "memory", "nats" or "kafka") from the environment.
+func Connect() Publisher {
+	switch os.Getenv("EVENT_PUBLISHER") {
+	case "", "stdout":
+		return NewStdoutPublisher()
+	case "memory":
+		return NewMemoryPublisher()
+	case "nats":
+		publisher, err := NewNATSPublisher(NATSConfig{
+			URL:           envOrDefault("NATS_URL", "nats://localhost:4222"),
+			SubjectPrefix: envOrDefault("NATS_SUBJECT_PREFIX", "vehix."),
+			Token:         os.Getenv("NATS_TOKEN"),
+		})
+		if err != nil {
+			log.Fatal("failed to initialise NATS publisher:", err)
+		}
+		return publisher
+	case "kafka":
+		publisher, err := NewKafkaPublisher(KafkaConfig{
+			RESTProxyURL: os.Getenv("KAFKA_REST_URL"),
+			Topic:        envOrDefault("KAFKA_TOPIC", "vehix.events"),
+			Username:     os.Getenv("KAFKA_REST_USERNAME"),
+			Password:     os.Getenv("KAFKA_REST_PASSWORD"),
+		})
+		if err != nil {
+			log.Fatal("failed to initialise Kafka publisher:", err)
+		}
+		return publisher
+	default:
+		log.Fatalf("unknown EVENT_PUBLISHER %q", os.Getenv("EVENT_PUBLISHER"))
+		return nil
+	}
+}
+
+func envOrDefault(name, fallback string) string {
+	if v := strings.TrimSpace(os.Getenv(name)); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,102 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type KafkaConfig struct {
+	RESTProxyURL string // e.g. http://localhost:8082
+	Topic        string
+	Username     string // optional basic auth for the proxy
+	Password     string
+}
+
+// KafkaPublisher produces to Kafka through a REST Proxy speaking the v2 API
+// (Confluent REST Proxy and compatible gateways). Records are keyed by
+// aggregate ID so the events about one rental or user share a partition and
+// stay in order.
+type KafkaPublisher struct {
+	cfg      KafkaConfig
+	endpoint string
+	client   *http.Client
+}
+
+func NewKafkaPublisher(cfg KafkaConfig) (*KafkaPublisher, error) {
+	if cfg.RESTProxyURL == "" || cfg.Topic == "" {
+		return nil, errors.New("KAFKA_REST_URL and KAFKA_TOPIC are required")
+	}
+	base, err := url.Parse(strings.TrimRight(cfg.RESTProxyURL, "/"))
+	if err != nil || base.Host == "" {
+		return nil, fmt.Errorf("invalid Kafka REST Proxy URL %q", cfg.RESTProxyURL)
+	}
+	return &KafkaPublisher{
+		cfg:      cfg,
+		endpoint: base.String() + "/topics/" + url.PathEscape(cfg.Topic),
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (p *KafkaPublisher) Publish(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(map[string]any{
+		"records": []map[string]any{{"key": msg.AggregateID, "value": msg}},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+	if p.cfg.Username != "" {
+		req.SetBasicAuth(p.cfg.Username, p.cfg.Password)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("kafka rest proxy: %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+	}
+
+	// The proxy answers 200 even when a record fails; the per-record result
+	// carries the error.
+	var result struct {
+		Offsets []struct {
+			ErrorCode *int    `json:"error_code"`
+			Error     *string `json:"error"`
+		} `json:"offsets"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("kafka rest proxy: decoding response: %w", err)
+	}
+	for _, offset := range result.Offsets {
+		if offset.ErrorCode != nil {
+			reason := ""
+			if offset.Error != nil {
+				reason = *offset.Error
+			}
+			return fmt.Errorf("kafka rest proxy: error %d: %s", *offset.ErrorCode, reason)
+		}
+	}
+	return nil
+}
+
+func (p *KafkaPublisher) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,36 @@
+package events
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// MemoryPublisher keeps published messages in memory, for tests and local
+// runs that want to inspect what would have been sent.
+type MemoryPublisher struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (p *MemoryPublisher) Publish(_ context.Context, msg Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.messages = append(p.messages, msg)
+	return nil
+}
+
+// Messages returns everything published so far, oldest first.
+func (p *MemoryPublisher) Messages() []Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.messages)
+}
+
+func (p *MemoryPublisher) Close() error {
+	return nil
+}
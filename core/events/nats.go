@@ -0,0 +1,216 @@
+package events
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+type NATSConfig struct {
+	URL           string // nats://[user:pass@]host:port, or tls:// for TLS
+	SubjectPrefix string // prepended to the event type, e.g. "vehix." publishes to vehix.rental.created
+	Token         string
+}
+
+// NATSPublisher speaks the NATS client protocol directly. Each message carries
+// a Nats-Msg-Id header with the event ID, which JetStream streams use to drop
+// duplicates, and is followed by a PING. The server answers PONG only after
+// processing everything before it, so the PONG confirms the message arrived.
+type NATSPublisher struct {
+	cfg     NATSConfig
+	addr    string
+	host    string
+	useTLS  bool
+	user    string
+	pass    string
+	timeout time.Duration
+	mu      sync.Mutex
+	conn    net.Conn
+	reader  *bufio.Reader
+}
+
+func NewNATSPublisher(cfg NATSConfig) (*NATSPublisher, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid NATS URL %q", cfg.URL)
+	}
+	port := u.Port()
+	if port == "" {
+		port = "4222"
+	}
+	p := &NATSPublisher{
+		cfg:     cfg,
+		addr:    net.JoinHostPort(u.Hostname(), port),
+		host:    u.Hostname(),
+		useTLS:  u.Scheme == "tls",
+		timeout: 10 * time.Second,
+	}
+	if u.User != nil {
+		p.user = u.User.Username()
+		p.pass, _ = u.User.Password()
+	}
+	return p, nil
+}
+
+// Publish connects on first use and again after any failure, so a broker
+// outage only delays events.
+func (p *NATSPublisher) Publish(ctx context.Context, msg Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.publish(ctx, msg); err != nil {
+		p.disconnect()
+		return err
+	}
+	return nil
+}
+
+func (p *NATSPublisher) publish(ctx context.Context, msg Message) error {
+	if p.conn == nil {
+		if err := p.connect(ctx); err != nil {
+			return err
+		}
+	}
+	if err := p.conn.SetDeadline(p.deadline(ctx)); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	header := "NATS/1.0\r\nNats-Msg-Id: " + msg.ID.String() + "\r\n\r\n"
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "HPUB %s%s %d %d\r\n", p.cfg.SubjectPrefix, msg.Type, len(header), len(header)+len(body))
+	buf.WriteString(header)
+	buf.Write(body)
+	buf.WriteString("\r\nPING\r\n")
+	if _, err := p.conn.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	return p.awaitPong()
+}
+
+func (p *NATSPublisher) connect(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: p.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", p.addr)
+	if err != nil {
+		return err
+	}
+	p.conn, p.reader = conn, bufio.NewReader(conn)
+	if err := conn.SetDeadline(p.deadline(ctx)); err != nil {
+		return err
+	}
+
+	line, err := p.readLine()
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		return fmt.Errorf("nats: unexpected greeting %q", line)
+	}
+	var info struct {
+		TLSRequired bool `json:"tls_required"`
+		Headers     bool `json:"headers"`
+	}
+	if err := json.Unmarshal([]byte(line[len("INFO "):]), &info); err != nil {
+		return fmt.Errorf("nats: parsing server info: %w", err)
+	}
+	if !info.Headers {
+		return errors.New("nats: server does not support message headers")
+	}
+
+	if p.useTLS || info.TLSRequired {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: p.host, MinVersion: tls.VersionTLS12})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return err
+		}
+		p.conn, p.reader = tlsConn, bufio.NewReader(tlsConn)
+	}
+
+	options := map[string]any{
+		"verbose":      false,
+		"pedantic":     false,
+		"tls_required": p.useTLS || info.TLSRequired,
+		"name":         "vehix",
+		"lang":         "go",
+		"version":      "1.0.0",
+		"protocol":     1,
+		"headers":      true,
+	}
+	if p.user != "" {
+		options["user"], options["pass"] = p.user, p.pass
+	}
+	if p.cfg.Token != "" {
+		options["auth_token"] = p.cfg.Token
+	}
+	connect, err := json.Marshal(options)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(p.conn, "CONNECT %s\r\nPING\r\n", connect); err != nil {
+		return err
+	}
+	return p.awaitPong()
+}
+
+// awaitPong reads until the server's PONG, answering its own PINGs and
+// surfacing any -ERR sent in between.
+func (p *NATSPublisher) awaitPong() error {
+	for {
+		line, err := p.readLine()
+		if err != nil {
+			return err
+		}
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := p.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("nats: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
+
+func (p *NATSPublisher) readLine() (string, error) {
+	line, err := p.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (p *NATSPublisher) deadline(ctx context.Context) time.Time {
+	deadline := time.Now().Add(p.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		return d
+	}
+	return deadline
+}
+
+func (p *NATSPublisher) disconnect() {
+	if p.conn != nil {
+		p.conn.Close()
+		p.conn, p.reader = nil, nil
+	}
+}
+
+func (p *NATSPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.disconnect()
+	return nil
+}
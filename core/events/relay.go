@@ -0,0 +1,132 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	"vehix/core/scheduler"
+	"vehix/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Failed publishes are retried after retryBase, doubling up to retryCap. There
+// is no give-up point: the broker coming back is the only fix, and dropping
+// an event would break at-least-once delivery.
+const (
+	retryBase = 5 * time.Second
+	retryCap  = 15 * time.Minute
+)
+
+// Relay moves events from the outbox to the publisher. Any number of relays
+// can share a database; each pending event is locked by one at a time.
+type Relay struct {
+	db        *gorm.DB
+	publisher Publisher
+	batchSize int
+	poll      time.Duration
+	retention time.Duration
+}
+
+func NewRelay(db *gorm.DB, publisher Publisher) *Relay {
+	return &Relay{
+		db:        db,
+		publisher: publisher,
+		batchSize: envInt("OUTBOX_BATCH_SIZE", 100),
+		poll:      time.Duration(envInt("OUTBOX_POLL_MILLISECONDS", 1000)) * time.Millisecond,
+		retention: time.Duration(envInt("OUTBOX_RETENTION_HOURS", 168)) * time.Hour,
+	}
+}
+
+// Run publishes events until ctx is cancelled. Published events are kept for
+// the retention period so a consumer can be replayed from the table, then
+// deleted.
+func (r *Relay) Run(ctx context.Context) {
+	logger.Info(fmt.Sprintf("[%s] %s", messages.INFO_EVENT_RELAY_STARTED.Code, messages.INFO_EVENT_RELAY_STARTED.Text))
+
+	go scheduler.Every(ctx, "prune-outbox", time.Hour, func(ctx context.Context) error {
+		return r.db.WithContext(ctx).
+			Where("published_at < ?", time.Now().Add(-r.retention)).
+			Delete(&models.OutboxEvent{}).Error
+	})
+
+	for ctx.Err() == nil {
+		claimed, err := r.relayBatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.Error(fmt.Sprintf("[%s] %s: %s", messages.ERR_EVENT_PUBLISH.Code,
+				messages.ERR_EVENT_PUBLISH.Text, err.Error()))
+		}
+		if claimed < r.batchSize {
+			select {
+			case <-ctx.Done():
+			case <-time.After(r.poll):
+			}
+		}
+	}
+}
+
+// relayBatch publishes up to one batch of due events and records the outcome
+// of each. The rows stay locked until the outcomes are saved; if the process
+// dies first they are published again.
+func (r *Relay) relayBatch(ctx context.Context) (int, error) {
+	var pending []models.OutboxEvent
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// An event waits while an earlier one about the same aggregate is
+		// unpublished, so consumers see each aggregate's events in order.
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("published_at IS NULL AND next_attempt_at <= ?", time.Now()).
+			Where(`NOT EXISTS (SELECT 1 FROM outbox_events earlier
+				WHERE earlier.aggregate_type = outbox_events.aggregate_type
+				AND earlier.aggregate_id = outbox_events.aggregate_id
+				AND earlier.published_at IS NULL AND earlier.id < outbox_events.id)`).
+			Order("id").Limit(r.batchSize).Find(&pending).Error; err != nil {
+			return err
+		}
+
+		for i := range pending {
+			event := &pending[i]
+			if publishErr := r.publisher.Publish(ctx, FromOutbox(event)); publishErr != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				event.Attempts++
+				logger.Warn(fmt.Sprintf("[%s] %s: %s %s, attempt %d: %s", messages.ERR_EVENT_PUBLISH.Code,
+					messages.ERR_EVENT_PUBLISH.Text, event.Type, event.EventID, event.Attempts, publishErr.Error()))
+				if err := tx.Model(event).Updates(map[string]any{
+					"attempts":        event.Attempts,
+					"last_error":      publishErr.Error(),
+					"next_attempt_at": time.Now().Add(retryDelay(event.Attempts)),
+				}).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if err := tx.Model(event).Update("published_at", time.Now()).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return len(pending), err
+}
+
+func retryDelay(attempts int) time.Duration {
+	delay := retryBase
+	for i := 1; i < attempts && delay < retryCap; i++ {
+		delay *= 2
+	}
+	return min(delay, retryCap)
+}
+
+func envInt(name string, fallback int) int {
+	v, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || v <= 0 {
+		return fallback
+	}
+	return v
+}
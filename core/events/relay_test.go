@@ -0,0 +1,205 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"vehix/core/database/dbtest"
+	"vehix/models"
+
+	"gorm.io/gorm"
+)
+
+// flakyPublisher hands messages on to a MemoryPublisher unless fail says the
+// message should fail this time.
+type flakyPublisher struct {
+	*MemoryPublisher
+	fail func(msg Message) error
+}
+
+func (p *flakyPublisher) Publish(ctx context.Context, msg Message) error {
+	if p.fail != nil {
+		if err := p.fail(msg); err != nil {
+			return err
+		}
+	}
+	return p.MemoryPublisher.Publish(ctx, msg)
+}
+
+func recordEvents(t *testing.T, db *gorm.DB, events ...[2]string) {
+	t.Helper()
+	for _, e := range events {
+		if err := Record(db, RentalModified, "rental", e[0], map[string]string{"step": e[1]}); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+}
+
+func steps(msgs []Message) []string {
+	var out []string
+	for _, m := range msgs {
+		out = append(out, m.AggregateID+":"+m.Data["step"].(string))
+	}
+	return out
+}
+
+func outboxRows(t *testing.T, db *gorm.DB) []models.OutboxEvent {
+	t.Helper()
+	var rows []models.OutboxEvent
+	if err := db.Order("id").Find(&rows).Error; err != nil {
+		t.Fatalf("loading outbox: %v", err)
+	}
+	return rows
+}
+
+// makeDue brings failed events' retries forward to now.
+func makeDue(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	if err := db.Model(&models.OutboxEvent{}).Where("published_at IS NULL").
+		Update("next_attempt_at", time.Now()).Error; err != nil {
+		t.Fatalf("making events due: %v", err)
+	}
+}
+
+// drain relays batches until nothing is left to claim.
+func drain(t *testing.T, r *Relay) {
+	t.Helper()
+	for i := 0; i < 10; i++ {
+		claimed, err := r.relayBatch(context.Background())
+		if err != nil {
+			t.Fatalf("relayBatch: %v", err)
+		}
+		if claimed == 0 {
+			return
+		}
+	}
+	t.Fatal("relay never ran out of events")
+}
+
+func TestRelayPublishesEachEventOnce(t *testing.T) {
+	db := dbtest.Open(t)
+	publisher := NewMemoryPublisher()
+	relay := NewRelay(db, publisher)
+
+	recordEvents(t, db, [2]string{"a", "1"}, [2]string{"b", "1"}, [2]string{"a", "2"})
+	drain(t, relay)
+
+	if got := steps(publisher.Messages()); len(got) != 3 {
+		t.Fatalf("published %v, want three events", got)
+	}
+	for _, row := range outboxRows(t, db) {
+		if row.PublishedAt == nil {
+			t.Errorf("event %d left unpublished", row.ID)
+		}
+	}
+
+	drain(t, relay)
+	if got := len(publisher.Messages()); got != 3 {
+		t.Errorf("published %d messages after a second pass, want still 3", got)
+	}
+}
+
+func TestRelayRepublishesWhenOutcomeIsLost(t *testing.T) {
+	db := dbtest.Open(t)
+	recordEvents(t, db, [2]string{"a", "1"})
+
+	// The broker takes the message but the relay stops before saving that it
+	// did; the event must go out again rather than be lost.
+	ctx, cancel := context.WithCancel(context.Background())
+	publisher := &flakyPublisher{MemoryPublisher: NewMemoryPublisher()}
+	publisher.fail = func(msg Message) error {
+		publisher.MemoryPublisher.Publish(ctx, msg)
+		cancel()
+		return ctx.Err()
+	}
+	if _, err := NewRelay(db, publisher).relayBatch(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("relayBatch: err = %v, want context.Canceled", err)
+	}
+	if rows := outboxRows(t, db); rows[0].PublishedAt != nil || rows[0].Attempts != 0 {
+		t.Fatalf("interrupted event = %+v, want unpublished and no attempt counted", rows[0])
+	}
+
+	publisher.fail = nil
+	drain(t, NewRelay(db, publisher))
+	msgs := publisher.Messages()
+	if len(msgs) != 2 || msgs[0].ID != msgs[1].ID {
+		t.Fatalf("published %v, want the same event twice", steps(msgs))
+	}
+	if rows := outboxRows(t, db); rows[0].PublishedAt == nil {
+		t.Error("event still unpublished after a successful relay")
+	}
+}
+
+func TestRelayFailureLeavesEventUnsent(t *testing.T) {
+	db := dbtest.Open(t)
+	recordEvents(t, db, [2]string{"a", "1"})
+
+	publisher := &flakyPublisher{MemoryPublisher: NewMemoryPublisher()}
+	publisher.fail = func(Message) error { return errors.New("broker unavailable") }
+	relay := NewRelay(db, publisher)
+
+	start := time.Now()
+	if _, err := relay.relayBatch(context.Background()); err != nil {
+		t.Fatalf("relayBatch: %v", err)
+	}
+	row := outboxRows(t, db)[0]
+	if row.PublishedAt != nil {
+		t.Error("failed event marked published")
+	}
+	if row.Attempts != 1 || row.LastError != "broker unavailable" {
+		t.Errorf("attempts = %d, last error = %q; want 1 and the publish error", row.Attempts, row.LastError)
+	}
+	if row.NextAttemptAt.Before(start.Add(retryBase)) {
+		t.Errorf("next attempt at %s, want at least %s after the failure", row.NextAttemptAt, retryBase)
+	}
+
+	// Not due yet, so the next pass leaves it alone.
+	if claimed, err := relay.relayBatch(context.Background()); err != nil || claimed != 0 {
+		t.Errorf("relayBatch before retry is due: claimed %d, err %v; want nothing", claimed, err)
+	}
+	if got := publisher.Messages(); len(got) != 0 {
+		t.Errorf("published %v while the broker was failing", steps(got))
+	}
+
+	publisher.fail = nil
+	makeDue(t, db)
+	drain(t, relay)
+	if got := publisher.Messages(); len(got) != 1 {
+		t.Errorf("published %v once the broker was back, want the event once", steps(got))
+	}
+}
+
+func TestRelayKeepsAggregateOrder(t *testing.T) {
+	db := dbtest.Open(t)
+	recordEvents(t, db, [2]string{"a", "1"}, [2]string{"a", "2"}, [2]string{"b", "1"}, [2]string{"a", "3"})
+
+	publisher := &flakyPublisher{MemoryPublisher: NewMemoryPublisher()}
+	publisher.fail = func(msg Message) error {
+		if msg.AggregateID == "a" && msg.Data["step"] == "1" {
+			return errors.New("broker unavailable")
+		}
+		return nil
+	}
+	relay := NewRelay(db, publisher)
+	drain(t, relay)
+
+	// a:1 failed, so the rest of a waits for it; b is unaffected.
+	if got := steps(publisher.Messages()); len(got) != 1 || got[0] != "b:1" {
+		t.Fatalf("published %v while a:1 was failing, want only b:1", got)
+	}
+
+	publisher.fail = nil
+	makeDue(t, db)
+	drain(t, relay)
+
+	var order []string
+	for _, s := range steps(publisher.Messages()) {
+		if s[0] == 'a' {
+			order = append(order, s)
+		}
+	}
+	if len(order) != 3 || order[0] != "a:1" || order[1] != "a:2" || order[2] != "a:3" {
+		t.Errorf("aggregate a published as %v, want a:1, a:2, a:3", order)
+	}
+}
@@ -0,0 +1,177 @@
+package jobs
+
+import (
+	"errors"
+	"math/rand/v2"
+	"time"
+	"vehix/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const defaultMaxAttempts = 5
+
+// Backoff between attempts doubles from retryBase up to retryCap, with up to
+// a fifth added at random so jobs that failed together don't retry together.
+const (
+	retryBase = 30 * time.Second
+	retryCap  = time.Hour
+)
+
+// Options tune a single enqueued job. Zero values run it now with the
+// default number of attempts.
+type Options struct {
+	RunAt       time.Time
+	MaxAttempts int
+
+	recurringJob string
+}
+
+// Enqueue adds a job. Pass the caller's transaction so the job only exists
+// if the work that asked for it commits.
+func Enqueue(tx *gorm.DB, kind string, payload models.JSONMap, opts Options) (*models.Job, error) {
+	if payload == nil {
+		payload = models.JSONMap{}
+	}
+	job := &models.Job{
+		Kind:        kind,
+		Payload:     payload,
+		Status:      models.JobStatusQueued,
+		RunAt:       opts.RunAt,
+		MaxAttempts: opts.MaxAttempts,
+
+		RecurringJob: opts.recurringJob,
+	}
+	if job.RunAt.IsZero() {
+		job.RunAt = time.Now()
+	}
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = defaultMaxAttempts
+	}
+	return job, tx.Create(job).Error
+}
+
+// Recurring registers a recurring job, or updates it if one by that name
+// exists. The next run is only recomputed when the schedule itself changes.
+func Recurring(db *gorm.DB, name, expr, kind string, payload models.JSONMap) error {
+	schedule, err := ParseSchedule(expr)
+	if err != nil {
+		return err
+	}
+	if payload == nil {
+		payload = models.JSONMap{}
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var existing models.RecurringJob
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("name = ?", name).Limit(1).Find(&existing).Error; err != nil {
+			return err
+		}
+		job := models.RecurringJob{
+			Name:      name,
+			Schedule:  expr,
+			Kind:      kind,
+			Payload:   payload,
+			NextRunAt: existing.NextRunAt,
+			LastRunAt: existing.LastRunAt,
+		}
+		if existing.Name == "" || existing.Schedule != expr {
+			job.NextRunAt = schedule.Next(time.Now())
+		}
+		return tx.Save(&job).Error
+	})
+}
+
+// EnqueueDue turns every recurring job that has come due into a queued job
+// and advances its next run. Runs missed while nothing was polling collapse
+// into one.
+func EnqueueDue(db *gorm.DB) (int, error) {
+	now := time.Now()
+	count := 0
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var due []models.RecurringJob
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("next_run_at <= ?", now).Find(&due).Error; err != nil {
+			return err
+		}
+		for _, r := range due {
+			schedule, err := ParseSchedule(r.Schedule)
+			if err != nil {
+				return err
+			}
+			if _, err := Enqueue(tx, r.Kind, r.Payload, Options{RunAt: r.NextRunAt, recurringJob: r.Name}); err != nil {
+				return err
+			}
+			if err := tx.Model(&r).Updates(map[string]any{
+				"next_run_at": schedule.Next(now),
+				"last_run_at": now,
+			}).Error; err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// claim takes the oldest ready job for workerID: a queued job whose time has
+// come, or a running one whose worker let its lease run out.
+func claim(db *gorm.DB, workerID string, lease time.Duration) (*models.Job, error) {
+	var job models.Job
+	err := db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("(status = ? AND run_at <= ?) OR (status = ? AND locked_until < ?)",
+				models.JobStatusQueued, now, models.JobStatusRunning, now).
+			Order("run_at").Limit(1).Find(&job).Error
+		if err != nil || job.ID == uuid.Nil {
+			return err
+		}
+		lockedUntil := now.Add(lease)
+		job.Status = models.JobStatusRunning
+		job.Attempts++
+		job.LockedBy = workerID
+		job.LockedUntil = &lockedUntil
+		return tx.Model(&job).Select("status", "attempts", "locked_by", "locked_until").Updates(&job).Error
+	})
+	if err != nil || job.LockedBy != workerID {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// finish records the outcome of an attempt. A failed job goes back in the
+// queue with backoff until it runs out of attempts. Nothing is written if the
+// lease was lost and another worker has taken the job over.
+func finish(db *gorm.DB, job *models.Job, workerID string, runErr error) error {
+	now := time.Now()
+	updates := map[string]any{"locked_by": "", "locked_until": nil}
+	switch {
+	case runErr == nil:
+		updates["status"] = models.JobStatusSucceeded
+		updates["finished_at"] = now
+		updates["last_error"] = ""
+	case job.Attempts >= job.MaxAttempts || errors.Is(runErr, ErrPermanent):
+		updates["status"] = models.JobStatusDead
+		updates["finished_at"] = now
+		updates["last_error"] = runErr.Error()
+	default:
+		updates["status"] = models.JobStatusQueued
+		updates["run_at"] = now.Add(backoff(job.Attempts))
+		updates["last_error"] = runErr.Error()
+	}
+	return db.Model(&models.Job{}).
+		Where("id = ? AND locked_by = ? AND status = ?", job.ID, workerID, models.JobStatusRunning).
+		Updates(updates).Error
+}
+
+func backoff(attempt int) time.Duration {
+	d := retryCap
+	if attempt < 20 {
+		d = min(retryBase<<(attempt-1), retryCap)
+	}
+	return d + rand.N(d/5+1)
+}
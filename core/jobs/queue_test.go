@@ -0,0 +1,111 @@
+package jobs
+
+import (
+	"fmt"
+	"testing"
+	"time"
+	"vehix/core/database/dbtest"
+	"vehix/models"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{4, 4 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, time.Hour},
+		{20, time.Hour},
+		{64, time.Hour},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 50; i++ {
+			// Up to a fifth is added at random.
+			if got := backoff(tt.attempt); got < tt.want || got > tt.want+tt.want/5 {
+				t.Fatalf("backoff(%d) = %s, want between %s and %s", tt.attempt, got, tt.want, tt.want+tt.want/5)
+			}
+		}
+	}
+}
+
+func TestFailedJobRetriesWithBackoff(t *testing.T) {
+	db := dbtest.Open(t)
+	const worker = "test-worker"
+
+	job, err := Enqueue(db, "test.flaky", models.JSONMap{"n": 1}, Options{MaxAttempts: 3})
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		// Bring the retry forward rather than wait for it.
+		if err := db.Model(job).Update("run_at", time.Now()).Error; err != nil {
+			t.Fatalf("moving run_at: %v", err)
+		}
+		claimed, err := claim(db, worker, time.Minute)
+		if err != nil || claimed == nil || claimed.ID != job.ID {
+			t.Fatalf("attempt %d: claim = %v, %v; want the job", attempt, claimed, err)
+		}
+		if claimed.Attempts != attempt {
+			t.Errorf("attempt %d: claimed with Attempts = %d", attempt, claimed.Attempts)
+		}
+
+		failedAt := time.Now()
+		if err := finish(db, claimed, worker, fmt.Errorf("attempt %d failed", attempt)); err != nil {
+			t.Fatalf("finish: %v", err)
+		}
+		var saved models.Job
+		if err := db.First(&saved, "id = ?", job.ID).Error; err != nil {
+			t.Fatalf("loading job: %v", err)
+		}
+		if saved.LastError != fmt.Sprintf("attempt %d failed", attempt) {
+			t.Errorf("attempt %d: LastError = %q", attempt, saved.LastError)
+		}
+
+		if attempt < 3 {
+			delay := retryBase << (attempt - 1)
+			if saved.Status != models.JobStatusQueued {
+				t.Errorf("attempt %d: status = %s, want queued for a retry", attempt, saved.Status)
+			}
+			if wait := saved.RunAt.Sub(failedAt); wait < delay-time.Second || wait > delay+delay/5+time.Second {
+				t.Errorf("attempt %d: retry in %s, want about %s", attempt, wait, delay)
+			}
+			if again, _ := claim(db, worker, time.Minute); again != nil {
+				t.Errorf("attempt %d: job claimed again before its retry was due", attempt)
+			}
+			continue
+		}
+		if saved.Status != models.JobStatusDead || saved.FinishedAt == nil {
+			t.Errorf("after the last attempt: status = %s, finished at %v; want dead", saved.Status, saved.FinishedAt)
+		}
+	}
+}
+
+func TestPermanentFailureIsNotRetried(t *testing.T) {
+	db := dbtest.Open(t)
+	const worker = "test-worker"
+
+	job, err := Enqueue(db, "test.broken", nil, Options{})
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	claimed, err := claim(db, worker, time.Minute)
+	if err != nil || claimed == nil {
+		t.Fatalf("claim = %v, %v", claimed, err)
+	}
+	if err := finish(db, claimed, worker, fmt.Errorf("%w: bad payload", ErrPermanent)); err != nil {
+		t.Fatalf("finish: %v", err)
+	}
+
+	var saved models.Job
+	if err := db.First(&saved, "id = ?", job.ID).Error; err != nil {
+		t.Fatalf("loading job: %v", err)
+	}
+	if saved.Status != models.JobStatusDead || saved.Attempts != 1 {
+		t.Errorf("status = %s after %d attempts, want dead after the first", saved.Status, saved.Attempts)
+	}
+}
@@ -0,0 +1,134 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	"vehix/core/scheduler"
+	"vehix/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrPermanent marks a failure that retrying won't fix. Wrap it and the job
+// goes straight to dead.
+var ErrPermanent = errors.New("permanent failure")
+
+// Handler does the work for one kind of job. A worker can die after the work
+// is done but before it is recorded, so handlers must be safe to run twice.
+type Handler func(ctx context.Context, job *models.Job) error
+
+// Worker runs queued jobs. Any number of workers, in any number of
+// processes, can share a database; each job is claimed by exactly one.
+type Worker struct {
+	db          *gorm.DB
+	id          string
+	handlers    map[string]Handler
+	concurrency int
+	lease       time.Duration
+	poll        time.Duration
+}
+
+func NewWorker(db *gorm.DB, handlers map[string]Handler) *Worker {
+	host, _ := os.Hostname()
+	return &Worker{
+		db:          db,
+		id:          fmt.Sprintf("%s:%d:%s", host, os.Getpid(), uuid.NewString()[:8]),
+		handlers:    handlers,
+		concurrency: envInt("JOB_WORKER_CONCURRENCY", 4),
+		lease:       time.Duration(envInt("JOB_LEASE_SECONDS", 300)) * time.Second,
+		poll:        time.Duration(envInt("JOB_POLL_MILLISECONDS", 1000)) * time.Millisecond,
+	}
+}
+
+// Run works through jobs until ctx is cancelled, then waits for the jobs in
+// hand. It also enqueues recurring jobs as they come due.
+func (w *Worker) Run(ctx context.Context) {
+	logger.Info(fmt.Sprintf("[%s] %s: %s, %d at a time", messages.INFO_WORKER_STARTED.Code,
+		messages.INFO_WORKER_STARTED.Text, w.id, w.concurrency))
+
+	go scheduler.Every(ctx, "enqueue-recurring-jobs", 30*time.Second, func(ctx context.Context) error {
+		_, err := EnqueueDue(w.db.WithContext(ctx))
+		return err
+	})
+
+	var wg sync.WaitGroup
+	for range w.concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.loop(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (w *Worker) loop(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, err := claim(w.db.WithContext(ctx), w.id, w.lease)
+		if err != nil && ctx.Err() == nil {
+			logger.Error(fmt.Sprintf("[%s] %s: claiming job: %s", messages.ERR_JOB_FAILED.Code,
+				messages.ERR_JOB_FAILED.Text, err.Error()))
+		}
+		if job == nil {
+			select {
+			case <-ctx.Done():
+			case <-time.After(w.poll):
+			}
+			continue
+		}
+		w.run(ctx, job)
+	}
+}
+
+func (w *Worker) run(ctx context.Context, job *models.Job) {
+	jobCtx, cancel := context.WithTimeout(ctx, w.lease)
+	defer cancel()
+
+	var err error
+	if handler, ok := w.handlers[job.Kind]; ok {
+		err = safely(jobCtx, handler, job)
+	} else {
+		err = fmt.Errorf("%w: no handler for job kind %q", ErrPermanent, job.Kind)
+	}
+
+	// Record the outcome even if we are shutting down.
+	if finishErr := finish(w.db.WithContext(context.WithoutCancel(ctx)), job, w.id, err); finishErr != nil {
+		logger.Error(fmt.Sprintf("[%s] %s: recording job %s: %s", messages.ERR_JOB_FAILED.Code,
+			messages.ERR_JOB_FAILED.Text, job.ID, finishErr.Error()))
+		return
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("[%s] %s: %s %s, attempt %d of %d: %s", messages.ERR_JOB_FAILED.Code,
+			messages.ERR_JOB_FAILED.Text, job.Kind, job.ID, job.Attempts, job.MaxAttempts, err.Error()))
+		return
+	}
+	logger.Info(fmt.Sprintf("[%s] %s: %s %s", messages.INFO_JOB_SUCCEEDED.Code,
+		messages.INFO_JOB_SUCCEEDED.Text, job.Kind, job.ID))
+}
+
+// safely runs the handler, turning a panic into an ordinary failure so one
+// bad job can't take the worker down.
+func safely(ctx context.Context, handler Handler, job *models.Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+func envInt(name string, fallback int) int {
+	v, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || v <= 0 {
+		return fallback
+	}
+	return v
+}
@@ -11,6 +11,8 @@ var (
 	ERR_SERVER_STARTUP   = Message{Code: "SYS003E", Text: "Failed to start server"}
 	ERR_UNEXPECTED_ERROR = Message{Code: "SYS004E", Text: "Unexpected Error"}
 	ERR_BAD_REQUEST      = Message{Code: "SYS005E", Text: "Bad Request"}
+	ERR_MIGRATION_FAILED = Message{Code: "SYS006E", Text: "Failed to migrate database"}
+	ERR_SCHEDULED_TASK   = Message{Code: "SYS007E", Text: "Scheduled task failed"}
 )
 
 // Auth Messages
@@ -42,4 +44,279 @@ var (
 	INFO_USER_UPDATE_SUCCESS = Message{Code: "USR008I", Text: "User updated successfully"}
 
 	ERR_EMAIL_ALREADY_EXISTS = Message{Code: "USR009E", Text: "Email already in use"}
+
+	INFO_USER_DELETE_SUCCESS         = Message{Code: "USR010I", Text: "User deleted successfully"}
+	INFO_USER_ROLE_UPDATE_SUCCESS    = Message{Code: "USR011I", Text: "User role updated successfully"}
+	INFO_USER_SUSPEND_SUCCESS        = Message{Code: "USR012I", Text: "User suspended successfully"}
+	INFO_USER_REACTIVATE_SUCCESS     = Message{Code: "USR013I", Text: "User reactivated successfully"}
+	INFO_USER_PASSWORD_RESET_SUCCESS = Message{Code: "USR014I", Text: "Password reset required for user"}
+
+	ERR_INVALID_ROLE            = Message{Code: "USR015E", Text: "Invalid role"}
+	ERR_ACCOUNT_SUSPENDED       = Message{Code: "USR016E", Text: "Account is suspended"}
+	ERR_PASSWORD_RESET_REQUIRED = Message{Code: "USR017E", Text: "Password reset required"}
+	ERR_SELF_MANAGEMENT         = Message{Code: "USR018E", Text: "Admins cannot change their own role, status or account"}
+
+	INFO_USER_EXPORT_SUCCESS    = Message{Code: "USR019I", Text: "User data exported successfully"}
+	INFO_USER_ANONYMIZE_SUCCESS = Message{Code: "USR020I", Text: "Deleted users anonymized"}
+)
+
+// API Key Messages
+var (
+	INFO_API_KEY_CREATE_SUCCESS = Message{Code: "KEY001I", Text: "API key created successfully"}
+	INFO_API_KEY_FETCH_SUCCESS  = Message{Code: "KEY002I", Text: "API keys fetched successfully"}
+	INFO_API_KEY_REVOKE_SUCCESS = Message{Code: "KEY003I", Text: "API key revoked successfully"}
+
+	ERR_API_KEY_NOT_FOUND          = Message{Code: "KEY004E", Text: "API key not found"}
+	ERR_INVALID_API_KEY            = Message{Code: "KEY005E", Text: "API key invalid, expired or revoked"}
+	ERR_INVALID_API_KEY_PERMISSION = Message{Code: "KEY006E", Text: "Invalid API key permission"}
+)
+
+// Vehicle Messages
+var (
+	INFO_VEHICLE_CREATE_SUCCESS = Message{Code: "VEH001I", Text: "Vehicle created successfully"}
+	INFO_VEHICLE_FETCH_SUCCESS  = Message{Code: "VEH002I", Text: "Vehicle fetched successfully"}
+	INFO_VEHICLE_UPDATE_SUCCESS = Message{Code: "VEH003I", Text: "Vehicle updated successfully"}
+	INFO_VEHICLE_DELETE_SUCCESS = Message{Code: "VEH004I", Text: "Vehicle deleted successfully"}
+
+	ERR_VEHICLE_NOT_FOUND = Message{Code: "VEH005E", Text: "Vehicle not found"}
+	ERR_VEHICLE_IN_USE    = Message{Code: "VEH006E", Text: "Vehicle has upcoming rentals"}
+
+	ERR_INVALID_VIN               = Message{Code: "VEH007E", Text: "Invalid VIN"}
+	ERR_VIN_EXISTS                = Message{Code: "VEH008E", Text: "VIN already registered"}
+	ERR_INVALID_VEHICLE_ATTRIBUTE = Message{Code: "VEH009E", Text: "Invalid vehicle attribute"}
+)
+
+// Rental Messages
+var (
+	INFO_RENTAL_CREATE_SUCCESS = Message{Code: "RNT001I", Text: "Rental created successfully"}
+	INFO_RENTAL_FETCH_SUCCESS  = Message{Code: "RNT002I", Text: "Rental fetched successfully"}
+	INFO_RENTAL_CANCEL_SUCCESS = Message{Code: "RNT003I", Text: "Rental cancelled successfully"}
+
+	ERR_RENTAL_NOT_FOUND       = Message{Code: "RNT004E", Text: "Rental not found"}
+	ERR_VEHICLE_UNAVAILABLE    = Message{Code: "RNT005E", Text: "Vehicle is not available for the requested period"}
+	ERR_INVALID_RENTAL_PERIOD  = Message{Code: "RNT006E", Text: "Invalid rental period"}
+	ERR_RENTAL_NOT_CANCELLABLE = Message{Code: "RNT007E", Text: "Rental can no longer be cancelled"}
+
+	INFO_RENTAL_RETURN_SUCCESS              = Message{Code: "RNT008I", Text: "Rental returned successfully"}
+	ERR_RENTAL_NOT_RETURNABLE               = Message{Code: "RNT009E", Text: "Rental cannot be returned"}
+	ERR_VEHICLE_NOT_AT_BRANCH               = Message{Code: "RNT010E", Text: "Vehicle is not available at the requested branch"}
+	ERR_BRANCH_CLOSED                       = Message{Code: "RNT011E", Text: "Branch is closed at the requested time"}
+	ERR_VEHICLE_IN_MAINTENANCE              = Message{Code: "RNT012E", Text: "Vehicle is out of service for maintenance"}
+	INFO_RENTAL_QUOTE_SUCCESS               = Message{Code: "RNT013I", Text: "Rental quoted successfully"}
+	INFO_RENTAL_MODIFY_SUCCESS              = Message{Code: "RNT014I", Text: "Rental modified successfully"}
+	ERR_RENTAL_NOT_MODIFIABLE               = Message{Code: "RNT015E", Text: "Rental cannot be modified"}
+	INFO_RENTAL_MODIFICATIONS_FETCH_SUCCESS = Message{Code: "RNT016I", Text: "Rental modifications fetched successfully"}
+	INFO_RENTAL_NO_SHOW_SUCCESS             = Message{Code: "RNT017I", Text: "Rental marked as a no-show"}
+	ERR_RENTAL_OVERDUE                      = Message{Code: "RNT018E", Text: "Rental is overdue"}
+	ERR_RENTAL_BLOCKED                      = Message{Code: "RNT019E", Text: "Rental is blocked by an overdue rental of its vehicle"}
+	INFO_OVERDUE_RENTALS_PROCESSED          = Message{Code: "RNT020I", Text: "Overdue rentals processed"}
+	INFO_UNPAID_RENTALS_EXPIRED             = Message{Code: "RNT021I", Text: "Unpaid rentals expired"}
+	INFO_RENTAL_HOLD_SUCCESS                = Message{Code: "RNT022I", Text: "Vehicle held successfully"}
+	INFO_RENTAL_CONFIRM_SUCCESS             = Message{Code: "RNT023I", Text: "Rental confirmed successfully"}
+	ERR_RENTAL_NOT_HELD                     = Message{Code: "RNT024E", Text: "Rental is not an active hold"}
+	INFO_RENTAL_HOLDS_EXPIRED               = Message{Code: "RNT025I", Text: "Rental holds expired"}
+)
+
+// Audit Messages
+var (
+	INFO_AUDIT_FETCH_SUCCESS  = Message{Code: "AUD001I", Text: "Audit log fetched successfully"}
+	INFO_AUDIT_VERIFY_SUCCESS = Message{Code: "AUD002I", Text: "Audit chain verified"}
+)
+
+// Branch Messages
+var (
+	INFO_BRANCH_CREATE_SUCCESS      = Message{Code: "BRN001I", Text: "Branch created successfully"}
+	INFO_BRANCH_FETCH_SUCCESS       = Message{Code: "BRN002I", Text: "Branch fetched successfully"}
+	INFO_BRANCH_UPDATE_SUCCESS      = Message{Code: "BRN003I", Text: "Branch updated successfully"}
+	INFO_ONE_WAY_FEE_UPDATE_SUCCESS = Message{Code: "BRN004I", Text: "One-way fee updated successfully"}
+
+	ERR_BRANCH_NOT_FOUND      = Message{Code: "BRN005E", Text: "Branch not found"}
+	ERR_BRANCH_CODE_EXISTS    = Message{Code: "BRN006E", Text: "Branch code already in use"}
+	ERR_INVALID_TIMEZONE      = Message{Code: "BRN007E", Text: "Invalid timezone"}
+	ERR_INVALID_OPENING_HOURS = Message{Code: "BRN008E", Text: "Invalid opening hours"}
+)
+
+// Vehicle Media Messages
+var (
+	INFO_MEDIA_UPLOAD_SUCCESS = Message{Code: "MED001I", Text: "Media uploaded successfully"}
+	INFO_MEDIA_FETCH_SUCCESS  = Message{Code: "MED002I", Text: "Media fetched successfully"}
+	INFO_MEDIA_DELETE_SUCCESS = Message{Code: "MED003I", Text: "Media deleted successfully"}
+
+	ERR_MEDIA_NOT_FOUND        = Message{Code: "MED004E", Text: "Media not found"}
+	ERR_UNSUPPORTED_MEDIA_TYPE = Message{Code: "MED005E", Text: "Unsupported media type"}
+	ERR_MEDIA_TOO_LARGE        = Message{Code: "MED006E", Text: "Media file too large"}
+)
+
+// Maintenance Messages
+var (
+	INFO_MAINTENANCE_CREATE_SUCCESS   = Message{Code: "MNT001I", Text: "Maintenance window created successfully"}
+	INFO_MAINTENANCE_FETCH_SUCCESS    = Message{Code: "MNT002I", Text: "Maintenance fetched successfully"}
+	INFO_MAINTENANCE_UPDATE_SUCCESS   = Message{Code: "MNT003I", Text: "Maintenance window updated successfully"}
+	INFO_MAINTENANCE_COMPLETE_SUCCESS = Message{Code: "MNT004I", Text: "Maintenance completed successfully"}
+	INFO_SERVICE_INTERVAL_SUCCESS     = Message{Code: "MNT005I", Text: "Service interval saved successfully"}
+	INFO_SERVICE_TASKS_CREATED        = Message{Code: "MNT006I", Text: "Service tasks created"}
+
+	ERR_MAINTENANCE_NOT_FOUND      = Message{Code: "MNT007E", Text: "Maintenance window not found"}
+	ERR_SERVICE_INTERVAL_NOT_FOUND = Message{Code: "MNT008E", Text: "Service interval not found"}
+	ERR_INVALID_MAINTENANCE_WINDOW = Message{Code: "MNT009E", Text: "Invalid maintenance window"}
+	ERR_MAINTENANCE_CONFLICT       = Message{Code: "MNT010E", Text: "Maintenance window overlaps existing rentals"}
+	ERR_MAINTENANCE_NOT_EDITABLE   = Message{Code: "MNT011E", Text: "Maintenance window can no longer be changed"}
+)
+
+// Inspection Messages
+var (
+	INFO_INSPECTION_CREATE_SUCCESS = Message{Code: "INS001I", Text: "Inspection recorded successfully"}
+	INFO_INSPECTION_FETCH_SUCCESS  = Message{Code: "INS002I", Text: "Inspection fetched successfully"}
+	INFO_CHARGES_FETCH_SUCCESS     = Message{Code: "INS003I", Text: "Rental charges fetched successfully"}
+
+	ERR_INSPECTION_EXISTS     = Message{Code: "INS004E", Text: "Inspection already recorded"}
+	ERR_INVALID_INSPECTION    = Message{Code: "INS005E", Text: "Invalid inspection"}
+	ERR_CHECKOUT_REQUIRED     = Message{Code: "INS006E", Text: "Checkout inspection must be recorded first"}
+	ERR_DAMAGE_ITEM_NOT_FOUND = Message{Code: "INS007E", Text: "Damage item not found"}
+
+	INFO_CHARGE_REVIEW_SUCCESS = Message{Code: "INS008I", Text: "Rental charge reviewed successfully"}
+	ERR_CHARGE_NOT_FOUND       = Message{Code: "INS009E", Text: "Rental charge not found"}
+	ERR_CHARGE_NOT_REVIEWABLE  = Message{Code: "INS010E", Text: "Rental charge cannot be reviewed"}
+)
+
+// Invoice Messages
+var (
+	INFO_INVOICE_ISSUE_SUCCESS      = Message{Code: "INV001I", Text: "Invoice issued successfully"}
+	INFO_INVOICE_FETCH_SUCCESS      = Message{Code: "INV002I", Text: "Invoice fetched successfully"}
+	INFO_CREDIT_NOTE_CREATE_SUCCESS = Message{Code: "INV003I", Text: "Credit note issued successfully"}
+
+	ERR_INVOICE_NOT_FOUND      = Message{Code: "INV004E", Text: "Invoice not found"}
+	ERR_INVOICE_EXISTS         = Message{Code: "INV005E", Text: "Rental has already been invoiced"}
+	ERR_RENTAL_NOT_INVOICEABLE = Message{Code: "INV006E", Text: "Rental cannot be invoiced yet"}
+	ERR_CHARGES_PENDING_REVIEW = Message{Code: "INV007E", Text: "Rental has charges awaiting review"}
+	ERR_INVALID_CREDIT_NOTE    = Message{Code: "INV008E", Text: "Invalid credit note"}
+)
+
+// Extra Messages
+var (
+	INFO_EXTRA_FETCH_SUCCESS     = Message{Code: "EXT001I", Text: "Extras fetched successfully"}
+	INFO_EXTRA_CREATE_SUCCESS    = Message{Code: "EXT002I", Text: "Extra created successfully"}
+	INFO_EXTRA_UPDATE_SUCCESS    = Message{Code: "EXT003I", Text: "Extra updated successfully"}
+	INFO_EXTRA_STOCK_SET_SUCCESS = Message{Code: "EXT004I", Text: "Extra stock updated successfully"}
+
+	ERR_EXTRA_NOT_FOUND   = Message{Code: "EXT005E", Text: "Extra not found"}
+	ERR_EXTRA_CODE_EXISTS = Message{Code: "EXT006E", Text: "Extra code already exists"}
+	ERR_INVALID_EXTRA     = Message{Code: "EXT007E", Text: "Invalid extra"}
+	ERR_EXTRA_UNAVAILABLE = Message{Code: "EXT008E", Text: "Extra is not available for the requested period"}
+)
+
+// Promo Code Messages
+var (
+	INFO_PROMO_FETCH_SUCCESS  = Message{Code: "PRM001I", Text: "Promo codes fetched successfully"}
+	INFO_PROMO_CREATE_SUCCESS = Message{Code: "PRM002I", Text: "Promo code created successfully"}
+	INFO_PROMO_UPDATE_SUCCESS = Message{Code: "PRM003I", Text: "Promo code updated successfully"}
+
+	ERR_PROMO_NOT_FOUND   = Message{Code: "PRM004E", Text: "Promo code not found"}
+	ERR_PROMO_CODE_EXISTS = Message{Code: "PRM005E", Text: "Promo code already exists"}
+	ERR_INVALID_PROMO     = Message{Code: "PRM006E", Text: "Invalid promo code"}
+	ERR_PROMO_REJECTED    = Message{Code: "PRM007E", Text: "Promo code cannot be applied to this rental"}
+)
+
+// Driver Messages
+var (
+	INFO_DRIVER_PROFILE_FETCH_SUCCESS  = Message{Code: "DRV001I", Text: "Driver profile fetched successfully"}
+	INFO_DRIVER_PROFILE_UPDATE_SUCCESS = Message{Code: "DRV002I", Text: "Driver profile updated successfully"}
+	INFO_LICENSE_PHOTO_UPLOAD_SUCCESS  = Message{Code: "DRV003I", Text: "License photo uploaded successfully"}
+	INFO_DRIVER_PROFILE_REVIEW_SUCCESS = Message{Code: "DRV004I", Text: "Driver profile reviewed successfully"}
+	INFO_VEHICLE_CLASS_FETCH_SUCCESS   = Message{Code: "DRV005I", Text: "Vehicle classes fetched successfully"}
+	INFO_VEHICLE_CLASS_UPDATE_SUCCESS  = Message{Code: "DRV006I", Text: "Vehicle class updated successfully"}
+
+	ERR_DRIVER_PROFILE_NOT_FOUND = Message{Code: "DRV007E", Text: "Driver profile not found"}
+	ERR_INVALID_DRIVER_PROFILE   = Message{Code: "DRV008E", Text: "Invalid driver profile"}
+	ERR_DRIVER_NOT_VERIFIED      = Message{Code: "DRV009E", Text: "Driver has not been verified"}
+	ERR_DRIVER_NOT_ELIGIBLE      = Message{Code: "DRV010E", Text: "Driver is not eligible for this rental"}
+	ERR_INVALID_VEHICLE_CLASS    = Message{Code: "DRV011E", Text: "Invalid vehicle class"}
+
+	INFO_DRIVER_INVITATION_FETCH_SUCCESS = Message{Code: "DRV012I", Text: "Driver invitations fetched successfully"}
+	INFO_DRIVER_INVITATION_RESPONDED     = Message{Code: "DRV013I", Text: "Driver invitation answered successfully"}
+	ERR_DRIVER_INVITATION_NOT_FOUND      = Message{Code: "DRV014E", Text: "Driver invitation not found"}
+	ERR_INVALID_ADDITIONAL_DRIVER        = Message{Code: "DRV015E", Text: "Invalid additional driver"}
+	ERR_DRIVER_INVITATION_CLOSED         = Message{Code: "DRV016E", Text: "Driver invitation can no longer be answered"}
+)
+
+// Payment Messages
+var (
+	INFO_PAYMENT_WEBHOOK_PROCESSED = Message{Code: "PAY001I", Text: "Payment webhook processed"}
+
+	ERR_PAYMENT_DECLINED  = Message{Code: "PAY002E", Text: "Payment was declined"}
+	ERR_PAYMENT_GATEWAY   = Message{Code: "PAY003E", Text: "Payment gateway request failed"}
+	ERR_INVALID_WEBHOOK   = Message{Code: "PAY004E", Text: "Invalid payment webhook"}
+	ERR_PAYMENT_NOT_FOUND = Message{Code: "PAY005E", Text: "No rental matches the payment"}
+)
+
+// Job Messages
+var (
+	INFO_WORKER_STARTED               = Message{Code: "JOB001I", Text: "Job worker started"}
+	INFO_JOB_SUCCEEDED                = Message{Code: "JOB002I", Text: "Job succeeded"}
+	INFO_JOBS_FETCH_SUCCESS           = Message{Code: "JOB003I", Text: "Jobs fetched successfully"}
+	INFO_JOB_FETCH_SUCCESS            = Message{Code: "JOB004I", Text: "Job fetched successfully"}
+	INFO_JOB_RETRY_SUCCESS            = Message{Code: "JOB005I", Text: "Job queued for retry"}
+	INFO_RECURRING_JOBS_FETCH_SUCCESS = Message{Code: "JOB006I", Text: "Recurring jobs fetched successfully"}
+
+	ERR_JOB_FAILED        = Message{Code: "JOB007E", Text: "Job failed"}
+	ERR_JOB_NOT_FOUND     = Message{Code: "JOB008E", Text: "Job not found"}
+	ERR_JOB_NOT_RETRYABLE = Message{Code: "JOB009E", Text: "Job cannot be retried"}
+)
+
+// Event Messages
+var (
+	INFO_EVENT_RELAY_STARTED = Message{Code: "EVT001I", Text: "Event relay started"}
+
+	ERR_EVENT_PUBLISH = Message{Code: "EVT002E", Text: "Failed to publish event"}
+)
+
+// Webhook Messages
+var (
+	INFO_WEBHOOK_CREATE_SUCCESS           = Message{Code: "WHK001I", Text: "Webhook created successfully"}
+	INFO_WEBHOOKS_FETCH_SUCCESS           = Message{Code: "WHK002I", Text: "Webhooks fetched successfully"}
+	INFO_WEBHOOK_DELETE_SUCCESS           = Message{Code: "WHK003I", Text: "Webhook deleted successfully"}
+	INFO_WEBHOOK_DELIVERIES_FETCH_SUCCESS = Message{Code: "WHK004I", Text: "Webhook deliveries fetched successfully"}
+	INFO_WEBHOOK_REPLAY_SUCCESS           = Message{Code: "WHK005I", Text: "Webhook delivery queued for replay"}
+	INFO_WEBHOOK_PING_SUCCESS             = Message{Code: "WHK006I", Text: "Webhook ping queued"}
+	INFO_WEBHOOK_RECEIVED                 = Message{Code: "WHK007I", Text: "Webhook received"}
+
+	ERR_WEBHOOK_NOT_FOUND          = Message{Code: "WHK008E", Text: "Webhook not found"}
+	ERR_INVALID_WEBHOOK_URL        = Message{Code: "WHK009E", Text: "Invalid webhook URL"}
+	ERR_INVALID_WEBHOOK_EVENT      = Message{Code: "WHK010E", Text: "Unknown webhook event type"}
+	ERR_WEBHOOK_DELIVERY_NOT_FOUND = Message{Code: "WHK011E", Text: "Webhook delivery not found"}
+	ERR_WEBHOOK_SIGNATURE          = Message{Code: "WHK012E", Text: "Webhook signature verification failed"}
+
+	INFO_WEBHOOK_DELIVERIES_PRUNED = Message{Code: "WHK013I", Text: "Old webhook deliveries pruned"}
+)
+
+// Notification Messages
+var (
+	INFO_NOTIFICATION_PREFERENCES_FETCH_SUCCESS  = Message{Code: "NTF001I", Text: "Notification preferences fetched successfully"}
+	INFO_NOTIFICATION_PREFERENCES_UPDATE_SUCCESS = Message{Code: "NTF002I", Text: "Notification preferences updated successfully"}
+	INFO_NOTIFICATIONS_FETCH_SUCCESS             = Message{Code: "NTF003I", Text: "Notifications fetched successfully"}
+	INFO_PICKUP_REMINDERS_QUEUED                 = Message{Code: "NTF004I", Text: "Pickup reminders queued"}
+
+	ERR_INVALID_NOTIFICATION_PREFERENCES = Message{Code: "NTF005E", Text: "Invalid notification preferences"}
+)
+
+// Stream Messages
+var (
+	INFO_STREAM_OPENED = Message{Code: "STR001I", Text: "Event stream opened"}
+	INFO_STREAM_CLOSED = Message{Code: "STR002I", Text: "Event stream closed"}
+
+	ERR_STREAM_LISTEN  = Message{Code: "STR003E", Text: "Event stream listener failed"}
+	ERR_INVALID_STREAM = Message{Code: "STR004E", Text: "Invalid stream filter"}
+)
+
+// Telematics Messages
+var (
+	INFO_TELEMATICS_DEVICE_CREATE_SUCCESS = Message{Code: "TLM001I", Text: "Telematics device registered successfully"}
+	INFO_TELEMATICS_DEVICES_FETCH_SUCCESS = Message{Code: "TLM002I", Text: "Telematics devices fetched successfully"}
+	INFO_TELEMATICS_DEVICE_REVOKE_SUCCESS = Message{Code: "TLM003I", Text: "Telematics device revoked successfully"}
+	INFO_TELEMATICS_READINGS_INGESTED     = Message{Code: "TLM004I", Text: "Telematics readings ingested"}
+	INFO_TELEMATICS_READINGS_PRUNED       = Message{Code: "TLM005I", Text: "Old telematics readings pruned"}
+
+	ERR_TELEMATICS_DEVICE_NOT_FOUND = Message{Code: "TLM006E", Text: "Telematics device not found"}
+	ERR_INVALID_TELEMATICS_DEVICE   = Message{Code: "TLM007E", Text: "Invalid telematics device token"}
+	ERR_INVALID_TELEMATICS_BATCH    = Message{Code: "TLM008E", Text: "Invalid telematics batch"}
 )
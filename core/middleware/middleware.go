@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"vehix/core/logger"
 	"vehix/core/messages"
 	auth "vehix/core/service"
@@ -10,9 +12,25 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
-func Middleware(authSvc auth.AuthService) fiber.Handler {
+const (
+	bearerPrefix = "Bearer "
+	apiKeyPrefix = "ApiKey "
+	apiKeyHeader = "X-API-Key"
+)
+
+func Middleware(authSvc auth.AuthService, apiKeySvc auth.APIKeyService) fiber.Handler {
 	return func(ctx *fiber.Ctx) error {
-		tokenString := ctx.Get("Authorization") // Bearer <token>
+		tokenString := ctx.Get("Authorization") // Bearer <token> | ApiKey <key>
+		apiKey := ctx.Get(apiKeyHeader)
+
+		if strings.HasPrefix(tokenString, apiKeyPrefix) {
+			apiKey = tokenString[len(apiKeyPrefix):]
+		}
+
+		if apiKey != "" {
+			return authenticateAPIKey(ctx, authSvc, apiKeySvc, apiKey)
+		}
+
 		if tokenString == "" {
 			return throwMiddlewareError(ctx, fiber.StatusUnauthorized, &models.ErrorResponse{
 				MessageID: messages.ERR_UNAUTHORIZED.Code,
@@ -21,14 +39,13 @@ func Middleware(authSvc auth.AuthService) fiber.Handler {
 			})
 		}
 
-		const bearerPrefix = "Bearer "
 		if len(tokenString) > len(bearerPrefix) && tokenString[:len(bearerPrefix)] == bearerPrefix {
 			tokenString = tokenString[len(bearerPrefix):]
 		} else {
 			return throwMiddlewareError(ctx, fiber.StatusUnauthorized, &models.ErrorResponse{
 				MessageID: messages.ERR_UNAUTHORIZED.Code,
 				Message:   messages.ERR_UNAUTHORIZED.Text,
-				Exception: "Authorization header must start with Bearer",
+				Exception: "Authorization header must start with Bearer or ApiKey",
 			})
 		}
 
@@ -44,10 +61,98 @@ func Middleware(authSvc auth.AuthService) fiber.Handler {
 		ctx.Locals("userID", claims.UserID)
 		ctx.Locals("email", claims.Email)
 		ctx.Locals("role", claims.Role)
+		ctx.Locals("authMethod", "jwt")
+		return validateAccount(ctx, authSvc, claims.UserID)
+	}
+}
+
+func authenticateAPIKey(ctx *fiber.Ctx, authSvc auth.AuthService, apiKeySvc auth.APIKeyService, apiKey string) error {
+	claims, err := apiKeySvc.VerifyAPIKey(ctx.Context(), apiKey)
+	if err != nil {
+		return throwMiddlewareError(ctx, fiber.StatusUnauthorized, &models.ErrorResponse{
+			MessageID: messages.ERR_INVALID_API_KEY.Code,
+			Message:   messages.ERR_INVALID_API_KEY.Text,
+			Exception: "API key verification failed: " + err.Error(),
+		})
+	}
+
+	resource, action := requiredPermission(ctx)
+	if !claims.Allows(resource, action) {
+		return throwMiddlewareError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+			MessageID: messages.ERR_FORBIDDEN.Code,
+			Message:   messages.ERR_FORBIDDEN.Text,
+			Exception: fmt.Sprintf("API key lacks permission %s:%s", resource, action),
+		})
+	}
+
+	ctx.Locals("userID", claims.UserID)
+	ctx.Locals("email", claims.Email)
+	ctx.Locals("role", claims.Role)
+	ctx.Locals("authMethod", "api_key")
+	ctx.Locals("apiKeyID", claims.KeyID)
+	ctx.Locals("organization", claims.Organization)
+	return validateAccount(ctx, authSvc, claims.UserID)
+}
+
+// validateAccount rejects suspended accounts even when they present a still
+// valid credential. Accounts with a pending forced password reset may only
+// read their profile and change their password through /v1/me. The role is
+// taken from the account rather than the credential, so a role change applies
+// to tokens already issued.
+func validateAccount(ctx *fiber.Ctx, authSvc auth.AuthService, userID string) error {
+	role, err := authSvc.ValidateAccount(ctx.Context(), userID)
+	if role != "" {
+		ctx.Locals("role", role)
+	}
+	switch {
+	case err == nil:
 		return ctx.Next()
+	case errors.Is(err, auth.ErrAccountSuspended):
+		return throwMiddlewareError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+			MessageID: messages.ERR_ACCOUNT_SUSPENDED.Code,
+			Message:   messages.ERR_ACCOUNT_SUSPENDED.Text,
+			Exception: "request rejected for suspended account",
+		})
+	case errors.Is(err, auth.ErrPasswordResetPending):
+		if ctx.Path() == "/v1/me" && (ctx.Method() == fiber.MethodGet || ctx.Method() == fiber.MethodPatch) {
+			return ctx.Next()
+		}
+		return throwMiddlewareError(ctx, fiber.StatusForbidden, &models.ErrorResponse{
+			MessageID: messages.ERR_PASSWORD_RESET_REQUIRED.Code,
+			Message:   messages.ERR_PASSWORD_RESET_REQUIRED.Text,
+			Exception: "password must be changed via PATCH /v1/me before continuing",
+		})
+	case errors.Is(err, auth.ErrAccountNotFound):
+		return throwMiddlewareError(ctx, fiber.StatusUnauthorized, &models.ErrorResponse{
+			MessageID: messages.ERR_UNAUTHORIZED.Code,
+			Message:   messages.ERR_UNAUTHORIZED.Text,
+			Exception: "account no longer exists",
+		})
+	default:
+		return throwMiddlewareError(ctx, fiber.StatusInternalServerError, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		})
 	}
 }
 
+// requiredPermission maps a request to the API key permission it needs: the
+// resource is the first path segment under /v1 and the action is "read" for
+// safe methods and "write" for everything else.
+func requiredPermission(ctx *fiber.Ctx) (string, string) {
+	path := strings.TrimPrefix(ctx.Path(), "/v1/")
+	resource, _, _ := strings.Cut(path, "/")
+
+	action := "write"
+	switch ctx.Method() {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+		action = "read"
+	}
+
+	return resource, action
+}
+
 func throwMiddlewareError(ctx *fiber.Ctx, statusCode int, errResp *models.ErrorResponse) error {
 	logger.Error(fmt.Sprintf("[%s] %s", errResp.MessageID, fmt.Sprintf("%s: %s", errResp.Message, errResp.Exception)))
 	return ctx.Status(statusCode).JSON(errResp)
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const maxRequestIDLength = 64
+
+// RequestContext tags every request with a request ID (propagated from the
+// X-Request-ID header when the caller sends one) and the client IP, so that
+// services can attribute the changes they make.
+func RequestContext() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		requestID := ctx.Get(fiber.HeaderXRequestID)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = uuid.NewString()
+		}
+
+		ctx.Set(fiber.HeaderXRequestID, requestID)
+		ctx.Locals("requestID", requestID)
+		ctx.Locals("ip", ctx.IP())
+		return ctx.Next()
+	}
+}
@@ -0,0 +1,48 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileNotifier appends each message to a file as a line of JSON, for local
+// runs and tests that need to see what would have been sent.
+type FileNotifier struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileNotifier(path string) (*FileNotifier, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	return &FileNotifier{path: path}, nil
+}
+
+func (n *FileNotifier) Send(_ context.Context, msg Message) error {
+	line, err := json.Marshal(map[string]any{
+		"to":      msg.To,
+		"subject": msg.Subject,
+		"body":    msg.Body,
+		"sent_at": time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	f, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
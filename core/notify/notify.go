@@ -0,0 +1,92 @@
+package notify
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+)
+
+// Message is a rendered notification for one recipient. Subject is ignored
+// by channels that have none.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier delivers messages on one channel. Send returns once the provider
+// has accepted the message.
+type Notifier interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Notifiers holds the adapter for each channel.
+type Notifiers struct {
+	Email Notifier
+	SMS   Notifier
+}
+
+// Connect builds the notifiers selected by NOTIFY_EMAIL ("file", the default,
+// or "smtp") and NOTIFY_SMS ("file", the default, or "twilio") from the
+// environment. Both file sinks write to NOTIFY_FILE_PATH.
+func Connect() Notifiers {
+	var notifiers Notifiers
+	var file *FileNotifier
+	fileSink := func() Notifier {
+		if file == nil {
+			var err error
+			if file, err = NewFileNotifier(envOrDefault("NOTIFY_FILE_PATH", "./data/notifications.jsonl")); err != nil {
+				log.Fatal("failed to initialise notification file sink:", err)
+			}
+		}
+		return file
+	}
+
+	switch os.Getenv("NOTIFY_EMAIL") {
+	case "", "file":
+		notifiers.Email = fileSink()
+	case "smtp":
+		port, _ := strconv.Atoi(envOrDefault("SMTP_PORT", "587"))
+		notifier, err := NewSMTPNotifier(SMTPConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     port,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("SMTP_FROM"),
+		})
+		if err != nil {
+			log.Fatal("failed to initialise SMTP notifier:", err)
+		}
+		notifiers.Email = notifier
+	default:
+		log.Fatalf("unknown NOTIFY_EMAIL %q", os.Getenv("NOTIFY_EMAIL"))
+	}
+
+	switch os.Getenv("NOTIFY_SMS") {
+	case "", "file":
+		notifiers.SMS = fileSink()
+	case "twilio":
+		notifier, err := NewTwilioNotifier(TwilioConfig{
+			AccountSID: os.Getenv("TWILIO_ACCOUNT_SID"),
+			AuthToken:  os.Getenv("TWILIO_AUTH_TOKEN"),
+			From:       os.Getenv("TWILIO_FROM"),
+			BaseURL:    os.Getenv("TWILIO_BASE_URL"),
+		})
+		if err != nil {
+			log.Fatal("failed to initialise SMS notifier:", err)
+		}
+		notifiers.SMS = notifier
+	default:
+		log.Fatalf("unknown NOTIFY_SMS %q", os.Getenv("NOTIFY_SMS"))
+	}
+
+	return notifiers
+}
+
+func envOrDefault(name, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,128 @@
+package notify
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultLocale is used when a user's locale has no templates.
+const DefaultLocale = "en"
+
+// RentalData is what the rental templates are rendered with. Times are in
+// the pickup branch's timezone, amounts in minor units of Currency.
+type RentalData struct {
+	Name         string
+	Reference    string
+	Vehicle      string
+	Pickup       string
+	Dropoff      string
+	Start        time.Time
+	End          time.Time
+	ReturnedAt   time.Time
+	Currency     string
+	TotalCents   int64
+	ChargedCents int64
+	LateFeeCents int64
+	// LateHourlyCents is what each further started hour late adds.
+	LateHourlyCents int64
+	// Customer and WaitingOn are only set in staff alerts: who booked the
+	// rental, and the reference of the overdue rental a blocked one waits on.
+	Customer  string
+	WaitingOn string
+}
+
+// Each templates/<locale>/<kind>.tmpl defines "subject" and "email", and
+// "sms" for the short text.
+//
+//go:embed templates
+var templateFS embed.FS
+
+var templates = mustLoadTemplates()
+
+// Locales lists the locales with templates.
+func Locales() []string {
+	locales := make([]string, 0, len(templates))
+	for locale := range templates {
+		locales = append(locales, locale)
+	}
+	return locales
+}
+
+// NormalizeLocale reduces a tag such as "de-AT" to its language and falls
+// back to DefaultLocale when there are no templates for it.
+func NormalizeLocale(locale string) string {
+	locale, _, _ = strings.Cut(strings.ToLower(strings.ReplaceAll(locale, "_", "-")), "-")
+	if _, ok := templates[locale]; !ok {
+		return DefaultLocale
+	}
+	return locale
+}
+
+// Render fills in the templates of kind for channel ("email" or "sms").
+// Subject is empty for SMS.
+func Render(kind, channel, locale string, data RentalData) (subject, body string, err error) {
+	tmpl, ok := templates[NormalizeLocale(locale)][kind]
+	if !ok {
+		if tmpl, ok = templates[DefaultLocale][kind]; !ok {
+			return "", "", fmt.Errorf("no template for notification kind %q", kind)
+		}
+	}
+
+	execute := func(name string) (string, error) {
+		var buf bytes.Buffer
+		if err := tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(buf.String()), nil
+	}
+	switch channel {
+	case "email":
+		if subject, err = execute("subject"); err != nil {
+			return "", "", err
+		}
+		body, err = execute("email")
+		return subject, body + "\n", err
+	case "sms":
+		body, err = execute("sms")
+		return "", body, err
+	default:
+		return "", "", fmt.Errorf("unknown notification channel %q", channel)
+	}
+}
+
+func mustLoadTemplates() map[string]map[string]*template.Template {
+	funcs := template.FuncMap{
+		"money": func(cents int64, separator string) string {
+			sign := ""
+			if cents < 0 {
+				sign, cents = "-", -cents
+			}
+			return fmt.Sprintf("%s%d%s%02d", sign, cents/100, separator, cents%100)
+		},
+	}
+
+	loaded := map[string]map[string]*template.Template{}
+	files, err := fs.Glob(templateFS, "templates/*/*.tmpl")
+	if err != nil {
+		panic(err)
+	}
+	for _, file := range files {
+		locale := path.Base(path.Dir(file))
+		kind := strings.TrimSuffix(path.Base(file), ".tmpl")
+		tmpl := template.Must(template.New(kind).Funcs(funcs).ParseFS(templateFS, file))
+		if loaded[locale] == nil {
+			loaded[locale] = map[string]*template.Template{}
+		}
+		loaded[locale][kind] = tmpl
+	}
+	if len(loaded[DefaultLocale]) == 0 {
+		panic("notify: no templates for default locale " + DefaultLocale)
+	}
+	return loaded
+}
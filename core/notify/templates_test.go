@@ -0,0 +1,123 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+var testRentalData = RentalData{
+	Name:            "Alex",
+	Reference:       "AB12CD34",
+	Vehicle:         "VW Golf",
+	Pickup:          "Vehix Mitte, Torstraße 1, Berlin",
+	Dropoff:         "Vehix Mitte, Torstraße 1, Berlin",
+	Start:           time.Date(2025, 3, 7, 9, 30, 0, 0, time.UTC),
+	End:             time.Date(2025, 3, 9, 18, 0, 0, 0, time.UTC),
+	ReturnedAt:      time.Date(2025, 3, 9, 17, 45, 0, 0, time.UTC),
+	Currency:        "EUR",
+	TotalCents:      12345,
+	ChargedCents:    12345,
+	LateFeeCents:    1500,
+	LateHourlyCents: 1500,
+	Customer:        "Sam <sam@example.com>",
+	WaitingOn:       "EF56AB78",
+}
+
+func TestNormalizeLocale(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"en", "en"},
+		{"de", "de"},
+		{"de-AT", "de"},
+		{"DE_at", "de"},
+		{"de-CH-1901", "de"},
+		{"en-GB", "en"},
+		{"fr", DefaultLocale},
+		{"fr-DE", DefaultLocale},
+		{"", DefaultLocale},
+		{"-de", DefaultLocale},
+	}
+	for _, tt := range tests {
+		if got := NormalizeLocale(tt.in); got != tt.want {
+			t.Errorf("NormalizeLocale(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRenderLocalises(t *testing.T) {
+	tests := []struct {
+		name, locale, want string
+	}{
+		{"German", "de", "Hallo Alex"},
+		{"regional German", "de-AT", "am\n07.03.2025 um 09:30 Uhr"},
+		{"English", "en", "Hello Alex"},
+		{"unsupported locale falls back to English", "fr", "on\nFriday 7 March 2025 at 09:30"},
+		{"no locale falls back to English", "", "Hello Alex"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, body, err := Render("pickup_reminder", "email", tt.locale, testRentalData)
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			if !strings.Contains(body, tt.want) {
+				t.Errorf("body in locale %q doesn't mention %q:\n%s", tt.locale, tt.want, body)
+			}
+		})
+	}
+}
+
+func TestRenderFallsBackPerKind(t *testing.T) {
+	// A kind not yet translated goes out in English rather than not at all.
+	saved := templates["de"]["receipt"]
+	delete(templates["de"], "receipt")
+	t.Cleanup(func() { templates["de"]["receipt"] = saved })
+
+	de, _, err := Render("receipt", "email", "de", testRentalData)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	en, _, _ := Render("receipt", "email", "en", testRentalData)
+	if de != en {
+		t.Errorf("untranslated kind rendered %q, want the English %q", de, en)
+	}
+}
+
+func TestRenderEveryTemplate(t *testing.T) {
+	for locale, kinds := range templates {
+		for kind := range kinds {
+			subject, body, err := Render(kind, "email", locale, testRentalData)
+			if err != nil || subject == "" || strings.TrimSpace(body) == "" {
+				t.Errorf("%s/%s email: subject %q, body %q, err %v", locale, kind, subject, body, err)
+			}
+			if !strings.Contains(subject+body, testRentalData.Reference) {
+				t.Errorf("%s/%s email doesn't carry the booking reference", locale, kind)
+			}
+			if strings.Contains(subject+body, "<no value>") {
+				t.Errorf("%s/%s email uses a field RentalData doesn't have", locale, kind)
+			}
+
+			subject, body, err = Render(kind, "sms", locale, testRentalData)
+			if err != nil || subject != "" || body == "" {
+				t.Errorf("%s/%s sms: subject %q, body %q, err %v", locale, kind, subject, body, err)
+			}
+			if strings.Contains(body, "\n") {
+				t.Errorf("%s/%s sms spans lines: %q", locale, kind, body)
+			}
+		}
+		if len(kinds) != len(templates[DefaultLocale]) {
+			t.Errorf("locale %s has %d kinds, %s has %d", locale, len(kinds), DefaultLocale, len(templates[DefaultLocale]))
+		}
+	}
+}
+
+func TestRenderRejectsUnknown(t *testing.T) {
+	if _, _, err := Render("no_such_kind", "email", "en", testRentalData); err == nil {
+		t.Error("Render of an unknown kind succeeded")
+	}
+	if _, _, err := Render("receipt", "fax", "en", testRentalData); err == nil {
+		t.Error("Render on an unknown channel succeeded")
+	}
+}
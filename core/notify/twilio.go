@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type TwilioConfig struct {
+	AccountSID string
+	AuthToken  string
+	From       string // sender number in E.164 form, or a messaging service SID
+	BaseURL    string // defaults to https://api.twilio.com; point elsewhere for compatible providers
+}
+
+// TwilioNotifier sends SMS through Twilio's Messages API.
+type TwilioNotifier struct {
+	cfg      TwilioConfig
+	endpoint string
+	client   *http.Client
+}
+
+func NewTwilioNotifier(cfg TwilioConfig) (*TwilioNotifier, error) {
+	if cfg.AccountSID == "" || cfg.AuthToken == "" || cfg.From == "" {
+		return nil, errors.New("TWILIO_ACCOUNT_SID, TWILIO_AUTH_TOKEN and TWILIO_FROM are required")
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "https://api.twilio.com"
+	}
+	return &TwilioNotifier{
+		cfg:      cfg,
+		endpoint: strings.TrimRight(cfg.BaseURL, "/") + "/2010-04-01/Accounts/" + url.PathEscape(cfg.AccountSID) + "/Messages.json",
+		client:   &http.Client{Timeout: 15 * time.Second},
+	}, nil
+}
+
+func (n *TwilioNotifier) Send(ctx context.Context, msg Message) error {
+	form := url.Values{"To": {msg.To}, "Body": {msg.Body}}
+	if strings.HasPrefix(n.cfg.From, "MG") {
+		form.Set("MessagingServiceSid", n.cfg.From)
+	} else {
+		form.Set("From", n.cfg.From)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(n.cfg.AccountSID, n.cfg.AuthToken)
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("sms provider: %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
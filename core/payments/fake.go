@@ -0,0 +1,205 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Test tokens understood by FakeGateway. Any other non-empty token authorizes.
+const (
+	FakeTokenDecline = "tok_decline"
+	FakeTokenPending = "tok_pending"
+)
+
+// FakeGateway is an in-process PaymentGateway for development and tests. It
+// keeps authorizations in memory, honours idempotency keys and signs the
+// webhook events it emits with the same scheme real traffic is checked with.
+type FakeGateway struct {
+	secret string
+
+	mu      sync.Mutex
+	auths   map[string]*fakeAuthorization
+	results map[string]Result // by idempotency key
+}
+
+type fakeAuthorization struct {
+	reference  string
+	authorized int64
+	captured   int64
+	refunded   int64
+	voided     bool
+	pending    bool
+}
+
+func NewFakeGateway(secret string) *FakeGateway {
+	return &FakeGateway{
+		secret:  secret,
+		auths:   map[string]*fakeAuthorization{},
+		results: map[string]Result{},
+	}
+}
+
+func (g *FakeGateway) Authorize(_ context.Context, req AuthorizeRequest) (*Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if res, ok := g.replay(req.IdempotencyKey); ok {
+		return res, nil
+	}
+	if req.AmountCents <= 0 {
+		return nil, errors.New("amount must be positive")
+	}
+
+	res := Result{Status: StatusSucceeded, AmountCents: req.AmountCents}
+	switch req.PaymentToken {
+	case "", FakeTokenDecline:
+		res.Status, res.DeclineReason = StatusDeclined, "card_declined"
+	default:
+		res.AuthorizationID = "auth_" + uuid.NewString()
+		g.auths[res.AuthorizationID] = &fakeAuthorization{
+			reference:  req.Reference,
+			authorized: req.AmountCents,
+			pending:    req.PaymentToken == FakeTokenPending,
+		}
+		if req.PaymentToken == FakeTokenPending {
+			res.Status = StatusPending
+		}
+	}
+	return g.remember(req.IdempotencyKey, res), nil
+}
+
+func (g *FakeGateway) Capture(_ context.Context, authorizationID string, amountCents int64, idempotencyKey string) (*Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if res, ok := g.replay(idempotencyKey); ok {
+		return res, nil
+	}
+	auth, err := g.settled(authorizationID)
+	if err != nil {
+		return nil, err
+	}
+	if auth.captured > 0 {
+		return nil, errors.New("authorization already captured")
+	}
+	if amountCents < 0 || amountCents > auth.authorized {
+		return nil, fmt.Errorf("capture of %d exceeds authorized %d", amountCents, auth.authorized)
+	}
+	auth.captured = amountCents
+	return g.remember(idempotencyKey, Result{AuthorizationID: authorizationID, Status: StatusSucceeded, AmountCents: amountCents}), nil
+}
+
+func (g *FakeGateway) Void(_ context.Context, authorizationID, idempotencyKey string) (*Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if res, ok := g.replay(idempotencyKey); ok {
+		return res, nil
+	}
+	auth, ok := g.auths[authorizationID]
+	if !ok {
+		return nil, fmt.Errorf("unknown authorization %s", authorizationID)
+	}
+	if auth.captured > 0 {
+		return nil, errors.New("captured authorizations must be refunded, not voided")
+	}
+	auth.voided = true
+	return g.remember(idempotencyKey, Result{AuthorizationID: authorizationID, Status: StatusSucceeded}), nil
+}
+
+func (g *FakeGateway) Refund(_ context.Context, authorizationID string, amountCents int64, idempotencyKey string) (*Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if res, ok := g.replay(idempotencyKey); ok {
+		return res, nil
+	}
+	auth, ok := g.auths[authorizationID]
+	if !ok {
+		return nil, fmt.Errorf("unknown authorization %s", authorizationID)
+	}
+	if amountCents <= 0 || auth.refunded+amountCents > auth.captured {
+		return nil, fmt.Errorf("refund of %d exceeds captured balance %d", amountCents, auth.captured-auth.refunded)
+	}
+	auth.refunded += amountCents
+	return g.remember(idempotencyKey, Result{AuthorizationID: authorizationID, Status: StatusSucceeded, AmountCents: amountCents}), nil
+}
+
+func (g *FakeGateway) ParseWebhook(body []byte, signature string) (*Event, error) {
+	if err := VerifySignature(g.secret, body, signature, time.Now()); err != nil {
+		return nil, err
+	}
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("decoding webhook: %w", err)
+	}
+	if event.ID == "" || event.Type == "" || event.AuthorizationID == "" {
+		return nil, errors.New("webhook event is missing id, type or authorization_id")
+	}
+	return &event, nil
+}
+
+// Settle resolves a pending authorization the way a real gateway would some
+// time after the request, returning the signed webhook it would deliver.
+func (g *FakeGateway) Settle(authorizationID string, approve bool) (body []byte, signature string, err error) {
+	g.mu.Lock()
+	auth, ok := g.auths[authorizationID]
+	if !ok || !auth.pending {
+		g.mu.Unlock()
+		return nil, "", fmt.Errorf("no pending authorization %s", authorizationID)
+	}
+	auth.pending = false
+	event := Event{
+		ID:              "evt_" + uuid.NewString(),
+		Type:            EventAuthorized,
+		AuthorizationID: authorizationID,
+		Reference:       auth.reference,
+		AmountCents:     auth.authorized,
+		CreatedAt:       time.Now().UTC(),
+	}
+	if !approve {
+		auth.voided = true
+		event.Type, event.AmountCents, event.Reason = EventAuthorizationFailed, 0, "card_declined"
+	}
+	g.mu.Unlock()
+
+	if body, err = json.Marshal(event); err != nil {
+		return nil, "", err
+	}
+	return body, SignPayload(g.secret, body, time.Now()), nil
+}
+
+// settled returns an authorization that can be captured.
+func (g *FakeGateway) settled(authorizationID string) (*fakeAuthorization, error) {
+	auth, ok := g.auths[authorizationID]
+	switch {
+	case !ok:
+		return nil, fmt.Errorf("unknown authorization %s", authorizationID)
+	case auth.pending:
+		return nil, errors.New("authorization is still pending")
+	case auth.voided:
+		return nil, errors.New("authorization was voided")
+	}
+	return auth, nil
+}
+
+func (g *FakeGateway) replay(key string) (*Result, bool) {
+	res, ok := g.results[key]
+	if !ok || key == "" {
+		return nil, false
+	}
+	return &res, true
+}
+
+func (g *FakeGateway) remember(key string, res Result) *Result {
+	if key != "" {
+		g.results[key] = res
+	}
+	return &res
+}
@@ -0,0 +1,190 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func authorize(t *testing.T, g *FakeGateway, amount int64, token, key string) *Result {
+	t.Helper()
+	res, err := g.Authorize(context.Background(), AuthorizeRequest{
+		AmountCents:    amount,
+		Currency:       "EUR",
+		PaymentToken:   token,
+		Reference:      "rental-1",
+		IdempotencyKey: key,
+	})
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	return res
+}
+
+func TestFakeGatewayAuthorizeCaptureRefund(t *testing.T) {
+	g := NewFakeGateway("whsec_test")
+	ctx := context.Background()
+
+	auth := authorize(t, g, 10000, "tok_visa", "auth-1")
+	if auth.Status != StatusSucceeded || auth.AuthorizationID == "" || auth.AmountCents != 10000 {
+		t.Fatalf("Authorize = %+v", auth)
+	}
+
+	if _, err := g.Capture(ctx, auth.AuthorizationID, 12000, "capture-over"); err == nil {
+		t.Error("capturing more than authorized succeeded")
+	}
+	capture, err := g.Capture(ctx, auth.AuthorizationID, 8000, "capture-1")
+	if err != nil || capture.Status != StatusSucceeded || capture.AmountCents != 8000 {
+		t.Fatalf("Capture = %+v, %v", capture, err)
+	}
+	if _, err := g.Capture(ctx, auth.AuthorizationID, 8000, "capture-2"); err == nil {
+		t.Error("second capture under a new key succeeded")
+	}
+	if _, err := g.Void(ctx, auth.AuthorizationID, "void-1"); err == nil {
+		t.Error("voiding a captured authorization succeeded")
+	}
+
+	if _, err := g.Refund(ctx, auth.AuthorizationID, 3000, "refund-1"); err != nil {
+		t.Fatalf("Refund: %v", err)
+	}
+	if _, err := g.Refund(ctx, auth.AuthorizationID, 5001, "refund-2"); err == nil {
+		t.Error("refunding more than the captured balance succeeded")
+	}
+	if _, err := g.Refund(ctx, auth.AuthorizationID, 5000, "refund-3"); err != nil {
+		t.Errorf("refunding the rest of the balance: %v", err)
+	}
+}
+
+func TestFakeGatewayVoid(t *testing.T) {
+	g := NewFakeGateway("whsec_test")
+	ctx := context.Background()
+
+	auth := authorize(t, g, 5000, "tok_visa", "auth-1")
+	res, err := g.Void(ctx, auth.AuthorizationID, "void-1")
+	if err != nil || res.Status != StatusSucceeded {
+		t.Fatalf("Void = %+v, %v", res, err)
+	}
+	if _, err := g.Capture(ctx, auth.AuthorizationID, 5000, "capture-1"); err == nil {
+		t.Error("capturing a voided authorization succeeded")
+	}
+	if _, err := g.Refund(ctx, auth.AuthorizationID, 100, "refund-1"); err == nil {
+		t.Error("refunding a voided authorization succeeded")
+	}
+}
+
+func TestFakeGatewayIdempotencyReplay(t *testing.T) {
+	g := NewFakeGateway("whsec_test")
+	ctx := context.Background()
+
+	first := authorize(t, g, 10000, "tok_visa", "auth-1")
+	again := authorize(t, g, 10000, "tok_visa", "auth-1")
+	if *again != *first {
+		t.Errorf("replayed Authorize = %+v, want %+v", again, first)
+	}
+	if len(g.auths) != 1 {
+		t.Errorf("replayed Authorize placed %d holds, want 1", len(g.auths))
+	}
+	if other := authorize(t, g, 10000, "tok_visa", "auth-2"); other.AuthorizationID == first.AuthorizationID {
+		t.Error("a new idempotency key reused the earlier authorization")
+	}
+
+	// A retried capture returns the original result instead of failing as a
+	// second capture would.
+	capture, err := g.Capture(ctx, first.AuthorizationID, 10000, "capture-1")
+	if err != nil {
+		t.Fatalf("Capture: %v", err)
+	}
+	retried, err := g.Capture(ctx, first.AuthorizationID, 10000, "capture-1")
+	if err != nil || *retried != *capture {
+		t.Errorf("replayed Capture = %+v, %v; want %+v", retried, err, capture)
+	}
+
+	// Retrying a refund must not refund twice.
+	for range 3 {
+		if _, err := g.Refund(ctx, first.AuthorizationID, 6000, "refund-1"); err != nil {
+			t.Fatalf("Refund: %v", err)
+		}
+	}
+	if refunded := g.auths[first.AuthorizationID].refunded; refunded != 6000 {
+		t.Errorf("refunded %d after retries, want 6000", refunded)
+	}
+
+	// Void replays too, even once the hold is gone.
+	second := authorize(t, g, 2000, "tok_visa", "auth-3")
+	void, err := g.Void(ctx, second.AuthorizationID, "void-1")
+	if err != nil {
+		t.Fatalf("Void: %v", err)
+	}
+	if retried, err := g.Void(ctx, second.AuthorizationID, "void-1"); err != nil || *retried != *void {
+		t.Errorf("replayed Void = %+v, %v; want %+v", retried, err, void)
+	}
+
+	// A declined attempt replays as declined rather than being retried.
+	declined := authorize(t, g, 1000, FakeTokenDecline, "auth-4")
+	if declined.Status != StatusDeclined || declined.DeclineReason == "" {
+		t.Fatalf("Authorize with %s = %+v", FakeTokenDecline, declined)
+	}
+	if again := authorize(t, g, 1000, FakeTokenDecline, "auth-4"); *again != *declined {
+		t.Errorf("replayed decline = %+v, want %+v", again, declined)
+	}
+}
+
+func TestFakeGatewayPendingSettlement(t *testing.T) {
+	g := NewFakeGateway("whsec_test")
+	ctx := context.Background()
+
+	for _, approve := range []bool{true, false} {
+		auth := authorize(t, g, 4000, FakeTokenPending, "")
+		if auth.Status != StatusPending {
+			t.Fatalf("Authorize with %s = %+v", FakeTokenPending, auth)
+		}
+		if _, err := g.Capture(ctx, auth.AuthorizationID, 4000, ""); err == nil {
+			t.Error("capturing a pending authorization succeeded")
+		}
+
+		body, signature, err := g.Settle(auth.AuthorizationID, approve)
+		if err != nil {
+			t.Fatalf("Settle: %v", err)
+		}
+		event, err := g.ParseWebhook(body, signature)
+		if err != nil {
+			t.Fatalf("ParseWebhook: %v", err)
+		}
+		if event.AuthorizationID != auth.AuthorizationID || event.Reference != "rental-1" {
+			t.Errorf("event = %+v", event)
+		}
+
+		_, captureErr := g.Capture(ctx, auth.AuthorizationID, 4000, "")
+		if approve {
+			if event.Type != EventAuthorized || event.AmountCents != 4000 || captureErr != nil {
+				t.Errorf("approved: event = %+v, capture err = %v", event, captureErr)
+			}
+		} else if event.Type != EventAuthorizationFailed || captureErr == nil {
+			t.Errorf("declined: event = %+v, capture err = %v", event, captureErr)
+		}
+
+		if _, _, err := g.Settle(auth.AuthorizationID, approve); err == nil {
+			t.Error("settling twice succeeded")
+		}
+	}
+}
+
+func TestFakeGatewayParseWebhookRejectsForgery(t *testing.T) {
+	g := NewFakeGateway("whsec_test")
+	auth := authorize(t, g, 4000, FakeTokenPending, "")
+	body, signature, err := g.Settle(auth.AuthorizationID, true)
+	if err != nil {
+		t.Fatalf("Settle: %v", err)
+	}
+
+	var event map[string]any
+	json.Unmarshal(body, &event)
+	event["amount_cents"] = 1
+	forged, _ := json.Marshal(event)
+	if _, err := g.ParseWebhook(forged, signature); err == nil {
+		t.Error("ParseWebhook accepted a modified body")
+	}
+	if _, err := NewFakeGateway("whsec_other").ParseWebhook(body, signature); err == nil {
+		t.Error("ParseWebhook accepted an event signed with another secret")
+	}
+}
@@ -0,0 +1,148 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Result statuses. Gateways that settle asynchronously answer pending and
+// report the outcome later through a webhook.
+const (
+	StatusSucceeded = "succeeded"
+	StatusPending   = "pending"
+	StatusDeclined  = "declined"
+)
+
+// Webhook event types.
+const (
+	EventAuthorized          = "payment.authorized"
+	EventAuthorizationFailed = "payment.authorization_failed"
+	EventCaptured            = "payment.captured"
+	EventRefunded            = "payment.refunded"
+	EventVoided              = "payment.voided"
+)
+
+// SignatureHeader carries the webhook signature: "t=<unix seconds>,v1=<hex>",
+// where v1 is the HMAC-SHA256 of "<t>.<body>" under the webhook secret.
+const SignatureHeader = "Vehix-Signature"
+
+// signatureTolerance bounds how old a signed webhook may be, which limits
+// replays of captured requests.
+const signatureTolerance = 5 * time.Minute
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// PaymentGateway places and settles card holds. Every call takes an
+// idempotency key; repeating a call with the same key returns the original
+// result instead of moving money twice, so callers can retry freely.
+type PaymentGateway interface {
+	// Authorize places a hold on the customer's payment method.
+	Authorize(ctx context.Context, req AuthorizeRequest) (*Result, error)
+	// Capture takes up to the authorized amount and releases the rest.
+	Capture(ctx context.Context, authorizationID string, amountCents int64, idempotencyKey string) (*Result, error)
+	// Void releases a hold that was never captured.
+	Void(ctx context.Context, authorizationID, idempotencyKey string) (*Result, error)
+	// Refund returns money that was already captured.
+	Refund(ctx context.Context, authorizationID string, amountCents int64, idempotencyKey string) (*Result, error)
+	// ParseWebhook verifies the signature of an incoming event and decodes it.
+	ParseWebhook(body []byte, signature string) (*Event, error)
+}
+
+type AuthorizeRequest struct {
+	AmountCents    int64
+	Currency       string
+	PaymentToken   string // tokenised payment method from the client
+	Reference      string // our rental ID, echoed back in webhooks
+	IdempotencyKey string
+}
+
+type Result struct {
+	AuthorizationID string
+	Status          string
+	AmountCents     int64
+	DeclineReason   string
+}
+
+// Event is an asynchronous notification from the gateway. Amounts are
+// running totals for the authorization (total captured, total refunded), so
+// applying an event twice, or one for an operation whose result we already
+// recorded, changes nothing.
+type Event struct {
+	ID              string    `json:"id"`
+	Type            string    `json:"type"`
+	AuthorizationID string    `json:"authorization_id"`
+	Reference       string    `json:"reference"`
+	AmountCents     int64     `json:"amount_cents"`
+	Reason          string    `json:"reason,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// Currency is the ISO 4217 code all amounts are charged in.
+var Currency = envOr("PAYMENT_CURRENCY", "EUR")
+
+// Connect builds the gateway selected by PAYMENT_GATEWAY. Only the in-process
+// fake ("fake", the default) ships today; real providers plug in here.
+func Connect() PaymentGateway {
+	switch os.Getenv("PAYMENT_GATEWAY") {
+	case "", "fake":
+		return NewFakeGateway(envOr("PAYMENT_WEBHOOK_SECRET", "whsec_dev"))
+	default:
+		log.Fatalf("unknown PAYMENT_GATEWAY %q", os.Getenv("PAYMENT_GATEWAY"))
+		return nil
+	}
+}
+
+// SignPayload produces a SignatureHeader value for body at time t.
+func SignPayload(secret string, body []byte, t time.Time) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + ts + ",v1=" + signature(secret, ts, body)
+}
+
+// VerifySignature checks a SignatureHeader value against body.
+func VerifySignature(secret string, body []byte, header string, now time.Time) error {
+	var ts, sig string
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			sig = v
+		}
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || sig == "" {
+		return ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(unix, 0)); age > signatureTolerance || age < -signatureTolerance {
+		return fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidSignature)
+	}
+	if !hmac.Equal([]byte(sig), []byte(signature(secret, ts, body))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func signature(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func envOr(name, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return fallback
+}
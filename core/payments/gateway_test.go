@@ -0,0 +1,65 @@
+package payments
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignPayloadKnownAnswer(t *testing.T) {
+	got := SignPayload("whsec_test", []byte(`{"id":"evt_1"}`), time.Unix(1700000000, 0))
+	want := "t=1700000000,v1=c89214b5b5da833daed6f0b8c5bb6bd58cea9022bd80ccc78230f3942d632925"
+	if got != want {
+		t.Errorf("SignPayload = %q, want %q", got, want)
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	const secret = "whsec_test"
+	body := []byte(`{"id":"evt_1","type":"payment.authorized"}`)
+	now := time.Unix(1700000000, 0)
+	valid := SignPayload(secret, body, now)
+	mac := valid[strings.Index(valid, "v1=")+3:]
+
+	tests := []struct {
+		name   string
+		secret string
+		body   []byte
+		header string
+		now    time.Time
+		stale  bool
+		ok     bool
+	}{
+		{name: "valid", secret: secret, body: body, header: valid, now: now, ok: true},
+		{name: "valid within tolerance", secret: secret, body: body, header: valid, now: now.Add(4 * time.Minute), ok: true},
+		{name: "reordered with spaces", secret: secret, body: body, header: "v1=" + mac + ", t=1700000000", now: now, ok: true},
+		{name: "tampered body", secret: secret, body: []byte(`{"id":"evt_2","type":"payment.authorized"}`), header: valid, now: now},
+		{name: "wrong secret", secret: "whsec_other", body: body, header: valid, now: now},
+		{name: "flipped MAC", secret: secret, body: body, header: "t=1700000000,v1=0" + mac[1:], now: now},
+		{name: "MAC for another timestamp", secret: secret, body: body, header: "t=1700000001,v1=" + mac, now: now},
+		{name: "stale", secret: secret, body: body, header: valid, now: now.Add(6 * time.Minute), stale: true},
+		{name: "from the future", secret: secret, body: body, header: valid, now: now.Add(-6 * time.Minute), stale: true},
+		{name: "missing MAC", secret: secret, body: body, header: "t=1700000000", now: now},
+		{name: "missing timestamp", secret: secret, body: body, header: "v1=" + mac, now: now},
+		{name: "empty", secret: secret, body: body, header: "", now: now},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifySignature(tt.secret, tt.body, tt.header, tt.now)
+			if tt.ok {
+				if err != nil {
+					t.Fatalf("VerifySignature: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidSignature) {
+				t.Fatalf("VerifySignature: err = %v, want ErrInvalidSignature", err)
+			}
+			if stale := strings.Contains(err.Error(), "tolerance"); stale != tt.stale {
+				t.Errorf("VerifySignature: err = %v, stale = %v, want stale = %v", err, stale, tt.stale)
+			}
+		})
+	}
+}
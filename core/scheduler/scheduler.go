@@ -0,0 +1,29 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"vehix/core/logger"
+	"vehix/core/messages"
+)
+
+// Every runs task immediately and then once per interval until ctx is
+// cancelled. Failures are logged and retried on the next tick.
+func Every(ctx context.Context, name string, interval time.Duration, task func(context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := task(ctx); err != nil {
+			logger.Error(fmt.Sprintf("[%s] %s: %s: %s",
+				messages.ERR_SCHEDULED_TASK.Code, messages.ERR_SCHEDULED_TASK.Text, name, err.Error()))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,340 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// API keys have the form vhx_<prefix>_<secret>. The prefix is stored in clear
+// so a key can be identified (and looked up) without storing the secret.
+const (
+	apiKeyScheme       = "vhx"
+	apiKeyPrefixBytes  = 6
+	apiKeySecretBytes  = 32
+	apiKeyWildcardPerm = "*"
+)
+
+var apiKeyPermissionPattern = regexp.MustCompile(`^(\*|[a-z][a-z0-9-]*):(\*|read|write)$`)
+
+type APIKeyClaims struct {
+	KeyID        string
+	UserID       string
+	Email        string
+	Role         string
+	Organization string
+	Permissions  []string
+}
+
+// Allows reports whether the key may perform the given action ("read" or
+// "write") on the given resource (the first path segment under /v1).
+func (c *APIKeyClaims) Allows(resource, action string) bool {
+	for _, p := range c.Permissions {
+		if p == apiKeyWildcardPerm {
+			return true
+		}
+		res, act, ok := strings.Cut(p, ":")
+		if !ok {
+			continue
+		}
+		if (res == "*" || res == resource) && (act == "*" || act == action) {
+			return true
+		}
+	}
+	return false
+}
+
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, createdBy string, payload models.CreateAPIKeyPayload) (int, *models.CreateAPIKeyResponse, *models.ErrorResponse)
+	ListAPIKeys(ctx context.Context) (int, *[]models.APIKeyResponse, *models.ErrorResponse)
+	RevokeAPIKey(ctx context.Context, keyID string) (int, *models.ErrorResponse)
+	VerifyAPIKey(ctx context.Context, rawKey string) (*APIKeyClaims, error)
+}
+
+type APIKeyServiceImpl struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyService(db *gorm.DB) APIKeyService {
+	return &APIKeyServiceImpl{db: db}
+}
+
+func (s *APIKeyServiceImpl) CreateAPIKey(ctx context.Context, createdBy string, payload models.CreateAPIKeyPayload) (int, *models.CreateAPIKeyResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	for _, p := range payload.Permissions {
+		if p != apiKeyWildcardPerm && !apiKeyPermissionPattern.MatchString(p) {
+			return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+				MessageID: messages.ERR_INVALID_API_KEY_PERMISSION.Code,
+				Message:   messages.ERR_INVALID_API_KEY_PERMISSION.Text,
+				Exception: fmt.Sprintf("permission %q must be '*' or '<resource>:<read|write|*>'", p),
+			}
+		}
+	}
+
+	if payload.ExpiresAt != nil && payload.ExpiresAt.Before(time.Now()) {
+		return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_BAD_REQUEST.Code,
+			Message:   messages.ERR_BAD_REQUEST.Text,
+			Exception: "expires_at must be in the future",
+		}
+	}
+
+	ownerID, err := uuid.Parse(payload.UserID)
+	if err != nil {
+		return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_BAD_REQUEST.Code,
+			Message:   messages.ERR_BAD_REQUEST.Text,
+			Exception: "user_id must be a valid UUID",
+		}
+	}
+
+	var owner models.User
+	if err := db.Where("id = ?", ownerID).First(&owner).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.StatusNotFound, nil, &models.ErrorResponse{
+				MessageID: messages.ERR_USER_NOT_FOUND.Code,
+				Message:   messages.ERR_USER_NOT_FOUND.Text,
+				Exception: "owning user not found",
+			}
+		}
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	creatorID, err := uuid.Parse(createdBy)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNAUTHORIZED.Code,
+			Message:   messages.ERR_UNAUTHORIZED.Text,
+			Exception: "invalid creator ID",
+		}
+	}
+
+	prefix, rawKey, err := generateAPIKey()
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	key := models.APIKey{
+		Name:         payload.Name,
+		Prefix:       prefix,
+		KeyHash:      hashAPIKey(rawKey),
+		Organization: payload.Organization,
+		UserID:       owner.ID,
+		Permissions:  models.StringList(payload.Permissions),
+		CreatedBy:    creatorID,
+		ExpiresAt:    payload.ExpiresAt,
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&key).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditAPIKeyCreate, "api_key", key.ID.String(), nil, key)
+	})
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	return fiber.StatusCreated, &models.CreateAPIKeyResponse{
+		APIKeyResponse: toAPIKeyResponse(key),
+		Key:            rawKey,
+	}, nil
+}
+
+func (s *APIKeyServiceImpl) ListAPIKeys(ctx context.Context) (int, *[]models.APIKeyResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	var keys []models.APIKey
+	if err := db.Order("created_at DESC").Find(&keys).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := []models.APIKeyResponse{}
+	for _, k := range keys {
+		response = append(response, toAPIKeyResponse(k))
+	}
+
+	return fiber.StatusOK, &response, nil
+}
+
+func (s *APIKeyServiceImpl) RevokeAPIKey(ctx context.Context, keyID string) (int, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	if _, err := uuid.Parse(keyID); err != nil {
+		return fiber.StatusNotFound, &models.ErrorResponse{
+			MessageID: messages.ERR_API_KEY_NOT_FOUND.Code,
+			Message:   messages.ERR_API_KEY_NOT_FOUND.Text,
+			Exception: "invalid API key ID",
+		}
+	}
+
+	var key models.APIKey
+	err := db.Where("id = ? AND revoked_at IS NULL", keyID).First(&key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.StatusNotFound, &models.ErrorResponse{
+				MessageID: messages.ERR_API_KEY_NOT_FOUND.Code,
+				Message:   messages.ERR_API_KEY_NOT_FOUND.Text,
+				Exception: "API key not found or already revoked",
+			}
+		}
+		return fiber.StatusInternalServerError, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	before := key
+	err = db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		key.RevokedAt = &now
+		if err := tx.Model(&key).Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditAPIKeyRevoke, "api_key", keyID, before, key)
+	})
+	if err != nil {
+		return fiber.StatusInternalServerError, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	return fiber.StatusNoContent, nil
+}
+
+func (s *APIKeyServiceImpl) VerifyAPIKey(ctx context.Context, rawKey string) (*APIKeyClaims, error) {
+	db := s.db.WithContext(ctx)
+
+	prefix, err := parseAPIKeyPrefix(rawKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var key models.APIKey
+	if err := db.Where("prefix = ?", prefix).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("unknown API key")
+		}
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(key.KeyHash), []byte(hashAPIKey(rawKey))) != 1 {
+		return nil, errors.New("unknown API key")
+	}
+
+	if key.RevokedAt != nil {
+		return nil, errors.New("API key has been revoked")
+	}
+
+	now := time.Now()
+	if key.ExpiresAt != nil && key.ExpiresAt.Before(now) {
+		return nil, errors.New("API key has expired")
+	}
+
+	var owner models.User
+	if err := db.Where("id = ?", key.UserID).First(&owner).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("API key owner no longer exists")
+		}
+		return nil, err
+	}
+
+	// Failing to record last use shouldn't fail the request it was used for.
+	if err := db.Model(&key).UpdateColumn("last_used_at", now).Error; err != nil {
+		logger.Warn(fmt.Sprintf("[%s] %s: recording use of API key %s: %s", messages.ERR_UNEXPECTED_ERROR.Code,
+			messages.ERR_UNEXPECTED_ERROR.Text, key.ID, err))
+	}
+
+	return &APIKeyClaims{
+		KeyID:        key.ID.String(),
+		UserID:       owner.ID.String(),
+		Email:        owner.Email,
+		Role:         owner.Role,
+		Organization: key.Organization,
+		Permissions:  key.Permissions,
+	}, nil
+}
+
+func generateAPIKey() (prefix, rawKey string, err error) {
+	prefixBytes := make([]byte, apiKeyPrefixBytes)
+	if _, err = rand.Read(prefixBytes); err != nil {
+		return "", "", err
+	}
+	secretBytes := make([]byte, apiKeySecretBytes)
+	if _, err = rand.Read(secretBytes); err != nil {
+		return "", "", err
+	}
+
+	prefix = hex.EncodeToString(prefixBytes)
+	rawKey = fmt.Sprintf("%s_%s_%s", apiKeyScheme, prefix, hex.EncodeToString(secretBytes))
+	return prefix, rawKey, nil
+}
+
+func parseAPIKeyPrefix(rawKey string) (string, error) {
+	parts := strings.Split(rawKey, "_")
+	if len(parts) != 3 || parts[0] != apiKeyScheme || len(parts[1]) != apiKeyPrefixBytes*2 || len(parts[2]) != apiKeySecretBytes*2 {
+		return "", errors.New("malformed API key")
+	}
+	return parts[1], nil
+}
+
+func hashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
+
+func toAPIKeyResponse(k models.APIKey) models.APIKeyResponse {
+	resp := models.APIKeyResponse{
+		ID:           k.ID,
+		Name:         k.Name,
+		Prefix:       k.Prefix,
+		Organization: k.Organization,
+		UserID:       k.UserID,
+		Permissions:  k.Permissions,
+		CreatedAt:    k.CreatedAt.Format(time.RFC3339),
+	}
+	if k.ExpiresAt != nil {
+		resp.ExpiresAt = k.ExpiresAt.Format(time.RFC3339)
+	}
+	if k.LastUsedAt != nil {
+		resp.LastUsedAt = k.LastUsedAt.Format(time.RFC3339)
+	}
+	if k.RevokedAt != nil {
+		resp.RevokedAt = k.RevokedAt.Format(time.RFC3339)
+	}
+	return resp
+}
@@ -0,0 +1,401 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+	"vehix/core/messages"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Audit actions recorded by the services.
+const (
+	AuditUserRegister      = "user.register"
+	AuditUserUpdate        = "user.update"
+	AuditUserDelete        = "user.delete"
+	AuditUserAnonymize     = "user.anonymize"
+	AuditUserRoleAssign    = "user.role_assign"
+	AuditUserSuspend       = "user.suspend"
+	AuditUserReactivate    = "user.reactivate"
+	AuditUserPasswordReset = "user.password_reset_force"
+	AuditAPIKeyCreate      = "api_key.create"
+	AuditAPIKeyRevoke      = "api_key.revoke"
+	AuditWebhookCreate     = "webhook.create"
+	AuditWebhookDelete     = "webhook.delete"
+	AuditWebhookReplay     = "webhook_delivery.replay"
+	AuditVehicleCreate     = "vehicle.create"
+	AuditVehicleUpdate     = "vehicle.update"
+	AuditVehicleDelete     = "vehicle.delete"
+	AuditRentalCreate      = "rental.create"
+	AuditRentalHold        = "rental.hold"
+	AuditRentalConfirm     = "rental.confirm"
+	AuditRentalCancel      = "rental.cancel"
+	AuditRentalReturn      = "rental.return"
+	AuditRentalModify      = "rental.modify"
+	AuditRentalNoShow      = "rental.no_show"
+	AuditRentalOverdue     = "rental.overdue"
+	AuditRentalBlock       = "rental.block"
+	AuditJobRetry          = "job.retry"
+	AuditRentalPayment     = "rental.payment"
+	AuditVehicleRelocate   = "vehicle.relocate"
+	AuditMediaUpload       = "vehicle_media.upload"
+	AuditMediaDelete       = "vehicle_media.delete"
+	AuditMaintenanceCreate = "maintenance.create"
+	AuditMaintenanceUpdate = "maintenance.update"
+	AuditMaintenanceDone   = "maintenance.complete"
+	AuditIntervalCreate    = "service_interval.create"
+	AuditIntervalDelete    = "service_interval.delete"
+	AuditInspectionCreate  = "inspection.create"
+	AuditChargeCreate      = "rental_charge.create"
+	AuditChargeReview      = "rental_charge.review"
+	AuditInvoiceIssue      = "invoice.issue"
+	AuditCreditNoteIssue   = "invoice.credit_note"
+	AuditExtraCreate       = "extra.create"
+	AuditExtraUpdate       = "extra.update"
+	AuditExtraStock        = "extra.stock_set"
+	AuditPromoCreate       = "promo_code.create"
+	AuditPromoUpdate       = "promo_code.update"
+	AuditDriverUpdate      = "driver_profile.update"
+	AuditDriverReview      = "driver_profile.review"
+	AuditDriverRespond     = "rental_driver.respond"
+	AuditVehicleClassSet   = "vehicle_class.set"
+	AuditBranchCreate      = "branch.create"
+	AuditBranchUpdate      = "branch.update"
+	AuditBranchOneWayFee   = "branch.one_way_fee_set"
+	AuditNotificationPrefs = "notification_preference.update"
+	AuditDeviceCreate      = "telematics_device.create"
+	AuditDeviceRevoke      = "telematics_device.revoke"
+)
+
+const (
+	auditGenesisHash     = "0000000000000000000000000000000000000000000000000000000000000000"
+	auditChainLockKey    = 7_340_001
+	auditDefaultPageSize = 50
+	auditMaxPageSize     = 500
+)
+
+var errAuditChainBroken = errors.New("audit chain broken")
+
+// Personal data is kept out of the (immutable) audit trail so that erasing an
+// account doesn't require rewriting history. The diff still records that these
+// fields changed, just not their values. A dotted path reaches into nested
+// objects and into every element of a list.
+var auditRedactedFields = map[string][]string{
+	"user":                    {"Name", "Email"},
+	"notification_preference": {"Phone"},
+	"invoice":                 {"BillToName", "BillToEmail"},
+	"driver_profile":          {"DateOfBirth", "LicenseNumber", "LicenseCountry", "LicensePhotoKey"},
+	"rental_driver":           {"Name"},
+	"rental":                  {"Drivers.Name"},
+}
+
+const auditRedacted = "[redacted]"
+
+type AuditService interface {
+	ListAuditLogs(ctx context.Context, filter models.AuditLogFilter) (int, *[]models.AuditLogResponse, *models.ErrorResponse)
+	VerifyAuditChain(ctx context.Context) (int, *models.AuditChainVerification, *models.ErrorResponse)
+}
+
+type AuditServiceImpl struct {
+	db *gorm.DB
+}
+
+func NewAuditService(db *gorm.DB) AuditService {
+	return &AuditServiceImpl{db: db}
+}
+
+func (s *AuditServiceImpl) ListAuditLogs(ctx context.Context, filter models.AuditLogFilter) (int, *[]models.AuditLogResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	query := db.Model(&models.AuditLog{})
+	if filter.ActorID != "" {
+		query = query.Where("actor_id = ? OR api_key_id = ?", filter.ActorID, filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.ResourceType != "" {
+		query = query.Where("resource_type = ?", filter.ResourceType)
+	}
+	if filter.ResourceID != "" {
+		query = query.Where("resource_id = ?", filter.ResourceID)
+	}
+	for _, bound := range []struct {
+		value string
+		cond  string
+		name  string
+	}{
+		{filter.From, "created_at >= ?", "from"},
+		{filter.To, "created_at < ?", "to"},
+	} {
+		if bound.value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, bound.value)
+		if err != nil {
+			return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: bound.name + " must be an RFC3339 timestamp",
+			}
+		}
+		query = query.Where(bound.cond, t)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = auditDefaultPageSize
+	}
+	if limit > auditMaxPageSize {
+		limit = auditMaxPageSize
+	}
+
+	var entries []models.AuditLog
+	if err := query.Order("id DESC").Limit(limit).Offset(max(filter.Offset, 0)).Find(&entries).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := []models.AuditLogResponse{}
+	for _, e := range entries {
+		response = append(response, toAuditLogResponse(e))
+	}
+
+	return fiber.StatusOK, &response, nil
+}
+
+// VerifyAuditChain walks the whole log in insertion order and recomputes every
+// hash, reporting the first entry whose content or link no longer matches.
+func (s *AuditServiceImpl) VerifyAuditChain(ctx context.Context) (int, *models.AuditChainVerification, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	result := models.AuditChainVerification{Valid: true}
+	prevHash := auditGenesisHash
+
+	var batch []models.AuditLog
+	err := db.Order("id").FindInBatches(&batch, 1000, func(tx *gorm.DB, _ int) error {
+		for _, e := range batch {
+			result.Checked++
+			expected, err := computeAuditHash(&e)
+			if err != nil {
+				return err
+			}
+			if e.PrevHash != prevHash || e.Hash != expected {
+				id := e.ID
+				result.Valid = false
+				result.BrokenAtID = &id
+				return errAuditChainBroken
+			}
+			prevHash = e.Hash
+		}
+		return nil
+	}).Error
+
+	if err != nil && !errors.Is(err, errAuditChainBroken) {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	return fiber.StatusOK, &result, nil
+}
+
+// recordAudit appends an entry for a change made inside tx, so the entry is
+// committed (or rolled back) together with the change itself. The actor, IP
+// and request ID are taken from the request locals carried by ctx.
+func recordAudit(ctx context.Context, tx *gorm.DB, action, resourceType, resourceID string, before, after any) error {
+	entry, err := newAuditEntry(ctx, action, resourceType, resourceID, before, after)
+	if err != nil {
+		return err
+	}
+
+	// Serialise appends so every entry links to the one committed before it.
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", auditChainLockKey).Error; err != nil {
+		return err
+	}
+
+	var last models.AuditLog
+	if err := tx.Select("hash").Order("id DESC").Limit(1).Find(&last).Error; err != nil {
+		return err
+	}
+	entry.PrevHash = last.Hash
+	if entry.PrevHash == "" {
+		entry.PrevHash = auditGenesisHash
+	}
+
+	if entry.Hash, err = computeAuditHash(&entry); err != nil {
+		return err
+	}
+
+	return tx.Create(&entry).Error
+}
+
+// newAuditEntry builds an unchained entry with its snapshots, diff and actor
+// filled in and personal data already redacted.
+func newAuditEntry(ctx context.Context, action, resourceType, resourceID string, before, after any) (models.AuditLog, error) {
+	entry := models.AuditLog{
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		CreatedAt:    time.Now().UTC().Truncate(time.Microsecond),
+	}
+	setAuditActor(ctx, &entry)
+
+	var err error
+	if entry.Before, err = auditSnapshot(before); err != nil {
+		return entry, err
+	}
+	if entry.After, err = auditSnapshot(after); err != nil {
+		return entry, err
+	}
+	entry.Changes = auditDiff(entry.Before, entry.After)
+	redactAudit(&entry)
+	return entry, nil
+}
+
+func setAuditActor(ctx context.Context, entry *models.AuditLog) {
+	entry.ActorType = models.AuditActorSystem
+	if ip, ok := ctx.Value("ip").(string); ok && ip != "" {
+		entry.IP = ip
+		entry.ActorType = models.AuditActorAnonymous
+	}
+	if requestID, ok := ctx.Value("requestID").(string); ok {
+		entry.RequestID = requestID
+	}
+	if userID, ok := ctx.Value("userID").(string); ok && userID != "" {
+		entry.ActorID = userID
+		entry.ActorType = models.AuditActorUser
+	}
+	if keyID, ok := ctx.Value("apiKeyID").(string); ok && keyID != "" {
+		entry.APIKeyID = keyID
+		entry.ActorType = models.AuditActorAPIKey
+	}
+}
+
+// auditSnapshot converts a model into the generic JSON shape it is stored as,
+// so the hash computed before insert matches the one recomputed after reading
+// the jsonb column back.
+func auditSnapshot(v any) (models.JSONMap, error) {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Pointer && reflect.ValueOf(v).IsNil()) {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m models.JSONMap
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func auditDiff(before, after models.JSONMap) models.JSONMap {
+	if before == nil || after == nil {
+		return nil
+	}
+	changes := models.JSONMap{}
+	for _, m := range []models.JSONMap{before, after} {
+		for k := range m {
+			if k == "UpdatedAt" || changes[k] != nil || reflect.DeepEqual(before[k], after[k]) {
+				continue
+			}
+			changes[k] = map[string]any{"before": before[k], "after": after[k]}
+		}
+	}
+	return changes
+}
+
+func redactAudit(entry *models.AuditLog) {
+	for _, field := range auditRedactedFields[entry.ResourceType] {
+		path := strings.Split(field, ".")
+		for _, m := range []models.JSONMap{entry.Before, entry.After} {
+			redactAuditValue(map[string]any(m), path)
+		}
+		change, ok := entry.Changes[path[0]].(map[string]any)
+		if !ok {
+			continue
+		}
+		if len(path) == 1 {
+			entry.Changes[path[0]] = map[string]any{"before": auditRedacted, "after": auditRedacted}
+			continue
+		}
+		change["before"] = redactAuditValue(change["before"], path[1:])
+		change["after"] = redactAuditValue(change["after"], path[1:])
+	}
+}
+
+func redactAuditValue(v any, path []string) any {
+	if len(path) == 0 {
+		return auditRedacted
+	}
+	switch v := v.(type) {
+	case map[string]any:
+		if child, ok := v[path[0]]; ok {
+			v[path[0]] = redactAuditValue(child, path[1:])
+		}
+	case []any:
+		for i := range v {
+			v[i] = redactAuditValue(v[i], path)
+		}
+	}
+	return v
+}
+
+func computeAuditHash(e *models.AuditLog) (string, error) {
+	content, err := json.Marshal(map[string]any{
+		"actor_type":    e.ActorType,
+		"actor_id":      e.ActorID,
+		"api_key_id":    e.APIKeyID,
+		"action":        e.Action,
+		"resource_type": e.ResourceType,
+		"resource_id":   e.ResourceID,
+		"before":        e.Before,
+		"after":         e.After,
+		"changes":       e.Changes,
+		"ip":            e.IP,
+		"request_id":    e.RequestID,
+		"created_at":    e.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write([]byte(e.PrevHash))
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func toAuditLogResponse(e models.AuditLog) models.AuditLogResponse {
+	return models.AuditLogResponse{
+		ID:           e.ID,
+		ActorType:    e.ActorType,
+		ActorID:      e.ActorID,
+		APIKeyID:     e.APIKeyID,
+		Action:       e.Action,
+		ResourceType: e.ResourceType,
+		ResourceID:   e.ResourceID,
+		Before:       e.Before,
+		After:        e.After,
+		Changes:      e.Changes,
+		IP:           e.IP,
+		RequestID:    e.RequestID,
+		PrevHash:     e.PrevHash,
+		Hash:         e.Hash,
+		CreatedAt:    e.CreatedAt.Format(time.RFC3339Nano),
+	}
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+	"vehix/models"
+
+	"github.com/google/uuid"
+)
+
+func TestNewAuditEntryRedactsPersonalData(t *testing.T) {
+	userID := uuid.New()
+	profile := models.DriverProfile{
+		UserID:           userID,
+		DateOfBirth:      time.Date(1990, 4, 1, 0, 0, 0, 0, time.UTC),
+		LicenseNumber:    "B072RRE2I55",
+		LicenseCountry:   "DE",
+		LicenseExpiresOn: time.Date(2031, 1, 1, 0, 0, 0, 0, time.UTC),
+		LicensePhotoKey:  "licenses/" + userID.String() + ".jpg",
+		Status:           models.DriverStatusPending,
+	}
+	reviewed := profile
+	reviewed.LicenseNumber = "B072RRE2I56"
+	reviewed.Status = models.DriverStatusVerified
+
+	driver := models.RentalDriver{ID: uuid.New(), RentalID: uuid.New(), UserID: userID, Name: "Erika Mustermann", Status: "pending"}
+	accepted := driver
+	accepted.Status = "accepted"
+
+	rental := models.Rental{ID: driver.RentalID, Status: models.RentalStatusConfirmed}
+	withDriver := rental
+	withDriver.Drivers = []models.RentalDriver{driver}
+
+	invoice := models.Invoice{ID: uuid.New(), UserID: userID, BillToName: "Erika Mustermann", BillToEmail: "erika@example.com", Number: "BER-2026-000001"}
+
+	tests := []struct {
+		name         string
+		resourceType string
+		before       any
+		after        any
+		personal     []string
+		kept         map[string]any
+		changed      []string
+	}{
+		{
+			name:         "driver profile",
+			resourceType: "driver_profile",
+			before:       profile,
+			after:        reviewed,
+			personal:     []string{"B072RRE2I55", "B072RRE2I56", "1990-04-01", `"DE"`, userID.String() + ".jpg"},
+			kept:         map[string]any{"Status": models.DriverStatusVerified},
+			changed:      []string{"LicenseNumber", "Status"},
+		},
+		{
+			name:         "driver profile created",
+			resourceType: "driver_profile",
+			after:        profile,
+			personal:     []string{"B072RRE2I55", "1990-04-01", `"DE"`, userID.String() + ".jpg"},
+			kept:         map[string]any{"Status": models.DriverStatusPending},
+		},
+		{
+			name:         "rental driver",
+			resourceType: "rental_driver",
+			before:       driver,
+			after:        accepted,
+			personal:     []string{"Erika"},
+			kept:         map[string]any{"Status": "accepted"},
+			changed:      []string{"Status"},
+		},
+		{
+			name:         "rental with additional drivers",
+			resourceType: "rental",
+			before:       rental,
+			after:        withDriver,
+			personal:     []string{"Erika"},
+			kept:         map[string]any{"Status": models.RentalStatusConfirmed},
+			changed:      []string{"Drivers"},
+		},
+		{
+			name:         "invoice",
+			resourceType: "invoice",
+			after:        invoice,
+			personal:     []string{"Erika", "erika@example.com"},
+			kept:         map[string]any{"Number": "BER-2026-000001"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := newAuditEntry(context.Background(), "test", tt.resourceType, "id", tt.before, tt.after)
+			if err != nil {
+				t.Fatalf("newAuditEntry: %v", err)
+			}
+
+			stored, _ := json.Marshal(map[string]any{"before": entry.Before, "after": entry.After, "changes": entry.Changes})
+			for _, value := range tt.personal {
+				if strings.Contains(string(stored), value) {
+					t.Errorf("audit entry contains %q: %s", value, stored)
+				}
+			}
+			if !strings.Contains(string(stored), auditRedacted) {
+				t.Errorf("audit entry carries no redaction marker: %s", stored)
+			}
+
+			for field, want := range tt.kept {
+				if got := entry.After[field]; got != want {
+					t.Errorf("After[%s] = %v, want %v", field, got, want)
+				}
+			}
+			for _, field := range tt.changed {
+				if _, ok := entry.Changes[field]; !ok {
+					t.Errorf("Changes lacks %s: %v", field, entry.Changes)
+				}
+			}
+		})
+	}
+}
+
+func TestNewAuditEntryRedactsEveryListedField(t *testing.T) {
+	profile := models.DriverProfile{UserID: uuid.New(), LicenseNumber: "X1", LicenseCountry: "FR", LicensePhotoKey: "k"}
+	entry, err := newAuditEntry(context.Background(), AuditDriverUpdate, "driver_profile", profile.UserID.String(), profile, profile)
+	if err != nil {
+		t.Fatalf("newAuditEntry: %v", err)
+	}
+	for _, field := range auditRedactedFields["driver_profile"] {
+		for side, snapshot := range map[string]models.JSONMap{"before": entry.Before, "after": entry.After} {
+			if got := snapshot[field]; got != auditRedacted {
+				t.Errorf("%s[%s] = %v, want %q", side, field, got, auditRedacted)
+			}
+		}
+	}
+}
+
+func TestNewAuditEntryRedactsUser(t *testing.T) {
+	user := models.User{ID: uuid.New(), Name: "Erika Mustermann", Email: "erika@example.com", Role: models.RoleUser}
+	promoted := user
+	promoted.Role = models.RoleAdmin
+	promoted.Email = "erika@example.org"
+
+	entry, err := newAuditEntry(context.Background(), AuditUserRoleAssign, "user", user.ID.String(), user, promoted)
+	if err != nil {
+		t.Fatalf("newAuditEntry: %v", err)
+	}
+	if entry.Before["Email"] != auditRedacted || entry.After["Name"] != auditRedacted {
+		t.Errorf("user snapshot not redacted: before %v, after %v", entry.Before, entry.After)
+	}
+	change, _ := entry.Changes["Email"].(map[string]any)
+	if change["before"] != auditRedacted || change["after"] != auditRedacted {
+		t.Errorf("Changes[Email] = %v, want both sides redacted", entry.Changes["Email"])
+	}
+	if role, _ := entry.Changes["Role"].(map[string]any); role["after"] != models.RoleAdmin {
+		t.Errorf("Changes[Role] = %v, want the role change recorded", entry.Changes["Role"])
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"time"
+	"vehix/core/events"
 	"vehix/core/messages"
 	"vehix/models"
 
@@ -21,6 +22,12 @@ var (
 	refreshTokenTTL = time.Hour * 24 * 7
 )
 
+var (
+	ErrAccountNotFound      = errors.New("account not found")
+	ErrAccountSuspended     = errors.New("account is suspended")
+	ErrPasswordResetPending = errors.New("password reset required")
+)
+
 type Claims struct {
 	UserID string `json:"sub"`
 	Email  string `json:"email"`
@@ -35,6 +42,7 @@ type AuthService interface {
 	GenerateToken(userID, email, role string) (*models.LoginSuccess, error)
 	GenerateAccessToken(userID, email, role string) (string, error)
 	VerifyJWT(tokenString, expectedType string) (*Claims, error)
+	ValidateAccount(ctx context.Context, userID string) (string, error)
 }
 
 type AuthServiceImpl struct {
@@ -80,7 +88,21 @@ func (s *AuthServiceImpl) Register(ctx context.Context, payload models.RegisterU
 		Password: string(hashedPassword),
 	}
 
-	if err = db.Create(&user).Error; err != nil {
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, tx, AuditUserRegister, "user", user.ID.String(), nil, user); err != nil {
+			return err
+		}
+		return events.Record(tx, events.UserRegistered, "user", user.ID.String(), models.JSONMap{
+			"user_id": user.ID,
+			"name":    user.Name,
+			"email":   user.Email,
+			"role":    user.Role,
+		})
+	})
+	if err != nil {
 		return fiber.StatusInternalServerError, &models.ErrorResponse{
 			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
 			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
@@ -111,6 +133,14 @@ func (s *AuthServiceImpl) Login(ctx context.Context, payload models.LoginUserPay
 		}
 	}
 
+	if user.Status == models.UserStatusSuspended {
+		return fiber.StatusForbidden, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_ACCOUNT_SUSPENDED.Code,
+			Message:   messages.ERR_ACCOUNT_SUSPENDED.Text,
+			Exception: "login rejected for suspended account",
+		}
+	}
+
 	loginResp, err := s.GenerateToken(user.ID.String(), user.Email, user.Role)
 	if err != nil {
 		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
@@ -203,3 +233,31 @@ func (s *AuthServiceImpl) VerifyJWT(tokenString, expectedType string) (*Claims,
 
 	return claims, nil
 }
+
+// ValidateAccount checks the current state of an account on every request so
+// that suspensions and forced password resets take effect before issued access
+// tokens expire. It returns the account's current role, which callers use in
+// place of the role in the token, and ErrPasswordResetPending for accounts
+// that may only change their password.
+func (s *AuthServiceImpl) ValidateAccount(ctx context.Context, userID string) (string, error) {
+	db := s.db.WithContext(ctx)
+
+	var user models.User
+	err := db.Select("id", "role", "status", "password_reset_required").Where("id = ?", userID).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrAccountNotFound
+		}
+		return "", err
+	}
+
+	if user.Status == models.UserStatusSuspended {
+		return "", ErrAccountSuspended
+	}
+
+	if user.PasswordResetRequired {
+		return user.Role, ErrPasswordResetPending
+	}
+
+	return user.Role, nil
+}
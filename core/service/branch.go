@@ -0,0 +1,404 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"vehix/core/messages"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BranchService interface {
+	ListBranches(ctx context.Context) (int, *[]models.BranchResponse, *models.ErrorResponse)
+	GetBranch(ctx context.Context, branchID string) (int, *models.BranchResponse, *models.ErrorResponse)
+	CreateBranch(ctx context.Context, payload models.CreateBranchPayload) (int, *models.BranchResponse, *models.ErrorResponse)
+	UpdateBranch(ctx context.Context, branchID string, req *models.UpdateBranchPayload) (int, *models.BranchResponse, *models.ErrorResponse)
+	ListOneWayFees(ctx context.Context, branchID string) (int, *[]models.OneWayFeeResponse, *models.ErrorResponse)
+	SetOneWayFee(ctx context.Context, branchID string, payload models.SetOneWayFeePayload) (int, *models.OneWayFeeResponse, *models.ErrorResponse)
+}
+
+type BranchServiceImpl struct {
+	db *gorm.DB
+}
+
+func NewBranchService(db *gorm.DB) BranchService {
+	return &BranchServiceImpl{db: db}
+}
+
+func (s *BranchServiceImpl) ListBranches(ctx context.Context) (int, *[]models.BranchResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	var branches []models.Branch
+	if err := db.Order("code").Find(&branches).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := []models.BranchResponse{}
+	for _, b := range branches {
+		response = append(response, toBranchResponse(b))
+	}
+
+	return fiber.StatusOK, &response, nil
+}
+
+func (s *BranchServiceImpl) GetBranch(ctx context.Context, branchID string) (int, *models.BranchResponse, *models.ErrorResponse) {
+	statusCode, branch, errResp := findBranch(ctx, s.db, branchID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	response := toBranchResponse(*branch)
+	return fiber.StatusOK, &response, nil
+}
+
+func (s *BranchServiceImpl) CreateBranch(ctx context.Context, payload models.CreateBranchPayload) (int, *models.BranchResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	branch := models.Branch{
+		Code:           strings.ToUpper(strings.TrimSpace(payload.Code)),
+		Name:           payload.Name,
+		AddressLine1:   payload.AddressLine1,
+		AddressLine2:   payload.AddressLine2,
+		City:           payload.City,
+		Region:         payload.Region,
+		PostalCode:     payload.PostalCode,
+		Country:        strings.ToUpper(payload.Country),
+		Timezone:       payload.Timezone,
+		OpeningHours:   payload.OpeningHours,
+		OneWayFeeCents: payload.OneWayFeeCents,
+		TaxRateBps:     payload.TaxRateBps,
+	}
+	if branch.OpeningHours == nil {
+		branch.OpeningHours = models.OpeningHours{}
+	}
+
+	if statusCode, errResp := validateBranch(&branch); errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	var existing int64
+	if err := db.Model(&models.Branch{}).Where("code = ?", branch.Code).Count(&existing).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+	if existing > 0 {
+		return fiber.StatusConflict, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_BRANCH_CODE_EXISTS.Code,
+			Message:   messages.ERR_BRANCH_CODE_EXISTS.Text,
+			Exception: "a branch with this code already exists",
+		}
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&branch).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditBranchCreate, "branch", branch.ID.String(), nil, branch)
+	})
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := toBranchResponse(branch)
+	return fiber.StatusCreated, &response, nil
+}
+
+func (s *BranchServiceImpl) UpdateBranch(ctx context.Context, branchID string, req *models.UpdateBranchPayload) (int, *models.BranchResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	statusCode, branch, errResp := findBranch(ctx, s.db, branchID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	before := *branch
+
+	if req.Name != nil {
+		branch.Name = *req.Name
+	}
+	if req.AddressLine1 != nil {
+		branch.AddressLine1 = *req.AddressLine1
+	}
+	if req.AddressLine2 != nil {
+		branch.AddressLine2 = *req.AddressLine2
+	}
+	if req.City != nil {
+		branch.City = *req.City
+	}
+	if req.Region != nil {
+		branch.Region = *req.Region
+	}
+	if req.PostalCode != nil {
+		branch.PostalCode = *req.PostalCode
+	}
+	if req.Country != nil {
+		branch.Country = strings.ToUpper(*req.Country)
+	}
+	if req.Timezone != nil {
+		branch.Timezone = *req.Timezone
+	}
+	if req.OpeningHours != nil {
+		branch.OpeningHours = *req.OpeningHours
+	}
+	if req.OneWayFeeCents != nil {
+		branch.OneWayFeeCents = *req.OneWayFeeCents
+	}
+	if req.TaxRateBps != nil {
+		branch.TaxRateBps = *req.TaxRateBps
+	}
+
+	if statusCode, errResp := validateBranch(branch); errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(branch).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditBranchUpdate, "branch", branchID, before, *branch)
+	})
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := toBranchResponse(*branch)
+	return fiber.StatusOK, &response, nil
+}
+
+func (s *BranchServiceImpl) ListOneWayFees(ctx context.Context, branchID string) (int, *[]models.OneWayFeeResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	statusCode, branch, errResp := findBranch(ctx, s.db, branchID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	var fees []models.OneWayFee
+	if err := db.Where("from_branch_id = ?", branch.ID).Find(&fees).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := []models.OneWayFeeResponse{}
+	for _, f := range fees {
+		response = append(response, models.OneWayFeeResponse{
+			FromBranchID: f.FromBranchID,
+			ToBranchID:   f.ToBranchID,
+			FeeCents:     f.FeeCents,
+		})
+	}
+
+	return fiber.StatusOK, &response, nil
+}
+
+// SetOneWayFee creates or replaces the fee charged for picking up at branchID
+// and dropping off at payload.ToBranchID.
+func (s *BranchServiceImpl) SetOneWayFee(ctx context.Context, branchID string, payload models.SetOneWayFeePayload) (int, *models.OneWayFeeResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	if payload.FeeCents < 0 {
+		return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_BAD_REQUEST.Code,
+			Message:   messages.ERR_BAD_REQUEST.Text,
+			Exception: "fee_cents must not be negative",
+		}
+	}
+
+	statusCode, from, errResp := findBranch(ctx, s.db, branchID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+	statusCode, to, errResp := findBranch(ctx, s.db, payload.ToBranchID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+	if from.ID == to.ID {
+		return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_BAD_REQUEST.Code,
+			Message:   messages.ERR_BAD_REQUEST.Text,
+			Exception: "a one-way fee needs two different branches",
+		}
+	}
+
+	fee := models.OneWayFee{FromBranchID: from.ID, ToBranchID: to.ID, FeeCents: payload.FeeCents}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var before *models.OneWayFee
+		var existing models.OneWayFee
+		if err := tx.Where("from_branch_id = ? AND to_branch_id = ?", from.ID, to.ID).Limit(1).Find(&existing).Error; err != nil {
+			return err
+		}
+		if existing.FromBranchID != uuid.Nil {
+			before = &existing
+		}
+
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "from_branch_id"}, {Name: "to_branch_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"fee_cents", "updated_at"}),
+		}).Create(&fee).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditBranchOneWayFee, "branch", from.ID.String(), before, fee)
+	})
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	return fiber.StatusOK, &models.OneWayFeeResponse{
+		FromBranchID: fee.FromBranchID,
+		ToBranchID:   fee.ToBranchID,
+		FeeCents:     fee.FeeCents,
+	}, nil
+}
+
+func validateBranch(b *models.Branch) (int, *models.ErrorResponse) {
+	if b.Code == "" || b.Name == "" || b.AddressLine1 == "" || b.City == "" || len(b.Country) != 2 {
+		return fiber.StatusBadRequest, &models.ErrorResponse{
+			MessageID: messages.ERR_BAD_REQUEST.Code,
+			Message:   messages.ERR_BAD_REQUEST.Text,
+			Exception: "code, name, address_line1, city and a two-letter country are required",
+		}
+	}
+	if b.OneWayFeeCents < 0 {
+		return fiber.StatusBadRequest, &models.ErrorResponse{
+			MessageID: messages.ERR_BAD_REQUEST.Code,
+			Message:   messages.ERR_BAD_REQUEST.Text,
+			Exception: "one_way_fee_cents must not be negative",
+		}
+	}
+	if b.TaxRateBps < 0 || b.TaxRateBps > 10_000 {
+		return fiber.StatusBadRequest, &models.ErrorResponse{
+			MessageID: messages.ERR_BAD_REQUEST.Code,
+			Message:   messages.ERR_BAD_REQUEST.Text,
+			Exception: "tax_rate_bps must be between 0 and 10000",
+		}
+	}
+	if _, err := time.LoadLocation(b.Timezone); b.Timezone == "" || err != nil {
+		return fiber.StatusBadRequest, &models.ErrorResponse{
+			MessageID: messages.ERR_INVALID_TIMEZONE.Code,
+			Message:   messages.ERR_INVALID_TIMEZONE.Text,
+			Exception: "timezone must be an IANA zone name such as Europe/Berlin",
+		}
+	}
+	if err := b.OpeningHours.Validate(); err != nil {
+		return fiber.StatusBadRequest, &models.ErrorResponse{
+			MessageID: messages.ERR_INVALID_OPENING_HOURS.Code,
+			Message:   messages.ERR_INVALID_OPENING_HOURS.Text,
+			Exception: err.Error(),
+		}
+	}
+	return fiber.StatusOK, nil
+}
+
+func findBranch(ctx context.Context, db *gorm.DB, branchID string) (int, *models.Branch, *models.ErrorResponse) {
+	if _, err := uuid.Parse(branchID); err != nil {
+		return fiber.StatusNotFound, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_BRANCH_NOT_FOUND.Code,
+			Message:   messages.ERR_BRANCH_NOT_FOUND.Text,
+			Exception: "invalid branch ID",
+		}
+	}
+
+	var branch models.Branch
+	if err := db.WithContext(ctx).Where("id = ?", branchID).First(&branch).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.StatusNotFound, nil, &models.ErrorResponse{
+				MessageID: messages.ERR_BRANCH_NOT_FOUND.Code,
+				Message:   messages.ERR_BRANCH_NOT_FOUND.Text,
+				Exception: "branch not found",
+			}
+		}
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	return fiber.StatusOK, &branch, nil
+}
+
+func loadBranch(tx *gorm.DB, branchID uuid.UUID) (*models.Branch, error) {
+	var branch models.Branch
+	if err := tx.Where("id = ?", branchID).First(&branch).Error; err != nil {
+		return nil, err
+	}
+	return &branch, nil
+}
+
+// branchIsOpen checks t against the branch's opening hours in its own
+// timezone. Timezones are validated on write, so a zone that fails to load
+// here is treated as UTC rather than rejecting bookings.
+func branchIsOpen(b *models.Branch, t time.Time) bool {
+	loc, err := time.LoadLocation(b.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	return b.OpeningHours.IsOpen(t, loc)
+}
+
+// oneWayFee returns the fee for a rental picked up at from and returned to to:
+// a configured pair override if there is one, otherwise the drop-off branch's
+// default. Round trips are free.
+func oneWayFee(tx *gorm.DB, from, to *models.Branch) (int64, error) {
+	if from == nil || to == nil || from.ID == to.ID {
+		return 0, nil
+	}
+
+	var override models.OneWayFee
+	if err := tx.Where("from_branch_id = ? AND to_branch_id = ?", from.ID, to.ID).Limit(1).Find(&override).Error; err != nil {
+		return 0, err
+	}
+	if override.FromBranchID != uuid.Nil {
+		return override.FeeCents, nil
+	}
+	return to.OneWayFeeCents, nil
+}
+
+func toBranchResponse(b models.Branch) models.BranchResponse {
+	return models.BranchResponse{
+		ID:             b.ID,
+		Code:           b.Code,
+		Name:           b.Name,
+		AddressLine1:   b.AddressLine1,
+		AddressLine2:   b.AddressLine2,
+		City:           b.City,
+		Region:         b.Region,
+		PostalCode:     b.PostalCode,
+		Country:        b.Country,
+		Timezone:       b.Timezone,
+		OpeningHours:   b.OpeningHours,
+		OneWayFeeCents: b.OneWayFeeCents,
+		TaxRateBps:     b.TaxRateBps,
+		CreatedAt:      b.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      b.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"time"
+	"vehix/models"
+)
+
+// Cancellation terms for vehicle categories that have no VehicleClass row.
+var defaultCancellationPolicy = models.CancellationPolicy{
+	FreeHours:    int(envInt64("DEFAULT_FREE_CANCELLATION_HOURS", 48)),
+	LateFee:      envOrDefault("DEFAULT_LATE_CANCELLATION_FEE", models.CancellationFeeOneDay),
+	LateFeeBps:   int(envInt64("DEFAULT_LATE_CANCELLATION_FEE_BPS", 0)),
+	NoShowFee:    envOrDefault("DEFAULT_NO_SHOW_FEE", models.CancellationFeeOneDay),
+	NoShowFeeBps: int(envInt64("DEFAULT_NO_SHOW_FEE_BPS", 0)),
+}
+
+var cancellationFees = []string{models.CancellationFeeNone, models.CancellationFeePercent, models.CancellationFeeOneDay}
+
+// cancellationFee works out what calling the rental off at now costs under
+// the policy it was booked with. A rental cancelled once it has started is a
+// no-show.
+func cancellationFee(rental *models.Rental, now time.Time) int64 {
+	policy := rental.CancellationPolicy
+	switch {
+	case !rental.StartDate.After(now):
+		return policyFee(rental, policy.NoShowFee, policy.NoShowFeeBps)
+	case !now.After(cancellationDeadline(rental)):
+		return 0
+	}
+	return policyFee(rental, policy.LateFee, policy.LateFeeBps)
+}
+
+func cancellationDeadline(rental *models.Rental) time.Time {
+	return rental.StartDate.Add(-time.Duration(rental.CancellationPolicy.FreeHours) * time.Hour)
+}
+
+func policyFee(rental *models.Rental, fee string, bps int) int64 {
+	subtotal := rentalSubtotalCents(rental)
+	total := subtotal + percentOf(subtotal, rental.TaxRateBps)
+	switch fee {
+	case models.CancellationFeePercent:
+		return percentOf(total, bps)
+	case models.CancellationFeeOneDay:
+		return total / int64(rentalDays(rental))
+	}
+	return 0
+}
+
+func validateCancellationPolicy(p models.CancellationPolicyPayload) string {
+	switch {
+	case p.FreeHours < 0 || p.FreeHours > 24*365:
+		return "cancellation_policy.free_hours must be between 0 and 8760"
+	case !slices.Contains(cancellationFees, p.LateFee):
+		return fmt.Sprintf("cancellation_policy.late_fee must be one of %v", cancellationFees)
+	case !slices.Contains(cancellationFees, p.NoShowFee):
+		return fmt.Sprintf("cancellation_policy.no_show_fee must be one of %v", cancellationFees)
+	case p.LateFee == models.CancellationFeePercent && (p.LateFeeBps <= 0 || p.LateFeeBps > 10_000):
+		return "cancellation_policy.late_fee_bps must be between 1 and 10000"
+	case p.NoShowFee == models.CancellationFeePercent && (p.NoShowFeeBps <= 0 || p.NoShowFeeBps > 10_000):
+		return "cancellation_policy.no_show_fee_bps must be between 1 and 10000"
+	}
+	return ""
+}
+
+func toCancellationPolicy(p models.CancellationPolicyPayload) models.CancellationPolicy {
+	policy := models.CancellationPolicy{
+		FreeHours: p.FreeHours,
+		LateFee:   p.LateFee,
+		NoShowFee: p.NoShowFee,
+	}
+	if p.LateFee == models.CancellationFeePercent {
+		policy.LateFeeBps = p.LateFeeBps
+	}
+	if p.NoShowFee == models.CancellationFeePercent {
+		policy.NoShowFeeBps = p.NoShowFeeBps
+	}
+	return policy
+}
+
+func toCancellationPolicyResponse(p models.CancellationPolicy) models.CancellationPolicyResponse {
+	return models.CancellationPolicyResponse{
+		FreeHours:    p.FreeHours,
+		LateFee:      p.LateFee,
+		LateFeeBps:   p.LateFeeBps,
+		NoShowFee:    p.NoShowFee,
+		NoShowFeeBps: p.NoShowFeeBps,
+	}
+}
+
+// rentalCancellationPolicy describes the rental's terms with the fees worked
+// out for its price.
+func rentalCancellationPolicy(rental *models.Rental) models.CancellationPolicyResponse {
+	policy := rental.CancellationPolicy
+	resp := toCancellationPolicyResponse(policy)
+	resp.FreeUntil = cancellationDeadline(rental).Format(time.RFC3339)
+	lateFee := policyFee(rental, policy.LateFee, policy.LateFeeBps)
+	noShowFee := policyFee(rental, policy.NoShowFee, policy.NoShowFeeBps)
+	resp.LateFeeCents, resp.NoShowFeeCents = &lateFee, &noShowFee
+	return resp
+}
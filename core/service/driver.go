@@ -0,0 +1,855 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	"vehix/core/storage"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Rules for vehicle categories that have no VehicleClass row.
+var (
+	defaultMinDriverAge        = int(envInt64("DEFAULT_MIN_DRIVER_AGE", 21))
+	defaultYoungDriverAge      = int(envInt64("DEFAULT_YOUNG_DRIVER_AGE", 25))
+	defaultYoungDriverFeeCents = envInt64("DEFAULT_YOUNG_DRIVER_FEE_CENTS", 0)
+)
+
+// Each additional driver costs this much per rental day, on top of any young
+// driver surcharge of their own.
+var additionalDriverFeeCents = envInt64("ADDITIONAL_DRIVER_FEE_CENTS", 1_000)
+
+const maxAdditionalDrivers = 4
+
+var (
+	licenseContentTypes = []string{"image/jpeg", "image/png", "application/pdf"}
+	countryCodePattern  = regexp.MustCompile(`^[A-Z]{2}$`)
+	driverStatuses      = []string{models.DriverStatusPending, models.DriverStatusVerified, models.DriverStatusRejected}
+	errDriverRejected   = errors.New("driver rejected")
+)
+
+type DriverService interface {
+	GetDriverProfile(ctx context.Context, userID string) (int, *models.DriverProfileResponse, *models.ErrorResponse)
+	SaveDriverProfile(ctx context.Context, userID string, payload models.DriverProfilePayload) (int, *models.DriverProfileResponse, *models.ErrorResponse)
+	UploadLicensePhoto(ctx context.Context, userID string, data []byte) (int, *models.DriverProfileResponse, *models.ErrorResponse)
+	OpenLicensePhoto(ctx context.Context, userID string) (int, *MediaContent, *models.ErrorResponse)
+	ListDriverProfiles(ctx context.Context, filter models.DriverProfileFilter) (int, *[]models.DriverProfileResponse, *models.ErrorResponse)
+	ReviewDriverProfile(ctx context.Context, userID, reviewerID string, payload models.ReviewDriverProfilePayload) (int, *models.DriverProfileResponse, *models.ErrorResponse)
+	ListVehicleClasses(ctx context.Context) (int, *[]models.VehicleClassResponse, *models.ErrorResponse)
+	SetVehicleClass(ctx context.Context, category string, payload models.VehicleClassPayload) (int, *models.VehicleClassResponse, *models.ErrorResponse)
+	ListDriverInvitations(ctx context.Context, userID string) (int, *[]models.DriverInvitationResponse, *models.ErrorResponse)
+	RespondToInvitation(ctx context.Context, rentalID, userID string, payload models.RespondDriverInvitationPayload) (int, *models.DriverInvitationResponse, *models.ErrorResponse)
+}
+
+type DriverServiceImpl struct {
+	db    *gorm.DB
+	blobs storage.BlobStore
+}
+
+func NewDriverService(db *gorm.DB, blobs storage.BlobStore) DriverService {
+	return &DriverServiceImpl{db: db, blobs: blobs}
+}
+
+func (s *DriverServiceImpl) GetDriverProfile(ctx context.Context, userID string) (int, *models.DriverProfileResponse, *models.ErrorResponse) {
+	statusCode, profile, errResp := findDriverProfile(ctx, s.db, userID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	response := toDriverProfileResponse(*profile)
+	return fiber.StatusOK, &response, nil
+}
+
+// SaveDriverProfile creates or replaces the caller's licence details. Any
+// change to them needs verifying again.
+func (s *DriverServiceImpl) SaveDriverProfile(ctx context.Context, userID string, payload models.DriverProfilePayload) (int, *models.DriverProfileResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNAUTHORIZED.Code,
+			Message:   messages.ERR_UNAUTHORIZED.Text,
+			Exception: "invalid user ID",
+		}
+	}
+
+	invalid := func(reason string) (int, *models.DriverProfileResponse, *models.ErrorResponse) {
+		return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_INVALID_DRIVER_PROFILE.Code,
+			Message:   messages.ERR_INVALID_DRIVER_PROFILE.Text,
+			Exception: reason,
+		}
+	}
+
+	dob, errDOB := time.Parse(time.DateOnly, payload.DateOfBirth)
+	expires, errExpiry := time.Parse(time.DateOnly, payload.LicenseExpiresOn)
+	number := strings.ToUpper(strings.TrimSpace(payload.LicenseNumber))
+	country := strings.ToUpper(strings.TrimSpace(payload.LicenseCountry))
+	switch {
+	case errDOB != nil || errExpiry != nil:
+		return invalid("date_of_birth and license_expires_on must be YYYY-MM-DD dates")
+	case !dob.Before(time.Now()):
+		return invalid("date_of_birth must be in the past")
+	case number == "" || len(number) > 64:
+		return invalid("license_number is required and at most 64 characters")
+	case !countryCodePattern.MatchString(country):
+		return invalid("license_country must be an ISO 3166-1 alpha-2 code")
+	case !expires.After(dob):
+		return invalid("license_expires_on must be after date_of_birth")
+	}
+
+	var profile models.DriverProfile
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", uid).Limit(1).Find(&profile).Error; err != nil {
+			return err
+		}
+		var before any
+		if profile.UserID != uuid.Nil {
+			before = profile
+		}
+
+		changed := !profile.DateOfBirth.Equal(dob) || profile.LicenseNumber != number ||
+			profile.LicenseCountry != country || !profile.LicenseExpiresOn.Equal(expires)
+		profile.UserID = uid
+		profile.DateOfBirth = dob
+		profile.LicenseNumber = number
+		profile.LicenseCountry = country
+		profile.LicenseExpiresOn = expires
+		if changed {
+			resetDriverReview(&profile)
+		}
+
+		if err := tx.Save(&profile).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditDriverUpdate, "driver_profile", userID, before, profile)
+	})
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := toDriverProfileResponse(profile)
+	return fiber.StatusOK, &response, nil
+}
+
+// UploadLicensePhoto stores a scan of the licence for staff to check and sends
+// the profile back for review. The previous scan is removed once the new one
+// is recorded.
+func (s *DriverServiceImpl) UploadLicensePhoto(ctx context.Context, userID string, data []byte) (int, *models.DriverProfileResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	if len(data) > MaxDocumentBytes {
+		return fiber.StatusRequestEntityTooLarge, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_MEDIA_TOO_LARGE.Code,
+			Message:   messages.ERR_MEDIA_TOO_LARGE.Text,
+			Exception: fmt.Sprintf("license photos are limited to %d MiB", MaxDocumentBytes>>20),
+		}
+	}
+
+	contentType, _, _ := strings.Cut(http.DetectContentType(data), ";")
+	if !slices.Contains(licenseContentTypes, contentType) {
+		return fiber.StatusUnsupportedMediaType, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNSUPPORTED_MEDIA_TYPE.Code,
+			Message:   messages.ERR_UNSUPPORTED_MEDIA_TYPE.Text,
+			Exception: fmt.Sprintf("license photos must be one of %s, got %s", strings.Join(licenseContentTypes, ", "), contentType),
+		}
+	}
+
+	statusCode, profile, errResp := findDriverProfile(ctx, s.db, userID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	key := fmt.Sprintf("drivers/%s/license-%s%s", profile.UserID, uuid.New(), mediaExtensions[contentType])
+	if err := s.blobs.Put(ctx, key, data, contentType); err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	var oldKey string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", profile.UserID).First(profile).Error; err != nil {
+			return err
+		}
+		before := *profile
+		oldKey = profile.LicensePhotoKey
+		profile.LicensePhotoKey = key
+		profile.LicensePhotoType = contentType
+		resetDriverReview(profile)
+
+		if err := tx.Save(profile).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditDriverUpdate, "driver_profile", userID, before, *profile)
+	})
+	if err != nil {
+		s.removeBlob(ctx, key)
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+	if oldKey != "" {
+		s.removeBlob(ctx, oldKey)
+	}
+
+	response := toDriverProfileResponse(*profile)
+	return fiber.StatusCreated, &response, nil
+}
+
+func (s *DriverServiceImpl) OpenLicensePhoto(ctx context.Context, userID string) (int, *MediaContent, *models.ErrorResponse) {
+	statusCode, profile, errResp := findDriverProfile(ctx, s.db, userID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	notFound := &models.ErrorResponse{
+		MessageID: messages.ERR_MEDIA_NOT_FOUND.Code,
+		Message:   messages.ERR_MEDIA_NOT_FOUND.Text,
+		Exception: "no license photo has been uploaded",
+	}
+	if profile.LicensePhotoKey == "" {
+		return fiber.StatusNotFound, nil, notFound
+	}
+
+	body, err := s.blobs.Get(ctx, profile.LicensePhotoKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return fiber.StatusNotFound, nil, notFound
+		}
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	return fiber.StatusOK, &MediaContent{
+		Body:        body,
+		ContentType: profile.LicensePhotoType,
+		FileName:    "license" + mediaExtensions[profile.LicensePhotoType],
+	}, nil
+}
+
+// ListDriverProfiles is the review queue, oldest first so nobody waits
+// longest; ?status=pending shows only the profiles still to check.
+func (s *DriverServiceImpl) ListDriverProfiles(ctx context.Context, filter models.DriverProfileFilter) (int, *[]models.DriverProfileResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	query := db.Model(&models.DriverProfile{})
+	if filter.Status != "" {
+		if !slices.Contains(driverStatuses, filter.Status) {
+			return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: "status must be one of " + strings.Join(driverStatuses, ", "),
+			}
+		}
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var profiles []models.DriverProfile
+	if err := query.Order("updated_at").Find(&profiles).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := []models.DriverProfileResponse{}
+	for _, p := range profiles {
+		response = append(response, toDriverProfileResponse(p))
+	}
+
+	return fiber.StatusOK, &response, nil
+}
+
+// ReviewDriverProfile records staff's decision on a profile. A profile can
+// only be approved once the licence photo has been checked.
+func (s *DriverServiceImpl) ReviewDriverProfile(ctx context.Context, userID, reviewerID string, payload models.ReviewDriverProfilePayload) (int, *models.DriverProfileResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	reviewer, err := uuid.Parse(reviewerID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNAUTHORIZED.Code,
+			Message:   messages.ERR_UNAUTHORIZED.Text,
+			Exception: "invalid user ID",
+		}
+	}
+	reason := strings.TrimSpace(payload.Reason)
+	if !payload.Approved && reason == "" {
+		return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_BAD_REQUEST.Code,
+			Message:   messages.ERR_BAD_REQUEST.Text,
+			Exception: "a reason is required when rejecting a driver profile",
+		}
+	}
+
+	var errResp *models.ErrorResponse
+	statusCode := fiber.StatusOK
+	var profile *models.DriverProfile
+	err = db.Transaction(func(tx *gorm.DB) error {
+		statusCode, profile, errResp = findDriverProfile(ctx, tx.Clauses(clause.Locking{Strength: "UPDATE"}), userID)
+		if errResp != nil {
+			return errDriverRejected
+		}
+		if payload.Approved && profile.LicensePhotoKey == "" {
+			statusCode, errResp = fiber.StatusConflict, &models.ErrorResponse{
+				MessageID: messages.ERR_INVALID_DRIVER_PROFILE.Code,
+				Message:   messages.ERR_INVALID_DRIVER_PROFILE.Text,
+				Exception: "a license photo must be uploaded before the profile can be verified",
+			}
+			return errDriverRejected
+		}
+
+		before := *profile
+		now := time.Now()
+		profile.Status = models.DriverStatusVerified
+		profile.RejectionReason = ""
+		if !payload.Approved {
+			profile.Status = models.DriverStatusRejected
+			profile.RejectionReason = reason
+		}
+		profile.ReviewedBy = &reviewer
+		profile.ReviewedAt = &now
+
+		if err := tx.Save(profile).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditDriverReview, "driver_profile", userID, before, *profile)
+	})
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := toDriverProfileResponse(*profile)
+	return fiber.StatusOK, &response, nil
+}
+
+// ListVehicleClasses returns the rules for every category, filling in the
+// defaults for categories that haven't been configured.
+func (s *DriverServiceImpl) ListVehicleClasses(ctx context.Context) (int, *[]models.VehicleClassResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	var classes []models.VehicleClass
+	if err := db.Find(&classes).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := []models.VehicleClassResponse{}
+	for _, category := range vehicleCategories {
+		class := defaultVehicleClass(category)
+		for _, c := range classes {
+			if c.Category == category {
+				class = c
+			}
+		}
+		response = append(response, toVehicleClassResponse(class))
+	}
+
+	return fiber.StatusOK, &response, nil
+}
+
+func (s *DriverServiceImpl) SetVehicleClass(ctx context.Context, category string, payload models.VehicleClassPayload) (int, *models.VehicleClassResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	invalid := func(reason string) (int, *models.VehicleClassResponse, *models.ErrorResponse) {
+		return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_INVALID_VEHICLE_CLASS.Code,
+			Message:   messages.ERR_INVALID_VEHICLE_CLASS.Text,
+			Exception: reason,
+		}
+	}
+
+	category = strings.ToLower(category)
+	policy := payload.CancellationPolicy
+	if policy.LateFee == "" {
+		policy.LateFee = models.CancellationFeeNone
+	}
+	if policy.NoShowFee == "" {
+		policy.NoShowFee = models.CancellationFeeNone
+	}
+	switch {
+	case !slices.Contains(vehicleCategories, category):
+		return invalid("category must be one of " + strings.Join(vehicleCategories, ", "))
+	case payload.MinDriverAge < 16 || payload.MinDriverAge > 99:
+		return invalid("min_driver_age must be between 16 and 99")
+	case payload.YoungDriverAge != 0 && payload.YoungDriverAge <= payload.MinDriverAge:
+		return invalid("young_driver_age must be above min_driver_age, or 0 for no surcharge")
+	case payload.YoungDriverFeeCents < 0:
+		return invalid("young_driver_fee_cents must not be negative")
+	}
+	if reason := validateCancellationPolicy(policy); reason != "" {
+		return invalid(reason)
+	}
+
+	class := models.VehicleClass{
+		Category:            category,
+		MinDriverAge:        payload.MinDriverAge,
+		YoungDriverAge:      payload.YoungDriverAge,
+		YoungDriverFeeCents: payload.YoungDriverFeeCents,
+		CancellationPolicy:  toCancellationPolicy(policy),
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		before, err := loadVehicleClass(tx, category)
+		if err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&class).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditVehicleClassSet, "vehicle_class", category, before, class)
+	})
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := toVehicleClassResponse(class)
+	return fiber.StatusOK, &response, nil
+}
+
+// ListDriverInvitations returns the open rentals the caller is named on as an
+// additional driver.
+func (s *DriverServiceImpl) ListDriverInvitations(ctx context.Context, userID string) (int, *[]models.DriverInvitationResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	response, err := findDriverInvitations(db, userID, models.RentalStatusConfirmed)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	return fiber.StatusOK, &response, nil
+}
+
+// RespondToInvitation records a named driver's consent. Accepting re-checks
+// their eligibility, since their profile may have changed since the booking.
+// A driver can withdraw until the rental starts; their fee is then dropped.
+func (s *DriverServiceImpl) RespondToInvitation(ctx context.Context, rentalID, userID string, payload models.RespondDriverInvitationPayload) (int, *models.DriverInvitationResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	notFound := &models.ErrorResponse{
+		MessageID: messages.ERR_DRIVER_INVITATION_NOT_FOUND.Code,
+		Message:   messages.ERR_DRIVER_INVITATION_NOT_FOUND.Text,
+		Exception: "you are not named as a driver on this rental",
+	}
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return fiber.StatusNotFound, nil, notFound
+	}
+
+	var errResp *models.ErrorResponse
+	statusCode := fiber.StatusOK
+	var rental *models.Rental
+	var driver models.RentalDriver
+	err = db.Transaction(func(tx *gorm.DB) error {
+		statusCode, rental, errResp = findRental(ctx, tx.Clauses(clause.Locking{Strength: "UPDATE"}), rentalID)
+		if errResp != nil {
+			statusCode, errResp = fiber.StatusNotFound, notFound
+			return errDriverRejected
+		}
+		if err := tx.Where("rental_id = ? AND user_id = ?", rental.ID, uid).Limit(1).Find(&driver).Error; err != nil {
+			return err
+		}
+		if driver.ID == uuid.Nil {
+			statusCode, errResp = fiber.StatusNotFound, notFound
+			return errDriverRejected
+		}
+
+		closed := func(reason string) error {
+			statusCode, errResp = fiber.StatusConflict, &models.ErrorResponse{
+				MessageID: messages.ERR_DRIVER_INVITATION_CLOSED.Code,
+				Message:   messages.ERR_DRIVER_INVITATION_CLOSED.Text,
+				Exception: reason,
+			}
+			return errDriverRejected
+		}
+		switch {
+		case rental.Status != models.RentalStatusConfirmed:
+			return closed("the rental is " + rental.Status)
+		case payload.Accept && driver.Status != models.RentalDriverPending:
+			return closed("the invitation has already been " + driver.Status)
+		case !payload.Accept && driver.Status != models.RentalDriverPending && driver.Status != models.RentalDriverAccepted:
+			return closed("the invitation has already been " + driver.Status)
+		case !payload.Accept && driver.Status == models.RentalDriverAccepted && !rental.StartDate.After(time.Now()):
+			return closed("drivers can't withdraw once the rental has started")
+		}
+
+		before := driver
+		now := time.Now()
+		driver.Status = models.RentalDriverDeclined
+		driver.RespondedAt = &now
+		if payload.Accept {
+			var vehicle models.Vehicle
+			if err := tx.Unscoped().Where("id = ?", rental.VehicleID).First(&vehicle).Error; err != nil {
+				return err
+			}
+			class, err := loadVehicleClass(tx, vehicle.Category)
+			if err != nil {
+				return err
+			}
+			var respErr *models.ErrorResponse
+			if _, statusCode, respErr, err = driverSurcharge(tx, uid, rental, class); err != nil {
+				return err
+			}
+			if respErr != nil {
+				errResp = respErr
+				return errDriverRejected
+			}
+			driver.Status = models.RentalDriverAccepted
+		}
+
+		if err := tx.Model(&driver).Select("status", "responded_at").Updates(&driver).Error; err != nil {
+			return err
+		}
+		if !payload.Accept {
+			if err := repriceAdditionalDrivers(tx, rental); err != nil {
+				return err
+			}
+		}
+		return recordAudit(ctx, tx, AuditDriverRespond, "rental_driver", driver.ID.String(), before, driver)
+	})
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	return fiber.StatusOK, &models.DriverInvitationResponse{
+		RentalID:  rental.ID,
+		VehicleID: rental.VehicleID,
+		StartDate: rental.StartDate.Format(time.RFC3339),
+		EndDate:   rental.EndDate.Format(time.RFC3339),
+		Status:    driver.Status,
+		FeeCents:  driver.FeeCents,
+	}, nil
+}
+
+// checkDriver applies the vehicle class's driver rules to the renter and to
+// every additional driver named on the booking, pricing the young driver
+// surcharges and additional driver fees.
+func checkDriver(tx *gorm.DB, rental *models.Rental, vehicle *models.Vehicle, additional []string) (int, *models.ErrorResponse, error) {
+	class, err := loadVehicleClass(tx, vehicle.Category)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, err
+	}
+
+	surcharge, statusCode, errResp, err := driverSurcharge(tx, rental.UserID, rental, class)
+	if errResp != nil || err != nil {
+		return statusCode, errResp, err
+	}
+	rental.YoungDriverCents = surcharge
+
+	invalid := func(reason string) (int, *models.ErrorResponse, error) {
+		return fiber.StatusBadRequest, &models.ErrorResponse{
+			MessageID: messages.ERR_INVALID_ADDITIONAL_DRIVER.Code,
+			Message:   messages.ERR_INVALID_ADDITIONAL_DRIVER.Text,
+			Exception: reason,
+		}, nil
+	}
+
+	// An unknown email and an ineligible driver get the same answer, so
+	// bookings can't be used to find out who has an account, or anything
+	// about their licence. Named drivers see the details on their own profile.
+	cannotAdd := func(email string) (int, *models.ErrorResponse, error) {
+		return invalid(email + " cannot be added; additional drivers need an active account with a verified driver profile valid for this rental")
+	}
+
+	rental.Drivers, rental.AdditionalDriversCents = nil, 0
+	seen := map[string]bool{}
+	for _, email := range additional {
+		email = strings.ToLower(strings.TrimSpace(email))
+		if seen[email] {
+			continue
+		}
+		seen[email] = true
+		if len(seen) > maxAdditionalDrivers {
+			return invalid(fmt.Sprintf("at most %d additional drivers per rental", maxAdditionalDrivers))
+		}
+
+		var user models.User
+		if err := tx.Where("LOWER(email) = ? AND status = ?", email, models.UserStatusActive).Limit(1).Find(&user).Error; err != nil {
+			return fiber.StatusInternalServerError, nil, err
+		}
+		switch {
+		case user.ID == uuid.Nil:
+			return cannotAdd(email)
+		case user.ID == rental.UserID:
+			return invalid("the renter is already the main driver")
+		}
+
+		surcharge, statusCode, errResp, err := driverSurcharge(tx, user.ID, rental, class)
+		if err != nil {
+			return statusCode, nil, err
+		}
+		if errResp != nil {
+			return cannotAdd(email)
+		}
+
+		driver := models.RentalDriver{
+			UserID:   user.ID,
+			Name:     user.Name,
+			Status:   models.RentalDriverPending,
+			FeeCents: int64(rentalDays(rental))*additionalDriverFeeCents + surcharge,
+		}
+		rental.Drivers = append(rental.Drivers, driver)
+		rental.AdditionalDriversCents += driver.FeeCents
+	}
+	return fiber.StatusOK, nil, nil
+}
+
+// driverSurcharge checks one driver against the class rules: verified,
+// licensed until the rental ends and old enough when it starts. It returns
+// the young driver surcharge they attract for the rental.
+func driverSurcharge(tx *gorm.DB, userID uuid.UUID, rental *models.Rental, class models.VehicleClass) (int64, int, *models.ErrorResponse, error) {
+	var profile models.DriverProfile
+	if err := tx.Where("user_id = ?", userID).Limit(1).Find(&profile).Error; err != nil {
+		return 0, fiber.StatusInternalServerError, nil, err
+	}
+
+	notVerified := func(reason string) (int64, int, *models.ErrorResponse, error) {
+		return 0, fiber.StatusForbidden, &models.ErrorResponse{
+			MessageID: messages.ERR_DRIVER_NOT_VERIFIED.Code,
+			Message:   messages.ERR_DRIVER_NOT_VERIFIED.Text,
+			Exception: reason,
+		}, nil
+	}
+	switch profile.Status {
+	case models.DriverStatusVerified:
+	case models.DriverStatusRejected:
+		return notVerified("driver profile was rejected: " + profile.RejectionReason)
+	case models.DriverStatusPending:
+		return notVerified("driver profile is awaiting verification")
+	default:
+		return notVerified("a driver profile is required before booking")
+	}
+
+	notEligible := func(reason string) (int64, int, *models.ErrorResponse, error) {
+		return 0, fiber.StatusForbidden, &models.ErrorResponse{
+			MessageID: messages.ERR_DRIVER_NOT_ELIGIBLE.Code,
+			Message:   messages.ERR_DRIVER_NOT_ELIGIBLE.Text,
+			Exception: reason,
+		}, nil
+	}
+	// The licence is valid through the whole of its expiry date.
+	if !rental.EndDate.Before(profile.LicenseExpiresOn.AddDate(0, 0, 1)) {
+		return notEligible("driving license expires on " + profile.LicenseExpiresOn.Format(time.DateOnly) + ", before the rental ends")
+	}
+	age := ageOn(profile.DateOfBirth, rental.StartDate)
+	if age < class.MinDriverAge {
+		return notEligible(fmt.Sprintf("drivers of %s vehicles must be at least %d", class.Category, class.MinDriverAge))
+	}
+
+	if age < class.YoungDriverAge {
+		return int64(rentalDays(rental)) * class.YoungDriverFeeCents, fiber.StatusOK, nil, nil
+	}
+	return 0, fiber.StatusOK, nil, nil
+}
+
+// expireDriverInvitations stops charging for named drivers who never
+// answered. It runs when the rental is returned, before the capture.
+func expireDriverInvitations(tx *gorm.DB, rental *models.Rental) error {
+	now := time.Now()
+	if err := tx.Model(&models.RentalDriver{}).
+		Where("rental_id = ? AND status = ?", rental.ID, models.RentalDriverPending).
+		Updates(map[string]any{"status": models.RentalDriverExpired, "responded_at": now}).Error; err != nil {
+		return err
+	}
+	return repriceAdditionalDrivers(tx, rental)
+}
+
+// repriceAdditionalDrivers recomputes the rental's additional driver total
+// from the drivers that are still charged.
+func repriceAdditionalDrivers(tx *gorm.DB, rental *models.Rental) error {
+	var total int64
+	if err := tx.Model(&models.RentalDriver{}).
+		Where("rental_id = ? AND status IN ?", rental.ID, []string{models.RentalDriverPending, models.RentalDriverAccepted}).
+		Select("COALESCE(SUM(fee_cents), 0)").Scan(&total).Error; err != nil {
+		return err
+	}
+	rental.AdditionalDriversCents = total
+	return tx.Model(rental).Update("additional_drivers_cents", total).Error
+}
+
+// ageOn is the number of whole years between dob and t, counted on the
+// calendar so birthdays fall on the right day.
+func ageOn(dob, t time.Time) int {
+	ty, tm, td := t.Date()
+	by, bm, bd := dob.Date()
+	age := ty - by
+	if tm < bm || (tm == bm && td < bd) {
+		age--
+	}
+	return age
+}
+
+func resetDriverReview(p *models.DriverProfile) {
+	p.Status = models.DriverStatusPending
+	p.ReviewedBy = nil
+	p.ReviewedAt = nil
+	p.RejectionReason = ""
+}
+
+func (s *DriverServiceImpl) removeBlob(ctx context.Context, key string) {
+	if err := s.blobs.Delete(ctx, key); err != nil {
+		logger.Error(fmt.Sprintf("[%s] %s: removing blob %s: %s", messages.ERR_UNEXPECTED_ERROR.Code,
+			messages.ERR_UNEXPECTED_ERROR.Text, key, err.Error()))
+	}
+}
+
+func loadVehicleClass(tx *gorm.DB, category string) (models.VehicleClass, error) {
+	var class models.VehicleClass
+	if err := tx.Where("category = ?", category).Limit(1).Find(&class).Error; err != nil {
+		return class, err
+	}
+	if class.Category == "" {
+		return defaultVehicleClass(category), nil
+	}
+	return class, nil
+}
+
+func defaultVehicleClass(category string) models.VehicleClass {
+	return models.VehicleClass{
+		Category:            category,
+		MinDriverAge:        defaultMinDriverAge,
+		YoungDriverAge:      defaultYoungDriverAge,
+		YoungDriverFeeCents: defaultYoungDriverFeeCents,
+		CancellationPolicy:  defaultCancellationPolicy,
+	}
+}
+
+func findDriverProfile(ctx context.Context, db *gorm.DB, userID string) (int, *models.DriverProfile, *models.ErrorResponse) {
+	notFound := &models.ErrorResponse{
+		MessageID: messages.ERR_DRIVER_PROFILE_NOT_FOUND.Code,
+		Message:   messages.ERR_DRIVER_PROFILE_NOT_FOUND.Text,
+		Exception: "driver profile not found",
+	}
+	if _, err := uuid.Parse(userID); err != nil {
+		return fiber.StatusNotFound, nil, notFound
+	}
+
+	var profile models.DriverProfile
+	if err := db.WithContext(ctx).Where("user_id = ?", userID).First(&profile).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.StatusNotFound, nil, notFound
+		}
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	return fiber.StatusOK, &profile, nil
+}
+
+func toDriverProfileResponse(p models.DriverProfile) models.DriverProfileResponse {
+	resp := models.DriverProfileResponse{
+		UserID:           p.UserID,
+		DateOfBirth:      p.DateOfBirth.Format(time.DateOnly),
+		LicenseNumber:    p.LicenseNumber,
+		LicenseCountry:   p.LicenseCountry,
+		LicenseExpiresOn: p.LicenseExpiresOn.Format(time.DateOnly),
+		HasLicensePhoto:  p.LicensePhotoKey != "",
+		Status:           p.Status,
+		ReviewedBy:       p.ReviewedBy,
+		RejectionReason:  p.RejectionReason,
+		UpdatedAt:        p.UpdatedAt.Format(time.RFC3339),
+	}
+	if p.ReviewedAt != nil {
+		resp.ReviewedAt = p.ReviewedAt.Format(time.RFC3339)
+	}
+	return resp
+}
+
+func toVehicleClassResponse(c models.VehicleClass) models.VehicleClassResponse {
+	return models.VehicleClassResponse{
+		Category:            c.Category,
+		MinDriverAge:        c.MinDriverAge,
+		YoungDriverAge:      c.YoungDriverAge,
+		YoungDriverFeeCents: c.YoungDriverFeeCents,
+		CancellationPolicy:  toCancellationPolicyResponse(c.CancellationPolicy),
+	}
+}
+
+// findDriverInvitations returns the rentals the user is named on as an
+// additional driver, limited to rentals in the given statuses if any.
+func findDriverInvitations(db *gorm.DB, userID string, rentalStatuses ...string) ([]models.DriverInvitationResponse, error) {
+	var rows []struct {
+		models.RentalDriver
+		VehicleID uuid.UUID
+		StartDate time.Time
+		EndDate   time.Time
+	}
+	query := db.Model(&models.RentalDriver{}).
+		Joins("JOIN rentals ON rentals.id = rental_drivers.rental_id").
+		Where("rental_drivers.user_id = ?", userID)
+	if len(rentalStatuses) > 0 {
+		query = query.Where("rentals.status IN ?", rentalStatuses)
+	}
+	err := query.Select("rental_drivers.*, rentals.vehicle_id, rentals.start_date, rentals.end_date").
+		Order("rentals.start_date").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	invitations := []models.DriverInvitationResponse{}
+	for _, r := range rows {
+		invitations = append(invitations, models.DriverInvitationResponse{
+			RentalID:  r.RentalID,
+			VehicleID: r.VehicleID,
+			StartDate: r.StartDate.Format(time.RFC3339),
+			EndDate:   r.EndDate.Format(time.RFC3339),
+			Status:    r.Status,
+			FeeCents:  r.FeeCents,
+		})
+	}
+	return invitations, nil
+}
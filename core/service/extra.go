@@ -0,0 +1,486 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"vehix/core/messages"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var extraPricings = []string{models.ExtraPricingPerDay, models.ExtraPricingPerRental}
+
+type ExtraService interface {
+	ListExtras(ctx context.Context, filter models.ExtraFilter) (int, *[]models.ExtraResponse, *models.ErrorResponse)
+	GetExtra(ctx context.Context, extraID string) (int, *models.ExtraResponse, *models.ErrorResponse)
+	CreateExtra(ctx context.Context, payload models.CreateExtraPayload) (int, *models.ExtraResponse, *models.ErrorResponse)
+	UpdateExtra(ctx context.Context, extraID string, req *models.UpdateExtraPayload) (int, *models.ExtraResponse, *models.ErrorResponse)
+	ListExtraStock(ctx context.Context, extraID string) (int, *[]models.ExtraStockResponse, *models.ErrorResponse)
+	SetExtraStock(ctx context.Context, extraID string, payload models.SetExtraStockPayload) (int, *models.ExtraStockResponse, *models.ErrorResponse)
+}
+
+type ExtraServiceImpl struct {
+	db *gorm.DB
+}
+
+func NewExtraService(db *gorm.DB) ExtraService {
+	return &ExtraServiceImpl{db: db}
+}
+
+func (s *ExtraServiceImpl) ListExtras(ctx context.Context, filter models.ExtraFilter) (int, *[]models.ExtraResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	query := db.Model(&models.Extra{})
+	if !filter.IncludeInactive {
+		query = query.Where("active = true")
+	}
+
+	var extras []models.Extra
+	if err := query.Order("code").Find(&extras).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := []models.ExtraResponse{}
+	for _, e := range extras {
+		response = append(response, toExtraResponse(e))
+	}
+
+	if filter.BranchID == "" || filter.StartDate == "" || filter.EndDate == "" {
+		return fiber.StatusOK, &response, nil
+	}
+
+	branchID, err := uuid.Parse(filter.BranchID)
+	if err != nil {
+		return fiber.StatusNotFound, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_BRANCH_NOT_FOUND.Code,
+			Message:   messages.ERR_BRANCH_NOT_FOUND.Text,
+			Exception: "invalid branch ID",
+		}
+	}
+	start, errStart := time.Parse(time.RFC3339, filter.StartDate)
+	end, errEnd := time.Parse(time.RFC3339, filter.EndDate)
+	if errStart != nil || errEnd != nil || !end.After(start) {
+		return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_INVALID_RENTAL_PERIOD.Code,
+			Message:   messages.ERR_INVALID_RENTAL_PERIOD.Text,
+			Exception: "start_date and end_date must be RFC 3339 timestamps with end_date after start_date",
+		}
+	}
+
+	for i, e := range extras {
+		if !e.TrackInventory {
+			continue
+		}
+		stock, err := extraStock(db, e.ID, branchID)
+		if err != nil {
+			return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+				MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+				Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+				Exception: err.Error(),
+			}
+		}
+		reserved, err := extrasReserved(db, e.ID, branchID, start, end, uuid.Nil)
+		if err != nil {
+			return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+				MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+				Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+				Exception: err.Error(),
+			}
+		}
+		available := max(0, stock-reserved)
+		response[i].Available = &available
+	}
+
+	return fiber.StatusOK, &response, nil
+}
+
+func (s *ExtraServiceImpl) GetExtra(ctx context.Context, extraID string) (int, *models.ExtraResponse, *models.ErrorResponse) {
+	statusCode, extra, errResp := findExtra(ctx, s.db, extraID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	response := toExtraResponse(*extra)
+	return fiber.StatusOK, &response, nil
+}
+
+func (s *ExtraServiceImpl) CreateExtra(ctx context.Context, payload models.CreateExtraPayload) (int, *models.ExtraResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	extra := models.Extra{
+		Code:           strings.ToUpper(strings.TrimSpace(payload.Code)),
+		Name:           strings.TrimSpace(payload.Name),
+		Description:    payload.Description,
+		Pricing:        strings.ToLower(payload.Pricing),
+		PriceCents:     payload.PriceCents,
+		MaxPerRental:   payload.MaxPerRental,
+		TrackInventory: payload.TrackInventory,
+		Active:         true,
+	}
+	if extra.MaxPerRental == 0 {
+		extra.MaxPerRental = 1
+	}
+
+	if statusCode, errResp := validateExtra(&extra); errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	var existing int64
+	if err := db.Model(&models.Extra{}).Where("code = ?", extra.Code).Count(&existing).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+	if existing > 0 {
+		return fiber.StatusConflict, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_EXTRA_CODE_EXISTS.Code,
+			Message:   messages.ERR_EXTRA_CODE_EXISTS.Text,
+			Exception: "an extra with this code already exists",
+		}
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&extra).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditExtraCreate, "extra", extra.ID.String(), nil, extra)
+	})
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := toExtraResponse(extra)
+	return fiber.StatusCreated, &response, nil
+}
+
+// UpdateExtra changes the catalog entry. Rentals already booked keep the
+// price they were booked at.
+func (s *ExtraServiceImpl) UpdateExtra(ctx context.Context, extraID string, req *models.UpdateExtraPayload) (int, *models.ExtraResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	statusCode, extra, errResp := findExtra(ctx, s.db, extraID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	before := *extra
+
+	if req.Name != nil {
+		extra.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		extra.Description = *req.Description
+	}
+	if req.Pricing != nil {
+		extra.Pricing = strings.ToLower(*req.Pricing)
+	}
+	if req.PriceCents != nil {
+		extra.PriceCents = *req.PriceCents
+	}
+	if req.MaxPerRental != nil {
+		extra.MaxPerRental = *req.MaxPerRental
+	}
+	if req.TrackInventory != nil {
+		extra.TrackInventory = *req.TrackInventory
+	}
+	if req.Active != nil {
+		extra.Active = *req.Active
+	}
+
+	if statusCode, errResp := validateExtra(extra); errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(extra).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditExtraUpdate, "extra", extraID, before, *extra)
+	})
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := toExtraResponse(*extra)
+	return fiber.StatusOK, &response, nil
+}
+
+func (s *ExtraServiceImpl) ListExtraStock(ctx context.Context, extraID string) (int, *[]models.ExtraStockResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	statusCode, extra, errResp := findExtra(ctx, s.db, extraID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	var stock []models.ExtraStock
+	if err := db.Where("extra_id = ?", extra.ID).Find(&stock).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := []models.ExtraStockResponse{}
+	for _, st := range stock {
+		response = append(response, models.ExtraStockResponse{
+			ExtraID:  st.ExtraID,
+			BranchID: st.BranchID,
+			Quantity: st.Quantity,
+		})
+	}
+
+	return fiber.StatusOK, &response, nil
+}
+
+// SetExtraStock records how many of a stocked extra a branch owns. Lowering
+// it below what is already booked is allowed (items get lost or broken); the
+// shortfall only affects new bookings.
+func (s *ExtraServiceImpl) SetExtraStock(ctx context.Context, extraID string, payload models.SetExtraStockPayload) (int, *models.ExtraStockResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	if payload.Quantity < 0 {
+		return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_INVALID_EXTRA.Code,
+			Message:   messages.ERR_INVALID_EXTRA.Text,
+			Exception: "quantity must not be negative",
+		}
+	}
+
+	statusCode, extra, errResp := findExtra(ctx, s.db, extraID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+	if !extra.TrackInventory {
+		return fiber.StatusConflict, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_INVALID_EXTRA.Code,
+			Message:   messages.ERR_INVALID_EXTRA.Text,
+			Exception: "extra does not track inventory",
+		}
+	}
+	statusCode, branch, errResp := findBranch(ctx, s.db, payload.BranchID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	stock := models.ExtraStock{ExtraID: extra.ID, BranchID: branch.ID, Quantity: payload.Quantity}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "extra_id"}, {Name: "branch_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"quantity", "updated_at"}),
+		}).Create(&stock).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditExtraStock, "extra", extra.ID.String(), nil, stock)
+	})
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	return fiber.StatusOK, &models.ExtraStockResponse{
+		ExtraID:  stock.ExtraID,
+		BranchID: stock.BranchID,
+		Quantity: stock.Quantity,
+	}, nil
+}
+
+// reserveExtras validates and prices the extras requested for a rental.
+// Stocked extras are checked against the pickup branch's stock for the rental
+// window with the stock row locked, so two bookings can't take the last item.
+// The rental's own earlier reservations are left out of the count.
+func reserveExtras(ctx context.Context, tx *gorm.DB, rental *models.Rental, requested []models.RentalExtraPayload) ([]models.RentalExtra, int, *models.ErrorResponse, error) {
+	invalid := func(reason string) ([]models.RentalExtra, int, *models.ErrorResponse, error) {
+		return nil, fiber.StatusBadRequest, &models.ErrorResponse{
+			MessageID: messages.ERR_INVALID_EXTRA.Code,
+			Message:   messages.ERR_INVALID_EXTRA.Text,
+			Exception: reason,
+		}, nil
+	}
+
+	// The same extra listed twice is one line with the quantities added up.
+	var order []string
+	quantities := map[string]int{}
+	for _, r := range requested {
+		if r.Quantity == 0 {
+			r.Quantity = 1
+		}
+		if r.Quantity < 0 {
+			return invalid("quantity must be positive")
+		}
+		if _, seen := quantities[r.ExtraID]; !seen {
+			order = append(order, r.ExtraID)
+		}
+		quantities[r.ExtraID] += r.Quantity
+	}
+
+	days := rentalDays(rental)
+	var extras []models.RentalExtra
+	for _, id := range order {
+		statusCode, extra, errResp := findExtra(ctx, tx, id)
+		if errResp != nil {
+			return nil, statusCode, errResp, nil
+		}
+		quantity := quantities[id]
+		if !extra.Active {
+			return invalid(fmt.Sprintf("%s is no longer offered", extra.Name))
+		}
+		if quantity > extra.MaxPerRental {
+			return invalid(fmt.Sprintf("at most %d %s per rental", extra.MaxPerRental, extra.Name))
+		}
+
+		line := models.RentalExtra{
+			ExtraID:   extra.ID,
+			Name:      extra.Name,
+			Pricing:   extra.Pricing,
+			Quantity:  quantity,
+			UnitCents: extra.PriceCents,
+		}
+		line.AmountCents = int64(quantity) * extra.PriceCents
+		if extra.Pricing == models.ExtraPricingPerDay {
+			line.AmountCents *= int64(days)
+		}
+
+		if extra.TrackInventory {
+			if rental.PickupBranchID == nil {
+				return invalid(fmt.Sprintf("%s is stocked per branch; choose a pickup branch", extra.Name))
+			}
+			line.BranchID = rental.PickupBranchID
+
+			var stock models.ExtraStock
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("extra_id = ? AND branch_id = ?", extra.ID, *rental.PickupBranchID).
+				Limit(1).Find(&stock).Error; err != nil {
+				return nil, 0, nil, err
+			}
+			reserved, err := extrasReserved(tx, extra.ID, *rental.PickupBranchID, rental.StartDate, rental.EndDate, rental.ID)
+			if err != nil {
+				return nil, 0, nil, err
+			}
+			if reserved+quantity > stock.Quantity {
+				return nil, fiber.StatusConflict, &models.ErrorResponse{
+					MessageID: messages.ERR_EXTRA_UNAVAILABLE.Code,
+					Message:   messages.ERR_EXTRA_UNAVAILABLE.Text,
+					Exception: fmt.Sprintf("only %d %s left at the pickup branch for these dates", max(0, stock.Quantity-reserved), extra.Name),
+				}, nil
+			}
+		}
+
+		extras = append(extras, line)
+	}
+
+	return extras, fiber.StatusOK, nil, nil
+}
+
+// extrasReserved counts the units of a stocked extra booked out of a branch
+// for any part of [start, end), mirroring hasRentalConflict for vehicles.
+func extrasReserved(tx *gorm.DB, extraID, branchID uuid.UUID, start, end time.Time, excludeRentalID uuid.UUID) (int, error) {
+	var reserved int
+	err := tx.Model(&models.RentalExtra{}).
+		Joins("JOIN rentals ON rentals.id = rental_extras.rental_id").
+		Where("rental_extras.extra_id = ? AND rental_extras.branch_id = ?", extraID, branchID).
+		Where("rentals.id <> ? AND rentals.status <> ?", excludeRentalID, models.RentalStatusCancelled).
+		Where("(rentals.status <> ? OR rentals.hold_expires_at > ?)", models.RentalStatusHeld, time.Now()).
+		Where("rentals.start_date < ? AND COALESCE(rentals.returned_at, rentals.end_date) > ?", end, start).
+		Select("COALESCE(SUM(rental_extras.quantity), 0)").Scan(&reserved).Error
+	return reserved, err
+}
+
+func extraStock(tx *gorm.DB, extraID, branchID uuid.UUID) (int, error) {
+	var stock models.ExtraStock
+	err := tx.Where("extra_id = ? AND branch_id = ?", extraID, branchID).Limit(1).Find(&stock).Error
+	return stock.Quantity, err
+}
+
+func validateExtra(e *models.Extra) (int, *models.ErrorResponse) {
+	invalid := func(reason string) (int, *models.ErrorResponse) {
+		return fiber.StatusBadRequest, &models.ErrorResponse{
+			MessageID: messages.ERR_INVALID_EXTRA.Code,
+			Message:   messages.ERR_INVALID_EXTRA.Text,
+			Exception: reason,
+		}
+	}
+
+	switch {
+	case e.Code == "" || e.Name == "":
+		return invalid("code and name are required")
+	case !slices.Contains(extraPricings, e.Pricing):
+		return invalid("pricing must be one of " + strings.Join(extraPricings, ", "))
+	case e.PriceCents < 0:
+		return invalid("price_cents must not be negative")
+	case e.MaxPerRental < 1:
+		return invalid("max_per_rental must be at least 1")
+	}
+	return fiber.StatusOK, nil
+}
+
+func findExtra(ctx context.Context, db *gorm.DB, extraID string) (int, *models.Extra, *models.ErrorResponse) {
+	if _, err := uuid.Parse(extraID); err != nil {
+		return fiber.StatusNotFound, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_EXTRA_NOT_FOUND.Code,
+			Message:   messages.ERR_EXTRA_NOT_FOUND.Text,
+			Exception: "invalid extra ID",
+		}
+	}
+
+	var extra models.Extra
+	if err := db.WithContext(ctx).Where("id = ?", extraID).First(&extra).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.StatusNotFound, nil, &models.ErrorResponse{
+				MessageID: messages.ERR_EXTRA_NOT_FOUND.Code,
+				Message:   messages.ERR_EXTRA_NOT_FOUND.Text,
+				Exception: "extra not found",
+			}
+		}
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	return fiber.StatusOK, &extra, nil
+}
+
+func toExtraResponse(e models.Extra) models.ExtraResponse {
+	return models.ExtraResponse{
+		ID:             e.ID,
+		Code:           e.Code,
+		Name:           e.Name,
+		Description:    e.Description,
+		Pricing:        e.Pricing,
+		PriceCents:     e.PriceCents,
+		MaxPerRental:   e.MaxPerRental,
+		TrackInventory: e.TrackInventory,
+		Active:         e.Active,
+		CreatedAt:      e.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      e.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,561 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+	"vehix/core/messages"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var damageZones = []string{
+	"front_bumper", "rear_bumper", "hood", "roof", "trunk", "windshield", "rear_window",
+	"front_left_door", "front_right_door", "rear_left_door", "rear_right_door",
+	"left_mirror", "right_mirror", "left_side", "right_side",
+	"front_left_wheel", "front_right_wheel", "rear_left_wheel", "rear_right_wheel",
+	"interior", "other",
+}
+
+var damageSeverityRank = map[string]int{
+	models.DamageSeverityMinor:    1,
+	models.DamageSeverityModerate: 2,
+	models.DamageSeverityMajor:    3,
+}
+
+// Estimated repair cost per severity. Damage charges are raised for review,
+// so these only need to be in the right ballpark.
+var damageEstimateCents = map[string]int64{
+	models.DamageSeverityMinor:    15_000,
+	models.DamageSeverityModerate: 50_000,
+	models.DamageSeverityMajor:    150_000,
+}
+
+// Mileage and fuel rates used when a vehicle comes back. INCLUDED_KM_PER_DAY
+// of 0 means unlimited mileage.
+var (
+	includedKmPerDay     = envInt64("INCLUDED_KM_PER_DAY", 250)
+	excessKmCents        = envInt64("EXCESS_KM_CENTS", 25)
+	refuelCentsPerPct    = envInt64("REFUEL_CENTS_PER_PERCENT", 120)
+	errInspectionInvalid = errors.New("inspection rejected")
+)
+
+type InspectionService interface {
+	CreateInspection(ctx context.Context, rentalID, inspectedBy string, payload models.CreateInspectionPayload) (int, *models.InspectionResponse, *models.ErrorResponse)
+	GetConditionReport(ctx context.Context, rentalID string) (int, *models.ConditionReport, *models.ErrorResponse)
+	ListRentalCharges(ctx context.Context, rentalID string) (int, *[]models.RentalChargeResponse, *models.ErrorResponse)
+	ReviewCharge(ctx context.Context, chargeID string, payload models.ReviewChargePayload) (int, *models.RentalChargeResponse, *models.ErrorResponse)
+}
+
+type InspectionServiceImpl struct {
+	db *gorm.DB
+}
+
+func NewInspectionService(db *gorm.DB) InspectionService {
+	return &InspectionServiceImpl{db: db}
+}
+
+// CreateInspection records a checkout or checkin report. Recording the checkin
+// compares it with the checkout, marks damage that wasn't there before and
+// raises mileage, fuel and damage charges on the rental.
+func (s *InspectionServiceImpl) CreateInspection(ctx context.Context, rentalID, inspectedBy string, payload models.CreateInspectionPayload) (int, *models.InspectionResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	if statusCode, errResp := validateInspection(payload); errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	inspector, err := uuid.Parse(inspectedBy)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNAUTHORIZED.Code,
+			Message:   messages.ERR_UNAUTHORIZED.Text,
+			Exception: "invalid user ID",
+		}
+	}
+
+	var errResp *models.ErrorResponse
+	statusCode := fiber.StatusCreated
+	var inspection models.Inspection
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var rental *models.Rental
+		statusCode, rental, errResp = findRental(ctx, tx.Clauses(clause.Locking{Strength: "UPDATE"}), rentalID)
+		if errResp != nil {
+			return errInspectionInvalid
+		}
+		statusCode = fiber.StatusCreated
+
+		if rental.Status == models.RentalStatusCancelled ||
+			(payload.Type == models.InspectionTypeCheckout && rental.Status != models.RentalStatusConfirmed) {
+			statusCode, errResp = fiber.StatusConflict, &models.ErrorResponse{
+				MessageID: messages.ERR_INVALID_INSPECTION.Code,
+				Message:   messages.ERR_INVALID_INSPECTION.Text,
+				Exception: fmt.Sprintf("a %s inspection cannot be recorded for a %s rental", payload.Type, rental.Status),
+			}
+			return errInspectionInvalid
+		}
+		if payload.Type == models.InspectionTypeCheckout && rental.BlockedByRentalID != nil {
+			statusCode, errResp = fiber.StatusConflict, &models.ErrorResponse{
+				MessageID: messages.ERR_RENTAL_BLOCKED.Code,
+				Message:   messages.ERR_RENTAL_BLOCKED.Text,
+				Exception: "vehicle has not come back from rental " + rental.BlockedByRentalID.String() + "; move the booking to another vehicle",
+			}
+			return errInspectionInvalid
+		}
+
+		var existing []models.Inspection
+		if err := tx.Preload("Damages").Where("rental_id = ?", rental.ID).Find(&existing).Error; err != nil {
+			return err
+		}
+		var checkout *models.Inspection
+		for i := range existing {
+			if existing[i].Type == payload.Type {
+				statusCode, errResp = fiber.StatusConflict, &models.ErrorResponse{
+					MessageID: messages.ERR_INSPECTION_EXISTS.Code,
+					Message:   messages.ERR_INSPECTION_EXISTS.Text,
+					Exception: "a " + payload.Type + " inspection already exists for this rental",
+				}
+				return errInspectionInvalid
+			}
+			if existing[i].Type == models.InspectionTypeCheckout {
+				checkout = &existing[i]
+			}
+		}
+		if payload.Type == models.InspectionTypeCheckin {
+			if checkout == nil {
+				statusCode, errResp = fiber.StatusConflict, &models.ErrorResponse{
+					MessageID: messages.ERR_CHECKOUT_REQUIRED.Code,
+					Message:   messages.ERR_CHECKOUT_REQUIRED.Text,
+					Exception: "record the checkout inspection before the checkin",
+				}
+				return errInspectionInvalid
+			}
+			if payload.OdometerKm < checkout.OdometerKm {
+				statusCode, errResp = fiber.StatusBadRequest, &models.ErrorResponse{
+					MessageID: messages.ERR_INVALID_INSPECTION.Code,
+					Message:   messages.ERR_INVALID_INSPECTION.Text,
+					Exception: fmt.Sprintf("odometer_km must be at least the checkout reading of %d km", checkout.OdometerKm),
+				}
+				return errInspectionInvalid
+			}
+		}
+
+		inspection = models.Inspection{
+			RentalID:         rental.ID,
+			Type:             payload.Type,
+			VehicleID:        rental.VehicleID,
+			OdometerKm:       payload.OdometerKm,
+			FuelLevelPercent: payload.FuelLevelPercent,
+			Notes:            payload.Notes,
+			InspectedBy:      inspector,
+		}
+		for _, d := range payload.Damages {
+			item := models.DamageItem{Zone: d.Zone, Severity: d.Severity, Description: d.Description}
+			if checkout != nil {
+				item.IsNew = !coveredByCheckout(item, checkout.Damages)
+			}
+			inspection.Damages = append(inspection.Damages, item)
+		}
+
+		if err := tx.Create(&inspection).Error; err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, tx, AuditInspectionCreate, "inspection", inspection.ID.String(), nil, inspection); err != nil {
+			return err
+		}
+
+		if err := recordOdometer(ctx, tx, rental.VehicleID, inspection.OdometerKm); err != nil {
+			return err
+		}
+
+		if checkout == nil {
+			return nil
+		}
+		for _, charge := range inspectionCharges(rental, checkout, &inspection) {
+			if err := tx.Create(&charge).Error; err != nil {
+				return err
+			}
+			if err := recordAudit(ctx, tx, AuditChargeCreate, "rental_charge", charge.ID.String(), nil, charge); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := toInspectionResponse(inspection, nil)
+	return fiber.StatusCreated, &response, nil
+}
+
+func (s *InspectionServiceImpl) GetConditionReport(ctx context.Context, rentalID string) (int, *models.ConditionReport, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	statusCode, rental, errResp := findRental(ctx, s.db, rentalID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	var inspections []models.Inspection
+	if err := db.Preload("Damages").Where("rental_id = ?", rental.ID).Find(&inspections).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	var damageIDs []uuid.UUID
+	for _, i := range inspections {
+		for _, d := range i.Damages {
+			damageIDs = append(damageIDs, d.ID)
+		}
+	}
+	photos := map[uuid.UUID][]models.VehicleMediaResponse{}
+	if len(damageIDs) > 0 {
+		var media []models.VehicleMedia
+		if err := db.Where("damage_item_id IN ?", damageIDs).Order("created_at").Find(&media).Error; err != nil {
+			return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+				MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+				Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+				Exception: err.Error(),
+			}
+		}
+		for _, m := range media {
+			photos[*m.DamageItemID] = append(photos[*m.DamageItemID], toVehicleMediaResponse(m))
+		}
+	}
+
+	var charges []models.RentalCharge
+	if err := db.Where("rental_id = ?", rental.ID).Order("created_at").Find(&charges).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	report := models.ConditionReport{
+		RentalID:   rental.ID,
+		NewDamages: []models.DamageItemResponse{},
+		Charges:    []models.RentalChargeResponse{},
+	}
+	for _, i := range inspections {
+		resp := toInspectionResponse(i, photos)
+		switch i.Type {
+		case models.InspectionTypeCheckout:
+			report.Checkout = &resp
+		case models.InspectionTypeCheckin:
+			report.Checkin = &resp
+			for _, d := range resp.Damages {
+				if d.IsNew {
+					report.NewDamages = append(report.NewDamages, d)
+				}
+			}
+		}
+	}
+	if report.Checkout != nil && report.Checkin != nil {
+		distance := report.Checkin.OdometerKm - report.Checkout.OdometerKm
+		fuel := report.Checkin.FuelLevelPercent - report.Checkout.FuelLevelPercent
+		report.DistanceKm, report.FuelDeltaPercent = &distance, &fuel
+		if includedKmPerDay > 0 {
+			included := int(includedKmPerDay) * rentalDays(rental)
+			report.IncludedKm = &included
+		}
+	}
+	for _, c := range charges {
+		report.Charges = append(report.Charges, toRentalChargeResponse(c))
+	}
+
+	return fiber.StatusOK, &report, nil
+}
+
+func (s *InspectionServiceImpl) ListRentalCharges(ctx context.Context, rentalID string) (int, *[]models.RentalChargeResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	statusCode, rental, errResp := findRental(ctx, s.db, rentalID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	var charges []models.RentalCharge
+	if err := db.Where("rental_id = ?", rental.ID).Order("created_at").Find(&charges).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := []models.RentalChargeResponse{}
+	for _, c := range charges {
+		response = append(response, toRentalChargeResponse(c))
+	}
+
+	return fiber.StatusOK, &response, nil
+}
+
+// ReviewCharge settles a charge before it is invoiced. Approving may adjust
+// the amount (e.g. to the actual repair quote); rejecting waives it, keeping
+// the row at zero so the history stays visible.
+func (s *InspectionServiceImpl) ReviewCharge(ctx context.Context, chargeID string, payload models.ReviewChargePayload) (int, *models.RentalChargeResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	notFound := &models.ErrorResponse{
+		MessageID: messages.ERR_CHARGE_NOT_FOUND.Code,
+		Message:   messages.ERR_CHARGE_NOT_FOUND.Text,
+		Exception: "rental charge not found",
+	}
+	if _, err := uuid.Parse(chargeID); err != nil {
+		return fiber.StatusNotFound, nil, notFound
+	}
+	if payload.AmountCents != nil && *payload.AmountCents < 0 {
+		return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_BAD_REQUEST.Code,
+			Message:   messages.ERR_BAD_REQUEST.Text,
+			Exception: "amount_cents must not be negative",
+		}
+	}
+
+	var errResp *models.ErrorResponse
+	statusCode := fiber.StatusOK
+	var charge models.RentalCharge
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", chargeID).First(&charge).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				statusCode, errResp = fiber.StatusNotFound, notFound
+				return errInspectionInvalid
+			}
+			return err
+		}
+
+		var invoiced int64
+		if err := tx.Model(&models.Invoice{}).
+			Where("rental_id = ? AND kind = ?", charge.RentalID, models.InvoiceKindInvoice).
+			Count(&invoiced).Error; err != nil {
+			return err
+		}
+		if invoiced > 0 {
+			statusCode, errResp = fiber.StatusConflict, &models.ErrorResponse{
+				MessageID: messages.ERR_CHARGE_NOT_REVIEWABLE.Code,
+				Message:   messages.ERR_CHARGE_NOT_REVIEWABLE.Text,
+				Exception: "the rental has already been invoiced; issue a credit note instead",
+			}
+			return errInspectionInvalid
+		}
+
+		before := charge
+		charge.RequiresReview = false
+		switch {
+		case !payload.Approved:
+			charge.UnitCents, charge.AmountCents = 0, 0
+			charge.Description += " (waived)"
+		case payload.AmountCents != nil:
+			charge.Quantity, charge.UnitCents, charge.AmountCents = 1, *payload.AmountCents, *payload.AmountCents
+		}
+		if err := tx.Model(&charge).
+			Select("requires_review", "quantity", "unit_cents", "amount_cents", "description").
+			Updates(&charge).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditChargeReview, "rental_charge", charge.ID.String(), before, charge)
+	})
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := toRentalChargeResponse(charge)
+	return fiber.StatusOK, &response, nil
+}
+
+func validateInspection(payload models.CreateInspectionPayload) (int, *models.ErrorResponse) {
+	invalid := func(reason string) (int, *models.ErrorResponse) {
+		return fiber.StatusBadRequest, &models.ErrorResponse{
+			MessageID: messages.ERR_INVALID_INSPECTION.Code,
+			Message:   messages.ERR_INVALID_INSPECTION.Text,
+			Exception: reason,
+		}
+	}
+
+	if payload.Type != models.InspectionTypeCheckout && payload.Type != models.InspectionTypeCheckin {
+		return invalid("type must be checkout or checkin")
+	}
+	if payload.OdometerKm < 0 {
+		return invalid("odometer_km must not be negative")
+	}
+	if payload.FuelLevelPercent < 0 || payload.FuelLevelPercent > 100 {
+		return invalid("fuel_level_percent must be between 0 and 100")
+	}
+	for _, d := range payload.Damages {
+		if !slices.Contains(damageZones, d.Zone) {
+			return invalid(fmt.Sprintf("unknown damage zone %q", d.Zone))
+		}
+		if _, ok := damageSeverityRank[d.Severity]; !ok {
+			return invalid("severity must be minor, moderate or major")
+		}
+	}
+	return fiber.StatusOK, nil
+}
+
+// coveredByCheckout reports whether a checkin damage item was already present
+// at checkout: same zone, recorded at least as severe.
+func coveredByCheckout(item models.DamageItem, checkout []models.DamageItem) bool {
+	for _, d := range checkout {
+		if d.Zone == item.Zone && damageSeverityRank[d.Severity] >= damageSeverityRank[item.Severity] {
+			return true
+		}
+	}
+	return false
+}
+
+// inspectionCharges prices the difference between checkout and checkin:
+// kilometres over the included allowance, fuel below the checkout level and
+// an estimate for each new damage item.
+func inspectionCharges(rental *models.Rental, checkout, checkin *models.Inspection) []models.RentalCharge {
+	var charges []models.RentalCharge
+
+	distance := checkin.OdometerKm - checkout.OdometerKm
+	if includedKmPerDay > 0 {
+		included := int(includedKmPerDay) * rentalDays(rental)
+		if excess := distance - included; excess > 0 {
+			charges = append(charges, models.RentalCharge{
+				RentalID:    rental.ID,
+				Kind:        models.ChargeKindMileage,
+				Description: fmt.Sprintf("%d km over the %d km included", excess, included),
+				Quantity:    excess,
+				UnitCents:   excessKmCents,
+				AmountCents: int64(excess) * excessKmCents,
+				SourceID:    &checkin.ID,
+			})
+		}
+	}
+
+	if missing := checkout.FuelLevelPercent - checkin.FuelLevelPercent; missing > 0 {
+		charges = append(charges, models.RentalCharge{
+			RentalID:    rental.ID,
+			Kind:        models.ChargeKindFuel,
+			Description: fmt.Sprintf("Refuel %d%% of tank", missing),
+			Quantity:    missing,
+			UnitCents:   refuelCentsPerPct,
+			AmountCents: int64(missing) * refuelCentsPerPct,
+			SourceID:    &checkin.ID,
+		})
+	}
+
+	for _, d := range checkin.Damages {
+		if !d.IsNew {
+			continue
+		}
+		charges = append(charges, models.RentalCharge{
+			RentalID:       rental.ID,
+			Kind:           models.ChargeKindDamage,
+			Description:    fmt.Sprintf("New %s damage: %s", d.Severity, d.Zone),
+			Quantity:       1,
+			UnitCents:      damageEstimateCents[d.Severity],
+			AmountCents:    damageEstimateCents[d.Severity],
+			SourceID:       &d.ID,
+			RequiresReview: true,
+		})
+	}
+
+	return charges
+}
+
+// recordOdometer moves the vehicle's mileage forward to an observed reading.
+func recordOdometer(ctx context.Context, tx *gorm.DB, vehicleID uuid.UUID, odometerKm int) error {
+	var vehicle models.Vehicle
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", vehicleID).First(&vehicle).Error; err != nil {
+		return err
+	}
+	if odometerKm <= vehicle.MileageKm {
+		return nil
+	}
+	before := vehicle
+	vehicle.MileageKm = odometerKm
+	if err := tx.Model(&vehicle).Update("mileage_km", odometerKm).Error; err != nil {
+		return err
+	}
+	return recordAudit(ctx, tx, AuditVehicleUpdate, "vehicle", vehicle.ID.String(), before, vehicle)
+}
+
+// rentalDays counts started 24 hour periods, with a minimum of one.
+func rentalDays(r *models.Rental) int {
+	return max(1, int(math.Ceil(r.EndDate.Sub(r.StartDate).Hours()/24)))
+}
+
+func envInt64(name string, fallback int64) int64 {
+	v, err := strconv.ParseInt(os.Getenv(name), 10, 64)
+	if err != nil || v < 0 {
+		return fallback
+	}
+	return v
+}
+
+func toInspectionResponse(i models.Inspection, photos map[uuid.UUID][]models.VehicleMediaResponse) models.InspectionResponse {
+	resp := models.InspectionResponse{
+		ID:               i.ID,
+		RentalID:         i.RentalID,
+		VehicleID:        i.VehicleID,
+		Type:             i.Type,
+		OdometerKm:       i.OdometerKm,
+		FuelLevelPercent: i.FuelLevelPercent,
+		Notes:            i.Notes,
+		InspectedBy:      i.InspectedBy,
+		Damages:          []models.DamageItemResponse{},
+		CreatedAt:        i.CreatedAt.Format(time.RFC3339),
+	}
+	for _, d := range i.Damages {
+		item := models.DamageItemResponse{
+			ID:          d.ID,
+			Zone:        d.Zone,
+			Severity:    d.Severity,
+			Description: d.Description,
+			IsNew:       d.IsNew,
+			Photos:      photos[d.ID],
+		}
+		if item.Photos == nil {
+			item.Photos = []models.VehicleMediaResponse{}
+		}
+		resp.Damages = append(resp.Damages, item)
+	}
+	return resp
+}
+
+func toRentalChargeResponse(c models.RentalCharge) models.RentalChargeResponse {
+	return models.RentalChargeResponse{
+		ID:             c.ID,
+		RentalID:       c.RentalID,
+		Kind:           c.Kind,
+		Description:    c.Description,
+		Quantity:       c.Quantity,
+		UnitCents:      c.UnitCents,
+		AmountCents:    c.AmountCents,
+		SourceID:       c.SourceID,
+		RequiresReview: c.RequiresReview,
+		CreatedAt:      c.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,638 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"vehix/core/messages"
+	"vehix/core/payments"
+	"vehix/core/pdf"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Rentals booked without a pickup branch are taxed at this rate, and their
+// invoices are issued in this series under the company name.
+var (
+	defaultInvoiceSeries = "MAIN"
+	defaultTaxRateBps    = int(envInt64("DEFAULT_TAX_RATE_BPS", 0))
+	companyName          = envOrDefault("COMPANY_NAME", "Vehix")
+	errInvoiceRejected   = errors.New("invoice rejected")
+)
+
+type InvoiceService interface {
+	IssueInvoice(ctx context.Context, rentalID string) (int, *models.InvoiceResponse, *models.ErrorResponse)
+	GetRentalInvoice(ctx context.Context, rentalID string) (int, *models.InvoiceResponse, *models.ErrorResponse)
+	GetInvoice(ctx context.Context, invoiceID string) (int, *models.InvoiceResponse, *models.ErrorResponse)
+	CreateCreditNote(ctx context.Context, invoiceID string, payload models.CreateCreditNotePayload) (int, *models.InvoiceResponse, *models.ErrorResponse)
+}
+
+type InvoiceServiceImpl struct {
+	db      *gorm.DB
+	gateway payments.PaymentGateway
+}
+
+func NewInvoiceService(db *gorm.DB, gateway payments.PaymentGateway) InvoiceService {
+	return &InvoiceServiceImpl{db: db, gateway: gateway}
+}
+
+// IssueInvoice bills a returned rental: the base rate, one-way fee and every
+// reviewed charge, plus tax at the pickup branch's rate.
+func (s *InvoiceServiceImpl) IssueInvoice(ctx context.Context, rentalID string) (int, *models.InvoiceResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	var errResp *models.ErrorResponse
+	statusCode := fiber.StatusCreated
+	var invoice models.Invoice
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var rental *models.Rental
+		statusCode, rental, errResp = findRental(ctx, tx.Clauses(clause.Locking{Strength: "UPDATE"}), rentalID)
+		if errResp != nil {
+			return errInvoiceRejected
+		}
+		statusCode = fiber.StatusCreated
+
+		if rental.Status != models.RentalStatusReturned {
+			statusCode, errResp = fiber.StatusConflict, &models.ErrorResponse{
+				MessageID: messages.ERR_RENTAL_NOT_INVOICEABLE.Code,
+				Message:   messages.ERR_RENTAL_NOT_INVOICEABLE.Text,
+				Exception: "only returned rentals can be invoiced",
+			}
+			return errInvoiceRejected
+		}
+
+		var existing int64
+		if err := tx.Model(&models.Invoice{}).
+			Where("rental_id = ? AND kind = ?", rental.ID, models.InvoiceKindInvoice).
+			Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			statusCode, errResp = fiber.StatusConflict, &models.ErrorResponse{
+				MessageID: messages.ERR_INVOICE_EXISTS.Code,
+				Message:   messages.ERR_INVOICE_EXISTS.Text,
+				Exception: "issue a credit note to correct the existing invoice",
+			}
+			return errInvoiceRejected
+		}
+
+		var charges []models.RentalCharge
+		if err := tx.Where("rental_id = ?", rental.ID).Order("created_at").Find(&charges).Error; err != nil {
+			return err
+		}
+		for _, c := range charges {
+			if c.RequiresReview {
+				statusCode, errResp = fiber.StatusConflict, &models.ErrorResponse{
+					MessageID: messages.ERR_CHARGES_PENDING_REVIEW.Code,
+					Message:   messages.ERR_CHARGES_PENDING_REVIEW.Text,
+					Exception: fmt.Sprintf("charge %s must be reviewed first", c.ID),
+				}
+				return errInvoiceRejected
+			}
+		}
+
+		var err error
+		if invoice, err = newInvoiceDocument(tx, rental, models.InvoiceKindInvoice); err != nil {
+			return err
+		}
+
+		if err := loadRentalItems(tx, rental); err != nil {
+			return err
+		}
+		invoice.Lines = rentalLines(rental)
+		for _, c := range charges {
+			if c.AmountCents == 0 {
+				continue
+			}
+			invoice.Lines = append(invoice.Lines, models.InvoiceLine{
+				Kind:        c.Kind,
+				Description: c.Description,
+				Quantity:    c.Quantity,
+				UnitCents:   c.UnitCents,
+				AmountCents: c.AmountCents,
+			})
+		}
+		for i := range invoice.Lines {
+			invoice.Lines[i].Position = i + 1
+			invoice.SubtotalCents += invoice.Lines[i].AmountCents
+		}
+		invoice.TaxCents = percentOf(invoice.SubtotalCents, invoice.TaxRateBps)
+		invoice.TotalCents = invoice.SubtotalCents + invoice.TaxCents
+
+		if err := tx.Create(&invoice).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditInvoiceIssue, "invoice", invoice.ID.String(), nil, invoice)
+	})
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := toInvoiceResponse(invoice)
+	return fiber.StatusCreated, &response, nil
+}
+
+// GetRentalInvoice returns the rental's invoice together with any credit
+// notes issued against it.
+func (s *InvoiceServiceImpl) GetRentalInvoice(ctx context.Context, rentalID string) (int, *models.InvoiceResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	statusCode, rental, errResp := findRental(ctx, db, rentalID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	var invoice models.Invoice
+	if err := db.Preload("Lines", orderLines).
+		Where("rental_id = ? AND kind = ?", rental.ID, models.InvoiceKindInvoice).
+		First(&invoice).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.StatusNotFound, nil, &models.ErrorResponse{
+				MessageID: messages.ERR_INVOICE_NOT_FOUND.Code,
+				Message:   messages.ERR_INVOICE_NOT_FOUND.Text,
+				Exception: "rental has not been invoiced",
+			}
+		}
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	return s.withCreditNotes(db, invoice)
+}
+
+func (s *InvoiceServiceImpl) GetInvoice(ctx context.Context, invoiceID string) (int, *models.InvoiceResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	statusCode, invoice, errResp := findInvoice(db.Preload("Lines", orderLines), invoiceID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+	if invoice.Kind == models.InvoiceKindCreditNote {
+		response := toInvoiceResponse(*invoice)
+		return fiber.StatusOK, &response, nil
+	}
+
+	return s.withCreditNotes(db, *invoice)
+}
+
+// CreateCreditNote credits an invoice and refunds the credited amount to the
+// customer, as far as money was actually captured. The refund runs inside the
+// transaction; its idempotency key is derived from the invoice, the amount
+// already credited and the amount requested, so if the commit fails a retry
+// replays the refund instead of paying out twice.
+func (s *InvoiceServiceImpl) CreateCreditNote(ctx context.Context, invoiceID string, payload models.CreateCreditNotePayload) (int, *models.InvoiceResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	invalid := func(reason string) (int, *models.InvoiceResponse, *models.ErrorResponse) {
+		return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_INVALID_CREDIT_NOTE.Code,
+			Message:   messages.ERR_INVALID_CREDIT_NOTE.Text,
+			Exception: reason,
+		}
+	}
+	payload.Reason = strings.TrimSpace(payload.Reason)
+	if payload.Reason == "" {
+		return invalid("reason is required")
+	}
+	if payload.AmountCents < 0 {
+		return invalid("amount_cents must not be negative")
+	}
+
+	var errResp *models.ErrorResponse
+	statusCode := fiber.StatusCreated
+	var note models.Invoice
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Locking the rental serialises credit notes for the same invoice.
+		var original *models.Invoice
+		statusCode, original, errResp = findInvoice(tx, invoiceID)
+		if errResp != nil {
+			return errInvoiceRejected
+		}
+		var rental *models.Rental
+		statusCode, rental, errResp = findRental(ctx, tx.Clauses(clause.Locking{Strength: "UPDATE"}), original.RentalID.String())
+		if errResp != nil {
+			return errInvoiceRejected
+		}
+		statusCode = fiber.StatusCreated
+
+		if original.Kind != models.InvoiceKindInvoice {
+			statusCode, errResp = fiber.StatusConflict, &models.ErrorResponse{
+				MessageID: messages.ERR_INVALID_CREDIT_NOTE.Code,
+				Message:   messages.ERR_INVALID_CREDIT_NOTE.Text,
+				Exception: "credit notes can only be issued against invoices",
+			}
+			return errInvoiceRejected
+		}
+
+		var credited int64
+		if err := tx.Model(&models.Invoice{}).Where("credited_invoice_id = ?", original.ID).
+			Select("COALESCE(SUM(total_cents), 0)").Scan(&credited).Error; err != nil {
+			return err
+		}
+		remaining := original.TotalCents + credited // credit note totals are negative
+		amount := payload.AmountCents
+		if amount == 0 {
+			amount = remaining
+		}
+		if amount <= 0 || amount > remaining {
+			statusCode, errResp = fiber.StatusConflict, &models.ErrorResponse{
+				MessageID: messages.ERR_INVALID_CREDIT_NOTE.Code,
+				Message:   messages.ERR_INVALID_CREDIT_NOTE.Text,
+				Exception: fmt.Sprintf("at most %d can still be credited on this invoice", remaining),
+			}
+			return errInvoiceRejected
+		}
+
+		var err error
+		if note, err = newInvoiceDocument(tx, rental, models.InvoiceKindCreditNote); err != nil {
+			return err
+		}
+		// Keep the original's tax rate so the tax is reversed exactly as charged.
+		note.TaxRateBps = original.TaxRateBps
+		note.CreditedInvoiceID = &original.ID
+		note.Reason = payload.Reason
+		note.TotalCents = -amount
+		note.TaxCents = -(amount - amount*10_000/int64(10_000+original.TaxRateBps))
+		note.SubtotalCents = note.TotalCents - note.TaxCents
+		note.Lines = []models.InvoiceLine{{
+			Position:    1,
+			Kind:        models.InvoiceLineCredit,
+			Description: fmt.Sprintf("Credit against invoice %s: %s", original.Number, payload.Reason),
+			Quantity:    1,
+			UnitCents:   note.SubtotalCents,
+			AmountCents: note.SubtotalCents,
+		}}
+
+		if err := tx.Create(&note).Error; err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, tx, AuditCreditNoteIssue, "invoice", note.ID.String(), nil, note); err != nil {
+			return err
+		}
+
+		refund := min(amount, rental.CapturedCents-rental.RefundedCents)
+		if refund <= 0 {
+			return nil
+		}
+		key := fmt.Sprintf("invoice:%s:credit:%d:%d", original.ID, -credited, amount)
+		res, err := s.gateway.Refund(ctx, rental.PaymentAuthorizationID, refund, key)
+		if err != nil {
+			statusCode, errResp = fiber.StatusBadGateway, &models.ErrorResponse{
+				MessageID: messages.ERR_PAYMENT_GATEWAY.Code,
+				Message:   messages.ERR_PAYMENT_GATEWAY.Text,
+				Exception: err.Error(),
+			}
+			return errInvoiceRejected
+		}
+		before := *rental
+		rental.RefundedCents += res.AmountCents
+		if rental.RefundedCents >= rental.CapturedCents {
+			rental.PaymentStatus = models.PaymentStatusRefunded
+		}
+		if err := savePaymentState(tx, rental); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditRentalPayment, "rental", rental.ID.String(), before, *rental)
+	})
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := toInvoiceResponse(note)
+	return fiber.StatusCreated, &response, nil
+}
+
+func (s *InvoiceServiceImpl) withCreditNotes(db *gorm.DB, invoice models.Invoice) (int, *models.InvoiceResponse, *models.ErrorResponse) {
+	var notes []models.Invoice
+	if err := db.Preload("Lines", orderLines).Where("credited_invoice_id = ?", invoice.ID).
+		Order("issued_at").Find(&notes).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := toInvoiceResponse(invoice)
+	for _, n := range notes {
+		response.CreditNotes = append(response.CreditNotes, toInvoiceResponse(n))
+	}
+	return fiber.StatusOK, &response, nil
+}
+
+// rentalLines itemises what was agreed at booking: the base rate, one-way fee,
+// extras, driver surcharges and fees, and any promo discount. Quotes and invoices both use it so they always match.
+func rentalLines(rental *models.Rental) []models.InvoiceLine {
+	days := rentalDays(rental)
+	lines := []models.InvoiceLine{{
+		Kind:        models.InvoiceLineBaseRate,
+		Description: fmt.Sprintf("Vehicle rental, %s to %s", rental.StartDate.Format(time.DateOnly), rental.EndDate.Format(time.DateOnly)),
+		Quantity:    days,
+		UnitCents:   rental.RentalCents / int64(days),
+		AmountCents: rental.RentalCents,
+	}}
+	if rental.OneWayFeeCents > 0 {
+		lines = append(lines, models.InvoiceLine{
+			Kind:        models.InvoiceLineOneWayFee,
+			Description: "One-way fee",
+			Quantity:    1,
+			UnitCents:   rental.OneWayFeeCents,
+			AmountCents: rental.OneWayFeeCents,
+		})
+	}
+	for _, e := range rental.Extras {
+		line := models.InvoiceLine{
+			Kind:        models.InvoiceLineExtra,
+			Description: e.Name,
+			Quantity:    e.Quantity,
+			UnitCents:   e.UnitCents,
+			AmountCents: e.AmountCents,
+		}
+		if e.Pricing == models.ExtraPricingPerDay {
+			line.Description = fmt.Sprintf("%s, %d x %d days", e.Name, e.Quantity, days)
+			line.Quantity = e.Quantity * days
+		}
+		lines = append(lines, line)
+	}
+	if rental.YoungDriverCents > 0 {
+		lines = append(lines, models.InvoiceLine{
+			Kind:        models.InvoiceLineYoungDriver,
+			Description: "Young driver surcharge",
+			Quantity:    days,
+			UnitCents:   rental.YoungDriverCents / int64(days),
+			AmountCents: rental.YoungDriverCents,
+		})
+	}
+	for _, d := range rental.Drivers {
+		if d.Status != models.RentalDriverPending && d.Status != models.RentalDriverAccepted {
+			continue
+		}
+		lines = append(lines, models.InvoiceLine{
+			Kind:        models.InvoiceLineAdditionalDriver,
+			Description: "Additional driver: " + d.Name,
+			Quantity:    1,
+			UnitCents:   d.FeeCents,
+			AmountCents: d.FeeCents,
+		})
+	}
+	if rental.DiscountCents > 0 {
+		lines = append(lines, models.InvoiceLine{
+			Kind:        models.InvoiceLineDiscount,
+			Description: "Promo code " + rental.PromoCode,
+			Quantity:    1,
+			UnitCents:   -rental.DiscountCents,
+			AmountCents: -rental.DiscountCents,
+		})
+	}
+	return lines
+}
+
+// rentalSubtotalCents is the pre-tax price agreed at booking.
+func rentalSubtotalCents(rental *models.Rental) int64 {
+	return rental.RentalCents + rental.OneWayFeeCents + rental.ExtrasCents + rental.YoungDriverCents +
+		rental.AdditionalDriversCents - rental.DiscountCents
+}
+
+// newInvoiceDocument fills in everything an invoice and a credit note share:
+// the next number in the branch's series, issuer, customer and tax rate.
+func newInvoiceDocument(tx *gorm.DB, rental *models.Rental, kind string) (models.Invoice, error) {
+	doc := models.Invoice{
+		Kind:       kind,
+		RentalID:   rental.ID,
+		UserID:     rental.UserID,
+		BranchID:   rental.PickupBranchID,
+		IssuerName: companyName,
+		Currency:   payments.Currency,
+		TaxRateBps: rental.TaxRateBps,
+		IssuedAt:   time.Now(),
+	}
+
+	prefix := defaultInvoiceSeries
+	if rental.PickupBranchID != nil {
+		branch, err := loadBranch(tx, *rental.PickupBranchID)
+		if err != nil {
+			return doc, err
+		}
+		prefix = branch.Code
+		doc.IssuerAddress = strings.Join(nonEmpty(branch.Name, branch.AddressLine1, branch.AddressLine2,
+			strings.TrimSpace(branch.PostalCode+" "+branch.City), branch.Region, branch.Country), "\n")
+	}
+
+	// Anonymised customers keep their placeholder name; the invoice must still
+	// be issued, so soft-deleted users are included.
+	var user models.User
+	if err := tx.Unscoped().Where("id = ?", rental.UserID).First(&user).Error; err != nil {
+		return doc, err
+	}
+	doc.BillToName, doc.BillToEmail = user.Name, user.Email
+
+	doc.Series = prefix + "-INV"
+	if kind == models.InvoiceKindCreditNote {
+		doc.Series = prefix + "-CN"
+	}
+	if err := tx.Raw(`
+		INSERT INTO invoice_sequences (series, last_number) VALUES (?, 1)
+		ON CONFLICT (series) DO UPDATE SET last_number = invoice_sequences.last_number + 1
+		RETURNING last_number`, doc.Series).Scan(&doc.Sequence).Error; err != nil {
+		return doc, err
+	}
+	doc.Number = fmt.Sprintf("%s-%06d", doc.Series, doc.Sequence)
+	return doc, nil
+}
+
+func findInvoice(db *gorm.DB, invoiceID string) (int, *models.Invoice, *models.ErrorResponse) {
+	notFound := &models.ErrorResponse{
+		MessageID: messages.ERR_INVOICE_NOT_FOUND.Code,
+		Message:   messages.ERR_INVOICE_NOT_FOUND.Text,
+		Exception: "invoice not found",
+	}
+	if _, err := uuid.Parse(invoiceID); err != nil {
+		return fiber.StatusNotFound, nil, notFound
+	}
+
+	var invoice models.Invoice
+	if err := db.Where("id = ?", invoiceID).First(&invoice).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.StatusNotFound, nil, notFound
+		}
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+	return fiber.StatusOK, &invoice, nil
+}
+
+func orderLines(db *gorm.DB) *gorm.DB {
+	return db.Order("position")
+}
+
+// percentOf applies a basis-point rate, rounding half away from zero.
+func percentOf(cents int64, bps int) int64 {
+	v := cents * int64(bps)
+	if v < 0 {
+		return -((-v + 5_000) / 10_000)
+	}
+	return (v + 5_000) / 10_000
+}
+
+func nonEmpty(values ...string) []string {
+	var out []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func envOrDefault(name, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// RenderInvoicePDF lays out an invoice or credit note on A4 pages.
+func RenderInvoicePDF(inv models.InvoiceResponse) []byte {
+	doc := pdf.New()
+	const left, right = 50.0, pdf.PageWidth - 50
+	page := doc.AddPage()
+	y := pdf.PageHeight - 60
+
+	title := "Invoice"
+	if inv.Kind == models.InvoiceKindCreditNote {
+		title = "Credit note"
+	}
+	page.Text(left, y, 20, true, title)
+	page.TextRight(right, y, 11, true, inv.Number)
+	y -= 16
+	page.TextRight(right, y, 9, false, "Issued "+inv.IssuedAt)
+
+	y -= 30
+	top := y
+	page.Text(left, y, 10, true, inv.IssuerName)
+	for _, l := range strings.Split(inv.IssuerAddress, "\n") {
+		y -= 13
+		page.Text(left, y, 9, false, l)
+	}
+	page.Text(320, top, 10, true, "Bill to")
+	page.Text(320, top-13, 9, false, inv.BillToName)
+	page.Text(320, top-26, 9, false, inv.BillToEmail)
+	page.Text(320, top-39, 9, false, "Rental "+inv.RentalID.String())
+	y = min(y, top-39) - 30
+
+	header := func() {
+		page.Text(left, y, 9, true, "Description")
+		page.TextRight(380, y, 9, true, "Qty")
+		page.TextRight(460, y, 9, true, "Unit")
+		page.TextRight(right, y, 9, true, "Amount")
+		y -= 6
+		page.Line(left, y, right, y)
+		y -= 14
+	}
+	header()
+	for _, l := range inv.Lines {
+		if y < 120 {
+			page = doc.AddPage()
+			y = pdf.PageHeight - 60
+			header()
+		}
+		desc := l.Description
+		if r := []rune(desc); len(r) > 60 {
+			desc = string(r[:57]) + "..."
+		}
+		page.Text(left, y, 9, false, desc)
+		page.TextRight(380, y, 9, false, fmt.Sprint(l.Quantity))
+		page.TextRight(460, y, 9, false, formatCents(l.UnitCents))
+		page.TextRight(right, y, 9, false, formatCents(l.AmountCents))
+		y -= 14
+	}
+
+	page.Line(320, y+4, right, y+4)
+	y -= 10
+	for _, row := range [][2]string{
+		{"Subtotal", formatCents(inv.SubtotalCents)},
+		{fmt.Sprintf("Tax %s%%", formatCents(int64(inv.TaxRateBps))), formatCents(inv.TaxCents)},
+		{"Total " + inv.Currency, formatCents(inv.TotalCents)},
+	} {
+		bold := strings.HasPrefix(row[0], "Total")
+		page.Text(320, y, 10, bold, row[0])
+		page.TextRight(right, y, 10, bold, row[1])
+		y -= 15
+	}
+
+	if inv.Reason != "" {
+		y -= 15
+		page.Text(left, y, 9, false, "Reason: "+inv.Reason)
+	}
+	return doc.Bytes()
+}
+
+// formatCents renders an amount in minor units as 1234.56.
+func formatCents(c int64) string {
+	sign := ""
+	if c < 0 {
+		sign, c = "-", -c
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, c/100, c%100)
+}
+
+func toInvoiceResponse(i models.Invoice) models.InvoiceResponse {
+	resp := models.InvoiceResponse{
+		ID:                i.ID,
+		Number:            i.Number,
+		Kind:              i.Kind,
+		RentalID:          i.RentalID,
+		UserID:            i.UserID,
+		BranchID:          i.BranchID,
+		CreditedInvoiceID: i.CreditedInvoiceID,
+		IssuerName:        i.IssuerName,
+		IssuerAddress:     i.IssuerAddress,
+		BillToName:        i.BillToName,
+		BillToEmail:       i.BillToEmail,
+		Currency:          i.Currency,
+		Lines:             []models.InvoiceLineResponse{},
+		SubtotalCents:     i.SubtotalCents,
+		TaxRateBps:        i.TaxRateBps,
+		TaxCents:          i.TaxCents,
+		TotalCents:        i.TotalCents,
+		Reason:            i.Reason,
+		IssuedAt:          i.IssuedAt.Format(time.RFC3339),
+	}
+	for _, l := range i.Lines {
+		resp.Lines = append(resp.Lines, models.InvoiceLineResponse{
+			Kind:        l.Kind,
+			Description: l.Description,
+			Quantity:    l.Quantity,
+			UnitCents:   l.UnitCents,
+			AmountCents: l.AmountCents,
+		})
+	}
+	return resp
+}
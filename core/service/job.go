@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+	"vehix/core/messages"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	jobDefaultPageSize = 50
+	jobMaxPageSize     = 500
+)
+
+var (
+	jobStatuses    = []string{models.JobStatusQueued, models.JobStatusRunning, models.JobStatusSucceeded, models.JobStatusDead}
+	errJobRejected = errors.New("job rejected")
+)
+
+type JobService interface {
+	ListJobs(ctx context.Context, filter models.JobFilter) (int, *[]models.JobResponse, *models.ErrorResponse)
+	GetJob(ctx context.Context, jobID string) (int, *models.JobResponse, *models.ErrorResponse)
+	RetryJob(ctx context.Context, jobID string) (int, *models.JobResponse, *models.ErrorResponse)
+	ListRecurringJobs(ctx context.Context) (int, *[]models.RecurringJobResponse, *models.ErrorResponse)
+}
+
+type JobServiceImpl struct {
+	db *gorm.DB
+}
+
+func NewJobService(db *gorm.DB) JobService {
+	return &JobServiceImpl{db: db}
+}
+
+func (s *JobServiceImpl) ListJobs(ctx context.Context, filter models.JobFilter) (int, *[]models.JobResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	query := db.Model(&models.Job{})
+	if filter.Status != "" {
+		if !slices.Contains(jobStatuses, filter.Status) {
+			return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: "status must be one of " + strings.Join(jobStatuses, ", "),
+			}
+		}
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Kind != "" {
+		query = query.Where("kind = ?", filter.Kind)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = jobDefaultPageSize
+	}
+	if limit > jobMaxPageSize {
+		limit = jobMaxPageSize
+	}
+
+	var jobs []models.Job
+	if err := query.Order("created_at DESC").Limit(limit).Offset(max(filter.Offset, 0)).Find(&jobs).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := []models.JobResponse{}
+	for _, j := range jobs {
+		response = append(response, toJobResponse(j))
+	}
+
+	return fiber.StatusOK, &response, nil
+}
+
+func (s *JobServiceImpl) GetJob(ctx context.Context, jobID string) (int, *models.JobResponse, *models.ErrorResponse) {
+	statusCode, job, errResp := findJob(s.db.WithContext(ctx), jobID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	response := toJobResponse(*job)
+	return fiber.StatusOK, &response, nil
+}
+
+// RetryJob puts a dead job back in the queue with a fresh set of attempts.
+func (s *JobServiceImpl) RetryJob(ctx context.Context, jobID string) (int, *models.JobResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	var errResp *models.ErrorResponse
+	statusCode := fiber.StatusOK
+	var job *models.Job
+	err := db.Transaction(func(tx *gorm.DB) error {
+		statusCode, job, errResp = findJob(tx.Clauses(clause.Locking{Strength: "UPDATE"}), jobID)
+		if errResp != nil {
+			return errJobRejected
+		}
+		if job.Status != models.JobStatusDead {
+			statusCode, errResp = fiber.StatusConflict, &models.ErrorResponse{
+				MessageID: messages.ERR_JOB_NOT_RETRYABLE.Code,
+				Message:   messages.ERR_JOB_NOT_RETRYABLE.Text,
+				Exception: "only dead jobs can be retried, this one is " + job.Status,
+			}
+			return errJobRejected
+		}
+
+		before := *job
+		job.Status = models.JobStatusQueued
+		job.RunAt = time.Now()
+		job.Attempts = 0
+		job.FinishedAt = nil
+		if err := tx.Model(job).Select("status", "run_at", "attempts", "finished_at").Updates(job).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditJobRetry, "job", job.ID.String(), before, *job)
+	})
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := toJobResponse(*job)
+	return fiber.StatusOK, &response, nil
+}
+
+func (s *JobServiceImpl) ListRecurringJobs(ctx context.Context) (int, *[]models.RecurringJobResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	var recurring []models.RecurringJob
+	if err := db.Order("name").Find(&recurring).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := []models.RecurringJobResponse{}
+	for _, r := range recurring {
+		resp := models.RecurringJobResponse{
+			Name:      r.Name,
+			Schedule:  r.Schedule,
+			Kind:      r.Kind,
+			Payload:   r.Payload,
+			NextRunAt: r.NextRunAt.Format(time.RFC3339),
+		}
+		if r.LastRunAt != nil {
+			resp.LastRunAt = r.LastRunAt.Format(time.RFC3339)
+		}
+		response = append(response, resp)
+	}
+
+	return fiber.StatusOK, &response, nil
+}
+
+func findJob(db *gorm.DB, jobID string) (int, *models.Job, *models.ErrorResponse) {
+	notFound := &models.ErrorResponse{
+		MessageID: messages.ERR_JOB_NOT_FOUND.Code,
+		Message:   messages.ERR_JOB_NOT_FOUND.Text,
+		Exception: "job not found",
+	}
+	if _, err := uuid.Parse(jobID); err != nil {
+		return fiber.StatusNotFound, nil, notFound
+	}
+
+	var job models.Job
+	if err := db.Where("id = ?", jobID).First(&job).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.StatusNotFound, nil, notFound
+		}
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	return fiber.StatusOK, &job, nil
+}
+
+func toJobResponse(j models.Job) models.JobResponse {
+	resp := models.JobResponse{
+		ID:           j.ID,
+		Kind:         j.Kind,
+		Payload:      j.Payload,
+		Status:       j.Status,
+		RunAt:        j.RunAt.Format(time.RFC3339),
+		Attempts:     j.Attempts,
+		MaxAttempts:  j.MaxAttempts,
+		LastError:    j.LastError,
+		LockedBy:     j.LockedBy,
+		RecurringJob: j.RecurringJob,
+		CreatedAt:    j.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    j.UpdatedAt.Format(time.RFC3339),
+	}
+	if j.LockedUntil != nil {
+		resp.LockedUntil = j.LockedUntil.Format(time.RFC3339)
+	}
+	if j.FinishedAt != nil {
+		resp.FinishedAt = j.FinishedAt.Format(time.RFC3339)
+	}
+	return resp
+}
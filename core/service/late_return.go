@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+	"vehix/core/events"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	"vehix/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// A rental not returned within lateReturnGrace of its end date is overdue and
+// pays from the booked end: lateReturnHourlyCents per started hour, but never
+// more than lateReturnDailyBps of the booked daily rate for any one day.
+var (
+	lateReturnGrace       = time.Duration(envInt64("LATE_RETURN_GRACE_MINUTES", 30)) * time.Minute
+	lateReturnHourlyCents = envInt64("LATE_RETURN_HOURLY_CENTS", 1_500)
+	lateReturnDailyBps    = int(envInt64("LATE_RETURN_DAILY_BPS", 15_000))
+)
+
+// lateReturnLookahead is how soon the next booking of an overdue vehicle has
+// to start for it to be blocked. Later bookings are left alone since the
+// vehicle may well be back by then.
+var lateReturnLookahead = time.Duration(envInt64("LATE_RETURN_LOOKAHEAD_HOURS", 24)) * time.Hour
+
+// ProcessOverdueRentals flags rentals that haven't come back by the end of
+// their grace period, brings their late fee up to date and blocks the next
+// booking of the vehicle when it starts too soon to be served. Each rental is
+// handled in its own transaction and taken with SKIP LOCKED, so replicas
+// running the same scan share the work rather than repeat it. Flagging a
+// rental and blocking a booking each record an event, from which the
+// notification service alerts the customer and staff; as these happen once,
+// so do the alerts. A rental that fails is logged and skipped, and the scan
+// reports how many did once it has been through the rest.
+func (s *RentalServiceImpl) ProcessOverdueRentals(ctx context.Context) (int64, error) {
+	db := s.db.WithContext(ctx)
+
+	now := time.Now()
+	var ids []uuid.UUID
+	if err := db.Model(&models.Rental{}).
+		Where("status = ? AND end_date < ?", models.RentalStatusConfirmed, now.Add(-lateReturnGrace)).
+		Order("end_date").Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+
+	var processed, failed int64
+	for _, id := range ids {
+		var claimed bool
+		var alerts []string
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var rental models.Rental
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("id = ? AND status = ?", id, models.RentalStatusConfirmed).
+				Limit(1).Find(&rental).Error; err != nil {
+				return err
+			}
+			if rental.ID == uuid.Nil {
+				return nil // another replica has it, or it was returned meanwhile
+			}
+			claimed = true
+
+			if err := saveLateFee(tx, &rental, now); err != nil {
+				return err
+			}
+
+			if rental.OverdueAt == nil {
+				before := rental
+				rental.OverdueAt = &now
+				if err := tx.Model(&rental).Update("overdue_at", rental.OverdueAt).Error; err != nil {
+					return err
+				}
+				if err := recordAudit(ctx, tx, AuditRentalOverdue, "rental", rental.ID.String(), before, rental); err != nil {
+					return err
+				}
+				if err := events.Record(tx, events.RentalOverdue, "rental", rental.ID.String(), rentalEvent(&rental)); err != nil {
+					return err
+				}
+				alerts = append(alerts, fmt.Sprintf("[%s] %s: rental %s of vehicle %s was due back at %s",
+					messages.ERR_RENTAL_OVERDUE.Code, messages.ERR_RENTAL_OVERDUE.Text,
+					rental.ID, rental.VehicleID, rental.EndDate.Format(time.RFC3339)))
+			}
+
+			// Wait for the next booking rather than skip past it to a later
+			// one while it is being changed. Postgres rechecks the conditions
+			// once the lock is free, so this may then find nothing; if the
+			// booking still needs blocking, the next scan does it.
+			var next models.Rental
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("vehicle_id = ? AND id <> ? AND status = ? AND blocked_by_rental_id IS NULL",
+					rental.VehicleID, rental.ID, models.RentalStatusConfirmed).
+				Where("start_date >= ? AND start_date < ?", rental.EndDate, now.Add(lateReturnLookahead)).
+				Order("start_date").Limit(1).Find(&next).Error; err != nil {
+				return err
+			}
+			if next.ID == uuid.Nil {
+				return nil
+			}
+			before := next
+			next.BlockedByRentalID, next.BlockedAt = &rental.ID, &now
+			if err := tx.Model(&next).Select("blocked_by_rental_id", "blocked_at").Updates(&next).Error; err != nil {
+				return err
+			}
+			if err := recordAudit(ctx, tx, AuditRentalBlock, "rental", next.ID.String(), before, next); err != nil {
+				return err
+			}
+			data := rentalEvent(&next)
+			data["blocked_by_rental_id"] = rental.ID
+			if err := events.Record(tx, events.RentalBlocked, "rental", next.ID.String(), data); err != nil {
+				return err
+			}
+			alerts = append(alerts, fmt.Sprintf("[%s] %s: rental %s starting %s is waiting on rental %s",
+				messages.ERR_RENTAL_BLOCKED.Code, messages.ERR_RENTAL_BLOCKED.Text,
+				next.ID, next.StartDate.Format(time.RFC3339), rental.ID))
+			return nil
+		})
+		if err != nil {
+			// One rental failing shouldn't hold up the rest; the next scan
+			// picks it up again.
+			logger.Error(fmt.Sprintf("[%s] %s: processing overdue rental %s: %s", messages.ERR_UNEXPECTED_ERROR.Code,
+				messages.ERR_UNEXPECTED_ERROR.Text, id, err))
+			failed++
+			continue
+		}
+		if claimed {
+			processed++
+		}
+		for _, alert := range alerts {
+			logger.Warn(alert)
+		}
+	}
+
+	if failed > 0 {
+		return processed, fmt.Errorf("%d of %d overdue rentals could not be processed", failed, len(ids))
+	}
+	return processed, nil
+}
+
+// lateReturnFee prices a return at the given time and reports the started
+// hours past the booked end.
+func lateReturnFee(rental *models.Rental, at time.Time) (int64, int) {
+	late := at.Sub(rental.EndDate)
+	if late <= lateReturnGrace {
+		return 0, 0
+	}
+	hours := int(math.Ceil(late.Hours()))
+	dailyCap := percentOf(rental.RentalCents/int64(rentalDays(rental)), lateReturnDailyBps)
+	fee := int64(hours/24)*dailyCap + min(int64(hours%24)*lateReturnHourlyCents, dailyCap)
+	return fee, hours
+}
+
+// saveLateFee keeps the rental's single late return charge in line with
+// lateness at the given time, creating it on first use. Capture and invoicing
+// pick it up like any other charge.
+func saveLateFee(tx *gorm.DB, rental *models.Rental, at time.Time) error {
+	fee, hours := lateReturnFee(rental, at)
+
+	var charge models.RentalCharge
+	if err := tx.Where("rental_id = ? AND kind = ?", rental.ID, models.ChargeKindLate).Limit(1).Find(&charge).Error; err != nil {
+		return err
+	}
+	switch {
+	case charge.ID == uuid.Nil && fee == 0:
+		return nil
+	case charge.ID != uuid.Nil && fee == 0:
+		return tx.Delete(&charge).Error
+	case charge.ID != uuid.Nil && charge.AmountCents == fee:
+		return nil
+	}
+
+	charge.RentalID = rental.ID
+	charge.Kind = models.ChargeKindLate
+	charge.Description = fmt.Sprintf("Late return, %d hours after %s", hours, rental.EndDate.Format(time.RFC3339))
+	charge.Quantity = 1
+	charge.UnitCents = fee
+	charge.AmountCents = fee
+	return tx.Save(&charge).Error
+}
+
+// releaseBlockedRentals lifts the blocks an overdue rental put on later
+// bookings of its vehicle, once the vehicle is back or no longer late.
+func releaseBlockedRentals(ctx context.Context, tx *gorm.DB, rental *models.Rental) error {
+	var blocked []models.Rental
+	if err := tx.Where("blocked_by_rental_id = ?", rental.ID).Find(&blocked).Error; err != nil {
+		return err
+	}
+	for _, b := range blocked {
+		before := b
+		b.BlockedByRentalID, b.BlockedAt = nil, nil
+		if err := tx.Model(&b).Select("blocked_by_rental_id", "blocked_at").Updates(&b).Error; err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, tx, AuditRentalBlock, "rental", b.ID.String(), before, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,612 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"vehix/core/messages"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Service intervals raise a pending task this far ahead of the due date or
+// mileage, giving staff time to find a slot between bookings.
+const (
+	serviceTaskLeadTime = 14 * 24 * time.Hour
+	serviceTaskLeadKm   = 1000
+)
+
+// errMaintenanceRejected rolls back a maintenance transaction after the
+// closure has filled in the error response to return.
+var errMaintenanceRejected = errors.New("maintenance change rejected")
+
+var blockingMaintenanceStatuses = []string{models.MaintenanceStatusScheduled, models.MaintenanceStatusInProgress}
+
+var openMaintenanceStatuses = []string{
+	models.MaintenanceStatusPending, models.MaintenanceStatusScheduled, models.MaintenanceStatusInProgress,
+}
+
+type MaintenanceService interface {
+	ListMaintenance(ctx context.Context, filter models.MaintenanceFilter) (int, *[]models.MaintenanceResponse, *models.ErrorResponse)
+	CreateMaintenance(ctx context.Context, vehicleID, createdBy string, payload models.CreateMaintenancePayload) (int, *models.MaintenanceResponse, *models.ErrorResponse)
+	UpdateMaintenance(ctx context.Context, maintenanceID string, req *models.UpdateMaintenancePayload) (int, *models.MaintenanceResponse, *models.ErrorResponse)
+	CompleteMaintenance(ctx context.Context, maintenanceID string, payload models.CompleteMaintenancePayload) (int, *models.MaintenanceResponse, *models.ErrorResponse)
+	ListServiceIntervals(ctx context.Context, vehicleID string) (int, *[]models.ServiceIntervalResponse, *models.ErrorResponse)
+	CreateServiceInterval(ctx context.Context, vehicleID string, payload models.CreateServiceIntervalPayload) (int, *models.ServiceIntervalResponse, *models.ErrorResponse)
+	DeleteServiceInterval(ctx context.Context, intervalID string) (int, *models.ErrorResponse)
+	RaiseDueServiceTasks(ctx context.Context) (int64, error)
+}
+
+type MaintenanceServiceImpl struct {
+	db *gorm.DB
+}
+
+func NewMaintenanceService(db *gorm.DB) MaintenanceService {
+	return &MaintenanceServiceImpl{db: db}
+}
+
+func (s *MaintenanceServiceImpl) ListMaintenance(ctx context.Context, filter models.MaintenanceFilter) (int, *[]models.MaintenanceResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	query := db.Model(&models.MaintenanceWindow{})
+	if filter.VehicleID != "" {
+		statusCode, vehicle, errResp := findVehicle(ctx, s.db, filter.VehicleID)
+		if errResp != nil {
+			return statusCode, nil, errResp
+		}
+		query = query.Where("vehicle_id = ?", vehicle.ID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var windows []models.MaintenanceWindow
+	if err := query.Order("COALESCE(starts_at, due_at, created_at)").Find(&windows).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := []models.MaintenanceResponse{}
+	for _, w := range windows {
+		response = append(response, toMaintenanceResponse(w, nil))
+	}
+
+	return fiber.StatusOK, &response, nil
+}
+
+// CreateMaintenance blocks the vehicle for [starts_at, ends_at). Planned work
+// must fit between bookings; ad-hoc work (breakdowns, accident repairs) is
+// recorded regardless and the overlapping rentals are reported so they can be
+// moved to another vehicle.
+func (s *MaintenanceServiceImpl) CreateMaintenance(ctx context.Context, vehicleID, createdBy string, payload models.CreateMaintenancePayload) (int, *models.MaintenanceResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	if payload.Kind == "" {
+		payload.Kind = models.MaintenanceKindPlanned
+	}
+	if payload.Kind != models.MaintenanceKindPlanned && payload.Kind != models.MaintenanceKindAdHoc {
+		return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_INVALID_MAINTENANCE_WINDOW.Code,
+			Message:   messages.ERR_INVALID_MAINTENANCE_WINDOW.Text,
+			Exception: "kind must be planned or adhoc",
+		}
+	}
+	if payload.Reason == "" || !payload.EndsAt.After(payload.StartsAt) {
+		return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_INVALID_MAINTENANCE_WINDOW.Code,
+			Message:   messages.ERR_INVALID_MAINTENANCE_WINDOW.Text,
+			Exception: "reason is required and ends_at must be after starts_at",
+		}
+	}
+
+	statusCode, vehicle, errResp := findVehicle(ctx, s.db, vehicleID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	window := models.MaintenanceWindow{
+		VehicleID: vehicle.ID,
+		Kind:      payload.Kind,
+		Status:    models.MaintenanceStatusScheduled,
+		Reason:    payload.Reason,
+		StartsAt:  &payload.StartsAt,
+		EndsAt:    &payload.EndsAt,
+	}
+	if creator, err := uuid.Parse(createdBy); err == nil {
+		window.CreatedBy = &creator
+	}
+	if window.Kind == models.MaintenanceKindAdHoc && !payload.StartsAt.After(time.Now()) {
+		window.Status = models.MaintenanceStatusInProgress
+	}
+
+	var impacted []uuid.UUID
+	statusCode = fiber.StatusCreated
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		statusCode, impacted, errResp, err = checkMaintenanceSlot(tx, &window)
+		if errResp != nil {
+			return errMaintenanceRejected
+		}
+		if err != nil {
+			return err
+		}
+		statusCode = fiber.StatusCreated
+
+		if err := tx.Create(&window).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditMaintenanceCreate, "maintenance", window.ID.String(), nil, window)
+	})
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := toMaintenanceResponse(window, impacted)
+	return statusCode, &response, nil
+}
+
+// UpdateMaintenance reschedules a window, books a slot for a pending service
+// task (moving it to scheduled), or moves it to in_progress or cancelled.
+func (s *MaintenanceServiceImpl) UpdateMaintenance(ctx context.Context, maintenanceID string, req *models.UpdateMaintenancePayload) (int, *models.MaintenanceResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	var errResp *models.ErrorResponse
+	var window *models.MaintenanceWindow
+	var impacted []uuid.UUID
+	statusCode := fiber.StatusOK
+	err := db.Transaction(func(tx *gorm.DB) error {
+		statusCode, window, errResp = findMaintenance(ctx, tx.Clauses(clause.Locking{Strength: "UPDATE"}), maintenanceID)
+		if errResp != nil {
+			return errMaintenanceRejected
+		}
+		if window.Status == models.MaintenanceStatusCompleted || window.Status == models.MaintenanceStatusCancelled {
+			statusCode, errResp = fiber.StatusConflict, &models.ErrorResponse{
+				MessageID: messages.ERR_MAINTENANCE_NOT_EDITABLE.Code,
+				Message:   messages.ERR_MAINTENANCE_NOT_EDITABLE.Text,
+				Exception: "window is already " + window.Status,
+			}
+			return errMaintenanceRejected
+		}
+
+		before := *window
+
+		if req.Reason != nil {
+			window.Reason = *req.Reason
+		}
+		if req.StartsAt != nil {
+			window.StartsAt = req.StartsAt
+		}
+		if req.EndsAt != nil {
+			window.EndsAt = req.EndsAt
+		}
+		if req.Status != nil {
+			switch *req.Status {
+			case models.MaintenanceStatusInProgress, models.MaintenanceStatusCancelled, models.MaintenanceStatusScheduled:
+				window.Status = *req.Status
+			default:
+				statusCode, errResp = fiber.StatusBadRequest, &models.ErrorResponse{
+					MessageID: messages.ERR_INVALID_MAINTENANCE_WINDOW.Code,
+					Message:   messages.ERR_INVALID_MAINTENANCE_WINDOW.Text,
+					Exception: "status can only be set to scheduled, in_progress or cancelled; use /complete to finish",
+				}
+				return errMaintenanceRejected
+			}
+		}
+		if window.Status == models.MaintenanceStatusPending && window.StartsAt != nil && window.EndsAt != nil {
+			window.Status = models.MaintenanceStatusScheduled
+		}
+
+		if window.Status != models.MaintenanceStatusCancelled && window.Status != models.MaintenanceStatusPending {
+			if window.StartsAt == nil || window.EndsAt == nil || !window.EndsAt.After(*window.StartsAt) {
+				statusCode, errResp = fiber.StatusBadRequest, &models.ErrorResponse{
+					MessageID: messages.ERR_INVALID_MAINTENANCE_WINDOW.Code,
+					Message:   messages.ERR_INVALID_MAINTENANCE_WINDOW.Text,
+					Exception: "starts_at and ends_at are required and ends_at must be after starts_at",
+				}
+				return errMaintenanceRejected
+			}
+			var err error
+			statusCode, impacted, errResp, err = checkMaintenanceSlot(tx, window)
+			if errResp != nil {
+				return errMaintenanceRejected
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Save(window).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditMaintenanceUpdate, "maintenance", maintenanceID, before, *window)
+	})
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := toMaintenanceResponse(*window, impacted)
+	return fiber.StatusOK, &response, nil
+}
+
+// CompleteMaintenance closes a window (releasing the vehicle from now on),
+// records the odometer reading and, for service tasks, restarts the interval.
+func (s *MaintenanceServiceImpl) CompleteMaintenance(ctx context.Context, maintenanceID string, payload models.CompleteMaintenancePayload) (int, *models.MaintenanceResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	var errResp *models.ErrorResponse
+	var window *models.MaintenanceWindow
+	statusCode := fiber.StatusOK
+	err := db.Transaction(func(tx *gorm.DB) error {
+		statusCode, window, errResp = findMaintenance(ctx, tx.Clauses(clause.Locking{Strength: "UPDATE"}), maintenanceID)
+		if errResp != nil {
+			return errMaintenanceRejected
+		}
+		if window.Status == models.MaintenanceStatusCompleted || window.Status == models.MaintenanceStatusCancelled {
+			statusCode, errResp = fiber.StatusConflict, &models.ErrorResponse{
+				MessageID: messages.ERR_MAINTENANCE_NOT_EDITABLE.Code,
+				Message:   messages.ERR_MAINTENANCE_NOT_EDITABLE.Text,
+				Exception: "window is already " + window.Status,
+			}
+			return errMaintenanceRejected
+		}
+
+		var vehicle models.Vehicle
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", window.VehicleID).First(&vehicle).Error; err != nil {
+			return err
+		}
+		if payload.MileageKm < vehicle.MileageKm {
+			statusCode, errResp = fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_INVALID_VEHICLE_ATTRIBUTE.Code,
+				Message:   messages.ERR_INVALID_VEHICLE_ATTRIBUTE.Text,
+				Exception: fmt.Sprintf("mileage_km must be at least the recorded %d km", vehicle.MileageKm),
+			}
+			return errMaintenanceRejected
+		}
+
+		before := *window
+		now := time.Now()
+		window.Status = models.MaintenanceStatusCompleted
+		window.CompletedAt = &now
+		window.CompletedMileage = &payload.MileageKm
+		if window.StartsAt == nil || window.StartsAt.After(now) {
+			window.StartsAt = &now
+		}
+		if window.EndsAt == nil || window.EndsAt.After(now) {
+			window.EndsAt = &now
+		}
+		if err := tx.Save(window).Error; err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, tx, AuditMaintenanceDone, "maintenance", maintenanceID, before, *window); err != nil {
+			return err
+		}
+
+		if payload.MileageKm > vehicle.MileageKm {
+			vehicleBefore := vehicle
+			vehicle.MileageKm = payload.MileageKm
+			if err := tx.Model(&vehicle).Update("mileage_km", vehicle.MileageKm).Error; err != nil {
+				return err
+			}
+			if err := recordAudit(ctx, tx, AuditVehicleUpdate, "vehicle", vehicle.ID.String(), vehicleBefore, vehicle); err != nil {
+				return err
+			}
+		}
+
+		if window.ServiceIntervalID != nil {
+			return tx.Model(&models.ServiceInterval{}).Where("id = ?", *window.ServiceIntervalID).
+				Updates(map[string]any{"last_service_at": now, "last_service_km": payload.MileageKm}).Error
+		}
+		return nil
+	})
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := toMaintenanceResponse(*window, nil)
+	return fiber.StatusOK, &response, nil
+}
+
+func (s *MaintenanceServiceImpl) ListServiceIntervals(ctx context.Context, vehicleID string) (int, *[]models.ServiceIntervalResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	statusCode, vehicle, errResp := findVehicle(ctx, s.db, vehicleID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	var intervals []models.ServiceInterval
+	if err := db.Where("vehicle_id = ?", vehicle.ID).Order("name").Find(&intervals).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := []models.ServiceIntervalResponse{}
+	for _, i := range intervals {
+		response = append(response, toServiceIntervalResponse(i))
+	}
+
+	return fiber.StatusOK, &response, nil
+}
+
+func (s *MaintenanceServiceImpl) CreateServiceInterval(ctx context.Context, vehicleID string, payload models.CreateServiceIntervalPayload) (int, *models.ServiceIntervalResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	if payload.Name == "" || (payload.EveryKm <= 0 && payload.EveryDays <= 0) || payload.EveryKm < 0 || payload.EveryDays < 0 || payload.DurationHours < 0 {
+		return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_BAD_REQUEST.Code,
+			Message:   messages.ERR_BAD_REQUEST.Text,
+			Exception: "name and a positive every_km and/or every_days are required",
+		}
+	}
+
+	statusCode, vehicle, errResp := findVehicle(ctx, s.db, vehicleID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	interval := models.ServiceInterval{
+		VehicleID:     vehicle.ID,
+		Name:          payload.Name,
+		EveryKm:       payload.EveryKm,
+		EveryDays:     payload.EveryDays,
+		DurationHours: payload.DurationHours,
+		LastServiceAt: time.Now(),
+		LastServiceKm: vehicle.MileageKm,
+	}
+	if interval.DurationHours == 0 {
+		interval.DurationHours = 24
+	}
+	if payload.LastServiceAt != nil {
+		interval.LastServiceAt = *payload.LastServiceAt
+	}
+	if payload.LastServiceKm != nil {
+		interval.LastServiceKm = *payload.LastServiceKm
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&interval).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditIntervalCreate, "service_interval", interval.ID.String(), nil, interval)
+	})
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := toServiceIntervalResponse(interval)
+	return fiber.StatusCreated, &response, nil
+}
+
+func (s *MaintenanceServiceImpl) DeleteServiceInterval(ctx context.Context, intervalID string) (int, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	notFound := &models.ErrorResponse{
+		MessageID: messages.ERR_SERVICE_INTERVAL_NOT_FOUND.Code,
+		Message:   messages.ERR_SERVICE_INTERVAL_NOT_FOUND.Text,
+		Exception: "service interval not found",
+	}
+	if _, err := uuid.Parse(intervalID); err != nil {
+		return fiber.StatusNotFound, notFound
+	}
+
+	var interval models.ServiceInterval
+	if err := db.Where("id = ?", intervalID).First(&interval).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.StatusNotFound, notFound
+		}
+		return fiber.StatusInternalServerError, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	// Tasks already raised by the interval are kept; staff can cancel them.
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&interval).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditIntervalDelete, "service_interval", intervalID, interval, nil)
+	})
+	if err != nil {
+		return fiber.StatusInternalServerError, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	return fiber.StatusNoContent, nil
+}
+
+// RaiseDueServiceTasks creates a pending maintenance task for every service
+// interval that is due (or nearly due) by date or mileage and doesn't already
+// have an open task. It returns the number of tasks created.
+func (s *MaintenanceServiceImpl) RaiseDueServiceTasks(ctx context.Context) (int64, error) {
+	db := s.db.WithContext(ctx)
+
+	var intervals []models.ServiceInterval
+	err := db.Where("NOT EXISTS (SELECT 1 FROM maintenance_windows m WHERE m.service_interval_id = service_intervals.id AND m.status IN ?)",
+		openMaintenanceStatuses).Find(&intervals).Error
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	var created int64
+	for _, interval := range intervals {
+		var vehicle models.Vehicle
+		if err := db.Where("id = ?", interval.VehicleID).Limit(1).Find(&vehicle).Error; err != nil {
+			return created, err
+		}
+		if vehicle.ID == uuid.Nil {
+			continue // vehicle has been retired
+		}
+
+		window := models.MaintenanceWindow{
+			VehicleID:         vehicle.ID,
+			ServiceIntervalID: &interval.ID,
+			Kind:              models.MaintenanceKindPlanned,
+			Status:            models.MaintenanceStatusPending,
+			Reason:            interval.Name,
+		}
+		due := false
+		if interval.EveryDays > 0 {
+			dueAt := interval.LastServiceAt.AddDate(0, 0, interval.EveryDays)
+			window.DueAt = &dueAt
+			due = due || dueAt.Sub(now) <= serviceTaskLeadTime
+		}
+		if interval.EveryKm > 0 {
+			dueKm := interval.LastServiceKm + interval.EveryKm
+			window.DueMileageKm = &dueKm
+			due = due || vehicle.MileageKm >= dueKm-serviceTaskLeadKm
+		}
+		if !due {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&window).Error; err != nil {
+				return err
+			}
+			return recordAudit(ctx, tx, AuditMaintenanceCreate, "maintenance", window.ID.String(), nil, window)
+		})
+		if err != nil {
+			return created, err
+		}
+		created++
+	}
+
+	return created, nil
+}
+
+// checkMaintenanceSlot locks the vehicle and checks the window against its
+// rentals. Planned windows that overlap a booking are rejected; ad-hoc ones
+// go ahead and return the rentals they impact.
+func checkMaintenanceSlot(tx *gorm.DB, window *models.MaintenanceWindow) (int, []uuid.UUID, *models.ErrorResponse, error) {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", window.VehicleID).First(&models.Vehicle{}).Error; err != nil {
+		return 0, nil, nil, err
+	}
+
+	var overlapping []uuid.UUID
+	err := tx.Model(&models.Rental{}).
+		Where("vehicle_id = ? AND status IN ?", window.VehicleID, bookedStatuses).
+		Where(holdLiveSQL, models.RentalStatusHeld, time.Now()).
+		Where("start_date < ? AND end_date > ?", *window.EndsAt, *window.StartsAt).
+		Order("start_date").Pluck("id", &overlapping).Error
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	if len(overlapping) > 0 && window.Kind != models.MaintenanceKindAdHoc {
+		return fiber.StatusConflict, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_MAINTENANCE_CONFLICT.Code,
+			Message:   messages.ERR_MAINTENANCE_CONFLICT.Text,
+			Exception: fmt.Sprintf("%d rental(s) overlap the window; choose another slot or record it as adhoc", len(overlapping)),
+		}, nil
+	}
+
+	return fiber.StatusOK, overlapping, nil, nil
+}
+
+func findMaintenance(ctx context.Context, db *gorm.DB, maintenanceID string) (int, *models.MaintenanceWindow, *models.ErrorResponse) {
+	if _, err := uuid.Parse(maintenanceID); err != nil {
+		return fiber.StatusNotFound, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_MAINTENANCE_NOT_FOUND.Code,
+			Message:   messages.ERR_MAINTENANCE_NOT_FOUND.Text,
+			Exception: "invalid maintenance ID",
+		}
+	}
+
+	var window models.MaintenanceWindow
+	if err := db.WithContext(ctx).Where("id = ?", maintenanceID).First(&window).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.StatusNotFound, nil, &models.ErrorResponse{
+				MessageID: messages.ERR_MAINTENANCE_NOT_FOUND.Code,
+				Message:   messages.ERR_MAINTENANCE_NOT_FOUND.Text,
+				Exception: "maintenance window not found",
+			}
+		}
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	return fiber.StatusOK, &window, nil
+}
+
+func toMaintenanceResponse(w models.MaintenanceWindow, impacted []uuid.UUID) models.MaintenanceResponse {
+	format := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+	return models.MaintenanceResponse{
+		ID:                w.ID,
+		VehicleID:         w.VehicleID,
+		ServiceIntervalID: w.ServiceIntervalID,
+		Kind:              w.Kind,
+		Status:            w.Status,
+		Reason:            w.Reason,
+		StartsAt:          format(w.StartsAt),
+		EndsAt:            format(w.EndsAt),
+		DueAt:             format(w.DueAt),
+		DueMileageKm:      w.DueMileageKm,
+		CompletedAt:       format(w.CompletedAt),
+		CompletedMileage:  w.CompletedMileage,
+		ImpactedRentalIDs: impacted,
+		CreatedAt:         w.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func toServiceIntervalResponse(i models.ServiceInterval) models.ServiceIntervalResponse {
+	return models.ServiceIntervalResponse{
+		ID:            i.ID,
+		VehicleID:     i.VehicleID,
+		Name:          i.Name,
+		EveryKm:       i.EveryKm,
+		EveryDays:     i.EveryDays,
+		DurationHours: i.DurationHours,
+		LastServiceAt: i.LastServiceAt.Format(time.RFC3339),
+		LastServiceKm: i.LastServiceKm,
+	}
+}
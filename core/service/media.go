@@ -0,0 +1,474 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	"vehix/core/storage"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	MaxPhotoBytes    = 10 << 20
+	MaxDocumentBytes = 20 << 20
+
+	thumbnailMaxSide = 320
+	maxImagePixels   = 50_000_000
+)
+
+// Upload types are sniffed from the content, never trusted from the client.
+var mediaContentTypes = map[string][]string{
+	models.MediaKindPhoto:    {"image/jpeg", "image/png"},
+	models.MediaKindDocument: {"application/pdf", "image/jpeg", "image/png"},
+	models.MediaKindDamage:   {"image/jpeg", "image/png"},
+}
+
+var mediaExtensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"application/pdf": ".pdf",
+}
+
+// MediaContent is an opened media file; the caller must close Body.
+type MediaContent struct {
+	Body        io.ReadCloser
+	ContentType string
+	FileName    string
+}
+
+type MediaService interface {
+	UploadMedia(ctx context.Context, vehicleID, uploadedBy, kind, fileName string, data []byte) (int, *models.VehicleMediaResponse, *models.ErrorResponse)
+	AttachDamagePhoto(ctx context.Context, damageItemID, uploadedBy, fileName string, data []byte) (int, *models.VehicleMediaResponse, *models.ErrorResponse)
+	ListMedia(ctx context.Context, vehicleID string, includeInternal bool) (int, *[]models.VehicleMediaResponse, *models.ErrorResponse)
+	OpenMedia(ctx context.Context, vehicleID, mediaID string, thumbnail, includeInternal bool) (int, *MediaContent, *models.ErrorResponse)
+	DeleteMedia(ctx context.Context, vehicleID, mediaID string) (int, *models.ErrorResponse)
+}
+
+type MediaServiceImpl struct {
+	db    *gorm.DB
+	blobs storage.BlobStore
+}
+
+func NewMediaService(db *gorm.DB, blobs storage.BlobStore) MediaService {
+	return &MediaServiceImpl{db: db, blobs: blobs}
+}
+
+func (s *MediaServiceImpl) UploadMedia(ctx context.Context, vehicleID, uploadedBy, kind, fileName string, data []byte) (int, *models.VehicleMediaResponse, *models.ErrorResponse) {
+	if kind == models.MediaKindDamage {
+		return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_BAD_REQUEST.Code,
+			Message:   messages.ERR_BAD_REQUEST.Text,
+			Exception: "damage photos are attached to an inspection's damage item",
+		}
+	}
+
+	statusCode, vehicle, errResp := findVehicle(ctx, s.db, vehicleID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	return s.storeMedia(ctx, vehicle.ID, nil, uploadedBy, kind, fileName, data)
+}
+
+// AttachDamagePhoto stores a photo of a damage item recorded during an
+// inspection. It is kept with the vehicle's media so the damage history
+// follows the car across rentals.
+func (s *MediaServiceImpl) AttachDamagePhoto(ctx context.Context, damageItemID, uploadedBy, fileName string, data []byte) (int, *models.VehicleMediaResponse, *models.ErrorResponse) {
+	notFound := &models.ErrorResponse{
+		MessageID: messages.ERR_DAMAGE_ITEM_NOT_FOUND.Code,
+		Message:   messages.ERR_DAMAGE_ITEM_NOT_FOUND.Text,
+		Exception: "damage item not found",
+	}
+	if _, err := uuid.Parse(damageItemID); err != nil {
+		return fiber.StatusNotFound, nil, notFound
+	}
+
+	var inspection models.Inspection
+	err := s.db.WithContext(ctx).
+		Joins("JOIN damage_items ON damage_items.inspection_id = inspections.id").
+		Where("damage_items.id = ?", damageItemID).
+		First(&inspection).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.StatusNotFound, nil, notFound
+		}
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	damageID := uuid.MustParse(damageItemID)
+	return s.storeMedia(ctx, inspection.VehicleID, &damageID, uploadedBy, models.MediaKindDamage, fileName, data)
+}
+
+func (s *MediaServiceImpl) storeMedia(ctx context.Context, vehicleID uuid.UUID, damageItemID *uuid.UUID, uploadedBy, kind, fileName string, data []byte) (int, *models.VehicleMediaResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	allowed, ok := mediaContentTypes[kind]
+	if !ok {
+		return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_BAD_REQUEST.Code,
+			Message:   messages.ERR_BAD_REQUEST.Text,
+			Exception: "kind must be photo or document",
+		}
+	}
+
+	if limit := mediaSizeLimit(kind); len(data) > limit {
+		return fiber.StatusRequestEntityTooLarge, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_MEDIA_TOO_LARGE.Code,
+			Message:   messages.ERR_MEDIA_TOO_LARGE.Text,
+			Exception: fmt.Sprintf("%s uploads are limited to %d MiB", kind, limit>>20),
+		}
+	}
+
+	contentType, _, _ := strings.Cut(http.DetectContentType(data), ";")
+	if !slices.Contains(allowed, contentType) {
+		return fiber.StatusUnsupportedMediaType, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNSUPPORTED_MEDIA_TYPE.Code,
+			Message:   messages.ERR_UNSUPPORTED_MEDIA_TYPE.Text,
+			Exception: fmt.Sprintf("%s uploads must be one of %s, got %s", kind, strings.Join(allowed, ", "), contentType),
+		}
+	}
+
+	uploader, err := uuid.Parse(uploadedBy)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNAUTHORIZED.Code,
+			Message:   messages.ERR_UNAUTHORIZED.Text,
+			Exception: "invalid user ID",
+		}
+	}
+
+	media := models.VehicleMedia{
+		ID:           uuid.New(),
+		VehicleID:    vehicleID,
+		Kind:         kind,
+		FileName:     sanitizeFileName(fileName, contentType),
+		ContentType:  contentType,
+		SizeBytes:    int64(len(data)),
+		UploadedBy:   uploader,
+		DamageItemID: damageItemID,
+	}
+	prefix := fmt.Sprintf("vehicles/%s/%s/", vehicleID, media.ID)
+	media.StorageKey = prefix + "original" + mediaExtensions[contentType]
+
+	var thumb []byte
+	if strings.HasPrefix(contentType, "image/") {
+		thumb, media.Width, media.Height, err = makeThumbnail(data)
+		if err != nil {
+			return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+				MessageID: messages.ERR_UNSUPPORTED_MEDIA_TYPE.Code,
+				Message:   messages.ERR_UNSUPPORTED_MEDIA_TYPE.Text,
+				Exception: "image could not be decoded: " + err.Error(),
+			}
+		}
+		media.ThumbnailKey = prefix + "thumbnail.jpg"
+	}
+
+	// Blobs go first so a committed row always points at stored content; if
+	// the row can't be written the blobs are cleaned up again.
+	if err := s.blobs.Put(ctx, media.StorageKey, data, contentType); err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+	if thumb != nil {
+		if err := s.blobs.Put(ctx, media.ThumbnailKey, thumb, "image/jpeg"); err != nil {
+			s.removeBlobs(ctx, media)
+			return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+				MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+				Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+				Exception: err.Error(),
+			}
+		}
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&media).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditMediaUpload, "vehicle_media", media.ID.String(), nil, media)
+	})
+	if err != nil {
+		s.removeBlobs(ctx, media)
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := toVehicleMediaResponse(media)
+	return fiber.StatusCreated, &response, nil
+}
+
+// ListMedia returns a vehicle's photos. Documents and damage photos are
+// internal and only included when includeInternal is set.
+func (s *MediaServiceImpl) ListMedia(ctx context.Context, vehicleID string, includeInternal bool) (int, *[]models.VehicleMediaResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	statusCode, vehicle, errResp := findVehicle(ctx, s.db, vehicleID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	query := db.Where("vehicle_id = ?", vehicle.ID)
+	if !includeInternal {
+		query = query.Where("kind = ?", models.MediaKindPhoto)
+	}
+
+	var media []models.VehicleMedia
+	if err := query.Order("created_at").Find(&media).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := []models.VehicleMediaResponse{}
+	for _, m := range media {
+		response = append(response, toVehicleMediaResponse(m))
+	}
+
+	return fiber.StatusOK, &response, nil
+}
+
+func (s *MediaServiceImpl) OpenMedia(ctx context.Context, vehicleID, mediaID string, thumbnail, includeInternal bool) (int, *MediaContent, *models.ErrorResponse) {
+	statusCode, media, errResp := s.findMedia(ctx, vehicleID, mediaID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+	if media.Kind != models.MediaKindPhoto && !includeInternal {
+		return fiber.StatusNotFound, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_MEDIA_NOT_FOUND.Code,
+			Message:   messages.ERR_MEDIA_NOT_FOUND.Text,
+			Exception: "media not found",
+		}
+	}
+
+	key, contentType, fileName := media.StorageKey, media.ContentType, media.FileName
+	if thumbnail {
+		if media.ThumbnailKey == "" {
+			return fiber.StatusNotFound, nil, &models.ErrorResponse{
+				MessageID: messages.ERR_MEDIA_NOT_FOUND.Code,
+				Message:   messages.ERR_MEDIA_NOT_FOUND.Text,
+				Exception: "media has no thumbnail",
+			}
+		}
+		key, contentType = media.ThumbnailKey, "image/jpeg"
+		fileName = strings.TrimSuffix(fileName, filepath.Ext(fileName)) + "-thumbnail.jpg"
+	}
+
+	body, err := s.blobs.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return fiber.StatusNotFound, nil, &models.ErrorResponse{
+				MessageID: messages.ERR_MEDIA_NOT_FOUND.Code,
+				Message:   messages.ERR_MEDIA_NOT_FOUND.Text,
+				Exception: "stored file is missing",
+			}
+		}
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	return fiber.StatusOK, &MediaContent{Body: body, ContentType: contentType, FileName: fileName}, nil
+}
+
+func (s *MediaServiceImpl) DeleteMedia(ctx context.Context, vehicleID, mediaID string) (int, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	statusCode, media, errResp := s.findMedia(ctx, vehicleID, mediaID)
+	if errResp != nil {
+		return statusCode, errResp
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(media).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditMediaDelete, "vehicle_media", media.ID.String(), *media, nil)
+	})
+	if err != nil {
+		return fiber.StatusInternalServerError, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	s.removeBlobs(ctx, *media)
+	return fiber.StatusNoContent, nil
+}
+
+func (s *MediaServiceImpl) findMedia(ctx context.Context, vehicleID, mediaID string) (int, *models.VehicleMedia, *models.ErrorResponse) {
+	notFound := &models.ErrorResponse{
+		MessageID: messages.ERR_MEDIA_NOT_FOUND.Code,
+		Message:   messages.ERR_MEDIA_NOT_FOUND.Text,
+		Exception: "media not found",
+	}
+	if _, err := uuid.Parse(vehicleID); err != nil {
+		return fiber.StatusNotFound, nil, notFound
+	}
+	if _, err := uuid.Parse(mediaID); err != nil {
+		return fiber.StatusNotFound, nil, notFound
+	}
+
+	var media models.VehicleMedia
+	if err := s.db.WithContext(ctx).Where("id = ? AND vehicle_id = ?", mediaID, vehicleID).First(&media).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.StatusNotFound, nil, notFound
+		}
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	return fiber.StatusOK, &media, nil
+}
+
+// removeBlobs is best effort: an orphaned blob wastes space but is harmless,
+// so failures are logged rather than surfaced.
+func (s *MediaServiceImpl) removeBlobs(ctx context.Context, media models.VehicleMedia) {
+	for _, key := range []string{media.StorageKey, media.ThumbnailKey} {
+		if key == "" {
+			continue
+		}
+		if err := s.blobs.Delete(ctx, key); err != nil {
+			logger.Error(fmt.Sprintf("[%s] %s: removing blob %s: %s", messages.ERR_UNEXPECTED_ERROR.Code,
+				messages.ERR_UNEXPECTED_ERROR.Text, key, err.Error()))
+		}
+	}
+}
+
+func mediaSizeLimit(kind string) int {
+	if kind == models.MediaKindDocument {
+		return MaxDocumentBytes
+	}
+	return MaxPhotoBytes
+}
+
+// makeThumbnail decodes an image and returns a JPEG scaled to fit within
+// thumbnailMaxSide, along with the original dimensions. The header is checked
+// first so oversized images are rejected before they are decoded.
+func makeThumbnail(data []byte) ([]byte, int, int, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if cfg.Width*cfg.Height > maxImagePixels {
+		return nil, 0, 0, fmt.Errorf("image exceeds %d pixels", maxImagePixels)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	tw, th := w, h
+	if w > thumbnailMaxSide || h > thumbnailMaxSide {
+		if w >= h {
+			tw, th = thumbnailMaxSide, max(1, h*thumbnailMaxSide/w)
+		} else {
+			tw, th = max(1, w*thumbnailMaxSide/h), thumbnailMaxSide
+		}
+	}
+
+	// Box filter: each output pixel averages the source pixels it covers.
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		y0, y1 := bounds.Min.Y+y*h/th, bounds.Min.Y+max((y+1)*h/th, y*h/th+1)
+		for x := 0; x < tw; x++ {
+			x0, x1 := bounds.Min.X+x*w/tw, bounds.Min.X+max((x+1)*w/tw, x*w/tw+1)
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r, g, b, a, n = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa), n+1
+				}
+			}
+			i := dst.PixOffset(x, y)
+			dst.Pix[i+0] = uint8(r / n >> 8)
+			dst.Pix[i+1] = uint8(g / n >> 8)
+			dst.Pix[i+2] = uint8(b / n >> 8)
+			dst.Pix[i+3] = uint8(a / n >> 8)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 80}); err != nil {
+		return nil, 0, 0, err
+	}
+	return buf.Bytes(), w, h, nil
+}
+
+// sanitizeFileName keeps the client's base name for display and downloads,
+// stripped of path components and given an extension matching the sniffed
+// content type.
+func sanitizeFileName(name, contentType string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == '"' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, name)
+	ext := mediaExtensions[contentType]
+	if name == "" || name == "." || name == "/" {
+		name = "file"
+	}
+	if !strings.EqualFold(filepath.Ext(name), ext) {
+		name = strings.TrimSuffix(name, filepath.Ext(name)) + ext
+	}
+	if len(name) > 200 {
+		name = name[len(name)-200:]
+	}
+	return name
+}
+
+func toVehicleMediaResponse(m models.VehicleMedia) models.VehicleMediaResponse {
+	resp := models.VehicleMediaResponse{
+		ID:          m.ID,
+		VehicleID:   m.VehicleID,
+		Kind:        m.Kind,
+		FileName:    m.FileName,
+		ContentType: m.ContentType,
+		SizeBytes:   m.SizeBytes,
+		Width:       m.Width,
+		Height:      m.Height,
+		URL:         fmt.Sprintf("/v1/vehicles/%s/media/%s", m.VehicleID, m.ID),
+		CreatedAt:   m.CreatedAt.Format(time.RFC3339),
+	}
+	if m.ThumbnailKey != "" {
+		resp.ThumbnailURL = resp.URL + "?variant=thumbnail"
+	}
+	return resp
+}
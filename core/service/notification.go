@@ -0,0 +1,537 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"vehix/core/events"
+	"vehix/core/jobs"
+	"vehix/core/messages"
+	"vehix/core/notify"
+	"vehix/core/payments"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationSendJob is the job kind that sends one queued notification.
+const NotificationSendJob = "notifications.send"
+
+// pickupReminderLead is how long before pickup the reminder goes out.
+// Rentals booked closer to pickup than that have just had their confirmation
+// and get no reminder.
+var pickupReminderLead = time.Duration(envInt64("NOTIFY_PICKUP_REMINDER_HOURS", 24)) * time.Hour
+
+var (
+	notificationMaxAttempts = int(envInt64("NOTIFY_MAX_ATTEMPTS", 5))
+	notificationPhone       = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+	errNotificationFailed   = errors.New("notification failed")
+	errNotificationRejected = errors.New("notification preferences rejected")
+)
+
+const (
+	notificationDefaultPageSize = 50
+	notificationMaxPageSize     = 500
+)
+
+// NotificationService tells customers about their bookings by email and SMS,
+// and staff about overdue rentals and the bookings they block. It is an
+// events.Publisher: confirmations, overdue warnings, receipts and staff
+// alerts are queued when the outbox relay hands it the rental's event.
+// Pickup reminders come from a scan. Every notification is rendered and saved
+// when queued and sent by a job, so the log shows exactly what went out.
+type NotificationService interface {
+	GetNotificationPreferences(ctx context.Context, userID string) (int, *models.NotificationPreferencesResponse, *models.ErrorResponse)
+	UpdateNotificationPreferences(ctx context.Context, userID string, payload models.UpdateNotificationPreferencesPayload) (int, *models.NotificationPreferencesResponse, *models.ErrorResponse)
+	ListNotifications(ctx context.Context, userID string, filter models.NotificationFilter) (int, *[]models.NotificationResponse, *models.ErrorResponse)
+	QueuePickupReminders(ctx context.Context) (int64, error)
+	SendNotification(ctx context.Context, job *models.Job) error
+	events.Publisher
+}
+
+type NotificationServiceImpl struct {
+	db        *gorm.DB
+	notifiers notify.Notifiers
+}
+
+func NewNotificationService(db *gorm.DB, notifiers notify.Notifiers) NotificationService {
+	return &NotificationServiceImpl{db: db, notifiers: notifiers}
+}
+
+func (s *NotificationServiceImpl) GetNotificationPreferences(ctx context.Context, userID string) (int, *models.NotificationPreferencesResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	prefs, err := loadNotificationPreference(db, userID)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	resp := toNotificationPreferencesResponse(prefs)
+	return fiber.StatusOK, &resp, nil
+}
+
+// UpdateNotificationPreferences changes the fields the payload sets. A phone
+// number is needed before any kind can be sent by SMS.
+func (s *NotificationServiceImpl) UpdateNotificationPreferences(ctx context.Context, userID string, payload models.UpdateNotificationPreferencesPayload) (int, *models.NotificationPreferencesResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	var prefs models.NotificationPreference
+	var exception string
+	reject := func(format string, args ...any) error {
+		exception = fmt.Sprintf(format, args...)
+		return errNotificationRejected
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		current, err := loadNotificationPreference(tx.Clauses(clause.Locking{Strength: "UPDATE"}), userID)
+		if err != nil {
+			return err
+		}
+		before := current
+		prefs = current
+
+		if payload.Locale != nil {
+			// Only the language is kept: "de-AT" is stored as "de".
+			locale, _, _ := strings.Cut(strings.ToLower(strings.ReplaceAll(strings.TrimSpace(*payload.Locale), "_", "-")), "-")
+			if !slices.Contains(notify.Locales(), locale) {
+				return reject("locale must be one of %s", strings.Join(notificationLocales(), ", "))
+			}
+			prefs.Locale = locale
+		}
+		if payload.Phone != nil {
+			phone := strings.ReplaceAll(strings.TrimSpace(*payload.Phone), " ", "")
+			if phone != "" && !notificationPhone.MatchString(phone) {
+				return reject("phone must be in international format, e.g. +4915112345678")
+			}
+			prefs.Phone = phone
+		}
+		for _, set := range []struct {
+			kinds  *[]string
+			target *models.StringList
+		}{{payload.Email, &prefs.EmailKinds}, {payload.SMS, &prefs.SMSKinds}} {
+			if set.kinds == nil {
+				continue
+			}
+			kinds := models.StringList{}
+			for _, k := range *set.kinds {
+				if !slices.Contains(models.NotificationKinds, k) {
+					return reject("notification kind %q must be one of %s", k, strings.Join(models.NotificationKinds, ", "))
+				}
+				if !kinds.Contains(k) {
+					kinds = append(kinds, k)
+				}
+			}
+			*set.target = kinds
+		}
+		if len(prefs.SMSKinds) > 0 && prefs.Phone == "" {
+			return reject("a phone number is required for SMS notifications")
+		}
+
+		if err := tx.Save(&prefs).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditNotificationPrefs, "notification_preference", userID, before, prefs)
+	})
+	if errors.Is(err, errNotificationRejected) {
+		return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_INVALID_NOTIFICATION_PREFERENCES.Code,
+			Message:   messages.ERR_INVALID_NOTIFICATION_PREFERENCES.Text,
+			Exception: exception,
+		}
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	resp := toNotificationPreferencesResponse(prefs)
+	return fiber.StatusOK, &resp, nil
+}
+
+// ListNotifications returns what has been sent to the user, newest first.
+func (s *NotificationServiceImpl) ListNotifications(ctx context.Context, userID string, filter models.NotificationFilter) (int, *[]models.NotificationResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = notificationDefaultPageSize
+	}
+	limit = min(limit, notificationMaxPageSize)
+
+	var notifications []models.Notification
+	if err := db.Where("user_id = ?", userID).Order("created_at DESC").
+		Limit(limit).Offset(max(filter.Offset, 0)).Find(&notifications).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := []models.NotificationResponse{}
+	for _, n := range notifications {
+		response = append(response, toNotificationResponse(n))
+	}
+	return fiber.StatusOK, &response, nil
+}
+
+// QueuePickupReminders queues a reminder for each confirmed rental starting
+// within pickupReminderLead. It runs more often than the lead is long, so a
+// rental is seen several times; the dedup key keeps it to one reminder.
+func (s *NotificationServiceImpl) QueuePickupReminders(ctx context.Context) (int64, error) {
+	db := s.db.WithContext(ctx)
+
+	now := time.Now()
+	var ids []uuid.UUID
+	if err := db.Model(&models.Rental{}).
+		Where("status = ? AND start_date > ? AND start_date <= ?", models.RentalStatusConfirmed, now, now.Add(pickupReminderLead)).
+		Where("created_at <= start_date - ? * INTERVAL '1 second'", int64(pickupReminderLead.Seconds())).
+		Where("NOT EXISTS (SELECT 1 FROM notifications WHERE notifications.rental_id = rentals.id AND notifications.kind = ?)",
+			models.NotificationPickupReminder).
+		Order("start_date").Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+
+	var queued int64
+	for _, id := range ids {
+		n, err := s.notifyRental(ctx, models.NotificationPickupReminder, id.String())
+		if err != nil {
+			return queued, err
+		}
+		queued += n
+	}
+	return queued, nil
+}
+
+// Publish queues the notifications a rental event calls for, if any. Staff
+// hear about overdue rentals and the bookings they block.
+func (s *NotificationServiceImpl) Publish(ctx context.Context, msg events.Message) error {
+	var err error
+	switch msg.Type {
+	case events.RentalCreated:
+		_, err = s.notifyRental(ctx, models.NotificationBookingConfirmation, msg.AggregateID)
+	case events.RentalOverdue:
+		if _, err = s.notifyRental(ctx, models.NotificationOverdueWarning, msg.AggregateID); err == nil {
+			_, err = s.notifyStaff(ctx, models.NotificationStaffOverdue, msg.AggregateID)
+		}
+	case events.RentalBlocked:
+		_, err = s.notifyStaff(ctx, models.NotificationStaffBlocked, msg.AggregateID)
+	case events.RentalReturned:
+		_, err = s.notifyRental(ctx, models.NotificationReceipt, msg.AggregateID)
+	}
+	return err
+}
+
+func (s *NotificationServiceImpl) Close() error {
+	return nil
+}
+
+// SendNotification makes one attempt at sending a queued notification.
+// Returning an error has the job queue retry later with backoff.
+func (s *NotificationServiceImpl) SendNotification(ctx context.Context, job *models.Job) error {
+	db := s.db.WithContext(ctx)
+
+	notificationID, _ := job.Payload["notification_id"].(string)
+	var n models.Notification
+	if err := db.Where("id = ?", notificationID).Limit(1).Find(&n).Error; err != nil {
+		return err
+	}
+	if n.ID == uuid.Nil {
+		return fmt.Errorf("%w: notification %q not found", jobs.ErrPermanent, notificationID)
+	}
+	if n.Status == models.NotificationSent {
+		return nil
+	}
+
+	notifier := s.notifiers.Email
+	if n.Channel == models.NotificationChannelSMS {
+		notifier = s.notifiers.SMS
+	}
+	sendErr := notifier.Send(ctx, notify.Message{To: n.Recipient, Subject: n.Subject, Body: n.Body})
+
+	updates := map[string]any{"attempts": n.Attempts + 1, "last_error": ""}
+	switch {
+	case sendErr == nil:
+		updates["status"] = models.NotificationSent
+		updates["sent_at"] = time.Now()
+	case job.Attempts >= job.MaxAttempts:
+		updates["status"] = models.NotificationFailed
+		updates["last_error"] = sendErr.Error()
+	default:
+		updates["last_error"] = sendErr.Error()
+	}
+	if err := db.Model(&n).Updates(updates).Error; err != nil {
+		return err
+	}
+
+	if sendErr != nil {
+		return fmt.Errorf("%w: %s %s: %s", errNotificationFailed, n.Channel, n.ID, sendErr.Error())
+	}
+	return nil
+}
+
+// notifyRental queues kind for the rental's customer. Rentals of deleted
+// users are skipped.
+func (s *NotificationServiceImpl) notifyRental(ctx context.Context, kind, rentalID string) (int64, error) {
+	db := s.db.WithContext(ctx)
+
+	var rental models.Rental
+	if _, err := uuid.Parse(rentalID); err == nil {
+		if err := db.Where("id = ?", rentalID).Limit(1).Find(&rental).Error; err != nil {
+			return 0, err
+		}
+	}
+	if rental.ID == uuid.Nil {
+		return 0, nil
+	}
+	var user models.User
+	if err := db.Where("id = ?", rental.UserID).Limit(1).Find(&user).Error; err != nil {
+		return 0, err
+	}
+	if user.ID == uuid.Nil {
+		return 0, nil
+	}
+	prefs, err := loadNotificationPreference(db, user.ID.String())
+	if err != nil {
+		return 0, err
+	}
+	data, err := rentalNotificationData(db, &rental, &user)
+	if err != nil {
+		return 0, err
+	}
+	return queueUserNotifications(db, kind, &user, prefs, rental.ID, data, kind+":"+rental.ID.String())
+}
+
+// notifyStaff renders kind for every active admin, about the rental and its
+// customer, and queues it on the channels each admin wants it on.
+func (s *NotificationServiceImpl) notifyStaff(ctx context.Context, kind, rentalID string) (int64, error) {
+	db := s.db.WithContext(ctx)
+
+	var rental models.Rental
+	if _, err := uuid.Parse(rentalID); err == nil {
+		if err := db.Where("id = ?", rentalID).Limit(1).Find(&rental).Error; err != nil {
+			return 0, err
+		}
+	}
+	if rental.ID == uuid.Nil {
+		return 0, nil
+	}
+	// Staff still need to know about rentals of customers deleted since.
+	var customer models.User
+	if err := db.Unscoped().Where("id = ?", rental.UserID).Limit(1).Find(&customer).Error; err != nil {
+		return 0, err
+	}
+	data, err := rentalNotificationData(db, &rental, &customer)
+	if err != nil {
+		return 0, err
+	}
+	data.Customer = customer.Name
+	if customer.Email != "" {
+		data.Customer += " <" + customer.Email + ">"
+	}
+	// A booking blocked again by a later overdue rental is news again.
+	dedupKey := kind + ":" + rental.ID.String()
+	if rental.BlockedByRentalID != nil {
+		data.WaitingOn = strings.ToUpper(rental.BlockedByRentalID.String()[:8])
+		dedupKey += ":" + rental.BlockedByRentalID.String()
+	}
+
+	var admins []models.User
+	if err := db.Where("role = ? AND status = ?", models.RoleAdmin, models.UserStatusActive).
+		Order("created_at").Find(&admins).Error; err != nil {
+		return 0, err
+	}
+	var queued int64
+	for _, admin := range admins {
+		prefs, err := loadNotificationPreference(db, admin.ID.String())
+		if err != nil {
+			return queued, err
+		}
+		data.Name = admin.Name
+		n, err := queueUserNotifications(db, kind, &admin, prefs, rental.ID, data, dedupKey+":"+admin.ID.String())
+		queued += n
+		if err != nil {
+			return queued, err
+		}
+	}
+	return queued, nil
+}
+
+// queueUserNotifications renders kind for user on each channel they want it
+// on and queues it, each under dedupKey suffixed with the channel.
+func queueUserNotifications(db *gorm.DB, kind string, user *models.User, prefs models.NotificationPreference,
+	rentalID uuid.UUID, data notify.RentalData, dedupKey string) (int64, error) {
+	var queued int64
+	for _, channel := range []struct {
+		name, recipient string
+		kinds           models.StringList
+	}{
+		{models.NotificationChannelEmail, user.Email, prefs.EmailKinds},
+		{models.NotificationChannelSMS, prefs.Phone, prefs.SMSKinds},
+	} {
+		if channel.recipient == "" || !channel.kinds.Contains(kind) {
+			continue
+		}
+		subject, body, err := notify.Render(kind, channel.name, prefs.Locale, data)
+		if err != nil {
+			return queued, err
+		}
+		n := models.Notification{
+			UserID:    user.ID,
+			RentalID:  &rentalID,
+			Kind:      kind,
+			Channel:   channel.name,
+			Recipient: channel.recipient,
+			Subject:   subject,
+			Body:      body,
+			DedupKey:  dedupKey + ":" + channel.name,
+			Status:    models.NotificationQueued,
+		}
+		var created bool
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			created, err = queueNotification(tx, &n)
+			return err
+		}); err != nil {
+			return queued, err
+		}
+		if created {
+			queued++
+		}
+	}
+	return queued, nil
+}
+
+// queueNotification saves a notification and the job that sends it, unless
+// one with the same dedup key was queued before.
+func queueNotification(tx *gorm.DB, n *models.Notification) (bool, error) {
+	result := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "dedup_key"}},
+		DoNothing: true,
+	}).Create(n)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	_, err := jobs.Enqueue(tx, NotificationSendJob, models.JSONMap{"notification_id": n.ID.String()},
+		jobs.Options{MaxAttempts: notificationMaxAttempts})
+	return err == nil, err
+}
+
+// rentalNotificationData gathers what the templates show about a rental.
+// Times are given in the pickup branch's timezone.
+func rentalNotificationData(db *gorm.DB, rental *models.Rental, user *models.User) (notify.RentalData, error) {
+	subtotal := rentalSubtotalCents(rental)
+	data := notify.RentalData{
+		Name:            user.Name,
+		Reference:       strings.ToUpper(rental.ID.String()[:8]),
+		Start:           rental.StartDate,
+		End:             rental.EndDate,
+		Currency:        payments.Currency,
+		TotalCents:      subtotal + percentOf(subtotal, rental.TaxRateBps),
+		ChargedCents:    rental.CapturedCents - rental.RefundedCents,
+		LateHourlyCents: lateReturnHourlyCents,
+	}
+	if rental.ReturnedAt != nil {
+		data.ReturnedAt = *rental.ReturnedAt
+	}
+
+	var vehicle models.Vehicle
+	if err := db.Unscoped().Where("id = ?", rental.VehicleID).Limit(1).Find(&vehicle).Error; err != nil {
+		return data, err
+	}
+	data.Vehicle = strings.TrimSpace(vehicle.Make + " " + vehicle.Model)
+
+	if err := db.Model(&models.RentalCharge{}).Where("rental_id = ? AND kind = ?", rental.ID, models.ChargeKindLate).
+		Select("COALESCE(SUM(amount_cents), 0)").Scan(&data.LateFeeCents).Error; err != nil {
+		return data, err
+	}
+
+	loc := time.UTC
+	if rental.PickupBranchID != nil {
+		branch, err := loadBranch(db, *rental.PickupBranchID)
+		if err != nil {
+			return data, err
+		}
+		data.Pickup = branchAddress(branch)
+		if l, err := time.LoadLocation(branch.Timezone); err == nil {
+			loc = l
+		}
+	}
+	if rental.DropoffBranchID != nil {
+		branch, err := loadBranch(db, *rental.DropoffBranchID)
+		if err != nil {
+			return data, err
+		}
+		data.Dropoff = branchAddress(branch)
+	}
+	data.Start, data.End, data.ReturnedAt = data.Start.In(loc), data.End.In(loc), data.ReturnedAt.In(loc)
+	return data, nil
+}
+
+// loadNotificationPreference returns the user's preferences, or the defaults
+// (every kind by email, in English) when they have never set any.
+func loadNotificationPreference(db *gorm.DB, userID string) (models.NotificationPreference, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return models.NotificationPreference{}, err
+	}
+	prefs := models.NotificationPreference{
+		UserID:     id,
+		Locale:     notify.DefaultLocale,
+		EmailKinds: slices.Clone(models.NotificationKinds),
+		SMSKinds:   models.StringList{},
+	}
+	err = db.Where("user_id = ?", id).Limit(1).Find(&prefs).Error
+	return prefs, err
+}
+
+func branchAddress(b *models.Branch) string {
+	return fmt.Sprintf("%s, %s, %s", b.Name, b.AddressLine1, b.City)
+}
+
+func notificationLocales() []string {
+	locales := notify.Locales()
+	slices.Sort(locales)
+	return locales
+}
+
+func toNotificationPreferencesResponse(p models.NotificationPreference) models.NotificationPreferencesResponse {
+	return models.NotificationPreferencesResponse{
+		Locale: p.Locale,
+		Phone:  p.Phone,
+		Email:  p.EmailKinds,
+		SMS:    p.SMSKinds,
+	}
+}
+
+func toNotificationResponse(n models.Notification) models.NotificationResponse {
+	resp := models.NotificationResponse{
+		ID:        n.ID,
+		RentalID:  n.RentalID,
+		Kind:      n.Kind,
+		Channel:   n.Channel,
+		Recipient: n.Recipient,
+		Subject:   n.Subject,
+		Body:      n.Body,
+		Status:    n.Status,
+		Attempts:  n.Attempts,
+		LastError: n.LastError,
+		CreatedAt: n.CreatedAt.Format(time.RFC3339),
+	}
+	if n.SentAt != nil {
+		resp.SentAt = n.SentAt.Format(time.RFC3339)
+	}
+	return resp
+}
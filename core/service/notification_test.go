@@ -0,0 +1,123 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"vehix/core/database/dbtest"
+	"vehix/core/notify"
+	"vehix/models"
+
+	"gorm.io/gorm"
+)
+
+// sendQueuedNotifications runs every notification job still queued, as the
+// worker would.
+func sendQueuedNotifications(t *testing.T, db *gorm.DB, svc NotificationService) {
+	t.Helper()
+	var queued []models.Job
+	if err := db.Where("kind = ? AND status = ?", NotificationSendJob, models.JobStatusQueued).Find(&queued).Error; err != nil {
+		t.Fatalf("loading jobs: %v", err)
+	}
+	for _, job := range queued {
+		job.Attempts++
+		if err := svc.SendNotification(context.Background(), &job); err != nil {
+			t.Fatalf("SendNotification: %v", err)
+		}
+		if err := db.Model(&job).Updates(map[string]any{"status": models.JobStatusSucceeded, "attempts": job.Attempts}).Error; err != nil {
+			t.Fatalf("finishing job: %v", err)
+		}
+	}
+}
+
+func TestPickupReminderSweepSendsOncePerRentalAndChannel(t *testing.T) {
+	db := dbtest.Open(t)
+	sink := filepath.Join(t.TempDir(), "notifications.jsonl")
+	file, err := notify.NewFileNotifier(sink)
+	if err != nil {
+		t.Fatalf("NewFileNotifier: %v", err)
+	}
+	svc := NewNotificationService(db, notify.Notifiers{Email: file, SMS: file})
+
+	user := createTestUser(t, db, models.RoleUser)
+	prefs := models.NotificationPreference{
+		UserID:     user.ID,
+		Locale:     "de",
+		Phone:      "+4915112345678",
+		EmailKinds: models.StringList{models.NotificationPickupReminder},
+		SMSKinds:   models.StringList{models.NotificationPickupReminder},
+	}
+	if err := db.Create(&prefs).Error; err != nil {
+		t.Fatalf("saving preferences: %v", err)
+	}
+
+	now := time.Now()
+	weekAgo := now.Add(-7 * 24 * time.Hour)
+	due := []models.Rental{
+		createTestRental(t, db, user, now.Add(2*time.Hour), weekAgo),
+		createTestRental(t, db, user, now.Add(pickupReminderLead-time.Hour), weekAgo),
+	}
+	// Too far off yet, and booked too close to pickup to need a reminder.
+	createTestRental(t, db, user, now.Add(pickupReminderLead+time.Hour), weekAgo)
+	createTestRental(t, db, user, now.Add(3*time.Hour), now.Add(-time.Hour))
+
+	for run := 1; run <= 2; run++ {
+		queued, err := svc.QueuePickupReminders(context.Background())
+		if err != nil {
+			t.Fatalf("run %d: QueuePickupReminders: %v", run, err)
+		}
+		if want := map[int]int64{1: 4, 2: 0}[run]; queued != want {
+			t.Errorf("run %d queued %d reminders, want %d", run, queued, want)
+		}
+		sendQueuedNotifications(t, db, svc)
+	}
+
+	f, err := os.Open(sink)
+	if err != nil {
+		t.Fatalf("opening sink: %v", err)
+	}
+	defer f.Close()
+	sent, lines := map[string]int{}, 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines++
+		var entry struct{ To, Subject, Body string }
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("sink entry %s: %v", scanner.Text(), err)
+		}
+		if !strings.Contains(entry.Body, "Buchung") {
+			t.Errorf("reminder to %s not in the customer's German: %q", entry.To, entry.Body)
+		}
+		for _, r := range due {
+			if strings.Contains(entry.Body, strings.ToUpper(r.ID.String()[:8])) {
+				sent[r.ID.String()+" "+entry.To]++
+			}
+		}
+	}
+
+	if len(sent) != 4 || lines != 4 {
+		t.Errorf("sink has %d entries, reminders for %v; want one for each due rental on each channel", lines, sent)
+	}
+	for _, r := range due {
+		for _, to := range []string{user.Email, prefs.Phone} {
+			if n := sent[r.ID.String()+" "+to]; n != 1 {
+				t.Errorf("rental %s: %d reminders to %s, want 1", r.ID, n, to)
+			}
+		}
+	}
+
+	var notifications []models.Notification
+	if err := db.Where("user_id = ?", user.ID).Find(&notifications).Error; err != nil {
+		t.Fatalf("loading notifications: %v", err)
+	}
+	for _, n := range notifications {
+		if n.Status != models.NotificationSent || n.Kind != models.NotificationPickupReminder || n.RentalID == nil {
+			t.Errorf("notification %+v, want a sent pickup reminder", n)
+		}
+	}
+}
@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	"vehix/core/payments"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// rentalDepositCents is held on the customer's card on top of the rental
+// price and released when the rental is captured.
+var rentalDepositCents = envInt64("RENTAL_DEPOSIT_CENTS", 30_000)
+
+var errPaymentRejected = errors.New("payment rejected")
+
+type PaymentService interface {
+	HandleWebhook(ctx context.Context, body []byte, signature string) (int, *models.ErrorResponse)
+}
+
+type PaymentServiceImpl struct {
+	db      *gorm.DB
+	gateway payments.PaymentGateway
+}
+
+func NewPaymentService(db *gorm.DB, gateway payments.PaymentGateway) PaymentService {
+	return &PaymentServiceImpl{db: db, gateway: gateway}
+}
+
+// HandleWebhook applies an asynchronous gateway event to the rental it
+// belongs to. Events are recorded by gateway ID so redeliveries are no-ops.
+// An event for an authorization we don't know yet is answered with 404; the
+// gateway retries it, by which time the booking transaction has committed.
+// Events for a hold that a modification replaced are recorded and ignored.
+func (s *PaymentServiceImpl) HandleWebhook(ctx context.Context, body []byte, signature string) (int, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	event, err := s.gateway.ParseWebhook(body, signature)
+	if err != nil {
+		return fiber.StatusBadRequest, &models.ErrorResponse{
+			MessageID: messages.ERR_INVALID_WEBHOOK.Code,
+			Message:   messages.ERR_INVALID_WEBHOOK.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	var errResp *models.ErrorResponse
+	statusCode := fiber.StatusOK
+	err = db.Transaction(func(tx *gorm.DB) error {
+		record := models.PaymentEvent{
+			GatewayEventID:  event.ID,
+			Type:            event.Type,
+			AuthorizationID: event.AuthorizationID,
+			AmountCents:     event.AmountCents,
+		}
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+
+		var rental models.Rental
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("payment_authorization_id = ?", event.AuthorizationID).First(&rental).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				// Holds replaced by a modification are voided and of no
+				// further interest; record the event against the rental.
+				var replaced models.RentalModification
+				if err := tx.Where("previous_authorization_id = ?", event.AuthorizationID).Limit(1).Find(&replaced).Error; err != nil {
+					return err
+				}
+				if replaced.RentalID != uuid.Nil {
+					return tx.Model(&record).Update("rental_id", replaced.RentalID).Error
+				}
+				statusCode, errResp = fiber.StatusNotFound, &models.ErrorResponse{
+					MessageID: messages.ERR_PAYMENT_NOT_FOUND.Code,
+					Message:   messages.ERR_PAYMENT_NOT_FOUND.Text,
+					Exception: "unknown authorization " + event.AuthorizationID,
+				}
+				return errPaymentRejected
+			}
+			return err
+		}
+		if err := tx.Model(&record).Update("rental_id", rental.ID).Error; err != nil {
+			return err
+		}
+
+		before := rental
+		applyPaymentEvent(&rental, event)
+		if rental.Status == before.Status && rental.PaymentStatus == before.PaymentStatus &&
+			rental.AuthorizedCents == before.AuthorizedCents && rental.CapturedCents == before.CapturedCents &&
+			rental.RefundedCents == before.RefundedCents {
+			return nil
+		}
+		if err := savePaymentState(tx, &rental); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditRentalPayment, "rental", rental.ID.String(), before, rental)
+	})
+	if errResp != nil {
+		return statusCode, errResp
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	return fiber.StatusOK, nil
+}
+
+// applyPaymentEvent moves the rental's payment state forward. Event amounts
+// are running totals, so this never double counts a replayed event or one
+// for an operation whose synchronous result was already stored.
+func applyPaymentEvent(r *models.Rental, event *payments.Event) {
+	switch event.Type {
+	case payments.EventAuthorized:
+		if r.PaymentStatus == models.PaymentStatusPending {
+			r.PaymentStatus = models.PaymentStatusAuthorized
+			r.AuthorizedCents = event.AmountCents
+		}
+	case payments.EventAuthorizationFailed:
+		if r.PaymentStatus == models.PaymentStatusPending {
+			r.PaymentStatus = models.PaymentStatusFailed
+			// Without a hold the booking can't stand; free the vehicle.
+			if r.Status == models.RentalStatusConfirmed {
+				r.Status = models.RentalStatusCancelled
+			}
+		}
+	case payments.EventCaptured:
+		r.CapturedCents = max(r.CapturedCents, event.AmountCents)
+		if r.PaymentStatus != models.PaymentStatusRefunded {
+			r.PaymentStatus = models.PaymentStatusCaptured
+		}
+	case payments.EventRefunded:
+		r.RefundedCents = max(r.RefundedCents, event.AmountCents)
+		if r.RefundedCents >= r.CapturedCents {
+			r.PaymentStatus = models.PaymentStatusRefunded
+		}
+	case payments.EventVoided:
+		if r.CapturedCents == 0 {
+			r.PaymentStatus = models.PaymentStatusVoided
+		}
+	}
+}
+
+// authorizeRental places the booking hold: rental price, one-way fee, extras
+// and tax on them, plus the deposit. The idempotency key is derived from the
+// rental ID and operation, so a retried booking never places a second hold.
+func authorizeRental(ctx context.Context, gateway payments.PaymentGateway, rental *models.Rental, paymentToken, operation string) (int, *models.ErrorResponse) {
+	subtotal := rentalSubtotalCents(rental)
+	amount := subtotal + percentOf(subtotal, rental.TaxRateBps) + rental.DepositCents
+	if amount == 0 {
+		rental.PaymentStatus = models.PaymentStatusNone
+		return fiber.StatusOK, nil
+	}
+
+	res, err := gateway.Authorize(ctx, payments.AuthorizeRequest{
+		AmountCents:    amount,
+		Currency:       payments.Currency,
+		PaymentToken:   paymentToken,
+		Reference:      rental.ID.String(),
+		IdempotencyKey: paymentIdempotencyKey(rental, operation),
+	})
+	if err != nil {
+		return fiber.StatusBadGateway, &models.ErrorResponse{
+			MessageID: messages.ERR_PAYMENT_GATEWAY.Code,
+			Message:   messages.ERR_PAYMENT_GATEWAY.Text,
+			Exception: err.Error(),
+		}
+	}
+	if res.Status == payments.StatusDeclined {
+		return fiber.StatusPaymentRequired, &models.ErrorResponse{
+			MessageID: messages.ERR_PAYMENT_DECLINED.Code,
+			Message:   messages.ERR_PAYMENT_DECLINED.Text,
+			Exception: res.DeclineReason,
+		}
+	}
+
+	rental.PaymentAuthorizationID = res.AuthorizationID
+	rental.PaymentStatus = models.PaymentStatusAuthorized
+	rental.AuthorizedCents = res.AmountCents
+	if res.Status == payments.StatusPending {
+		rental.PaymentStatus = models.PaymentStatusPending
+		rental.AuthorizedCents = 0
+	}
+	return fiber.StatusOK, nil
+}
+
+// captureRental takes what the customer owes at return: the booked price and
+// any charges that don't need review, with tax. The rest of the hold,
+// deposit included, is released by the gateway. A declined capture doesn't
+// block the return, the vehicle is back either way; it is left as failed for
+// staff to chase.
+func captureRental(ctx context.Context, tx *gorm.DB, gateway payments.PaymentGateway, rental *models.Rental) (int, *models.ErrorResponse, error) {
+	if rental.PaymentStatus != models.PaymentStatusAuthorized {
+		return fiber.StatusOK, nil, nil
+	}
+
+	var charges int64
+	if err := tx.Model(&models.RentalCharge{}).
+		Where("rental_id = ? AND requires_review = false", rental.ID).
+		Select("COALESCE(SUM(amount_cents), 0)").Scan(&charges).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, err
+	}
+	subtotal := rentalSubtotalCents(rental) + charges
+	amount := min(subtotal+percentOf(subtotal, rental.TaxRateBps), rental.AuthorizedCents)
+
+	res, err := gateway.Capture(ctx, rental.PaymentAuthorizationID, amount, paymentIdempotencyKey(rental, "capture"))
+	if err != nil {
+		return fiber.StatusBadGateway, &models.ErrorResponse{
+			MessageID: messages.ERR_PAYMENT_GATEWAY.Code,
+			Message:   messages.ERR_PAYMENT_GATEWAY.Text,
+			Exception: err.Error(),
+		}, nil
+	}
+	if res.Status == payments.StatusDeclined {
+		logger.Warn(fmt.Sprintf("[%s] %s: rental %s: %s", messages.ERR_PAYMENT_DECLINED.Code,
+			messages.ERR_PAYMENT_DECLINED.Text, rental.ID, res.DeclineReason))
+		rental.PaymentStatus = models.PaymentStatusFailed
+		return fiber.StatusOK, nil, nil
+	}
+
+	rental.PaymentStatus = models.PaymentStatusCaptured
+	rental.CapturedCents = res.AmountCents
+	return fiber.StatusOK, nil, nil
+}
+
+// releaseRentalPayment gives the money back on cancellation: an open hold is
+// voided, anything already captured is refunded.
+func releaseRentalPayment(ctx context.Context, gateway payments.PaymentGateway, rental *models.Rental) (int, *models.ErrorResponse) {
+	var err error
+	switch rental.PaymentStatus {
+	case models.PaymentStatusAuthorized, models.PaymentStatusPending:
+		if _, err = gateway.Void(ctx, rental.PaymentAuthorizationID, paymentIdempotencyKey(rental, "void")); err == nil {
+			rental.PaymentStatus = models.PaymentStatusVoided
+		}
+	case models.PaymentStatusCaptured:
+		var res *payments.Result
+		amount := rental.CapturedCents - rental.RefundedCents
+		if res, err = gateway.Refund(ctx, rental.PaymentAuthorizationID, amount, paymentIdempotencyKey(rental, "refund")); err == nil {
+			rental.PaymentStatus = models.PaymentStatusRefunded
+			rental.RefundedCents += res.AmountCents
+		}
+	}
+	if err != nil {
+		return fiber.StatusBadGateway, &models.ErrorResponse{
+			MessageID: messages.ERR_PAYMENT_GATEWAY.Code,
+			Message:   messages.ERR_PAYMENT_GATEWAY.Text,
+			Exception: err.Error(),
+		}
+	}
+	return fiber.StatusOK, nil
+}
+
+// chargeCancellation settles the hold when a booking is called off. With no
+// fee due it is released as on any cancellation; otherwise the fee is
+// captured and the rest of the hold released by the gateway. A declined
+// capture doesn't stop the cancellation; it is left as failed for staff to
+// chase.
+func chargeCancellation(ctx context.Context, gateway payments.PaymentGateway, rental *models.Rental) (int, *models.ErrorResponse) {
+	if rental.CancellationFeeCents == 0 || rental.PaymentStatus != models.PaymentStatusAuthorized {
+		return releaseRentalPayment(ctx, gateway, rental)
+	}
+
+	amount := min(rental.CancellationFeeCents, rental.AuthorizedCents)
+	res, err := gateway.Capture(ctx, rental.PaymentAuthorizationID, amount, paymentIdempotencyKey(rental, "capture"))
+	if err != nil {
+		return fiber.StatusBadGateway, &models.ErrorResponse{
+			MessageID: messages.ERR_PAYMENT_GATEWAY.Code,
+			Message:   messages.ERR_PAYMENT_GATEWAY.Text,
+			Exception: err.Error(),
+		}
+	}
+	if res.Status == payments.StatusDeclined {
+		logger.Warn(fmt.Sprintf("[%s] %s: cancellation fee for rental %s: %s", messages.ERR_PAYMENT_DECLINED.Code,
+			messages.ERR_PAYMENT_DECLINED.Text, rental.ID, res.DeclineReason))
+		rental.PaymentStatus = models.PaymentStatusFailed
+		return fiber.StatusOK, nil
+	}
+
+	rental.PaymentStatus = models.PaymentStatusCaptured
+	rental.CapturedCents = res.AmountCents
+	return fiber.StatusOK, nil
+}
+
+func savePaymentState(tx *gorm.DB, rental *models.Rental) error {
+	return tx.Model(rental).Select("status", "payment_status", "authorized_cents", "captured_cents", "refunded_cents").
+		Updates(rental).Error
+}
+
+func paymentIdempotencyKey(rental *models.Rental, operation string) string {
+	return "rental:" + rental.ID.String() + ":" + operation
+}
@@ -0,0 +1,432 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"vehix/core/messages"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var promoDiscountTypes = []string{models.PromoDiscountPercent, models.PromoDiscountFixed}
+
+type PromoService interface {
+	ListPromoCodes(ctx context.Context) (int, *[]models.PromoCodeResponse, *models.ErrorResponse)
+	GetPromoCode(ctx context.Context, promoID string) (int, *models.PromoCodeResponse, *models.ErrorResponse)
+	CreatePromoCode(ctx context.Context, payload models.CreatePromoCodePayload) (int, *models.PromoCodeResponse, *models.ErrorResponse)
+	UpdatePromoCode(ctx context.Context, promoID string, req *models.UpdatePromoCodePayload) (int, *models.PromoCodeResponse, *models.ErrorResponse)
+}
+
+type PromoServiceImpl struct {
+	db *gorm.DB
+}
+
+func NewPromoService(db *gorm.DB) PromoService {
+	return &PromoServiceImpl{db: db}
+}
+
+func (s *PromoServiceImpl) ListPromoCodes(ctx context.Context) (int, *[]models.PromoCodeResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	var promos []models.PromoCode
+	if err := db.Order("created_at DESC").Find(&promos).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	var counts []struct {
+		PromoCodeID uuid.UUID
+		Count       int
+	}
+	if err := db.Model(&models.Rental{}).
+		Where("promo_code_id IS NOT NULL AND status <> ?", models.RentalStatusCancelled).
+		Where(holdLiveSQL, models.RentalStatusHeld, time.Now()).
+		Group("promo_code_id").Select("promo_code_id, COUNT(*) AS count").Scan(&counts).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+	redemptions := map[uuid.UUID]int{}
+	for _, c := range counts {
+		redemptions[c.PromoCodeID] = c.Count
+	}
+
+	response := []models.PromoCodeResponse{}
+	for _, p := range promos {
+		response = append(response, toPromoCodeResponse(p, redemptions[p.ID]))
+	}
+
+	return fiber.StatusOK, &response, nil
+}
+
+func (s *PromoServiceImpl) GetPromoCode(ctx context.Context, promoID string) (int, *models.PromoCodeResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	statusCode, promo, errResp := findPromoCode(ctx, db, promoID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	redemptions, err := promoRedemptions(db, promo.ID, uuid.Nil)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := toPromoCodeResponse(*promo, redemptions)
+	return fiber.StatusOK, &response, nil
+}
+
+func (s *PromoServiceImpl) CreatePromoCode(ctx context.Context, payload models.CreatePromoCodePayload) (int, *models.PromoCodeResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	promo := models.PromoCode{
+		Code:           normalizePromoCode(payload.Code),
+		Description:    payload.Description,
+		DiscountType:   strings.ToLower(payload.DiscountType),
+		DiscountBps:    payload.DiscountBps,
+		DiscountCents:  payload.DiscountCents,
+		ValidFrom:      payload.ValidFrom,
+		ValidUntil:     payload.ValidUntil,
+		MaxRedemptions: payload.MaxRedemptions,
+		MaxPerUser:     payload.MaxPerUser,
+		MinRentalDays:  payload.MinRentalDays,
+		Categories:     normalizeCategories(payload.Categories),
+		Active:         true,
+	}
+
+	if statusCode, errResp := validatePromoCode(&promo); errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	var existing int64
+	if err := db.Model(&models.PromoCode{}).Where("code = ?", promo.Code).Count(&existing).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+	if existing > 0 {
+		return fiber.StatusConflict, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_PROMO_CODE_EXISTS.Code,
+			Message:   messages.ERR_PROMO_CODE_EXISTS.Text,
+			Exception: "a promo code with this code already exists",
+		}
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&promo).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditPromoCreate, "promo_code", promo.ID.String(), nil, promo)
+	})
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := toPromoCodeResponse(promo, 0)
+	return fiber.StatusCreated, &response, nil
+}
+
+// UpdatePromoCode changes a campaign. Rentals already booked keep the
+// discount they were booked with; lowering a cap below the redemptions so far
+// only stops further use.
+func (s *PromoServiceImpl) UpdatePromoCode(ctx context.Context, promoID string, req *models.UpdatePromoCodePayload) (int, *models.PromoCodeResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	statusCode, promo, errResp := findPromoCode(ctx, db, promoID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	before := *promo
+
+	if req.Description != nil {
+		promo.Description = *req.Description
+	}
+	if req.DiscountType != nil {
+		promo.DiscountType = strings.ToLower(*req.DiscountType)
+	}
+	if req.DiscountBps != nil {
+		promo.DiscountBps = *req.DiscountBps
+	}
+	if req.DiscountCents != nil {
+		promo.DiscountCents = *req.DiscountCents
+	}
+	if req.ValidFrom != nil {
+		promo.ValidFrom = req.ValidFrom
+	}
+	if req.ValidUntil != nil {
+		promo.ValidUntil = req.ValidUntil
+	}
+	if req.MaxRedemptions != nil {
+		promo.MaxRedemptions = req.MaxRedemptions
+	}
+	if req.MaxPerUser != nil {
+		promo.MaxPerUser = req.MaxPerUser
+	}
+	if req.MinRentalDays != nil {
+		promo.MinRentalDays = *req.MinRentalDays
+	}
+	if req.Categories != nil {
+		promo.Categories = normalizeCategories(*req.Categories)
+	}
+	if req.Active != nil {
+		promo.Active = *req.Active
+	}
+
+	if statusCode, errResp := validatePromoCode(promo); errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	var redemptions int
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(promo).Error; err != nil {
+			return err
+		}
+		var err error
+		if redemptions, err = promoRedemptions(tx, promo.ID, uuid.Nil); err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditPromoUpdate, "promo_code", promoID, before, *promo)
+	})
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := toPromoCodeResponse(*promo, redemptions)
+	return fiber.StatusOK, &response, nil
+}
+
+// applyPromoCode checks a promo code against a priced rental and records the
+// discount on it. The code's row stays locked until the booking transaction
+// ends, so concurrent bookings are counted against the caps one at a time.
+func applyPromoCode(tx *gorm.DB, rental *models.Rental, vehicle *models.Vehicle, code string) (int, *models.ErrorResponse, error) {
+	rental.PromoCodeID, rental.PromoCode, rental.DiscountCents = nil, "", 0
+	code = normalizePromoCode(code)
+	if code == "" {
+		return fiber.StatusOK, nil, nil
+	}
+
+	rejected := func(statusCode int, reason string) (int, *models.ErrorResponse, error) {
+		return statusCode, &models.ErrorResponse{
+			MessageID: messages.ERR_PROMO_REJECTED.Code,
+			Message:   messages.ERR_PROMO_REJECTED.Text,
+			Exception: reason,
+		}, nil
+	}
+
+	var promo models.PromoCode
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("code = ?", code).First(&promo).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return rejected(fiber.StatusBadRequest, "promo code is not recognised")
+		}
+		return fiber.StatusInternalServerError, nil, err
+	}
+
+	// A booking being modified keeps a code it already redeemed even if the
+	// code has since been switched off, expired or used up.
+	var held int64
+	if err := tx.Model(&models.Rental{}).Where("id = ? AND promo_code_id = ?", rental.ID, promo.ID).Count(&held).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, err
+	}
+
+	now := time.Now()
+	switch {
+	case held > 0:
+	case !promo.Active:
+		return rejected(fiber.StatusBadRequest, "promo code is no longer active")
+	case promo.ValidFrom != nil && now.Before(*promo.ValidFrom):
+		return rejected(fiber.StatusBadRequest, "promo code is not valid until "+promo.ValidFrom.Format(time.RFC3339))
+	case promo.ValidUntil != nil && !now.Before(*promo.ValidUntil):
+		return rejected(fiber.StatusBadRequest, "promo code expired at "+promo.ValidUntil.Format(time.RFC3339))
+	}
+
+	switch {
+	case rentalDays(rental) < promo.MinRentalDays:
+		return rejected(fiber.StatusBadRequest, fmt.Sprintf("promo code requires a rental of at least %d days", promo.MinRentalDays))
+	case len(promo.Categories) > 0 && !slices.Contains(promo.Categories, vehicle.Category):
+		return rejected(fiber.StatusBadRequest, fmt.Sprintf("promo code does not apply to %s vehicles", vehicle.Category))
+	}
+
+	if promo.MaxRedemptions != nil && held == 0 {
+		used, err := promoRedemptions(tx, promo.ID, uuid.Nil)
+		if err != nil {
+			return fiber.StatusInternalServerError, nil, err
+		}
+		if used >= *promo.MaxRedemptions {
+			return rejected(fiber.StatusConflict, "promo code has been fully redeemed")
+		}
+	}
+	if promo.MaxPerUser != nil && held == 0 {
+		used, err := promoRedemptions(tx, promo.ID, rental.UserID)
+		if err != nil {
+			return fiber.StatusInternalServerError, nil, err
+		}
+		if used >= *promo.MaxPerUser {
+			return rejected(fiber.StatusConflict, fmt.Sprintf("promo code can be used %d times per customer", *promo.MaxPerUser))
+		}
+	}
+
+	// Discounts apply to the base rate and extras, never the one-way fee.
+	base := rental.RentalCents + rental.ExtrasCents
+	discount := promo.DiscountCents
+	if promo.DiscountType == models.PromoDiscountPercent {
+		discount = percentOf(base, promo.DiscountBps)
+	}
+
+	rental.PromoCodeID = &promo.ID
+	rental.PromoCode = promo.Code
+	rental.DiscountCents = min(discount, base)
+	return fiber.StatusOK, nil, nil
+}
+
+// promoRedemptions counts the non-cancelled rentals that used a promo code,
+// only those of userID when it is set.
+func promoRedemptions(tx *gorm.DB, promoID, userID uuid.UUID) (int, error) {
+	query := tx.Model(&models.Rental{}).Where("promo_code_id = ? AND status <> ?", promoID, models.RentalStatusCancelled).
+		Where(holdLiveSQL, models.RentalStatusHeld, time.Now())
+	if userID != uuid.Nil {
+		query = query.Where("user_id = ?", userID)
+	}
+	var count int64
+	err := query.Count(&count).Error
+	return int(count), err
+}
+
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func normalizeCategories(categories []string) models.StringList {
+	list := models.StringList{}
+	for _, c := range categories {
+		if c = strings.ToLower(strings.TrimSpace(c)); c != "" && !slices.Contains(list, c) {
+			list = append(list, c)
+		}
+	}
+	return list
+}
+
+func validatePromoCode(p *models.PromoCode) (int, *models.ErrorResponse) {
+	invalid := func(reason string) (int, *models.ErrorResponse) {
+		return fiber.StatusBadRequest, &models.ErrorResponse{
+			MessageID: messages.ERR_INVALID_PROMO.Code,
+			Message:   messages.ERR_INVALID_PROMO.Text,
+			Exception: reason,
+		}
+	}
+
+	switch {
+	case p.Code == "":
+		return invalid("code is required")
+	case len(p.Code) > 32:
+		return invalid("code must be at most 32 characters")
+	case !slices.Contains(promoDiscountTypes, p.DiscountType):
+		return invalid("discount_type must be one of " + strings.Join(promoDiscountTypes, ", "))
+	case p.DiscountType == models.PromoDiscountPercent && (p.DiscountBps <= 0 || p.DiscountBps > 10_000):
+		return invalid("discount_bps must be between 1 and 10000")
+	case p.DiscountType == models.PromoDiscountFixed && p.DiscountCents <= 0:
+		return invalid("discount_cents must be positive")
+	case p.ValidFrom != nil && p.ValidUntil != nil && !p.ValidUntil.After(*p.ValidFrom):
+		return invalid("valid_until must be after valid_from")
+	case p.MaxRedemptions != nil && *p.MaxRedemptions < 1:
+		return invalid("max_redemptions must be at least 1")
+	case p.MaxPerUser != nil && *p.MaxPerUser < 1:
+		return invalid("max_per_user must be at least 1")
+	case p.MinRentalDays < 0:
+		return invalid("min_rental_days must not be negative")
+	}
+	for _, c := range p.Categories {
+		if !slices.Contains(vehicleCategories, c) {
+			return invalid("categories must be drawn from " + strings.Join(vehicleCategories, ", "))
+		}
+	}
+
+	// Only the field for the chosen type is kept.
+	if p.DiscountType == models.PromoDiscountPercent {
+		p.DiscountCents = 0
+	} else {
+		p.DiscountBps = 0
+	}
+	return fiber.StatusOK, nil
+}
+
+func findPromoCode(ctx context.Context, db *gorm.DB, promoID string) (int, *models.PromoCode, *models.ErrorResponse) {
+	if _, err := uuid.Parse(promoID); err != nil {
+		return fiber.StatusNotFound, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_PROMO_NOT_FOUND.Code,
+			Message:   messages.ERR_PROMO_NOT_FOUND.Text,
+			Exception: "invalid promo code ID",
+		}
+	}
+
+	var promo models.PromoCode
+	if err := db.WithContext(ctx).Where("id = ?", promoID).First(&promo).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.StatusNotFound, nil, &models.ErrorResponse{
+				MessageID: messages.ERR_PROMO_NOT_FOUND.Code,
+				Message:   messages.ERR_PROMO_NOT_FOUND.Text,
+				Exception: "promo code not found",
+			}
+		}
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	return fiber.StatusOK, &promo, nil
+}
+
+func toPromoCodeResponse(p models.PromoCode, redemptions int) models.PromoCodeResponse {
+	resp := models.PromoCodeResponse{
+		ID:             p.ID,
+		Code:           p.Code,
+		Description:    p.Description,
+		DiscountType:   p.DiscountType,
+		DiscountBps:    p.DiscountBps,
+		DiscountCents:  p.DiscountCents,
+		MaxRedemptions: p.MaxRedemptions,
+		MaxPerUser:     p.MaxPerUser,
+		MinRentalDays:  p.MinRentalDays,
+		Categories:     p.Categories,
+		Active:         p.Active,
+		Redemptions:    redemptions,
+		CreatedAt:      p.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      p.UpdatedAt.Format(time.RFC3339),
+	}
+	if p.ValidFrom != nil {
+		resp.ValidFrom = p.ValidFrom.Format(time.RFC3339)
+	}
+	if p.ValidUntil != nil {
+		resp.ValidUntil = p.ValidUntil.Format(time.RFC3339)
+	}
+	return resp
+}
@@ -0,0 +1,1441 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+	"vehix/core/events"
+	"vehix/core/jobs"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	"vehix/core/payments"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RentalService interface {
+	ListRentals(ctx context.Context, filter models.RentalFilter) (int, *[]models.RentalResponse, *models.ErrorResponse)
+	GetRental(ctx context.Context, rentalID string) (int, *models.RentalResponse, *models.ErrorResponse)
+	CreateRental(ctx context.Context, userID string, payload models.CreateRentalPayload) (int, *models.RentalResponse, *models.ErrorResponse)
+	QuoteRental(ctx context.Context, userID string, payload models.CreateRentalPayload) (int, *models.QuoteResponse, *models.ErrorResponse)
+	HoldRental(ctx context.Context, userID string, payload models.CreateRentalPayload) (int, *models.RentalResponse, *models.ErrorResponse)
+	ConfirmRental(ctx context.Context, rentalID string, payload models.ConfirmRentalPayload) (int, *models.RentalResponse, *models.ErrorResponse)
+	ExpireRentalHolds(ctx context.Context) (int64, error)
+	CancelRental(ctx context.Context, rentalID string) (int, *models.RentalResponse, *models.ErrorResponse)
+	MarkNoShow(ctx context.Context, rentalID string) (int, *models.RentalResponse, *models.ErrorResponse)
+	ReturnRental(ctx context.Context, rentalID string, payload models.ReturnRentalPayload) (int, *models.RentalResponse, *models.ErrorResponse)
+	ModifyRental(ctx context.Context, rentalID, userID string, payload models.UpdateRentalPayload) (int, *models.RentalResponse, *models.ErrorResponse)
+	ListRentalModifications(ctx context.Context, rentalID string) (int, *[]models.RentalModificationResponse, *models.ErrorResponse)
+	ProcessOverdueRentals(ctx context.Context) (int64, error)
+	ExpireUnpaidRentals(ctx context.Context) (int64, error)
+	VoidExpiredPayment(ctx context.Context, job *models.Job) error
+}
+
+type RentalServiceImpl struct {
+	db      *gorm.DB
+	gateway payments.PaymentGateway
+}
+
+func NewRentalService(db *gorm.DB, gateway payments.PaymentGateway) RentalService {
+	return &RentalServiceImpl{db: db, gateway: gateway}
+}
+
+func (s *RentalServiceImpl) ListRentals(ctx context.Context, filter models.RentalFilter) (int, *[]models.RentalResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	query := db.Model(&models.Rental{})
+	if filter.UserID != "" {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.VehicleID != "" {
+		if _, err := uuid.Parse(filter.VehicleID); err != nil {
+			return fiber.StatusNotFound, nil, &models.ErrorResponse{
+				MessageID: messages.ERR_VEHICLE_NOT_FOUND.Code,
+				Message:   messages.ERR_VEHICLE_NOT_FOUND.Text,
+				Exception: "invalid vehicle ID",
+			}
+		}
+		query = query.Where("vehicle_id = ?", filter.VehicleID)
+	}
+	if filter.Overdue {
+		query = query.Where("status = ? AND overdue_at IS NOT NULL", models.RentalStatusConfirmed)
+	}
+	if filter.Blocked {
+		query = query.Where("status = ? AND blocked_by_rental_id IS NOT NULL", models.RentalStatusConfirmed)
+	}
+
+	var rentals []models.Rental
+	if err := query.Order("start_date DESC").Find(&rentals).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := []models.RentalResponse{}
+	for _, r := range rentals {
+		response = append(response, toRentalResponse(r))
+	}
+
+	return fiber.StatusOK, &response, nil
+}
+
+func (s *RentalServiceImpl) GetRental(ctx context.Context, rentalID string) (int, *models.RentalResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	statusCode, rental, errResp := findRental(ctx, db, rentalID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+	if err := loadRentalItems(db, rental); err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := toRentalResponse(*rental)
+	return fiber.StatusOK, &response, nil
+}
+
+func (s *RentalServiceImpl) CreateRental(ctx context.Context, userID string, payload models.CreateRentalPayload) (int, *models.RentalResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	if payload.PaymentToken == "" {
+		return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_BAD_REQUEST.Code,
+			Message:   messages.ERR_BAD_REQUEST.Text,
+			Exception: "payment_token is required",
+		}
+	}
+
+	statusCode, rental, errResp := newRental(userID, payload)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		statusCode, errResp, err = bookRental(ctx, tx, rental, payload)
+		if errResp != nil {
+			return errRentalRejected
+		}
+		if err != nil {
+			return err
+		}
+
+		if statusCode, errResp = authorizeRental(ctx, s.gateway, rental, payload.PaymentToken, "authorize"); errResp != nil {
+			return errRentalRejected
+		}
+		statusCode = fiber.StatusCreated
+
+		if err := tx.Create(rental).Error; err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, tx, AuditRentalCreate, "rental", rental.ID.String(), nil, *rental); err != nil {
+			return err
+		}
+		return events.Record(tx, events.RentalCreated, "rental", rental.ID.String(), rentalEvent(rental))
+	})
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+	if err != nil {
+		// The hold may already be in place; don't leave it on the card.
+		if rental.PaymentAuthorizationID != "" {
+			if _, voidErr := s.gateway.Void(ctx, rental.PaymentAuthorizationID, paymentIdempotencyKey(rental, "void")); voidErr != nil {
+				logger.Error(fmt.Sprintf("[%s] %s: voiding hold for rental %s: %s", messages.ERR_PAYMENT_GATEWAY.Code,
+					messages.ERR_PAYMENT_GATEWAY.Text, rental.ID, voidErr.Error()))
+			}
+		}
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := toRentalResponse(*rental)
+	return statusCode, &response, nil
+}
+
+// rentalHoldTTL is how long a hold keeps the vehicle while the customer pays.
+var rentalHoldTTL = time.Duration(envInt64("RENTAL_HOLD_TTL_MINUTES", 10)) * time.Minute
+
+// HoldRental reserves the vehicle, extras and price for rentalHoldTTL without
+// taking payment. The hold counts as a booking in every availability check
+// until it expires or is confirmed with ConfirmRental.
+func (s *RentalServiceImpl) HoldRental(ctx context.Context, userID string, payload models.CreateRentalPayload) (int, *models.RentalResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	statusCode, rental, errResp := newRental(userID, payload)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+	expiresAt := time.Now().Add(rentalHoldTTL)
+	rental.Status = models.RentalStatusHeld
+	rental.HoldExpiresAt = &expiresAt
+	rental.PaymentStatus = models.PaymentStatusNone
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		statusCode, errResp, err = bookRental(ctx, tx, rental, payload)
+		if errResp != nil {
+			return errRentalRejected
+		}
+		if err != nil {
+			return err
+		}
+		statusCode = fiber.StatusCreated
+
+		if err := tx.Create(rental).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditRentalHold, "rental", rental.ID.String(), nil, *rental)
+	})
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := toRentalResponse(*rental)
+	return statusCode, &response, nil
+}
+
+// ConfirmRental places the payment hold for a held rental and makes it a
+// booking at the price it was held at. A declined card leaves the hold in
+// place until it expires so the customer can try another.
+func (s *RentalServiceImpl) ConfirmRental(ctx context.Context, rentalID string, payload models.ConfirmRentalPayload) (int, *models.RentalResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	if payload.PaymentToken == "" {
+		return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_BAD_REQUEST.Code,
+			Message:   messages.ERR_BAD_REQUEST.Text,
+			Exception: "payment_token is required",
+		}
+	}
+
+	var errResp *models.ErrorResponse
+	statusCode := fiber.StatusOK
+	var rental *models.Rental
+	err := db.Transaction(func(tx *gorm.DB) error {
+		statusCode, rental, errResp = findRental(ctx, tx.Clauses(clause.Locking{Strength: "UPDATE"}), rentalID)
+		if errResp != nil {
+			return errRentalRejected
+		}
+		if rental.Status != models.RentalStatusHeld || !rental.HoldExpiresAt.After(time.Now()) {
+			statusCode, errResp = fiber.StatusConflict, &models.ErrorResponse{
+				MessageID: messages.ERR_RENTAL_NOT_HELD.Code,
+				Message:   messages.ERR_RENTAL_NOT_HELD.Text,
+				Exception: "the hold has expired or the rental is already " + rental.Status,
+			}
+			return errRentalRejected
+		}
+
+		before := *rental
+		// Each card gets its own idempotency key so a retry with the same card
+		// is a replay and a different card is a fresh attempt.
+		token := sha256.Sum256([]byte(payload.PaymentToken))
+		if statusCode, errResp = authorizeRental(ctx, s.gateway, rental, payload.PaymentToken, "authorize:"+hex.EncodeToString(token[:8])); errResp != nil {
+			return errRentalRejected
+		}
+		rental.Status = models.RentalStatusConfirmed
+		rental.HoldExpiresAt = nil
+		if err := savePaymentState(tx, rental); err != nil {
+			return err
+		}
+		if err := tx.Model(rental).Select("payment_authorization_id", "hold_expires_at").Updates(rental).Error; err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, tx, AuditRentalConfirm, "rental", rentalID, before, *rental); err != nil {
+			return err
+		}
+		// Holds are never announced, so to consumers this is the booking.
+		return events.Record(tx, events.RentalCreated, "rental", rentalID, rentalEvent(rental))
+	})
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+	if err != nil {
+		// The payment hold may already be in place; don't leave it on the card.
+		if rental != nil && rental.PaymentAuthorizationID != "" {
+			if _, voidErr := s.gateway.Void(ctx, rental.PaymentAuthorizationID, paymentIdempotencyKey(rental, "rollback:confirm")); voidErr != nil {
+				logger.Error(fmt.Sprintf("[%s] %s: voiding hold for rental %s: %s", messages.ERR_PAYMENT_GATEWAY.Code,
+					messages.ERR_PAYMENT_GATEWAY.Text, rental.ID, voidErr.Error()))
+			}
+		}
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	if err := loadRentalItems(db, rental); err != nil {
+		logger.Error(fmt.Sprintf("[%s] %s: %s", messages.ERR_UNEXPECTED_ERROR.Code, messages.ERR_UNEXPECTED_ERROR.Text, err.Error()))
+	}
+	response := toRentalResponse(*rental)
+	return fiber.StatusOK, &response, nil
+}
+
+// ExpireRentalHolds cancels holds that ran out without being confirmed.
+// Availability checks already ignore them; this tidies up their status and
+// releases any additional driver invitations.
+func (s *RentalServiceImpl) ExpireRentalHolds(ctx context.Context) (int64, error) {
+	db := s.db.WithContext(ctx)
+
+	var ids []uuid.UUID
+	if err := db.Model(&models.Rental{}).
+		Where("status = ? AND hold_expires_at <= ?", models.RentalStatusHeld, time.Now()).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+
+	var expired int64
+	for _, id := range ids {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var rental models.Rental
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("id = ? AND status = ? AND hold_expires_at <= ?", id, models.RentalStatusHeld, time.Now()).
+				Limit(1).Find(&rental).Error; err != nil {
+				return err
+			}
+			if rental.ID == uuid.Nil {
+				return nil
+			}
+
+			before := rental
+			if err := expireDriverInvitations(tx, &rental); err != nil {
+				return err
+			}
+			rental.Status = models.RentalStatusCancelled
+			if err := tx.Model(&rental).Update("status", rental.Status).Error; err != nil {
+				return err
+			}
+			expired++
+			return recordAudit(ctx, tx, AuditRentalCancel, "rental", rental.ID.String(), before, rental)
+		})
+		if err != nil {
+			return expired, err
+		}
+	}
+
+	return expired, nil
+}
+
+// QuoteRental runs every check and price calculation CreateRental would and
+// returns the result without booking anything or touching the card.
+func (s *RentalServiceImpl) QuoteRental(ctx context.Context, userID string, payload models.CreateRentalPayload) (int, *models.QuoteResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	statusCode, rental, errResp := newRental(userID, payload)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		statusCode, errResp, err = bookRental(ctx, tx, rental, payload)
+		if errResp != nil {
+			return errRentalRejected
+		}
+		return err
+	})
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := toQuoteResponse(*rental)
+	return fiber.StatusOK, &response, nil
+}
+
+// CancelRental calls off a booking that hasn't started. Within the policy's
+// free window the hold is released; after it the late cancellation fee is
+// taken from the hold.
+func (s *RentalServiceImpl) CancelRental(ctx context.Context, rentalID string) (int, *models.RentalResponse, *models.ErrorResponse) {
+	return s.cancelRental(ctx, rentalID, false)
+}
+
+// MarkNoShow cancels a rental whose customer never collected the vehicle and
+// charges the no-show fee. Staff use it once the start time has passed
+// without a checkout inspection.
+func (s *RentalServiceImpl) MarkNoShow(ctx context.Context, rentalID string) (int, *models.RentalResponse, *models.ErrorResponse) {
+	return s.cancelRental(ctx, rentalID, true)
+}
+
+// unpaidRentalTimeout is how long a booking may wait for its payment hold to
+// be confirmed before it is released.
+var unpaidRentalTimeout = time.Duration(envInt64("UNPAID_RENTAL_TIMEOUT_MINUTES", 30)) * time.Minute
+
+// PaymentVoidJob is the job kind that voids the hold of an expired unpaid
+// booking.
+const PaymentVoidJob = "payments.void_hold"
+
+// ExpireUnpaidRentals cancels bookings whose payment hold is still pending
+// after unpaidRentalTimeout. Rows are taken with SKIP LOCKED so concurrent
+// runs don't collide. The hold is voided by a job queued with the
+// cancellation, so the sweep doesn't call the gateway while it holds a
+// rental's lock and a failed void is retried on its own. A rental that fails
+// is logged and skipped, and the sweep reports how many did once it has been
+// through the rest.
+func (s *RentalServiceImpl) ExpireUnpaidRentals(ctx context.Context) (int64, error) {
+	db := s.db.WithContext(ctx)
+
+	var ids []uuid.UUID
+	if err := db.Model(&models.Rental{}).
+		Where("status = ? AND payment_status = ? AND created_at < ?",
+			models.RentalStatusConfirmed, models.PaymentStatusPending, time.Now().Add(-unpaidRentalTimeout)).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+
+	var expired, failed int64
+	for _, id := range ids {
+		var cancelled bool
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var rental models.Rental
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("id = ? AND status = ? AND payment_status = ?", id, models.RentalStatusConfirmed, models.PaymentStatusPending).
+				Limit(1).Find(&rental).Error; err != nil {
+				return err
+			}
+			if rental.ID == uuid.Nil {
+				return nil
+			}
+
+			before := rental
+			rental.Status = models.RentalStatusCancelled
+			if err := tx.Model(&rental).Update("status", rental.Status).Error; err != nil {
+				return err
+			}
+			if err := recordAudit(ctx, tx, AuditRentalCancel, "rental", rental.ID.String(), before, rental); err != nil {
+				return err
+			}
+			if err := events.Record(tx, events.RentalCancelled, "rental", rental.ID.String(), rentalEvent(&rental)); err != nil {
+				return err
+			}
+			if _, err := jobs.Enqueue(tx, PaymentVoidJob, models.JSONMap{"rental_id": rental.ID.String()}, jobs.Options{}); err != nil {
+				return err
+			}
+			cancelled = true
+			return nil
+		})
+		if err != nil {
+			logger.Error(fmt.Sprintf("[%s] %s: expiring unpaid rental %s: %s", messages.ERR_UNEXPECTED_ERROR.Code,
+				messages.ERR_UNEXPECTED_ERROR.Text, id, err))
+			failed++
+			continue
+		}
+		if cancelled {
+			expired++
+		}
+	}
+
+	if failed > 0 {
+		return expired, fmt.Errorf("%d of %d unpaid rentals could not be expired", failed, len(ids))
+	}
+	return expired, nil
+}
+
+// VoidExpiredPayment voids the hold of a booking ExpireUnpaidRentals
+// cancelled, so it can't settle later. The idempotency key makes retries
+// safe; holds the gateway has meanwhile reported failed or voided are left
+// alone.
+func (s *RentalServiceImpl) VoidExpiredPayment(ctx context.Context, job *models.Job) error {
+	db := s.db.WithContext(ctx)
+
+	rentalID, _ := job.Payload["rental_id"].(string)
+	var rental models.Rental
+	if _, err := uuid.Parse(rentalID); err == nil {
+		if err := db.Where("id = ?", rentalID).Limit(1).Find(&rental).Error; err != nil {
+			return err
+		}
+	}
+	if rental.ID == uuid.Nil {
+		return fmt.Errorf("%w: rental %q not found", jobs.ErrPermanent, rentalID)
+	}
+	if rental.Status != models.RentalStatusCancelled ||
+		(rental.PaymentStatus != models.PaymentStatusPending && rental.PaymentStatus != models.PaymentStatusAuthorized) {
+		return nil
+	}
+
+	before := rental
+	if _, errResp := releaseRentalPayment(ctx, s.gateway, &rental); errResp != nil {
+		return fmt.Errorf("voiding hold of rental %s: %s", rental.ID, errResp.Exception)
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		// A webhook may have moved the payment on while the gateway was
+		// being called; only record the void over the state it was made from.
+		res := tx.Model(&rental).Where("payment_status = ?", before.PaymentStatus).
+			Update("payment_status", rental.PaymentStatus)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		return recordAudit(ctx, tx, AuditRentalPayment, "rental", rental.ID.String(), before, rental)
+	})
+}
+
+func (s *RentalServiceImpl) cancelRental(ctx context.Context, rentalID string, noShow bool) (int, *models.RentalResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	var errResp *models.ErrorResponse
+	statusCode := fiber.StatusOK
+	var rental *models.Rental
+	err := db.Transaction(func(tx *gorm.DB) error {
+		statusCode, rental, errResp = findRental(ctx, tx.Clauses(clause.Locking{Strength: "UPDATE"}), rentalID)
+		if errResp != nil {
+			return errRentalRejected
+		}
+
+		now := time.Now()
+		var rejectReason string
+		switch {
+		case rental.Status == models.RentalStatusHeld && !noShow:
+		case rental.Status != models.RentalStatusConfirmed:
+			rejectReason = "only confirmed rentals can be cancelled"
+		case !noShow && !rental.StartDate.After(now):
+			rejectReason = "rentals that have started can only be marked as a no-show"
+		case noShow && rental.StartDate.After(now):
+			rejectReason = "a rental can't be a no-show before it starts"
+		}
+		if noShow && rejectReason == "" {
+			var checkouts int64
+			if err := tx.Model(&models.Inspection{}).
+				Where("rental_id = ? AND type = ?", rental.ID, models.InspectionTypeCheckout).
+				Count(&checkouts).Error; err != nil {
+				return err
+			}
+			if checkouts > 0 {
+				rejectReason = "the vehicle has already been handed over"
+			}
+		}
+		if rejectReason != "" {
+			statusCode, errResp = fiber.StatusConflict, &models.ErrorResponse{
+				MessageID: messages.ERR_RENTAL_NOT_CANCELLABLE.Code,
+				Message:   messages.ERR_RENTAL_NOT_CANCELLABLE.Text,
+				Exception: rejectReason,
+			}
+			return errRentalRejected
+		}
+
+		before := *rental
+		if rental.Status == models.RentalStatusConfirmed {
+			rental.CancellationFeeCents = cancellationFee(rental, now)
+		}
+		rental.NoShow = noShow
+		rental.HoldExpiresAt = nil
+		if statusCode, errResp = chargeCancellation(ctx, s.gateway, rental); errResp != nil {
+			return errRentalRejected
+		}
+		rental.Status = models.RentalStatusCancelled
+		if err := savePaymentState(tx, rental); err != nil {
+			return err
+		}
+		if err := tx.Model(rental).Select("cancellation_fee_cents", "no_show", "hold_expires_at").Updates(rental).Error; err != nil {
+			return err
+		}
+		if err := releaseBlockedRentals(ctx, tx, rental); err != nil {
+			return err
+		}
+		action := AuditRentalCancel
+		if noShow {
+			action = AuditRentalNoShow
+		}
+		if err := recordAudit(ctx, tx, action, "rental", rentalID, before, *rental); err != nil {
+			return err
+		}
+		if before.Status == models.RentalStatusHeld {
+			return nil
+		}
+		return events.Record(tx, events.RentalCancelled, "rental", rentalID, rentalEvent(rental))
+	})
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := toRentalResponse(*rental)
+	return fiber.StatusOK, &response, nil
+}
+
+// ReturnRental closes a rental when the vehicle is brought back and moves the
+// vehicle to the drop-off branch. Staff may record a different drop-off branch
+// than the one booked, in which case the one-way fee is recalculated.
+func (s *RentalServiceImpl) ReturnRental(ctx context.Context, rentalID string, payload models.ReturnRentalPayload) (int, *models.RentalResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	var errResp *models.ErrorResponse
+	statusCode := fiber.StatusOK
+	var rental *models.Rental
+	err := db.Transaction(func(tx *gorm.DB) error {
+		statusCode, rental, errResp = findRental(ctx, tx.Clauses(clause.Locking{Strength: "UPDATE"}), rentalID)
+		if errResp != nil {
+			return errRentalRejected
+		}
+
+		now := time.Now()
+		if rental.Status != models.RentalStatusConfirmed || rental.StartDate.After(now) {
+			statusCode, errResp = fiber.StatusConflict, &models.ErrorResponse{
+				MessageID: messages.ERR_RENTAL_NOT_RETURNABLE.Code,
+				Message:   messages.ERR_RENTAL_NOT_RETURNABLE.Text,
+				Exception: "only confirmed rentals that have started can be returned",
+			}
+			return errRentalRejected
+		}
+
+		before := *rental
+
+		if payload.DropoffBranchID != nil {
+			var dropoff *models.Branch
+			statusCode, dropoff, errResp = findBranch(ctx, tx, *payload.DropoffBranchID)
+			if errResp != nil {
+				return errRentalRejected
+			}
+			var pickup *models.Branch
+			if rental.PickupBranchID != nil {
+				var err error
+				if pickup, err = loadBranch(tx, *rental.PickupBranchID); err != nil {
+					return err
+				}
+			}
+			fee, err := oneWayFee(tx, pickup, dropoff)
+			if err != nil {
+				return err
+			}
+			rental.DropoffBranchID = &dropoff.ID
+			rental.OneWayFeeCents = fee
+		}
+
+		if err := expireDriverInvitations(tx, rental); err != nil {
+			return err
+		}
+		if err := saveLateFee(tx, rental, now); err != nil {
+			return err
+		}
+		if err := releaseBlockedRentals(ctx, tx, rental); err != nil {
+			return err
+		}
+
+		rental.Status = models.RentalStatusReturned
+		rental.ReturnedAt = &now
+		if err := tx.Model(rental).Select("status", "returned_at", "dropoff_branch_id", "one_way_fee_cents").Updates(rental).Error; err != nil {
+			return err
+		}
+
+		var err error
+		statusCode, errResp, err = captureRental(ctx, tx, s.gateway, rental)
+		if errResp != nil {
+			return errRentalRejected
+		}
+		if err != nil {
+			return err
+		}
+		statusCode = fiber.StatusOK
+		if err := savePaymentState(tx, rental); err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, tx, AuditRentalReturn, "rental", rentalID, before, *rental); err != nil {
+			return err
+		}
+		if err := events.Record(tx, events.RentalReturned, "rental", rentalID, rentalEvent(rental)); err != nil {
+			return err
+		}
+
+		if rental.DropoffBranchID == nil {
+			return nil
+		}
+
+		var vehicle models.Vehicle
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", rental.VehicleID).First(&vehicle).Error; err != nil {
+			return err
+		}
+		if vehicle.CurrentBranchID != nil && *vehicle.CurrentBranchID == *rental.DropoffBranchID {
+			return nil
+		}
+		vehicleBefore := vehicle
+		vehicle.CurrentBranchID = rental.DropoffBranchID
+		if err := tx.Model(&vehicle).Update("current_branch_id", vehicle.CurrentBranchID).Error; err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, tx, AuditVehicleRelocate, "vehicle", vehicle.ID.String(), vehicleBefore, vehicle); err != nil {
+			return err
+		}
+		return events.Record(tx, events.VehicleUpdated, "vehicle", vehicle.ID.String(), vehicleEvent(&vehicle, vehicleBefore.CurrentBranchID))
+	})
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := toRentalResponse(*rental)
+	return fiber.StatusOK, &response, nil
+}
+
+// ModifyRental moves a confirmed booking to another window or vehicle. The
+// new booking goes through the same availability checks and pricing as a
+// fresh one, ignoring the rental's own reservation. If the new price is more
+// than the current hold covers, a new hold for the full amount is placed and
+// the old one is voided once the change has committed. Once the rental has
+// started only the end date can move.
+func (s *RentalServiceImpl) ModifyRental(ctx context.Context, rentalID, userID string, payload models.UpdateRentalPayload) (int, *models.RentalResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	modifiedBy, err := uuid.Parse(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNAUTHORIZED.Code,
+			Message:   messages.ERR_UNAUTHORIZED.Text,
+			Exception: "invalid user ID",
+		}
+	}
+
+	var errResp *models.ErrorResponse
+	statusCode := fiber.StatusOK
+	var rental *models.Rental
+	var previousAuthorizationID, newAuthorizationID, operation string
+	err = db.Transaction(func(tx *gorm.DB) error {
+		statusCode, rental, errResp = findRental(ctx, tx.Clauses(clause.Locking{Strength: "UPDATE"}), rentalID)
+		if errResp != nil {
+			return errRentalRejected
+		}
+		if err := loadRentalItems(tx, rental); err != nil {
+			return err
+		}
+
+		var rejectReason string
+		updated := *rental
+		if payload.VehicleID != nil {
+			vehicleID, err := uuid.Parse(*payload.VehicleID)
+			if err != nil {
+				statusCode, errResp = fiber.StatusNotFound, &models.ErrorResponse{
+					MessageID: messages.ERR_VEHICLE_NOT_FOUND.Code,
+					Message:   messages.ERR_VEHICLE_NOT_FOUND.Text,
+					Exception: "invalid vehicle ID",
+				}
+				return errRentalRejected
+			}
+			updated.VehicleID = vehicleID
+		}
+		if payload.StartDate != nil {
+			updated.StartDate = *payload.StartDate
+		}
+		if payload.EndDate != nil {
+			updated.EndDate = *payload.EndDate
+		}
+
+		now := time.Now()
+		started := !rental.StartDate.After(now)
+		switch {
+		case rental.Status != models.RentalStatusConfirmed:
+			rejectReason = "only confirmed rentals can be modified"
+		case started && (updated.VehicleID != rental.VehicleID || !updated.StartDate.Equal(rental.StartDate)):
+			rejectReason = "only the end date of a rental that has started can be changed"
+		case updated.VehicleID == rental.VehicleID && updated.StartDate.Equal(rental.StartDate) && updated.EndDate.Equal(rental.EndDate):
+			rejectReason = "nothing to change"
+		}
+		if rejectReason != "" {
+			statusCode, errResp = fiber.StatusConflict, &models.ErrorResponse{
+				MessageID: messages.ERR_RENTAL_NOT_MODIFIABLE.Code,
+				Message:   messages.ERR_RENTAL_NOT_MODIFIABLE.Text,
+				Exception: rejectReason,
+			}
+			return errRentalRejected
+		}
+		if !updated.EndDate.After(updated.StartDate) || !updated.EndDate.After(now) ||
+			(!started && updated.StartDate.Before(now.Add(-time.Minute))) {
+			statusCode, errResp = fiber.StatusBadRequest, &models.ErrorResponse{
+				MessageID: messages.ERR_INVALID_RENTAL_PERIOD.Code,
+				Message:   messages.ERR_INVALID_RENTAL_PERIOD.Text,
+				Exception: "start_date must not be in the past and end_date must be after start_date and in the future",
+			}
+			return errRentalRejected
+		}
+
+		// Rebook with everything the customer originally chose.
+		booking, err := rentalBookingPayload(tx, rental)
+		if err != nil {
+			return err
+		}
+		statusCode, errResp, err = bookRental(ctx, tx, &updated, booking)
+		if errResp != nil {
+			return errRentalRejected
+		}
+		if err != nil {
+			return err
+		}
+
+		var sequence int64
+		if err := tx.Model(&models.RentalModification{}).Where("rental_id = ?", rental.ID).Count(&sequence).Error; err != nil {
+			return err
+		}
+		sequence++
+
+		subtotal := rentalSubtotalCents(&updated)
+		hold := subtotal + percentOf(subtotal, updated.TaxRateBps) + updated.DepositCents
+		if hold > 0 && (rental.PaymentStatus != models.PaymentStatusAuthorized || hold > rental.AuthorizedCents) {
+			switch {
+			case rental.PaymentStatus != models.PaymentStatusAuthorized && rental.PaymentStatus != models.PaymentStatusNone:
+				statusCode, errResp = fiber.StatusConflict, &models.ErrorResponse{
+					MessageID: messages.ERR_RENTAL_NOT_MODIFIABLE.Code,
+					Message:   messages.ERR_RENTAL_NOT_MODIFIABLE.Text,
+					Exception: "the payment hold is " + rental.PaymentStatus + " and cannot be increased",
+				}
+				return errRentalRejected
+			case payload.PaymentToken == "":
+				statusCode, errResp = fiber.StatusBadRequest, &models.ErrorResponse{
+					MessageID: messages.ERR_BAD_REQUEST.Code,
+					Message:   messages.ERR_BAD_REQUEST.Text,
+					Exception: "payment_token is required when the new price exceeds the current hold",
+				}
+				return errRentalRejected
+			}
+
+			operation = fmt.Sprintf("m%d", sequence)
+			if statusCode, errResp = authorizeRental(ctx, s.gateway, &updated, payload.PaymentToken, "authorize:"+operation); errResp != nil {
+				return errRentalRejected
+			}
+			previousAuthorizationID, newAuthorizationID = rental.PaymentAuthorizationID, updated.PaymentAuthorizationID
+			if updated.PaymentStatus == models.PaymentStatusPending {
+				// A change must not leave the booking without a confirmed
+				// hold; the old one still stands.
+				statusCode, errResp = fiber.StatusPaymentRequired, &models.ErrorResponse{
+					MessageID: messages.ERR_PAYMENT_DECLINED.Code,
+					Message:   messages.ERR_PAYMENT_DECLINED.Text,
+					Exception: "the new payment hold could not be confirmed",
+				}
+				return errRentalRejected
+			}
+		}
+
+		if err := saveRentalItems(tx, rental, &updated); err != nil {
+			return err
+		}
+		// The new window ends in the future, so the rental is no longer
+		// late, and any block on it is reassessed by the next overdue scan.
+		updated.OverdueAt, updated.BlockedByRentalID, updated.BlockedAt = nil, nil, nil
+		if err := saveLateFee(tx, &updated, now); err != nil {
+			return err
+		}
+		if err := releaseBlockedRentals(ctx, tx, &updated); err != nil {
+			return err
+		}
+		if err := tx.Model(&updated).Select("vehicle_id", "start_date", "end_date", "pickup_branch_id", "dropoff_branch_id",
+			"one_way_fee_cents", "rental_cents", "extras_cents", "young_driver_cents", "additional_drivers_cents",
+			"promo_code_id", "promo_code", "discount_cents", "tax_rate_bps", "deposit_cents", "cancellation_free_hours",
+			"cancellation_late_fee", "cancellation_late_fee_bps", "cancellation_no_show_fee", "cancellation_no_show_fee_bps",
+			"payment_authorization_id", "payment_status", "authorized_cents", "overdue_at", "blocked_by_rental_id", "blocked_at").
+			Updates(&updated).Error; err != nil {
+			return err
+		}
+
+		previousSubtotal := rentalSubtotalCents(rental)
+		modification := models.RentalModification{
+			RentalID:                rental.ID,
+			Sequence:                int(sequence),
+			ModifiedBy:              modifiedBy,
+			PreviousVehicleID:       rental.VehicleID,
+			PreviousStartDate:       rental.StartDate,
+			PreviousEndDate:         rental.EndDate,
+			VehicleID:               updated.VehicleID,
+			StartDate:               updated.StartDate,
+			EndDate:                 updated.EndDate,
+			PreviousTotalCents:      previousSubtotal + percentOf(previousSubtotal, rental.TaxRateBps),
+			TotalCents:              subtotal + percentOf(subtotal, updated.TaxRateBps),
+			PreviousAuthorizationID: previousAuthorizationID,
+			AuthorizationID:         updated.PaymentAuthorizationID,
+		}
+		if err := tx.Create(&modification).Error; err != nil {
+			return err
+		}
+
+		before := *rental
+		if err := loadRentalItems(tx, &updated); err != nil {
+			return err
+		}
+		*rental = updated
+		statusCode = fiber.StatusOK
+		if err := recordAudit(ctx, tx, AuditRentalModify, "rental", rentalID, before, *rental); err != nil {
+			return err
+		}
+		return events.Record(tx, events.RentalModified, "rental", rentalID, rentalEvent(rental))
+	})
+	if err != nil && newAuthorizationID != "" {
+		// The new hold was placed but the change didn't go through.
+		if _, voidErr := s.gateway.Void(ctx, newAuthorizationID, paymentIdempotencyKey(rental, "rollback:"+operation)); voidErr != nil {
+			logger.Error(fmt.Sprintf("[%s] %s: voiding new hold for rental %s: %s", messages.ERR_PAYMENT_GATEWAY.Code,
+				messages.ERR_PAYMENT_GATEWAY.Text, rental.ID, voidErr.Error()))
+		}
+	}
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	if previousAuthorizationID != "" && previousAuthorizationID != newAuthorizationID {
+		if _, voidErr := s.gateway.Void(ctx, previousAuthorizationID, paymentIdempotencyKey(rental, "void:"+operation)); voidErr != nil {
+			logger.Error(fmt.Sprintf("[%s] %s: voiding replaced hold for rental %s: %s", messages.ERR_PAYMENT_GATEWAY.Code,
+				messages.ERR_PAYMENT_GATEWAY.Text, rental.ID, voidErr.Error()))
+		}
+	}
+
+	response := toRentalResponse(*rental)
+	return fiber.StatusOK, &response, nil
+}
+
+func (s *RentalServiceImpl) ListRentalModifications(ctx context.Context, rentalID string) (int, *[]models.RentalModificationResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	statusCode, rental, errResp := findRental(ctx, db, rentalID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	var modifications []models.RentalModification
+	if err := db.Where("rental_id = ?", rental.ID).Order("sequence").Find(&modifications).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := []models.RentalModificationResponse{}
+	for _, m := range modifications {
+		response = append(response, models.RentalModificationResponse{
+			Sequence:           m.Sequence,
+			ModifiedBy:         m.ModifiedBy,
+			PreviousVehicleID:  m.PreviousVehicleID,
+			PreviousStartDate:  m.PreviousStartDate.Format(time.RFC3339),
+			PreviousEndDate:    m.PreviousEndDate.Format(time.RFC3339),
+			VehicleID:          m.VehicleID,
+			StartDate:          m.StartDate.Format(time.RFC3339),
+			EndDate:            m.EndDate.Format(time.RFC3339),
+			PreviousTotalCents: m.PreviousTotalCents,
+			TotalCents:         m.TotalCents,
+			DifferenceCents:    m.TotalCents - m.PreviousTotalCents,
+			HoldReplaced:       m.PreviousAuthorizationID != "",
+			CreatedAt:          m.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	return fiber.StatusOK, &response, nil
+}
+
+// rentalBookingPayload rebuilds the booking request behind an existing
+// rental: its branches, extras, promo code and the additional drivers that
+// haven't declined.
+func rentalBookingPayload(tx *gorm.DB, rental *models.Rental) (models.CreateRentalPayload, error) {
+	payload := models.CreateRentalPayload{
+		VehicleID: rental.VehicleID.String(),
+		StartDate: rental.StartDate,
+		EndDate:   rental.EndDate,
+		PromoCode: rental.PromoCode,
+	}
+	if rental.PickupBranchID != nil {
+		payload.PickupBranchID = rental.PickupBranchID.String()
+	}
+	if rental.DropoffBranchID != nil {
+		payload.DropoffBranchID = rental.DropoffBranchID.String()
+	}
+	for _, e := range rental.Extras {
+		payload.Extras = append(payload.Extras, models.RentalExtraPayload{ExtraID: e.ExtraID.String(), Quantity: e.Quantity})
+	}
+
+	var driverIDs []uuid.UUID
+	for _, d := range rental.Drivers {
+		if d.Status == models.RentalDriverPending || d.Status == models.RentalDriverAccepted {
+			driverIDs = append(driverIDs, d.UserID)
+		}
+	}
+	if len(driverIDs) > 0 {
+		if err := tx.Model(&models.User{}).Where("id IN ?", driverIDs).Pluck("email", &payload.AdditionalDrivers).Error; err != nil {
+			return payload, err
+		}
+	}
+	return payload, nil
+}
+
+// saveRentalItems replaces the rental's extras with the repriced ones and
+// updates the fees of drivers still on the rental. Drivers keep their
+// invitation state.
+func saveRentalItems(tx *gorm.DB, previous, updated *models.Rental) error {
+	if err := tx.Where("rental_id = ?", updated.ID).Delete(&models.RentalExtra{}).Error; err != nil {
+		return err
+	}
+	for i := range updated.Extras {
+		updated.Extras[i].RentalID = updated.ID
+	}
+	if len(updated.Extras) > 0 {
+		if err := tx.Create(&updated.Extras).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, d := range updated.Drivers {
+		err := tx.Model(&models.RentalDriver{}).Where("rental_id = ? AND user_id = ?", previous.ID, d.UserID).
+			Update("fee_cents", d.FeeCents).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newRental checks the parts of a booking request that don't need the
+// database. The ID is chosen up front so the payment hold can reference it.
+func newRental(userID string, payload models.CreateRentalPayload) (int, *models.Rental, *models.ErrorResponse) {
+	if !payload.EndDate.After(payload.StartDate) || payload.StartDate.Before(time.Now().Add(-time.Minute)) {
+		return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_INVALID_RENTAL_PERIOD.Code,
+			Message:   messages.ERR_INVALID_RENTAL_PERIOD.Text,
+			Exception: "start_date must not be in the past and end_date must be after start_date",
+		}
+	}
+
+	vehicleID, err := uuid.Parse(payload.VehicleID)
+	if err != nil {
+		return fiber.StatusNotFound, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_VEHICLE_NOT_FOUND.Code,
+			Message:   messages.ERR_VEHICLE_NOT_FOUND.Text,
+			Exception: "invalid vehicle ID",
+		}
+	}
+
+	renterID, err := uuid.Parse(userID)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNAUTHORIZED.Code,
+			Message:   messages.ERR_UNAUTHORIZED.Text,
+			Exception: "invalid user ID",
+		}
+	}
+
+	return fiber.StatusOK, &models.Rental{
+		ID:        uuid.New(),
+		UserID:    renterID,
+		VehicleID: vehicleID,
+		StartDate: payload.StartDate,
+		EndDate:   payload.EndDate,
+		Status:    models.RentalStatusConfirmed,
+	}, nil
+}
+
+// bookRental checks that the vehicle, branches and extras are available for
+// the rental and prices it. It leaves the vehicle and any stocked extras
+// locked until the caller's transaction ends.
+func bookRental(ctx context.Context, tx *gorm.DB, rental *models.Rental, payload models.CreateRentalPayload) (int, *models.ErrorResponse, error) {
+	// Lock the vehicle so concurrent bookings for it are checked one at a time.
+	var vehicle models.Vehicle
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", rental.VehicleID).First(&vehicle).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.StatusNotFound, &models.ErrorResponse{
+				MessageID: messages.ERR_VEHICLE_NOT_FOUND.Code,
+				Message:   messages.ERR_VEHICLE_NOT_FOUND.Text,
+				Exception: "vehicle not found",
+			}, nil
+		}
+		return fiber.StatusInternalServerError, nil, err
+	}
+
+	conflict, err := hasRentalConflict(tx, rental.VehicleID, rental.StartDate, rental.EndDate, rental.ID)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, err
+	}
+	if conflict {
+		return fiber.StatusConflict, &models.ErrorResponse{
+			MessageID: messages.ERR_VEHICLE_UNAVAILABLE.Code,
+			Message:   messages.ERR_VEHICLE_UNAVAILABLE.Text,
+			Exception: "vehicle is already booked for an overlapping period",
+		}, nil
+	}
+
+	inMaintenance, err := hasMaintenanceConflict(tx, rental.VehicleID, rental.StartDate, rental.EndDate)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, err
+	}
+	if inMaintenance {
+		return fiber.StatusConflict, &models.ErrorResponse{
+			MessageID: messages.ERR_VEHICLE_IN_MAINTENANCE.Code,
+			Message:   messages.ERR_VEHICLE_IN_MAINTENANCE.Text,
+			Exception: "vehicle is scheduled for maintenance during the requested period",
+		}, nil
+	}
+
+	statusCode, errResp, err := assignRentalBranches(ctx, tx, rental, &vehicle, payload.PickupBranchID, payload.DropoffBranchID)
+	if errResp != nil || err != nil {
+		return statusCode, errResp, err
+	}
+
+	rental.Extras, statusCode, errResp, err = reserveExtras(ctx, tx, rental, payload.Extras)
+	if errResp != nil || err != nil {
+		return statusCode, errResp, err
+	}
+
+	rental.RentalCents = int64(rentalDays(rental)) * vehicle.DailyRateCents
+	rental.ExtrasCents = 0
+	for _, e := range rental.Extras {
+		rental.ExtrasCents += e.AmountCents
+	}
+	rental.DepositCents = rentalDepositCents
+
+	statusCode, errResp, err = checkDriver(tx, rental, &vehicle, payload.AdditionalDrivers)
+	if errResp != nil || err != nil {
+		return statusCode, errResp, err
+	}
+
+	class, err := loadVehicleClass(tx, vehicle.Category)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, err
+	}
+	rental.CancellationPolicy = class.CancellationPolicy
+
+	return applyPromoCode(tx, rental, &vehicle, payload.PromoCode)
+}
+
+// loadRentalItems fills in the extras and additional drivers booked with a
+// rental.
+func loadRentalItems(tx *gorm.DB, rental *models.Rental) error {
+	if err := tx.Where("rental_id = ?", rental.ID).Order("created_at").Find(&rental.Extras).Error; err != nil {
+		return err
+	}
+	return tx.Where("rental_id = ?", rental.ID).Order("created_at").Find(&rental.Drivers).Error
+}
+
+func findRental(ctx context.Context, db *gorm.DB, rentalID string) (int, *models.Rental, *models.ErrorResponse) {
+	if _, err := uuid.Parse(rentalID); err != nil {
+		return fiber.StatusNotFound, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_RENTAL_NOT_FOUND.Code,
+			Message:   messages.ERR_RENTAL_NOT_FOUND.Text,
+			Exception: "invalid rental ID",
+		}
+	}
+
+	var rental models.Rental
+	if err := db.WithContext(ctx).Where("id = ?", rentalID).First(&rental).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.StatusNotFound, nil, &models.ErrorResponse{
+				MessageID: messages.ERR_RENTAL_NOT_FOUND.Code,
+				Message:   messages.ERR_RENTAL_NOT_FOUND.Text,
+				Exception: "rental not found",
+			}
+		}
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	return fiber.StatusOK, &rental, nil
+}
+
+// bookedStatuses are the rentals that claim their vehicle ahead of time. A
+// hold only does so until it expires; holdLiveSQL leaves out holds that have
+// lapsed but not yet been swept.
+var bookedStatuses = []string{models.RentalStatusConfirmed, models.RentalStatusHeld}
+
+const holdLiveSQL = "(status <> ? OR hold_expires_at > ?)"
+
+// errRentalRejected rolls back a rental transaction after the closure has
+// already filled in the error response to return.
+var errRentalRejected = errors.New("rental rejected")
+
+// hasRentalConflict reports whether the vehicle is already committed for any
+// part of [start, end). excludeRentalID lets a rental be re-checked against
+// everything but itself. A returned rental only occupies the vehicle until it
+// was actually brought back.
+func hasRentalConflict(tx *gorm.DB, vehicleID uuid.UUID, start, end time.Time, excludeRentalID uuid.UUID) (bool, error) {
+	var count int64
+	err := tx.Model(&models.Rental{}).
+		Where("vehicle_id = ? AND id <> ? AND status <> ?", vehicleID, excludeRentalID, models.RentalStatusCancelled).
+		Where(holdLiveSQL, models.RentalStatusHeld, time.Now()).
+		Where("start_date < ? AND COALESCE(returned_at, end_date) > ?", end, start).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// hasMaintenanceConflict reports whether a scheduled or in-progress
+// maintenance window takes the vehicle out of service during [start, end).
+func hasMaintenanceConflict(tx *gorm.DB, vehicleID uuid.UUID, start, end time.Time) (bool, error) {
+	var count int64
+	err := tx.Model(&models.MaintenanceWindow{}).
+		Where("vehicle_id = ? AND status IN ?", vehicleID, blockingMaintenanceStatuses).
+		Where("starts_at < ? AND ends_at > ?", end, start).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// vehicleBranchAt projects where the vehicle will be at time at: the drop-off
+// branch of the last open booking ending by then, or its current branch when
+// no such booking exists. Nil means the vehicle isn't tied to a branch.
+func vehicleBranchAt(tx *gorm.DB, vehicle *models.Vehicle, at time.Time, excludeRentalID uuid.UUID) (*uuid.UUID, error) {
+	var previous models.Rental
+	err := tx.Where("vehicle_id = ? AND id <> ? AND status IN ?", vehicle.ID, excludeRentalID, bookedStatuses).
+		Where(holdLiveSQL, models.RentalStatusHeld, time.Now()).
+		Where("end_date <= ? AND dropoff_branch_id IS NOT NULL", at).
+		Order("end_date DESC").Limit(1).Find(&previous).Error
+	if err != nil {
+		return nil, err
+	}
+	if previous.DropoffBranchID != nil {
+		return previous.DropoffBranchID, nil
+	}
+	return vehicle.CurrentBranchID, nil
+}
+
+// assignRentalBranches resolves the pickup and drop-off branches for a rental
+// and prices the one-way fee. Pickup defaults to wherever the vehicle will be
+// when the rental starts and drop-off defaults to pickup. The booking is
+// rejected if the vehicle won't be at the pickup branch, if either branch is
+// closed at the handover time, or if the drop-off would strand the vehicle
+// away from the pickup branch of its next booking.
+func assignRentalBranches(ctx context.Context, tx *gorm.DB, rental *models.Rental, vehicle *models.Vehicle, pickupID, dropoffID string) (int, *models.ErrorResponse, error) {
+	projected, err := vehicleBranchAt(tx, vehicle, rental.StartDate, rental.ID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var pickup, dropoff *models.Branch
+	switch {
+	case pickupID != "":
+		statusCode, branch, errResp := findBranch(ctx, tx, pickupID)
+		if errResp != nil {
+			return statusCode, errResp, nil
+		}
+		if projected != nil && *projected != branch.ID {
+			return fiber.StatusConflict, &models.ErrorResponse{
+				MessageID: messages.ERR_VEHICLE_NOT_AT_BRANCH.Code,
+				Message:   messages.ERR_VEHICLE_NOT_AT_BRANCH.Text,
+				Exception: "vehicle will not be at the pickup branch when the rental starts",
+			}, nil
+		}
+		pickup = branch
+	case projected != nil:
+		if pickup, err = loadBranch(tx, *projected); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	dropoff = pickup
+	if dropoffID != "" {
+		statusCode, branch, errResp := findBranch(ctx, tx, dropoffID)
+		if errResp != nil {
+			return statusCode, errResp, nil
+		}
+		dropoff = branch
+	}
+
+	if pickup != nil && !branchIsOpen(pickup, rental.StartDate) {
+		return fiber.StatusBadRequest, &models.ErrorResponse{
+			MessageID: messages.ERR_BRANCH_CLOSED.Code,
+			Message:   messages.ERR_BRANCH_CLOSED.Text,
+			Exception: "pickup branch is closed at start_date",
+		}, nil
+	}
+	if dropoff != nil && !branchIsOpen(dropoff, rental.EndDate) {
+		return fiber.StatusBadRequest, &models.ErrorResponse{
+			MessageID: messages.ERR_BRANCH_CLOSED.Code,
+			Message:   messages.ERR_BRANCH_CLOSED.Text,
+			Exception: "drop-off branch is closed at end_date",
+		}, nil
+	}
+
+	if dropoff != nil {
+		var next models.Rental
+		err := tx.Where("vehicle_id = ? AND id <> ? AND status IN ?", vehicle.ID, rental.ID, bookedStatuses).
+			Where(holdLiveSQL, models.RentalStatusHeld, time.Now()).
+			Where("start_date >= ?", rental.EndDate).
+			Order("start_date").Limit(1).Find(&next).Error
+		if err != nil {
+			return 0, nil, err
+		}
+		if next.PickupBranchID != nil && *next.PickupBranchID != dropoff.ID {
+			return fiber.StatusConflict, &models.ErrorResponse{
+				MessageID: messages.ERR_VEHICLE_NOT_AT_BRANCH.Code,
+				Message:   messages.ERR_VEHICLE_NOT_AT_BRANCH.Text,
+				Exception: "vehicle is needed at another branch for its next booking",
+			}, nil
+		}
+	}
+
+	fee, err := oneWayFee(tx, pickup, dropoff)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	rental.PickupBranchID, rental.DropoffBranchID = nil, nil
+	if pickup != nil {
+		rental.PickupBranchID = &pickup.ID
+	}
+	if dropoff != nil {
+		rental.DropoffBranchID = &dropoff.ID
+	}
+	rental.OneWayFeeCents = fee
+
+	// Tax is fixed at booking so a later rate change doesn't alter the price
+	// the customer was quoted.
+	rental.TaxRateBps = defaultTaxRateBps
+	if pickup != nil {
+		rental.TaxRateBps = pickup.TaxRateBps
+	}
+	return fiber.StatusOK, nil, nil
+}
+
+// rentalEvent is the payload of rental events: identifiers, dates and money,
+// leaving anything else for consumers to fetch through the API.
+func rentalEvent(r *models.Rental) models.JSONMap {
+	subtotal := rentalSubtotalCents(r)
+	return models.JSONMap{
+		"rental_id":              r.ID,
+		"user_id":                r.UserID,
+		"vehicle_id":             r.VehicleID,
+		"pickup_branch_id":       r.PickupBranchID,
+		"dropoff_branch_id":      r.DropoffBranchID,
+		"start_date":             r.StartDate,
+		"end_date":               r.EndDate,
+		"status":                 r.Status,
+		"total_cents":            subtotal + percentOf(subtotal, r.TaxRateBps),
+		"cancellation_fee_cents": r.CancellationFeeCents,
+		"no_show":                r.NoShow,
+	}
+}
+
+func toRentalResponse(r models.Rental) models.RentalResponse {
+	resp := models.RentalResponse{
+		ID:        r.ID,
+		UserID:    r.UserID,
+		VehicleID: r.VehicleID,
+		StartDate: r.StartDate.Format(time.RFC3339),
+		EndDate:   r.EndDate.Format(time.RFC3339),
+		Status:    r.Status,
+		CreatedAt: r.CreatedAt.Format(time.RFC3339),
+
+		PickupBranchID:  r.PickupBranchID,
+		DropoffBranchID: r.DropoffBranchID,
+		OneWayFeeCents:  r.OneWayFeeCents,
+
+		RentalCents:            r.RentalCents,
+		ExtrasCents:            r.ExtrasCents,
+		YoungDriverCents:       r.YoungDriverCents,
+		AdditionalDriversCents: r.AdditionalDriversCents,
+		PromoCode:              r.PromoCode,
+		DiscountCents:          r.DiscountCents,
+		TaxRateBps:             r.TaxRateBps,
+		DepositCents:           r.DepositCents,
+		PaymentStatus:          r.PaymentStatus,
+		AuthorizedCents:        r.AuthorizedCents,
+		CapturedCents:          r.CapturedCents,
+		RefundedCents:          r.RefundedCents,
+		CancellationFeeCents:   r.CancellationFeeCents,
+		NoShow:                 r.NoShow,
+		CancellationPolicy:     rentalCancellationPolicy(&r),
+	}
+	if r.HoldExpiresAt != nil {
+		resp.HoldExpiresAt = r.HoldExpiresAt.Format(time.RFC3339)
+	}
+	if r.ReturnedAt != nil {
+		resp.ReturnedAt = r.ReturnedAt.Format(time.RFC3339)
+	}
+	if r.OverdueAt != nil {
+		resp.OverdueAt = r.OverdueAt.Format(time.RFC3339)
+	}
+	if r.BlockedAt != nil {
+		resp.BlockedByRentalID = r.BlockedByRentalID
+		resp.BlockedAt = r.BlockedAt.Format(time.RFC3339)
+	}
+	for _, d := range r.Drivers {
+		driver := models.RentalDriverResponse{
+			UserID:   d.UserID,
+			Name:     d.Name,
+			Status:   d.Status,
+			FeeCents: d.FeeCents,
+		}
+		if d.RespondedAt != nil {
+			driver.RespondedAt = d.RespondedAt.Format(time.RFC3339)
+		}
+		resp.Drivers = append(resp.Drivers, driver)
+	}
+	for _, e := range r.Extras {
+		resp.Extras = append(resp.Extras, models.RentalExtraResponse{
+			ExtraID:     e.ExtraID,
+			Name:        e.Name,
+			Pricing:     e.Pricing,
+			Quantity:    e.Quantity,
+			UnitCents:   e.UnitCents,
+			AmountCents: e.AmountCents,
+		})
+	}
+	return resp
+}
+
+func toQuoteResponse(r models.Rental) models.QuoteResponse {
+	resp := models.QuoteResponse{
+		VehicleID:       r.VehicleID,
+		StartDate:       r.StartDate.Format(time.RFC3339),
+		EndDate:         r.EndDate.Format(time.RFC3339),
+		Days:            rentalDays(&r),
+		PickupBranchID:  r.PickupBranchID,
+		DropoffBranchID: r.DropoffBranchID,
+		Currency:        payments.Currency,
+		PromoCode:       r.PromoCode,
+		DiscountCents:   r.DiscountCents,
+		SubtotalCents:   rentalSubtotalCents(&r),
+		TaxRateBps:      r.TaxRateBps,
+		DepositCents:    r.DepositCents,
+
+		CancellationPolicy: rentalCancellationPolicy(&r),
+	}
+	for _, l := range rentalLines(&r) {
+		resp.Lines = append(resp.Lines, models.QuoteLine{
+			Kind:        l.Kind,
+			Description: l.Description,
+			Quantity:    l.Quantity,
+			UnitCents:   l.UnitCents,
+			AmountCents: l.AmountCents,
+		})
+	}
+	resp.TaxCents = percentOf(resp.SubtotalCents, resp.TaxRateBps)
+	resp.TotalCents = resp.SubtotalCents + resp.TaxCents
+	return resp
+}
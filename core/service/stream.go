@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"vehix/core/events"
+	"vehix/core/messages"
+	"vehix/core/stream"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Streams send a comment every streamHeartbeat so proxies keep them open, and
+// end after streamMaxDuration so clients reconnect, and are authenticated
+// again, with Last-Event-ID.
+var (
+	streamHeartbeat   = time.Duration(envInt64("STREAM_HEARTBEAT_SECONDS", 15)) * time.Second
+	streamMaxDuration = time.Duration(envInt64("STREAM_MAX_MINUTES", 30)) * time.Minute
+	streamBacklog     = int(envInt64("STREAM_BACKLOG_LIMIT", 500))
+)
+
+// streamTypes are the event types streams carry: bookings and vehicle
+// availability, not account events.
+var streamTypes = slices.DeleteFunc(slices.Clone(events.Types), func(t string) bool {
+	return !strings.HasPrefix(t, "rental.") && !strings.HasPrefix(t, "vehicle.")
+})
+
+// Stream is an open event stream. Backlog holds the events missed since the
+// client's last event; Events may repeat some of them, so skip IDs already
+// sent. Events is closed if the client falls too far behind.
+type Stream struct {
+	Backlog     []events.Message
+	Events      <-chan events.Message
+	Heartbeat   time.Duration
+	MaxDuration time.Duration
+	Close       func()
+}
+
+// StreamService opens live feeds of booking and fleet changes. Admins see
+// every rental and vehicle, customers only their own rentals; either can
+// narrow the feed to one branch and to some event types.
+type StreamService interface {
+	OpenStream(ctx context.Context, userID, role string, filter models.StreamFilter) (int, *Stream, *models.ErrorResponse)
+}
+
+type StreamServiceImpl struct {
+	db  *gorm.DB
+	hub *stream.Hub
+}
+
+func NewStreamService(db *gorm.DB, hub *stream.Hub) StreamService {
+	return &StreamServiceImpl{db: db, hub: hub}
+}
+
+func (s *StreamServiceImpl) OpenStream(ctx context.Context, userID, role string, filter models.StreamFilter) (int, *Stream, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	types := streamTypes
+	if filter.Types != "" {
+		types = nil
+		for _, t := range strings.Split(filter.Types, ",") {
+			t = strings.TrimSpace(t)
+			if !slices.Contains(streamTypes, t) {
+				return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+					MessageID: messages.ERR_INVALID_STREAM.Code,
+					Message:   messages.ERR_INVALID_STREAM.Text,
+					Exception: fmt.Sprintf("event type %q must be one of %s", t, strings.Join(streamTypes, ", ")),
+				}
+			}
+			types = append(types, t)
+		}
+	}
+
+	if filter.BranchID != "" {
+		statusCode, branch, errResp := findBranch(ctx, s.db, filter.BranchID)
+		if errResp != nil {
+			return statusCode, nil, errResp
+		}
+		filter.BranchID = branch.ID.String()
+	}
+
+	match := streamMatcher(userID, role == models.RoleAdmin, filter.BranchID, types)
+
+	// Subscribe before reading the backlog so nothing written in between is
+	// lost; the overlap is deduplicated by the caller.
+	live, unsubscribe := s.hub.Subscribe(match)
+	backlog, err := streamBacklogSince(db, filter.LastEventID, match)
+	if err != nil {
+		unsubscribe()
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	return fiber.StatusOK, &Stream{
+		Backlog:     backlog,
+		Events:      live,
+		Heartbeat:   streamHeartbeat,
+		MaxDuration: streamMaxDuration,
+		Close:       unsubscribe,
+	}, nil
+}
+
+// streamMatcher decides which events a caller sees. Event data carries IDs
+// as strings once it has been through the outbox.
+func streamMatcher(userID string, admin bool, branchID string, types []string) func(events.Message) bool {
+	return func(msg events.Message) bool {
+		if !slices.Contains(types, msg.Type) {
+			return false
+		}
+		if !admin {
+			if owner, _ := msg.Data["user_id"].(string); msg.AggregateType != "rental" || owner != userID {
+				return false
+			}
+		}
+		if branchID == "" {
+			return true
+		}
+		for _, key := range []string{"pickup_branch_id", "dropoff_branch_id", "home_branch_id", "current_branch_id", "previous_branch_id"} {
+			if id, _ := msg.Data[key].(string); id == branchID {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// streamBacklogSince returns the matching events written after the one with
+// ID lastEventID, oldest first. An unknown or pruned ID yields no backlog.
+func streamBacklogSince(db *gorm.DB, lastEventID string, match func(events.Message) bool) ([]events.Message, error) {
+	if _, err := uuid.Parse(lastEventID); err != nil {
+		return nil, nil
+	}
+	var last models.OutboxEvent
+	if err := db.Where("event_id = ?", lastEventID).Limit(1).Find(&last).Error; err != nil || last.ID == 0 {
+		return nil, err
+	}
+
+	var rows []models.OutboxEvent
+	if err := db.Where("id > ? AND type IN ?", last.ID, streamTypes).
+		Order("id").Limit(streamBacklog).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	backlog := []events.Message{}
+	for i := range rows {
+		if msg := events.FromOutbox(&rows[i]); match(msg) {
+			backlog = append(backlog, msg)
+		}
+	}
+	return backlog, nil
+}
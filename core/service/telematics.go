@@ -0,0 +1,382 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"vehix/core/messages"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Device tokens have the form vxd_<prefix>_<secret>, like API keys.
+const (
+	deviceTokenScheme      = "vxd"
+	deviceTokenPrefixBytes = 6
+	deviceTokenSecretBytes = 32
+)
+
+// A batch holds at most telematicsMaxBatch readings. Readings stamped more
+// than telematicsMaxSkew in the future, or older than the retention period,
+// are rejected.
+var (
+	telematicsMaxBatch  = int(envInt64("TELEMATICS_MAX_BATCH", 1_000))
+	telematicsMaxSkew   = time.Duration(envInt64("TELEMATICS_MAX_SKEW_SECONDS", 300)) * time.Second
+	telematicsRetention = time.Duration(envInt64("TELEMATICS_RETENTION_DAYS", 90)) * 24 * time.Hour
+)
+
+// vehicleTelemetryColumns are only written by ingestion.
+var vehicleTelemetryColumns = []string{"latitude", "longitude", "fuel_percent", "telemetry_at"}
+
+type TelematicsService interface {
+	CreateDevice(ctx context.Context, vehicleID, createdBy string, payload models.CreateTelematicsDevicePayload) (int, *models.CreateTelematicsDeviceResponse, *models.ErrorResponse)
+	ListDevices(ctx context.Context, vehicleID string) (int, *[]models.TelematicsDeviceResponse, *models.ErrorResponse)
+	RevokeDevice(ctx context.Context, deviceID string) (int, *models.ErrorResponse)
+	IngestReadings(ctx context.Context, rawToken string, payload models.TelematicsBatchPayload) (int, *models.TelematicsBatchResponse, *models.ErrorResponse)
+	PruneReadings(ctx context.Context) (int64, error)
+}
+
+type TelematicsServiceImpl struct {
+	db *gorm.DB
+}
+
+func NewTelematicsService(db *gorm.DB) TelematicsService {
+	return &TelematicsServiceImpl{db: db}
+}
+
+// CreateDevice registers a telematics unit for a vehicle. The token is
+// returned here and never again.
+func (s *TelematicsServiceImpl) CreateDevice(ctx context.Context, vehicleID, createdBy string, payload models.CreateTelematicsDevicePayload) (int, *models.CreateTelematicsDeviceResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	statusCode, vehicle, errResp := findVehicle(ctx, s.db, vehicleID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	creatorID, err := uuid.Parse(createdBy)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNAUTHORIZED.Code,
+			Message:   messages.ERR_UNAUTHORIZED.Text,
+			Exception: "invalid creator ID",
+		}
+	}
+
+	prefix, token, err := generateDeviceToken()
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	device := models.TelematicsDevice{
+		VehicleID: vehicle.ID,
+		Name:      strings.TrimSpace(payload.Name),
+		Prefix:    prefix,
+		TokenHash: hashAPIKey(token),
+		CreatedBy: creatorID,
+	}
+	if device.Name == "" {
+		device.Name = "Telematics unit"
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&device).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditDeviceCreate, "telematics_device", device.ID.String(), nil, device)
+	})
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	return fiber.StatusCreated, &models.CreateTelematicsDeviceResponse{
+		TelematicsDeviceResponse: toTelematicsDeviceResponse(device),
+		Token:                    token,
+	}, nil
+}
+
+func (s *TelematicsServiceImpl) ListDevices(ctx context.Context, vehicleID string) (int, *[]models.TelematicsDeviceResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	statusCode, vehicle, errResp := findVehicle(ctx, s.db, vehicleID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	var devices []models.TelematicsDevice
+	if err := db.Where("vehicle_id = ?", vehicle.ID).Order("created_at DESC").Find(&devices).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := []models.TelematicsDeviceResponse{}
+	for _, d := range devices {
+		response = append(response, toTelematicsDeviceResponse(d))
+	}
+	return fiber.StatusOK, &response, nil
+}
+
+func (s *TelematicsServiceImpl) RevokeDevice(ctx context.Context, deviceID string) (int, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	var device models.TelematicsDevice
+	if _, err := uuid.Parse(deviceID); err == nil {
+		if err := db.Where("id = ? AND revoked_at IS NULL", deviceID).Limit(1).Find(&device).Error; err != nil {
+			return fiber.StatusInternalServerError, &models.ErrorResponse{
+				MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+				Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+				Exception: err.Error(),
+			}
+		}
+	}
+	if device.ID == uuid.Nil {
+		return fiber.StatusNotFound, &models.ErrorResponse{
+			MessageID: messages.ERR_TELEMATICS_DEVICE_NOT_FOUND.Code,
+			Message:   messages.ERR_TELEMATICS_DEVICE_NOT_FOUND.Text,
+			Exception: "no active telematics device with that ID",
+		}
+	}
+
+	before := device
+	now := time.Now()
+	device.RevokedAt = &now
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&device).Update("revoked_at", device.RevokedAt).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditDeviceRevoke, "telematics_device", deviceID, before, device)
+	})
+	if err != nil {
+		return fiber.StatusInternalServerError, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	return fiber.StatusNoContent, nil
+}
+
+// IngestReadings stores a device's batch of readings and brings its vehicle's
+// mileage, fuel level and position up to the newest of them. Invalid readings
+// are reported back rather than failing the batch, so one bad sample doesn't
+// make the unit resend the rest forever. Readings older than the vehicle's
+// current telemetry are stored but don't move it back, and the odometer never
+// lowers the mileage.
+func (s *TelematicsServiceImpl) IngestReadings(ctx context.Context, rawToken string, payload models.TelematicsBatchPayload) (int, *models.TelematicsBatchResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	device, err := verifyDeviceToken(db, rawToken)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_INVALID_TELEMATICS_DEVICE.Code,
+			Message:   messages.ERR_INVALID_TELEMATICS_DEVICE.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	if len(payload.Readings) == 0 || len(payload.Readings) > telematicsMaxBatch {
+		return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_INVALID_TELEMATICS_BATCH.Code,
+			Message:   messages.ERR_INVALID_TELEMATICS_BATCH.Text,
+			Exception: fmt.Sprintf("a batch holds between 1 and %d readings", telematicsMaxBatch),
+		}
+	}
+
+	now := time.Now()
+	response := models.TelematicsBatchResponse{Rejected: []models.TelematicsRejection{}}
+	var readings []models.TelematicsReading
+	for i, r := range payload.Readings {
+		if reason := validateTelematicsReading(r, now); reason != "" {
+			response.Rejected = append(response.Rejected, models.TelematicsRejection{Index: i, Reason: reason})
+			continue
+		}
+		readings = append(readings, models.TelematicsReading{
+			VehicleID:   device.VehicleID,
+			DeviceID:    device.ID,
+			RecordedAt:  r.Timestamp.UTC(),
+			OdometerKm:  r.OdometerKm,
+			FuelPercent: r.FuelPercent,
+			Latitude:    r.Latitude,
+			Longitude:   r.Longitude,
+			ReceivedAt:  now,
+		})
+	}
+	sort.SliceStable(readings, func(i, j int) bool { return readings[i].RecordedAt.Before(readings[j].RecordedAt) })
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(device).UpdateColumn("last_seen_at", now).Error; err != nil {
+			return err
+		}
+		if len(readings) == 0 {
+			return nil
+		}
+
+		result := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "device_id"}, {Name: "recorded_at"}},
+			DoNothing: true,
+		}).Create(&readings)
+		if result.Error != nil {
+			return result.Error
+		}
+		response.Accepted = int(result.RowsAffected)
+		response.Duplicates = len(readings) - response.Accepted
+
+		var vehicle models.Vehicle
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", device.VehicleID).Limit(1).Find(&vehicle).Error; err != nil {
+			return err
+		}
+		if vehicle.ID == uuid.Nil {
+			return nil // retired; the readings are kept all the same
+		}
+
+		updates := map[string]any{}
+		for _, r := range readings {
+			if r.OdometerKm != nil && int(*r.OdometerKm) > vehicle.MileageKm {
+				vehicle.MileageKm = int(*r.OdometerKm)
+				updates["mileage_km"] = vehicle.MileageKm
+			}
+			if vehicle.TelemetryAt != nil && !r.RecordedAt.After(*vehicle.TelemetryAt) {
+				continue
+			}
+			if r.Latitude != nil && r.Longitude != nil {
+				updates["latitude"], updates["longitude"] = *r.Latitude, *r.Longitude
+			}
+			if r.FuelPercent != nil {
+				updates["fuel_percent"] = *r.FuelPercent
+			}
+			updates["telemetry_at"] = r.RecordedAt
+		}
+		if len(updates) == 0 {
+			return nil
+		}
+		return tx.Model(&vehicle).UpdateColumns(updates).Error
+	})
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	return fiber.StatusAccepted, &response, nil
+}
+
+// PruneReadings deletes readings older than the retention period, in chunks
+// so a large backlog doesn't hold one long transaction.
+func (s *TelematicsServiceImpl) PruneReadings(ctx context.Context) (int64, error) {
+	db := s.db.WithContext(ctx)
+
+	cutoff := time.Now().Add(-telematicsRetention)
+	var pruned int64
+	for {
+		result := db.Exec(`DELETE FROM telematics_readings WHERE id IN (
+			SELECT id FROM telematics_readings WHERE recorded_at < ? LIMIT 10000)`, cutoff)
+		if result.Error != nil {
+			return pruned, result.Error
+		}
+		pruned += result.RowsAffected
+		if result.RowsAffected == 0 {
+			return pruned, nil
+		}
+	}
+}
+
+func validateTelematicsReading(r models.TelematicsReadingPayload, now time.Time) string {
+	finite := func(v *float64) bool { return v == nil || !(math.IsNaN(*v) || math.IsInf(*v, 0)) }
+	switch {
+	case r.Timestamp.IsZero():
+		return "timestamp is required"
+	case r.Timestamp.After(now.Add(telematicsMaxSkew)):
+		return "timestamp is in the future"
+	case r.Timestamp.Before(now.Add(-telematicsRetention)):
+		return "timestamp is older than the retention period"
+	case !finite(r.OdometerKm) || !finite(r.FuelPercent) || !finite(r.Latitude) || !finite(r.Longitude):
+		return "values must be finite numbers"
+	case r.OdometerKm != nil && *r.OdometerKm < 0:
+		return "odometer_km must not be negative"
+	case r.FuelPercent != nil && (*r.FuelPercent < 0 || *r.FuelPercent > 100):
+		return "fuel_percent must be between 0 and 100"
+	case (r.Latitude == nil) != (r.Longitude == nil):
+		return "lat and lon must be given together"
+	case r.Latitude != nil && (*r.Latitude < -90 || *r.Latitude > 90):
+		return "lat must be between -90 and 90"
+	case r.Longitude != nil && (*r.Longitude < -180 || *r.Longitude > 180):
+		return "lon must be between -180 and 180"
+	}
+	return ""
+}
+
+func verifyDeviceToken(db *gorm.DB, rawToken string) (*models.TelematicsDevice, error) {
+	parts := strings.Split(rawToken, "_")
+	if len(parts) != 3 || parts[0] != deviceTokenScheme || len(parts[1]) != deviceTokenPrefixBytes*2 || len(parts[2]) != deviceTokenSecretBytes*2 {
+		return nil, errors.New("malformed device token")
+	}
+
+	var device models.TelematicsDevice
+	if err := db.Where("prefix = ?", parts[1]).Limit(1).Find(&device).Error; err != nil {
+		return nil, err
+	}
+	if device.ID == uuid.Nil || subtle.ConstantTimeCompare([]byte(device.TokenHash), []byte(hashAPIKey(rawToken))) != 1 {
+		return nil, errors.New("unknown device token")
+	}
+	if device.RevokedAt != nil {
+		return nil, errors.New("device has been revoked")
+	}
+	return &device, nil
+}
+
+func generateDeviceToken() (prefix, token string, err error) {
+	prefixBytes := make([]byte, deviceTokenPrefixBytes)
+	if _, err = rand.Read(prefixBytes); err != nil {
+		return "", "", err
+	}
+	secretBytes := make([]byte, deviceTokenSecretBytes)
+	if _, err = rand.Read(secretBytes); err != nil {
+		return "", "", err
+	}
+
+	prefix = hex.EncodeToString(prefixBytes)
+	token = fmt.Sprintf("%s_%s_%s", deviceTokenScheme, prefix, hex.EncodeToString(secretBytes))
+	return prefix, token, nil
+}
+
+func toTelematicsDeviceResponse(d models.TelematicsDevice) models.TelematicsDeviceResponse {
+	resp := models.TelematicsDeviceResponse{
+		ID:        d.ID,
+		VehicleID: d.VehicleID,
+		Name:      d.Name,
+		Prefix:    d.Prefix,
+		CreatedAt: d.CreatedAt.Format(time.RFC3339),
+	}
+	if d.LastSeenAt != nil {
+		resp.LastSeenAt = d.LastSeenAt.Format(time.RFC3339)
+	}
+	if d.RevokedAt != nil {
+		resp.RevokedAt = d.RevokedAt.Format(time.RFC3339)
+	}
+	return resp
+}
@@ -3,11 +3,18 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"os"
+	"strconv"
 	"time"
+	"vehix/core/events"
+	"vehix/core/logger"
 	"vehix/core/messages"
+	"vehix/core/storage"
 	"vehix/models"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
@@ -17,19 +24,43 @@ type UserService interface {
 	ListUsers(ctx context.Context) (int, *[]models.UserResponse, *models.ErrorResponse)
 	UpdateUser(ctx context.Context, userID string, req *models.UpdateUserPayload) (int, *models.UserResponse, *models.ErrorResponse)
 	DeleteUser(ctx context.Context, userID string) (int, *models.ErrorResponse)
+	AssignRole(ctx context.Context, userID, role string) (int, *models.UserResponse, *models.ErrorResponse)
+	UpdateUserStatus(ctx context.Context, userID, status, reason string) (int, *models.UserResponse, *models.ErrorResponse)
+	ForcePasswordReset(ctx context.Context, userID string) (int, *models.UserResponse, *models.ErrorResponse)
+	ExportUserData(ctx context.Context, userID string) (int, *models.UserDataExport, *models.ErrorResponse)
+	AnonymizeDeletedUsers(ctx context.Context) (int64, error)
 }
 
+// Soft-deleted accounts keep their personal data for this long (so support
+// and finance can still resolve open matters) before it is scrubbed.
+var userDataRetention = func() time.Duration {
+	days, err := strconv.Atoi(os.Getenv("USER_DATA_RETENTION_DAYS"))
+	if err != nil || days < 0 {
+		days = 30
+	}
+	return time.Duration(days) * 24 * time.Hour
+}()
+
 type UserServiceImpl struct {
-	db *gorm.DB
+	db    *gorm.DB
+	blobs storage.BlobStore
 }
 
-func NewUserService(db *gorm.DB) UserService {
-	return &UserServiceImpl{db: db}
+func NewUserService(db *gorm.DB, blobs storage.BlobStore) UserService {
+	return &UserServiceImpl{db: db, blobs: blobs}
 }
 
 func (s *UserServiceImpl) GetUser(ctx context.Context, userID string) (int, *models.UserResponse, *models.ErrorResponse) {
 	db := s.db.WithContext(ctx)
 
+	if _, err := uuid.Parse(userID); err != nil {
+		return fiber.StatusNotFound, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_USER_NOT_FOUND.Code,
+			Message:   messages.ERR_USER_NOT_FOUND.Text,
+			Exception: "invalid user ID",
+		}
+	}
+
 	var user models.User
 	err := db.Where("id = ?", userID).First(&user).Error
 	if err != nil {
@@ -48,34 +79,54 @@ func (s *UserServiceImpl) GetUser(ctx context.Context, userID string) (int, *mod
 		}
 	}
 
-	return fiber.StatusOK, &models.UserResponse{
-		ID:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		Role:      user.Role,
-		CreatedAt: user.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: user.UpdatedAt.Format(time.RFC3339),
-	}, nil
+	response := toUserResponse(user)
+	return fiber.StatusOK, &response, nil
 }
 
 func (s *UserServiceImpl) DeleteUser(ctx context.Context, userID string) (int, *models.ErrorResponse) {
 	db := s.db.WithContext(ctx)
 
-	result := db.Where("id = ?", userID).Delete(&models.User{})
+	if _, err := uuid.Parse(userID); err != nil {
+		return fiber.StatusNotFound, &models.ErrorResponse{
+			MessageID: messages.ERR_USER_NOT_FOUND.Code,
+			Message:   messages.ERR_USER_NOT_FOUND.Text,
+			Exception: "invalid user ID",
+		}
+	}
 
-	if result.Error != nil {
+	var user models.User
+	err := db.Where("id = ?", userID).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.StatusNotFound, &models.ErrorResponse{
+				MessageID: messages.ERR_USER_NOT_FOUND.Code,
+				Message:   messages.ERR_USER_NOT_FOUND.Text,
+				Exception: "user not found",
+			}
+		}
 		return fiber.StatusInternalServerError, &models.ErrorResponse{
 			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
 			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
-			Exception: result.Error.Error(),
+			Exception: err.Error(),
 		}
 	}
 
-	if result.RowsAffected == 0 {
-		return fiber.StatusNotFound, &models.ErrorResponse{
-			MessageID: messages.ERR_USER_NOT_FOUND.Code,
-			Message:   messages.ERR_USER_NOT_FOUND.Text,
-			Exception: "user not found",
+	// Soft delete: rentals and payments reference the user and must be kept
+	// for invoicing, so the row stays until AnonymizeDeletedUsers scrubs it.
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&user).Error; err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, tx, AuditUserDelete, "user", userID, user, nil); err != nil {
+			return err
+		}
+		return events.Record(tx, events.UserDeleted, "user", userID, models.JSONMap{"user_id": user.ID})
+	})
+	if err != nil {
+		return fiber.StatusInternalServerError, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
 		}
 	}
 
@@ -85,6 +136,14 @@ func (s *UserServiceImpl) DeleteUser(ctx context.Context, userID string) (int, *
 func (s *UserServiceImpl) UpdateUser(ctx context.Context, userID string, req *models.UpdateUserPayload) (int, *models.UserResponse, *models.ErrorResponse) {
 	db := s.db.WithContext(ctx)
 
+	if _, err := uuid.Parse(userID); err != nil {
+		return fiber.StatusNotFound, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_USER_NOT_FOUND.Code,
+			Message:   messages.ERR_USER_NOT_FOUND.Text,
+			Exception: "invalid user ID",
+		}
+	}
+
 	var user models.User
 	err := db.Where("id = ?", userID).First(&user).Error
 	if err != nil {
@@ -103,6 +162,8 @@ func (s *UserServiceImpl) UpdateUser(ctx context.Context, userID string, req *mo
 		}
 	}
 
+	before := user
+
 	if req.Name != nil {
 		user.Name = *req.Name
 	}
@@ -140,9 +201,15 @@ func (s *UserServiceImpl) UpdateUser(ctx context.Context, userID string, req *mo
 		}
 
 		user.Password = string(hashedPassword)
+		user.PasswordResetRequired = false
 	}
 
-	err = db.Updates(&user).Error
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&user).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditUserUpdate, "user", user.ID.String(), before, user)
+	})
 	if err != nil {
 		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
 			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
@@ -151,14 +218,8 @@ func (s *UserServiceImpl) UpdateUser(ctx context.Context, userID string, req *mo
 		}
 	}
 
-	return fiber.StatusOK, &models.UserResponse{
-		ID:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		Role:      user.Role,
-		CreatedAt: user.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: user.UpdatedAt.Format(time.RFC3339),
-	}, nil
+	response := toUserResponse(user)
+	return fiber.StatusOK, &response, nil
 }
 
 func (s *UserServiceImpl) ListUsers(ctx context.Context) (int, *[]models.UserResponse, *models.ErrorResponse) {
@@ -175,15 +236,313 @@ func (s *UserServiceImpl) ListUsers(ctx context.Context) (int, *[]models.UserRes
 
 	var response []models.UserResponse
 	for _, u := range users {
-		response = append(response, models.UserResponse{
-			ID:        u.ID,
-			Name:      u.Name,
-			Email:     u.Email,
-			Role:      u.Role,
-			CreatedAt: u.CreatedAt.Format(time.RFC3339Nano),
-			UpdatedAt: u.UpdatedAt.Format(time.RFC3339Nano),
-		})
+		response = append(response, toUserResponse(u))
+	}
+
+	return fiber.StatusOK, &response, nil
+}
+
+func (s *UserServiceImpl) AssignRole(ctx context.Context, userID, role string) (int, *models.UserResponse, *models.ErrorResponse) {
+	if role != models.RoleUser && role != models.RoleAdmin {
+		return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_INVALID_ROLE.Code,
+			Message:   messages.ERR_INVALID_ROLE.Text,
+			Exception: fmt.Sprintf("role must be one of %q, %q", models.RoleUser, models.RoleAdmin),
+		}
+	}
+
+	return s.updateUserColumns(ctx, userID, AuditUserRoleAssign, map[string]any{"role": role})
+}
+
+func (s *UserServiceImpl) UpdateUserStatus(ctx context.Context, userID, status, reason string) (int, *models.UserResponse, *models.ErrorResponse) {
+	columns := map[string]any{"status": status}
+	action := AuditUserReactivate
+
+	switch status {
+	case models.UserStatusSuspended:
+		columns["suspended_at"] = time.Now()
+		columns["suspension_reason"] = reason
+		action = AuditUserSuspend
+	case models.UserStatusActive:
+		columns["suspended_at"] = nil
+		columns["suspension_reason"] = ""
+	default:
+		return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_BAD_REQUEST.Code,
+			Message:   messages.ERR_BAD_REQUEST.Text,
+			Exception: fmt.Sprintf("unknown user status %q", status),
+		}
+	}
+
+	return s.updateUserColumns(ctx, userID, action, columns)
+}
+
+func (s *UserServiceImpl) ForcePasswordReset(ctx context.Context, userID string) (int, *models.UserResponse, *models.ErrorResponse) {
+	return s.updateUserColumns(ctx, userID, AuditUserPasswordReset, map[string]any{"password_reset_required": true})
+}
+
+func (s *UserServiceImpl) updateUserColumns(ctx context.Context, userID, action string, columns map[string]any) (int, *models.UserResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	if _, err := uuid.Parse(userID); err != nil {
+		return fiber.StatusNotFound, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_USER_NOT_FOUND.Code,
+			Message:   messages.ERR_USER_NOT_FOUND.Text,
+			Exception: "invalid user ID",
+		}
+	}
+
+	var user models.User
+	err := db.Where("id = ?", userID).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.StatusNotFound, nil, &models.ErrorResponse{
+				MessageID: messages.ERR_USER_NOT_FOUND.Code,
+				Message:   messages.ERR_USER_NOT_FOUND.Text,
+				Exception: "user not found",
+			}
+		}
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	before := user
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(columns).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id = ?", userID).First(&user).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, action, "user", userID, before, user)
+	})
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
 	}
 
+	response := toUserResponse(user)
 	return fiber.StatusOK, &response, nil
 }
+
+func toUserResponse(u models.User) models.UserResponse {
+	resp := models.UserResponse{
+		ID:                    u.ID,
+		Name:                  u.Name,
+		Email:                 u.Email,
+		Role:                  u.Role,
+		Status:                u.Status,
+		SuspensionReason:      u.SuspensionReason,
+		PasswordResetRequired: u.PasswordResetRequired,
+		CreatedAt:             u.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:             u.UpdatedAt.Format(time.RFC3339),
+	}
+	if u.SuspendedAt != nil {
+		resp.SuspendedAt = u.SuspendedAt.Format(time.RFC3339)
+	}
+	return resp
+}
+
+func (s *UserServiceImpl) ExportUserData(ctx context.Context, userID string) (int, *models.UserDataExport, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	statusCode, userResp, errResp := s.GetUser(ctx, userID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	var rentals []models.Rental
+	if err := db.Where("user_id = ?", userID).Order("start_date").Find(&rentals).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	export := models.UserDataExport{
+		ExportedAt: time.Now().UTC().Format(time.RFC3339),
+		User:       *userResp,
+		Rentals:    []models.RentalResponse{},
+	}
+
+	var profiles []models.DriverProfile
+	if err := db.Where("user_id = ?", userID).Limit(1).Find(&profiles).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+	if len(profiles) > 0 {
+		profile := toDriverProfileResponse(profiles[0])
+		export.DriverProfile = &profile
+	}
+	for _, r := range rentals {
+		export.Rentals = append(export.Rentals, toRentalResponse(r))
+	}
+
+	var prefs []models.NotificationPreference
+	if err := db.Where("user_id = ?", userID).Limit(1).Find(&prefs).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+	if len(prefs) > 0 {
+		resp := toNotificationPreferencesResponse(prefs[0])
+		export.NotificationPreferences = &resp
+	}
+
+	if err := exportUserRecords(db, userID, &export); err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	return fiber.StatusOK, &export, nil
+}
+
+// exportUserRecords adds the rest of the user's records to an export, the ones
+// held outside their profile and rentals.
+func exportUserRecords(db *gorm.DB, userID string, export *models.UserDataExport) error {
+	invitations, err := findDriverInvitations(db, userID)
+	if err != nil {
+		return err
+	}
+	export.DriverInvitations = invitations
+
+	var invoices []models.Invoice
+	if err := db.Preload("Lines", orderLines).Where("user_id = ?", userID).Order("issued_at").Find(&invoices).Error; err != nil {
+		return err
+	}
+	export.Invoices = []models.InvoiceResponse{}
+	for _, i := range invoices {
+		export.Invoices = append(export.Invoices, toInvoiceResponse(i))
+	}
+
+	var keys []models.APIKey
+	if err := db.Where("user_id = ?", userID).Order("created_at").Find(&keys).Error; err != nil {
+		return err
+	}
+	export.APIKeys = []models.APIKeyResponse{}
+	for _, k := range keys {
+		export.APIKeys = append(export.APIKeys, toAPIKeyResponse(k))
+	}
+
+	var notifications []models.Notification
+	if err := db.Where("user_id = ?", userID).Order("created_at").Find(&notifications).Error; err != nil {
+		return err
+	}
+	export.Notifications = []models.NotificationResponse{}
+	for _, n := range notifications {
+		export.Notifications = append(export.Notifications, toNotificationResponse(n))
+	}
+
+	// Resource IDs are UUIDs, so this matches the user and the records keyed
+	// on them without also matching other resources.
+	var entries []models.AuditLog
+	if err := db.Where("resource_id = ? OR actor_id = ?", userID, userID).Order("id").Find(&entries).Error; err != nil {
+		return err
+	}
+	export.AuditTrail = []models.AuditLogResponse{}
+	for _, e := range entries {
+		export.AuditTrail = append(export.AuditTrail, toAuditLogResponse(e))
+	}
+
+	return nil
+}
+
+// AnonymizeDeletedUsers scrubs personal data from accounts that were deleted
+// longer ago than the retention period. The rows themselves are kept so that
+// rentals and payments still resolve to a (now anonymous) customer; driver
+// profiles and licence scans are removed outright. The name snapshot kept on
+// rentals the user was added to as a driver is scrubbed like their own.
+func (s *UserServiceImpl) AnonymizeDeletedUsers(ctx context.Context) (int64, error) {
+	db := s.db.WithContext(ctx)
+
+	cutoff := time.Now().Add(-userDataRetention)
+
+	var userIDs []string
+	if err := db.Unscoped().Model(&models.User{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ? AND anonymized_at IS NULL", cutoff).
+		Pluck("id", &userIDs).Error; err != nil {
+		return 0, err
+	}
+
+	var anonymized int64
+	for _, id := range userIDs {
+		scrubbed := false
+		var licenseKey string
+		err := db.Transaction(func(tx *gorm.DB) error {
+			result := tx.Unscoped().Model(&models.User{}).
+				Where("id = ? AND anonymized_at IS NULL", id).
+				Updates(map[string]any{
+					"name":              "Deleted User",
+					"email":             fmt.Sprintf("deleted+%s@anonymized.invalid", id),
+					"password":          "",
+					"suspension_reason": "",
+					"anonymized_at":     time.Now(),
+				})
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+
+			var profile models.DriverProfile
+			if err := tx.Where("user_id = ?", id).Limit(1).Find(&profile).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("user_id = ?", id).Delete(&models.DriverProfile{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.RentalDriver{}).Where("user_id = ?", id).
+				Update("name", "Deleted User").Error; err != nil {
+				return err
+			}
+			// Published events are only kept for replays; the registration
+			// event carries the name and email, so it goes too, along with
+			// the copies webhook deliveries keep of it.
+			if err := tx.Where("aggregate_type = ? AND aggregate_id = ? AND published_at IS NOT NULL", "user", id).
+				Delete(&models.OutboxEvent{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("payload->>'aggregate_type' = ? AND payload->>'aggregate_id' = ?", "user", id).
+				Delete(&models.WebhookDelivery{}).Error; err != nil {
+				return err
+			}
+			// Sent notifications hold the address and phone number they went to.
+			if err := tx.Where("user_id = ?", id).Delete(&models.Notification{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("user_id = ?", id).Delete(&models.NotificationPreference{}).Error; err != nil {
+				return err
+			}
+			licenseKey = profile.LicensePhotoKey
+			scrubbed = true
+			return recordAudit(ctx, tx, AuditUserAnonymize, "user", id, nil, nil)
+		})
+		if err != nil {
+			return anonymized, err
+		}
+		if scrubbed {
+			anonymized++
+		}
+		if licenseKey != "" {
+			if err := s.blobs.Delete(ctx, licenseKey); err != nil {
+				logger.Error(fmt.Sprintf("[%s] %s: removing blob %s: %s", messages.ERR_UNEXPECTED_ERROR.Code,
+					messages.ERR_UNEXPECTED_ERROR.Text, licenseKey, err.Error()))
+			}
+		}
+	}
+
+	return anonymized, nil
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+	"vehix/core/database/dbtest"
+	"vehix/core/events"
+	"vehix/core/storage"
+	"vehix/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func createTestUser(t *testing.T, db *gorm.DB, role string) models.User {
+	t.Helper()
+	id := uuid.New()
+	user := models.User{
+		ID:       id,
+		Name:     "Test " + role,
+		Email:    id.String()[:8] + "@example.com",
+		Password: "x",
+		Role:     role,
+		Status:   models.UserStatusActive,
+	}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("creating user: %v", err)
+	}
+	return user
+}
+
+func createTestRental(t *testing.T, db *gorm.DB, user models.User, start, bookedAt time.Time) models.Rental {
+	t.Helper()
+	vehicle := models.Vehicle{Make: "VW", Model: "Golf", Year: 2024, DailyRateCents: 5_000}
+	if err := db.Create(&vehicle).Error; err != nil {
+		t.Fatalf("creating vehicle: %v", err)
+	}
+	rental := models.Rental{
+		UserID:      user.ID,
+		VehicleID:   vehicle.ID,
+		StartDate:   start,
+		EndDate:     start.Add(48 * time.Hour),
+		Status:      models.RentalStatusConfirmed,
+		RentalCents: 10_000,
+		CreatedAt:   bookedAt,
+	}
+	if err := db.Create(&rental).Error; err != nil {
+		t.Fatalf("creating rental: %v", err)
+	}
+	return rental
+}
+
+func TestAnonymizeDeletedUsersScrubsDriverNames(t *testing.T) {
+	db := dbtest.Open(t)
+	blobs, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStore: %v", err)
+	}
+	svc := NewUserService(db, blobs)
+
+	customer := createTestUser(t, db, models.RoleUser)
+	deleted := createTestUser(t, db, models.RoleUser)
+	kept := createTestUser(t, db, models.RoleUser)
+	rental := createTestRental(t, db, customer, time.Now().Add(24*time.Hour), time.Now())
+	for _, u := range []models.User{deleted, kept} {
+		driver := models.RentalDriver{RentalID: rental.ID, UserID: u.ID, Name: u.Name, FeeCents: 1_000}
+		if err := db.Create(&driver).Error; err != nil {
+			t.Fatalf("adding driver: %v", err)
+		}
+	}
+
+	// Webhook deliveries keep a copy of the registration event.
+	for _, u := range []models.User{deleted, kept} {
+		delivery := models.WebhookDelivery{
+			SubscriptionID: uuid.New(),
+			EventID:        uuid.New(),
+			EventType:      events.UserRegistered,
+			Payload: models.JSONMap{
+				"type":           events.UserRegistered,
+				"aggregate_type": "user",
+				"aggregate_id":   u.ID.String(),
+				"data":           map[string]any{"user_id": u.ID, "name": u.Name, "email": u.Email},
+			},
+			Status: models.WebhookDeliverySucceeded,
+		}
+		if err := db.Create(&delivery).Error; err != nil {
+			t.Fatalf("creating delivery: %v", err)
+		}
+	}
+
+	deletedAt := time.Now().Add(-userDataRetention - time.Hour)
+	if err := db.Model(&deleted).Update("deleted_at", deletedAt).Error; err != nil {
+		t.Fatalf("deleting user: %v", err)
+	}
+
+	for run, want := range []int64{1, 0} {
+		n, err := svc.AnonymizeDeletedUsers(context.Background())
+		if err != nil {
+			t.Fatalf("run %d: AnonymizeDeletedUsers: %v", run+1, err)
+		}
+		if n != want {
+			t.Errorf("run %d anonymized %d users, want %d", run+1, n, want)
+		}
+	}
+
+	var user models.User
+	if err := db.Unscoped().First(&user, "id = ?", deleted.ID).Error; err != nil {
+		t.Fatalf("loading user: %v", err)
+	}
+	if user.Name != "Deleted User" || user.Email == deleted.Email || user.AnonymizedAt == nil {
+		t.Errorf("user after anonymization: %+v", user)
+	}
+
+	var drivers []models.RentalDriver
+	if err := db.Where("rental_id = ?", rental.ID).Find(&drivers).Error; err != nil {
+		t.Fatalf("loading drivers: %v", err)
+	}
+	if len(drivers) != 2 {
+		t.Fatalf("rental has %d drivers, want 2", len(drivers))
+	}
+	for _, d := range drivers {
+		want := kept.Name
+		if d.UserID == deleted.ID {
+			want = "Deleted User"
+		}
+		if d.Name != want {
+			t.Errorf("driver %s named %q, want %q", d.UserID, d.Name, want)
+		}
+	}
+
+	for _, u := range []models.User{deleted, kept} {
+		var n int64
+		if err := db.Model(&models.WebhookDelivery{}).Where("payload->>'aggregate_id' = ?", u.ID.String()).
+			Count(&n).Error; err != nil {
+			t.Fatalf("counting deliveries: %v", err)
+		}
+		want := int64(1)
+		if u.ID == deleted.ID {
+			want = 0
+		}
+		if n != want {
+			t.Errorf("user %s has %d webhook deliveries left, want %d", u.ID, n, want)
+		}
+	}
+}
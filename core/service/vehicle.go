@@ -0,0 +1,589 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+	"vehix/core/events"
+	"vehix/core/messages"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type VehicleService interface {
+	ListVehicles(ctx context.Context, filter models.VehicleFilter) (int, *[]models.VehicleResponse, *models.ErrorResponse)
+	GetVehicle(ctx context.Context, vehicleID string) (int, *models.VehicleResponse, *models.ErrorResponse)
+	CreateVehicle(ctx context.Context, payload models.CreateVehiclePayload) (int, *models.VehicleResponse, *models.ErrorResponse)
+	UpdateVehicle(ctx context.Context, vehicleID string, req *models.UpdateVehiclePayload) (int, *models.VehicleResponse, *models.ErrorResponse)
+	DeleteVehicle(ctx context.Context, vehicleID string) (int, *models.ErrorResponse)
+}
+
+type VehicleServiceImpl struct {
+	db *gorm.DB
+}
+
+func NewVehicleService(db *gorm.DB) VehicleService {
+	return &VehicleServiceImpl{db: db}
+}
+
+// ListVehicles returns the fleet, optionally narrowed to a branch. When a
+// rental window is given only vehicles that are free for the whole window are
+// returned, and the branch filter matches where each vehicle is projected to
+// be when the window starts rather than where it is now.
+func (s *VehicleServiceImpl) ListVehicles(ctx context.Context, filter models.VehicleFilter) (int, *[]models.VehicleResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	query := db.Model(&models.Vehicle{})
+
+	if filter.BranchID != "" {
+		if _, err := uuid.Parse(filter.BranchID); err != nil {
+			return fiber.StatusNotFound, nil, &models.ErrorResponse{
+				MessageID: messages.ERR_BRANCH_NOT_FOUND.Code,
+				Message:   messages.ERR_BRANCH_NOT_FOUND.Text,
+				Exception: "invalid branch ID",
+			}
+		}
+	}
+
+	if filter.StartDate != "" || filter.EndDate != "" {
+		start, errStart := time.Parse(time.RFC3339, filter.StartDate)
+		end, errEnd := time.Parse(time.RFC3339, filter.EndDate)
+		if errStart != nil || errEnd != nil || !end.After(start) {
+			return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+				MessageID: messages.ERR_INVALID_RENTAL_PERIOD.Code,
+				Message:   messages.ERR_INVALID_RENTAL_PERIOD.Text,
+				Exception: "start_date and end_date must both be RFC3339 timestamps with end_date after start_date",
+			}
+		}
+
+		// Mirrors hasRentalConflict, hasMaintenanceConflict and vehicleBranchAt in rental.go.
+		query = query.Where(`NOT EXISTS (SELECT 1 FROM rentals r WHERE r.vehicle_id = vehicles.id AND r.status <> ?
+			AND (r.status <> ? OR r.hold_expires_at > ?)
+			AND r.start_date < ? AND COALESCE(r.returned_at, r.end_date) > ?)`,
+			models.RentalStatusCancelled, models.RentalStatusHeld, time.Now(), end, start)
+		query = query.Where(`NOT EXISTS (SELECT 1 FROM maintenance_windows m WHERE m.vehicle_id = vehicles.id AND m.status IN ?
+			AND m.starts_at < ? AND m.ends_at > ?)`, blockingMaintenanceStatuses, end, start)
+		if filter.BranchID != "" {
+			query = query.Where(`COALESCE((SELECT r.dropoff_branch_id FROM rentals r WHERE r.vehicle_id = vehicles.id AND r.status IN ?
+				AND (r.status <> ? OR r.hold_expires_at > ?)
+				AND r.end_date <= ? AND r.dropoff_branch_id IS NOT NULL ORDER BY r.end_date DESC LIMIT 1), vehicles.current_branch_id) = ?`,
+				bookedStatuses, models.RentalStatusHeld, time.Now(), start, filter.BranchID)
+		}
+	} else if filter.BranchID != "" {
+		query = query.Where("current_branch_id = ?", filter.BranchID)
+	}
+
+	for column, value := range map[string]string{
+		"make":         filter.Make,
+		"model":        filter.Model,
+		"category":     filter.Category,
+		"transmission": filter.Transmission,
+		"fuel_type":    filter.FuelType,
+		"color":        filter.Color,
+	} {
+		if value != "" {
+			query = query.Where("LOWER("+column+") = LOWER(?)", value)
+		}
+	}
+	for cond, value := range map[string]int{
+		"year >= ?":             filter.MinYear,
+		"year <= ?":             filter.MaxYear,
+		"seats >= ?":            filter.MinSeats,
+		"doors >= ?":            filter.MinDoors,
+		"luggage_capacity >= ?": filter.MinLuggage,
+		"mileage_km <= ?":       filter.MaxMileageKm,
+	} {
+		if value > 0 {
+			query = query.Where(cond, value)
+		}
+	}
+	if filter.Features != "" {
+		features := models.StringList{}
+		for _, f := range strings.Split(filter.Features, ",") {
+			if f = normalizeFeature(f); f != "" {
+				features = append(features, f)
+			}
+		}
+		wanted, err := features.Value()
+		if err != nil {
+			return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: err.Error(),
+			}
+		}
+		query = query.Where("features @> ?::jsonb", wanted)
+	}
+
+	var vehicles []models.Vehicle
+	if err := query.Order("make, model, year").Find(&vehicles).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := []models.VehicleResponse{}
+	for _, v := range vehicles {
+		response = append(response, toVehicleResponse(v))
+	}
+
+	return fiber.StatusOK, &response, nil
+}
+
+func (s *VehicleServiceImpl) GetVehicle(ctx context.Context, vehicleID string) (int, *models.VehicleResponse, *models.ErrorResponse) {
+	statusCode, vehicle, errResp := findVehicle(ctx, s.db, vehicleID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	response := toVehicleResponse(*vehicle)
+	return fiber.StatusOK, &response, nil
+}
+
+func (s *VehicleServiceImpl) CreateVehicle(ctx context.Context, payload models.CreateVehiclePayload) (int, *models.VehicleResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	vehicle := models.Vehicle{
+		Make:            payload.Make,
+		Model:           payload.Model,
+		Year:            payload.Year,
+		VIN:             strings.ToUpper(strings.TrimSpace(payload.VIN)),
+		LicensePlate:    normalizePlate(payload.LicensePlate),
+		Category:        strings.ToLower(payload.Category),
+		Transmission:    strings.ToLower(payload.Transmission),
+		FuelType:        strings.ToLower(payload.FuelType),
+		Seats:           payload.Seats,
+		Doors:           payload.Doors,
+		LuggageCapacity: payload.LuggageCapacity,
+		MileageKm:       payload.MileageKm,
+		Color:           payload.Color,
+		Features:        normalizeFeatures(payload.Features),
+		DailyRateCents:  payload.DailyRateCents,
+	}
+	if vehicle.Category == "" {
+		vehicle.Category = models.VehicleCategoryEconomy
+	}
+	if vehicle.Transmission == "" {
+		vehicle.Transmission = models.TransmissionManual
+	}
+	if vehicle.FuelType == "" {
+		vehicle.FuelType = models.FuelTypePetrol
+	}
+	if vehicle.Seats == 0 {
+		vehicle.Seats = 5
+	}
+	if vehicle.Doors == 0 {
+		vehicle.Doors = 4
+	}
+
+	if statusCode, errResp := s.validateVehicle(ctx, &vehicle); errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	if payload.HomeBranchID != "" {
+		statusCode, branch, errResp := findBranch(ctx, s.db, payload.HomeBranchID)
+		if errResp != nil {
+			return statusCode, nil, errResp
+		}
+		// A new vehicle starts out at its home branch.
+		vehicle.HomeBranchID = &branch.ID
+		vehicle.CurrentBranchID = &branch.ID
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&vehicle).Error; err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, tx, AuditVehicleCreate, "vehicle", vehicle.ID.String(), nil, vehicle); err != nil {
+			return err
+		}
+		return events.Record(tx, events.VehicleAdded, "vehicle", vehicle.ID.String(), vehicleEvent(&vehicle, nil))
+	})
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := toVehicleResponse(vehicle)
+	return fiber.StatusCreated, &response, nil
+}
+
+func (s *VehicleServiceImpl) UpdateVehicle(ctx context.Context, vehicleID string, req *models.UpdateVehiclePayload) (int, *models.VehicleResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	statusCode, vehicle, errResp := findVehicle(ctx, s.db, vehicleID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	before := *vehicle
+
+	if req.Make != nil {
+		vehicle.Make = *req.Make
+	}
+	if req.Model != nil {
+		vehicle.Model = *req.Model
+	}
+	if req.Year != nil {
+		vehicle.Year = *req.Year
+	}
+	if req.VIN != nil {
+		vehicle.VIN = strings.ToUpper(strings.TrimSpace(*req.VIN))
+	}
+	if req.LicensePlate != nil {
+		vehicle.LicensePlate = normalizePlate(*req.LicensePlate)
+	}
+	if req.Category != nil {
+		vehicle.Category = strings.ToLower(*req.Category)
+	}
+	if req.Transmission != nil {
+		vehicle.Transmission = strings.ToLower(*req.Transmission)
+	}
+	if req.FuelType != nil {
+		vehicle.FuelType = strings.ToLower(*req.FuelType)
+	}
+	if req.Seats != nil {
+		vehicle.Seats = *req.Seats
+	}
+	if req.Doors != nil {
+		vehicle.Doors = *req.Doors
+	}
+	if req.LuggageCapacity != nil {
+		vehicle.LuggageCapacity = *req.LuggageCapacity
+	}
+	if req.MileageKm != nil {
+		vehicle.MileageKm = *req.MileageKm
+	}
+	if req.Color != nil {
+		vehicle.Color = *req.Color
+	}
+	if req.Features != nil {
+		vehicle.Features = normalizeFeatures(*req.Features)
+	}
+	if req.DailyRateCents != nil {
+		vehicle.DailyRateCents = *req.DailyRateCents
+	}
+	if req.HomeBranchID != nil {
+		statusCode, branchID, errResp := s.resolveBranchID(ctx, *req.HomeBranchID)
+		if errResp != nil {
+			return statusCode, nil, errResp
+		}
+		vehicle.HomeBranchID = branchID
+	}
+	if req.CurrentBranchID != nil {
+		statusCode, branchID, errResp := s.resolveBranchID(ctx, *req.CurrentBranchID)
+		if errResp != nil {
+			return statusCode, nil, errResp
+		}
+		vehicle.CurrentBranchID = branchID
+	}
+
+	if statusCode, errResp := s.validateVehicle(ctx, vehicle); errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Telemetry is left to the ingestion path, which may have moved on
+		// since the vehicle was read.
+		if err := tx.Omit(vehicleTelemetryColumns...).Save(vehicle).Error; err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, tx, AuditVehicleUpdate, "vehicle", vehicleID, before, *vehicle); err != nil {
+			return err
+		}
+		return events.Record(tx, events.VehicleUpdated, "vehicle", vehicleID, vehicleEvent(vehicle, before.CurrentBranchID))
+	})
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := toVehicleResponse(*vehicle)
+	return fiber.StatusOK, &response, nil
+}
+
+func (s *VehicleServiceImpl) DeleteVehicle(ctx context.Context, vehicleID string) (int, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	statusCode, vehicle, errResp := findVehicle(ctx, s.db, vehicleID)
+	if errResp != nil {
+		return statusCode, errResp
+	}
+
+	var upcoming int64
+	if err := db.Model(&models.Rental{}).
+		Where("vehicle_id = ? AND status <> ? AND end_date > ?", vehicleID, models.RentalStatusCancelled, time.Now()).
+		Where(holdLiveSQL, models.RentalStatusHeld, time.Now()).
+		Count(&upcoming).Error; err != nil {
+		return fiber.StatusInternalServerError, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	if upcoming > 0 {
+		return fiber.StatusConflict, &models.ErrorResponse{
+			MessageID: messages.ERR_VEHICLE_IN_USE.Code,
+			Message:   messages.ERR_VEHICLE_IN_USE.Text,
+			Exception: "cancel or complete the vehicle's upcoming rentals first",
+		}
+	}
+
+	// Soft delete so past rentals keep pointing at a vehicle record.
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(vehicle).Error; err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, tx, AuditVehicleDelete, "vehicle", vehicleID, *vehicle, nil); err != nil {
+			return err
+		}
+		return events.Record(tx, events.VehicleRetired, "vehicle", vehicleID, models.JSONMap{
+			"vehicle_id":     vehicle.ID,
+			"vin":            vehicle.VIN,
+			"home_branch_id": vehicle.HomeBranchID,
+		})
+	})
+	if err != nil {
+		return fiber.StatusInternalServerError, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	return fiber.StatusNoContent, nil
+}
+
+func findVehicle(ctx context.Context, db *gorm.DB, vehicleID string) (int, *models.Vehicle, *models.ErrorResponse) {
+	db = db.WithContext(ctx)
+
+	if _, err := uuid.Parse(vehicleID); err != nil {
+		return fiber.StatusNotFound, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_VEHICLE_NOT_FOUND.Code,
+			Message:   messages.ERR_VEHICLE_NOT_FOUND.Text,
+			Exception: "invalid vehicle ID",
+		}
+	}
+
+	var vehicle models.Vehicle
+	if err := db.Where("id = ?", vehicleID).First(&vehicle).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.StatusNotFound, nil, &models.ErrorResponse{
+				MessageID: messages.ERR_VEHICLE_NOT_FOUND.Code,
+				Message:   messages.ERR_VEHICLE_NOT_FOUND.Text,
+				Exception: "vehicle not found",
+			}
+		}
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	return fiber.StatusOK, &vehicle, nil
+}
+
+var (
+	vehicleCategories = []string{
+		models.VehicleCategoryEconomy, models.VehicleCategoryCompact, models.VehicleCategoryMidsize,
+		models.VehicleCategoryFullsize, models.VehicleCategorySUV, models.VehicleCategoryVan, models.VehicleCategoryLuxury,
+	}
+	vehicleTransmissions = []string{models.TransmissionManual, models.TransmissionAutomatic}
+	vehicleFuelTypes     = []string{models.FuelTypePetrol, models.FuelTypeDiesel, models.FuelTypeHybrid, models.FuelTypeElectric}
+)
+
+// validateVehicle checks the catalog attributes and that the VIN isn't
+// already registered to another vehicle in the fleet.
+// vehicleEvent is the data of vehicle events. previousBranchID is where the
+// vehicle was before the change, so the branch it left hears about it too.
+func vehicleEvent(v *models.Vehicle, previousBranchID *uuid.UUID) models.JSONMap {
+	return models.JSONMap{
+		"vehicle_id":         v.ID,
+		"make":               v.Make,
+		"model":              v.Model,
+		"category":           v.Category,
+		"daily_rate_cents":   v.DailyRateCents,
+		"home_branch_id":     v.HomeBranchID,
+		"current_branch_id":  v.CurrentBranchID,
+		"previous_branch_id": previousBranchID,
+	}
+}
+
+func (s *VehicleServiceImpl) validateVehicle(ctx context.Context, v *models.Vehicle) (int, *models.ErrorResponse) {
+	invalid := func(reason string) (int, *models.ErrorResponse) {
+		return fiber.StatusBadRequest, &models.ErrorResponse{
+			MessageID: messages.ERR_INVALID_VEHICLE_ATTRIBUTE.Code,
+			Message:   messages.ERR_INVALID_VEHICLE_ATTRIBUTE.Text,
+			Exception: reason,
+		}
+	}
+
+	switch {
+	case !slices.Contains(vehicleCategories, v.Category):
+		return invalid("category must be one of " + strings.Join(vehicleCategories, ", "))
+	case !slices.Contains(vehicleTransmissions, v.Transmission):
+		return invalid("transmission must be one of " + strings.Join(vehicleTransmissions, ", "))
+	case !slices.Contains(vehicleFuelTypes, v.FuelType):
+		return invalid("fuel_type must be one of " + strings.Join(vehicleFuelTypes, ", "))
+	case v.Year < 1900 || v.Year > time.Now().Year()+1:
+		return invalid("year is out of range")
+	case v.Seats < 1 || v.Seats > 20:
+		return invalid("seats must be between 1 and 20")
+	case v.Doors < 0 || v.Doors > 6:
+		return invalid("doors must be between 0 and 6")
+	case v.LuggageCapacity < 0 || v.MileageKm < 0 || v.DailyRateCents < 0:
+		return invalid("luggage_capacity, mileage_km and daily_rate_cents must not be negative")
+	}
+
+	if v.VIN == "" {
+		return fiber.StatusOK, nil
+	}
+	if !validVIN(v.VIN) {
+		return fiber.StatusBadRequest, &models.ErrorResponse{
+			MessageID: messages.ERR_INVALID_VIN.Code,
+			Message:   messages.ERR_INVALID_VIN.Text,
+			Exception: "VIN must be 17 characters without I, O or Q and carry a valid check digit",
+		}
+	}
+
+	var taken int64
+	if err := s.db.WithContext(ctx).Model(&models.Vehicle{}).Where("vin = ? AND id <> ?", v.VIN, v.ID).Count(&taken).Error; err != nil {
+		return fiber.StatusInternalServerError, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+	if taken > 0 {
+		return fiber.StatusConflict, &models.ErrorResponse{
+			MessageID: messages.ERR_VIN_EXISTS.Code,
+			Message:   messages.ERR_VIN_EXISTS.Text,
+			Exception: "another vehicle is registered with this VIN",
+		}
+	}
+
+	return fiber.StatusOK, nil
+}
+
+// validVIN checks a 17 character VIN against the ISO 3779 character set and
+// the position 9 check digit (weighted sum of transliterated values mod 11).
+func validVIN(vin string) bool {
+	const weights = "8765432X098765432"
+	if len(vin) != 17 {
+		return false
+	}
+
+	sum := 0
+	for i := 0; i < len(vin); i++ {
+		value, ok := vinTransliteration(vin[i])
+		if !ok {
+			return false
+		}
+		weight := int(weights[i] - '0')
+		if weights[i] == 'X' {
+			weight = 10
+		}
+		sum += value * weight
+	}
+
+	check := byte('0' + sum%11)
+	if sum%11 == 10 {
+		check = 'X'
+	}
+	return vin[8] == check
+}
+
+func vinTransliteration(c byte) (int, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0'), true
+	case c == 'I' || c == 'O' || c == 'Q':
+		return 0, false
+	case c >= 'A' && c <= 'H':
+		return int(c-'A') + 1, true
+	case c >= 'J' && c <= 'R':
+		return int(c-'J') + 1, true
+	case c >= 'S' && c <= 'Z':
+		return int(c-'S') + 2, true
+	}
+	return 0, false
+}
+
+func normalizePlate(plate string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(plate), " "))
+}
+
+func normalizeFeature(feature string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(feature)), " ", "_")
+}
+
+func normalizeFeatures(features []string) models.StringList {
+	result := models.StringList{}
+	for _, f := range features {
+		if f = normalizeFeature(f); f != "" && !result.Contains(f) {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+// resolveBranchID maps an optional branch reference from a payload to its ID;
+// an empty string clears the reference.
+func (s *VehicleServiceImpl) resolveBranchID(ctx context.Context, branchID string) (int, *uuid.UUID, *models.ErrorResponse) {
+	if branchID == "" {
+		return fiber.StatusOK, nil, nil
+	}
+	statusCode, branch, errResp := findBranch(ctx, s.db, branchID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+	return fiber.StatusOK, &branch.ID, nil
+}
+
+func toVehicleResponse(v models.Vehicle) models.VehicleResponse {
+	resp := models.VehicleResponse{
+		ID:              v.ID,
+		Make:            v.Make,
+		Model:           v.Model,
+		Year:            v.Year,
+		VIN:             v.VIN,
+		LicensePlate:    v.LicensePlate,
+		Category:        v.Category,
+		Transmission:    v.Transmission,
+		FuelType:        v.FuelType,
+		Seats:           v.Seats,
+		Doors:           v.Doors,
+		LuggageCapacity: v.LuggageCapacity,
+		MileageKm:       v.MileageKm,
+		Color:           v.Color,
+		Features:        v.Features,
+		DailyRateCents:  v.DailyRateCents,
+		HomeBranchID:    v.HomeBranchID,
+		CurrentBranchID: v.CurrentBranchID,
+		FuelPercent:     v.FuelPercent,
+		CreatedAt:       v.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:       v.UpdatedAt.Format(time.RFC3339),
+	}
+	if v.Latitude != nil && v.Longitude != nil {
+		resp.Position = &models.VehiclePosition{Latitude: *v.Latitude, Longitude: *v.Longitude}
+	}
+	if v.TelemetryAt != nil {
+		resp.TelemetryAt = v.TelemetryAt.Format(time.RFC3339)
+	}
+	return resp
+}
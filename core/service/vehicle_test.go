@@ -0,0 +1,34 @@
+package service
+
+import "testing"
+
+func TestValidVIN(t *testing.T) {
+	tests := []struct {
+		name string
+		vin  string
+		want bool
+	}{
+		{"valid", "1HGCM82633A004352", true},
+		{"valid with letters throughout", "JH4KA7561PC008269", true},
+		{"all ones", "11111111111111111", true},
+		{"check digit X", "1M8GDM9AXKP042788", true},
+		{"X expected but digit given", "1M8GDM9A0KP042788", false},
+		{"wrong check digit", "1HGCM82643A004352", false},
+		{"transposed characters", "1HGCM82633A004325", false},
+		{"contains I", "1111111111111111I", false},
+		{"contains O", "11111111O11111111", false},
+		{"contains Q", "Q1111111111111111", false},
+		{"lower case", "1m8gdm9axkp042788", false},
+		{"too short", "1HGCM82633A00435", false},
+		{"too long", "1HGCM82633A0043521", false},
+		{"empty", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validVIN(tt.vin); got != tt.want {
+				t.Errorf("validVIN(%q) = %v, want %v", tt.vin, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,598 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"vehix/core/events"
+	"vehix/core/jobs"
+	"vehix/core/messages"
+	"vehix/core/webhooks"
+	"vehix/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WebhookDeliveryJob is the job kind that makes one delivery attempt.
+// Retries and their exponential backoff are the job queue's.
+const WebhookDeliveryJob = "webhooks.deliver"
+
+// webhookPing is sent by PingWebhook whatever the subscription's filter.
+const webhookPing = "webhook.ping"
+
+const (
+	webhookDefaultPageSize = 50
+	webhookMaxPageSize     = 500
+)
+
+var (
+	webhookMaxAttempts       = int(envInt64("WEBHOOK_MAX_ATTEMPTS", 10))
+	webhookDeliveryRetention = time.Duration(envInt64("WEBHOOK_DELIVERY_RETENTION_DAYS", 30)) * 24 * time.Hour
+	webhookDeliveryStatuses  = []string{models.WebhookDeliveryPending, models.WebhookDeliveryRetrying, models.WebhookDeliverySucceeded, models.WebhookDeliveryFailed}
+	errWebhookDeliveryFailed = errors.New("webhook delivery failed")
+)
+
+// WebhookService manages API clients' webhook subscriptions and delivers to
+// them. It is also an events.Publisher: the outbox relay hands it every event
+// and it queues a delivery for each subscription that wants it.
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, apiKeyID, createdBy string, payload models.CreateWebhookPayload) (int, *models.CreateWebhookResponse, *models.ErrorResponse)
+	ListWebhooks(ctx context.Context, apiKeyID string) (int, *[]models.WebhookResponse, *models.ErrorResponse)
+	DeleteWebhook(ctx context.Context, webhookID, apiKeyID string) (int, *models.ErrorResponse)
+	ListWebhookDeliveries(ctx context.Context, webhookID, apiKeyID string, filter models.WebhookDeliveryFilter) (int, *[]models.WebhookDeliveryResponse, *models.ErrorResponse)
+	ReplayWebhookDelivery(ctx context.Context, webhookID, deliveryID, apiKeyID string) (int, *models.WebhookDeliveryResponse, *models.ErrorResponse)
+	PingWebhook(ctx context.Context, webhookID, apiKeyID string) (int, *models.WebhookDeliveryResponse, *models.ErrorResponse)
+	DeliverWebhook(ctx context.Context, job *models.Job) error
+	PruneWebhookDeliveries(ctx context.Context) (int64, error)
+	events.Publisher
+}
+
+type WebhookServiceImpl struct {
+	db     *gorm.DB
+	sender *webhooks.Sender
+}
+
+func NewWebhookService(db *gorm.DB, sender *webhooks.Sender) WebhookService {
+	return &WebhookServiceImpl{db: db, sender: sender}
+}
+
+// CreateWebhook subscribes an API client. apiKeyID scopes every method to
+// one client; it is empty only for admins, who name the client in the
+// payload instead. The signing secret is returned here and never again.
+func (s *WebhookServiceImpl) CreateWebhook(ctx context.Context, apiKeyID, createdBy string, payload models.CreateWebhookPayload) (int, *models.CreateWebhookResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	if err := s.sender.ValidateURL(payload.URL); err != nil {
+		return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_INVALID_WEBHOOK_URL.Code,
+			Message:   messages.ERR_INVALID_WEBHOOK_URL.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	eventTypes := models.StringList{}
+	for _, t := range payload.EventTypes {
+		if !slices.Contains(events.Types, t) {
+			return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+				MessageID: messages.ERR_INVALID_WEBHOOK_EVENT.Code,
+				Message:   messages.ERR_INVALID_WEBHOOK_EVENT.Text,
+				Exception: fmt.Sprintf("event type %q must be one of %s", t, strings.Join(events.Types, ", ")),
+			}
+		}
+		if !eventTypes.Contains(t) {
+			eventTypes = append(eventTypes, t)
+		}
+	}
+
+	if apiKeyID == "" {
+		apiKeyID = payload.APIKeyID
+	}
+	var key models.APIKey
+	if _, err := uuid.Parse(apiKeyID); err == nil {
+		if err := db.Where("id = ? AND revoked_at IS NULL", apiKeyID).Limit(1).Find(&key).Error; err != nil {
+			return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+				MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+				Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+				Exception: err.Error(),
+			}
+		}
+	}
+	if key.ID == uuid.Nil {
+		return fiber.StatusNotFound, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_API_KEY_NOT_FOUND.Code,
+			Message:   messages.ERR_API_KEY_NOT_FOUND.Text,
+			Exception: "api_key_id must name an active API key",
+		}
+	}
+
+	creatorID, err := uuid.Parse(createdBy)
+	if err != nil {
+		return fiber.StatusUnauthorized, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNAUTHORIZED.Code,
+			Message:   messages.ERR_UNAUTHORIZED.Text,
+			Exception: "invalid creator ID",
+		}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	subscription := models.WebhookSubscription{
+		APIKeyID:   key.ID,
+		URL:        payload.URL,
+		EventTypes: eventTypes,
+		Secret:     "whsec_" + hex.EncodeToString(secret),
+		CreatedBy:  creatorID,
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&subscription).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditWebhookCreate, "webhook", subscription.ID.String(), nil, subscription)
+	})
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	return fiber.StatusCreated, &models.CreateWebhookResponse{
+		WebhookResponse: toWebhookResponse(subscription),
+		Secret:          subscription.Secret,
+	}, nil
+}
+
+func (s *WebhookServiceImpl) ListWebhooks(ctx context.Context, apiKeyID string) (int, *[]models.WebhookResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	query := db.Model(&models.WebhookSubscription{})
+	if apiKeyID != "" {
+		query = query.Where("api_key_id = ?", apiKeyID)
+	}
+
+	var subscriptions []models.WebhookSubscription
+	if err := query.Order("created_at DESC").Find(&subscriptions).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := []models.WebhookResponse{}
+	for _, sub := range subscriptions {
+		response = append(response, toWebhookResponse(sub))
+	}
+
+	return fiber.StatusOK, &response, nil
+}
+
+// DeleteWebhook stops new deliveries. Queued ones give up on their next
+// attempt; the delivery log is kept.
+func (s *WebhookServiceImpl) DeleteWebhook(ctx context.Context, webhookID, apiKeyID string) (int, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	statusCode, subscription, errResp := findWebhook(db, webhookID, apiKeyID)
+	if errResp != nil {
+		return statusCode, errResp
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(subscription).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, AuditWebhookDelete, "webhook", webhookID, *subscription, nil)
+	})
+	if err != nil {
+		return fiber.StatusInternalServerError, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	return fiber.StatusNoContent, nil
+}
+
+func (s *WebhookServiceImpl) ListWebhookDeliveries(ctx context.Context, webhookID, apiKeyID string, filter models.WebhookDeliveryFilter) (int, *[]models.WebhookDeliveryResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	statusCode, subscription, errResp := findWebhook(db, webhookID, apiKeyID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	query := db.Where("subscription_id = ?", subscription.ID)
+	if filter.Status != "" {
+		if !slices.Contains(webhookDeliveryStatuses, filter.Status) {
+			return fiber.StatusBadRequest, nil, &models.ErrorResponse{
+				MessageID: messages.ERR_BAD_REQUEST.Code,
+				Message:   messages.ERR_BAD_REQUEST.Text,
+				Exception: "status must be one of " + strings.Join(webhookDeliveryStatuses, ", "),
+			}
+		}
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = webhookDefaultPageSize
+	}
+	if limit > webhookMaxPageSize {
+		limit = webhookMaxPageSize
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := query.Order("created_at DESC").Limit(limit).Offset(max(filter.Offset, 0)).Find(&deliveries).Error; err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := []models.WebhookDeliveryResponse{}
+	for _, d := range deliveries {
+		response = append(response, toWebhookDeliveryResponse(d))
+	}
+
+	return fiber.StatusOK, &response, nil
+}
+
+// ReplayWebhookDelivery sends a past delivery's payload again as a new
+// delivery, whatever became of the original. The event ID is unchanged so
+// receivers that dedupe still can.
+func (s *WebhookServiceImpl) ReplayWebhookDelivery(ctx context.Context, webhookID, deliveryID, apiKeyID string) (int, *models.WebhookDeliveryResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	statusCode, subscription, errResp := findWebhook(db, webhookID, apiKeyID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	var original models.WebhookDelivery
+	if _, err := uuid.Parse(deliveryID); err == nil {
+		if err := db.Where("id = ? AND subscription_id = ?", deliveryID, subscription.ID).Limit(1).Find(&original).Error; err != nil {
+			return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+				MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+				Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+				Exception: err.Error(),
+			}
+		}
+	}
+	if original.ID == uuid.Nil {
+		return fiber.StatusNotFound, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_WEBHOOK_DELIVERY_NOT_FOUND.Code,
+			Message:   messages.ERR_WEBHOOK_DELIVERY_NOT_FOUND.Text,
+			Exception: "webhook delivery not found",
+		}
+	}
+
+	delivery := models.WebhookDelivery{
+		SubscriptionID: subscription.ID,
+		EventID:        original.EventID,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		Status:         models.WebhookDeliveryPending,
+		ReplayOfID:     &original.ID,
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := queueWebhookDelivery(tx, &delivery); err != nil {
+			return err
+		}
+		// The payload stays out of the audit trail; it may carry personal data.
+		return recordAudit(ctx, tx, AuditWebhookReplay, "webhook_delivery", delivery.ID.String(), nil, models.JSONMap{
+			"subscription_id": delivery.SubscriptionID,
+			"event_id":        delivery.EventID,
+			"event_type":      delivery.EventType,
+			"replay_of_id":    delivery.ReplayOfID,
+		})
+	})
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := toWebhookDeliveryResponse(delivery)
+	return fiber.StatusAccepted, &response, nil
+}
+
+// PingWebhook queues a webhook.ping delivery, so a client can check its
+// endpoint and signature verification without waiting for a real event.
+func (s *WebhookServiceImpl) PingWebhook(ctx context.Context, webhookID, apiKeyID string) (int, *models.WebhookDeliveryResponse, *models.ErrorResponse) {
+	db := s.db.WithContext(ctx)
+
+	statusCode, subscription, errResp := findWebhook(db, webhookID, apiKeyID)
+	if errResp != nil {
+		return statusCode, nil, errResp
+	}
+
+	msg := events.Message{
+		ID:            uuid.New(),
+		Type:          webhookPing,
+		AggregateType: "webhook",
+		AggregateID:   webhookID,
+		OccurredAt:    time.Now().UTC(),
+		Data:          models.JSONMap{"webhook_id": subscription.ID},
+	}
+	payload, err := webhookPayload(msg)
+	if err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	delivery := models.WebhookDelivery{
+		SubscriptionID: subscription.ID,
+		EventID:        msg.ID,
+		EventType:      msg.Type,
+		Payload:        payload,
+		Status:         models.WebhookDeliveryPending,
+	}
+	if err := queueWebhookDelivery(db, &delivery); err != nil {
+		return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+			Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+			Exception: err.Error(),
+		}
+	}
+
+	response := toWebhookDeliveryResponse(delivery)
+	return fiber.StatusAccepted, &response, nil
+}
+
+// Publish queues a delivery of msg to every subscription that wants it. The
+// relay may hand over the same event twice; the unique index on (subscription,
+// event) turns the repeat into a no-op.
+func (s *WebhookServiceImpl) Publish(ctx context.Context, msg events.Message) error {
+	db := s.db.WithContext(ctx)
+
+	var targets []struct {
+		models.WebhookSubscription
+		OwnerID   uuid.UUID
+		OwnerRole string
+	}
+	if err := db.Model(&models.WebhookSubscription{}).
+		Select("webhook_subscriptions.*, api_keys.user_id AS owner_id, users.role AS owner_role").
+		Joins("JOIN api_keys ON api_keys.id = webhook_subscriptions.api_key_id").
+		Joins("JOIN users ON users.id = api_keys.user_id AND users.deleted_at IS NULL").
+		Where("api_keys.revoked_at IS NULL AND (api_keys.expires_at IS NULL OR api_keys.expires_at > ?)", time.Now()).
+		Scan(&targets).Error; err != nil {
+		return err
+	}
+
+	payload, err := webhookPayload(msg)
+	if err != nil {
+		return err
+	}
+	subject, _ := msg.Data["user_id"].(string)
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, t := range targets {
+			if len(t.EventTypes) > 0 && !t.EventTypes.Contains(msg.Type) {
+				continue
+			}
+			if t.OwnerRole != "admin" && subject != t.OwnerID.String() {
+				continue
+			}
+			delivery := models.WebhookDelivery{
+				SubscriptionID: t.ID,
+				EventID:        msg.ID,
+				EventType:      msg.Type,
+				Payload:        payload,
+				Status:         models.WebhookDeliveryPending,
+			}
+			if err := queueWebhookDelivery(tx, &delivery); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *WebhookServiceImpl) Close() error {
+	return nil
+}
+
+// DeliverWebhook makes one attempt at a delivery and logs the outcome on it.
+// Returning an error has the job queue retry later with backoff.
+func (s *WebhookServiceImpl) DeliverWebhook(ctx context.Context, job *models.Job) error {
+	db := s.db.WithContext(ctx)
+
+	deliveryID, _ := job.Payload["delivery_id"].(string)
+	var delivery models.WebhookDelivery
+	if err := db.Where("id = ?", deliveryID).Limit(1).Find(&delivery).Error; err != nil {
+		return err
+	}
+	if delivery.ID == uuid.Nil {
+		return fmt.Errorf("%w: webhook delivery %q not found", jobs.ErrPermanent, deliveryID)
+	}
+
+	var subscription models.WebhookSubscription
+	if err := db.Where("id = ?", delivery.SubscriptionID).Limit(1).Find(&subscription).Error; err != nil {
+		return err
+	}
+	if subscription.ID == uuid.Nil {
+		if err := db.Model(&delivery).Updates(map[string]any{
+			"status":     models.WebhookDeliveryFailed,
+			"last_error": "webhook was deleted",
+		}).Error; err != nil {
+			return err
+		}
+		return fmt.Errorf("%w: webhook %s was deleted", jobs.ErrPermanent, delivery.SubscriptionID)
+	}
+
+	body, err := json.Marshal(delivery.Payload)
+	if err != nil {
+		return fmt.Errorf("%w: %s", jobs.ErrPermanent, err.Error())
+	}
+	resp, sendErr := s.sender.Send(ctx, webhooks.Request{
+		URL:        subscription.URL,
+		Secret:     subscription.Secret,
+		EventType:  delivery.EventType,
+		EventID:    delivery.EventID.String(),
+		DeliveryID: delivery.ID.String(),
+		Body:       body,
+	})
+
+	updates := map[string]any{
+		"attempts":        delivery.Attempts + 1,
+		"response_status": 0,
+		"response_body":   "",
+		"duration_millis": int64(0),
+		"last_error":      "",
+	}
+	if resp != nil {
+		updates["response_status"] = resp.StatusCode
+		updates["response_body"] = resp.Body
+		updates["duration_millis"] = resp.Duration.Milliseconds()
+	}
+	switch {
+	case sendErr == nil:
+		updates["status"] = models.WebhookDeliverySucceeded
+		updates["delivered_at"] = time.Now()
+	case job.Attempts >= job.MaxAttempts:
+		updates["status"] = models.WebhookDeliveryFailed
+		updates["last_error"] = sendErr.Error()
+	default:
+		updates["status"] = models.WebhookDeliveryRetrying
+		updates["last_error"] = sendErr.Error()
+	}
+	if err := db.Model(&delivery).Updates(updates).Error; err != nil {
+		return err
+	}
+
+	if sendErr != nil {
+		return fmt.Errorf("%w to %s: %s", errWebhookDeliveryFailed, subscription.URL, sendErr.Error())
+	}
+	return nil
+}
+
+// queueWebhookDelivery saves a delivery and the job that sends it, unless
+// the event was already delivered to the subscription.
+func queueWebhookDelivery(tx *gorm.DB, delivery *models.WebhookDelivery) error {
+	result := tx.Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "subscription_id"}, {Name: "event_id"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "replay_of_id IS NULL"}}},
+		DoNothing:   true,
+	}).Create(delivery)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+	_, err := jobs.Enqueue(tx, WebhookDeliveryJob, models.JSONMap{"delivery_id": delivery.ID.String()},
+		jobs.Options{MaxAttempts: webhookMaxAttempts})
+	return err
+}
+
+// webhookPayload is the body posted to receivers: the event envelope as
+// published to the broker.
+// PruneWebhookDeliveries deletes finished deliveries older than the retention
+// period, in chunks like PruneReadings. Each carries a copy of its event, so
+// keeping them for replay only as long as that is useful keeps personal data
+// in events from piling up.
+func (s *WebhookServiceImpl) PruneWebhookDeliveries(ctx context.Context) (int64, error) {
+	db := s.db.WithContext(ctx)
+
+	cutoff := time.Now().Add(-webhookDeliveryRetention)
+	var pruned int64
+	for {
+		result := db.Exec(`DELETE FROM webhook_deliveries WHERE id IN (
+			SELECT id FROM webhook_deliveries WHERE status IN ? AND created_at < ? LIMIT 10000)`,
+			[]string{models.WebhookDeliverySucceeded, models.WebhookDeliveryFailed}, cutoff)
+		if result.Error != nil {
+			return pruned, result.Error
+		}
+		pruned += result.RowsAffected
+		if result.RowsAffected == 0 {
+			return pruned, nil
+		}
+	}
+}
+
+func webhookPayload(msg events.Message) (models.JSONMap, error) {
+	raw, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	payload := models.JSONMap{}
+	return payload, json.Unmarshal(raw, &payload)
+}
+
+func findWebhook(db *gorm.DB, webhookID, apiKeyID string) (int, *models.WebhookSubscription, *models.ErrorResponse) {
+	var subscription models.WebhookSubscription
+	if _, err := uuid.Parse(webhookID); err == nil {
+		query := db.Where("id = ?", webhookID)
+		if apiKeyID != "" {
+			query = query.Where("api_key_id = ?", apiKeyID)
+		}
+		if err := query.Limit(1).Find(&subscription).Error; err != nil {
+			return fiber.StatusInternalServerError, nil, &models.ErrorResponse{
+				MessageID: messages.ERR_UNEXPECTED_ERROR.Code,
+				Message:   messages.ERR_UNEXPECTED_ERROR.Text,
+				Exception: err.Error(),
+			}
+		}
+	}
+	if subscription.ID == uuid.Nil {
+		return fiber.StatusNotFound, nil, &models.ErrorResponse{
+			MessageID: messages.ERR_WEBHOOK_NOT_FOUND.Code,
+			Message:   messages.ERR_WEBHOOK_NOT_FOUND.Text,
+			Exception: "webhook not found",
+		}
+	}
+	return fiber.StatusOK, &subscription, nil
+}
+
+func toWebhookResponse(w models.WebhookSubscription) models.WebhookResponse {
+	return models.WebhookResponse{
+		ID:         w.ID,
+		APIKeyID:   w.APIKeyID,
+		URL:        w.URL,
+		EventTypes: w.EventTypes,
+		CreatedAt:  w.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func toWebhookDeliveryResponse(d models.WebhookDelivery) models.WebhookDeliveryResponse {
+	resp := models.WebhookDeliveryResponse{
+		ID:             d.ID,
+		SubscriptionID: d.SubscriptionID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Payload:        d.Payload,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		ResponseStatus: d.ResponseStatus,
+		ResponseBody:   d.ResponseBody,
+		LastError:      d.LastError,
+		DurationMillis: d.DurationMillis,
+		ReplayOfID:     d.ReplayOfID,
+		CreatedAt:      d.CreatedAt.Format(time.RFC3339),
+	}
+	if d.DeliveredAt != nil {
+		resp.DeliveredAt = d.DeliveredAt.Format(time.RFC3339)
+	}
+	return resp
+}
@@ -0,0 +1,322 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"vehix/core/database/dbtest"
+	"vehix/core/events"
+	"vehix/core/payments"
+	"vehix/core/webhooks"
+	"vehix/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// receivedDelivery is one request made to a webhookReceiver.
+type receivedDelivery struct {
+	header http.Header
+	body   []byte
+}
+
+// webhookReceiver answers the queued statuses in turn, then 204, and keeps
+// every request it gets.
+type webhookReceiver struct {
+	mu       sync.Mutex
+	statuses []int
+	received []receivedDelivery
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.received = append(r.received, receivedDelivery{header: req.Header.Clone(), body: body})
+	status := http.StatusNoContent
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (r *webhookReceiver) deliveries() []receivedDelivery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]receivedDelivery(nil), r.received...)
+}
+
+type webhookFixture struct {
+	db           *gorm.DB
+	svc          WebhookService
+	receiver     *webhookReceiver
+	subscription models.WebhookSubscription
+}
+
+func newWebhookFixture(t *testing.T, statuses ...int) *webhookFixture {
+	t.Helper()
+	db := dbtest.Open(t)
+	receiver := &webhookReceiver{statuses: statuses}
+	srv := httptest.NewServer(receiver)
+	t.Cleanup(srv.Close)
+
+	admin := createTestUser(t, db, models.RoleAdmin)
+	key := models.APIKey{
+		Name:         "test",
+		Prefix:       uuid.NewString()[:8],
+		KeyHash:      strings.Repeat("0", 64),
+		Organization: "Test",
+		UserID:       admin.ID,
+		CreatedBy:    admin.ID,
+	}
+	if err := db.Create(&key).Error; err != nil {
+		t.Fatalf("creating API key: %v", err)
+	}
+	subscription := models.WebhookSubscription{
+		APIKeyID:  key.ID,
+		URL:       srv.URL,
+		Secret:    "whsec_test",
+		CreatedBy: admin.ID,
+	}
+	if err := db.Create(&subscription).Error; err != nil {
+		t.Fatalf("creating subscription: %v", err)
+	}
+
+	return &webhookFixture{
+		db:           db,
+		svc:          NewWebhookService(db, webhooks.NewSender(true)),
+		receiver:     receiver,
+		subscription: subscription,
+	}
+}
+
+func (f *webhookFixture) publish(t *testing.T) events.Message {
+	t.Helper()
+	msg := events.Message{
+		ID:            uuid.New(),
+		Type:          events.RentalCreated,
+		AggregateType: "rental",
+		AggregateID:   uuid.NewString(),
+		OccurredAt:    time.Now().UTC(),
+		Data:          models.JSONMap{"status": models.RentalStatusConfirmed},
+	}
+	if err := f.svc.Publish(context.Background(), msg); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	return msg
+}
+
+func (f *webhookFixture) delivery(t *testing.T, id uuid.UUID) models.WebhookDelivery {
+	t.Helper()
+	var d models.WebhookDelivery
+	if err := f.db.First(&d, "id = ?", id).Error; err != nil {
+		t.Fatalf("loading delivery: %v", err)
+	}
+	return d
+}
+
+// attempt runs the delivery's job once, as a worker that has just claimed it
+// would.
+func (f *webhookFixture) attempt(t *testing.T, deliveryID uuid.UUID) (*models.Job, error) {
+	t.Helper()
+	var job models.Job
+	if err := f.db.Where("kind = ? AND payload->>'delivery_id' = ?", WebhookDeliveryJob, deliveryID.String()).
+		First(&job).Error; err != nil {
+		t.Fatalf("loading delivery job: %v", err)
+	}
+	job.Attempts++
+	if err := f.db.Model(&job).Update("attempts", job.Attempts).Error; err != nil {
+		t.Fatalf("counting attempt: %v", err)
+	}
+	return &job, f.svc.DeliverWebhook(context.Background(), &job)
+}
+
+func TestWebhookDeliveryRetriesUntilAccepted(t *testing.T) {
+	f := newWebhookFixture(t, http.StatusServiceUnavailable, http.StatusInternalServerError)
+	msg := f.publish(t)
+	// The relay may hand over the same event again; it's delivered once.
+	if err := f.svc.Publish(context.Background(), msg); err != nil {
+		t.Fatalf("Publish again: %v", err)
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := f.db.Where("event_id = ?", msg.ID).Find(&deliveries).Error; err != nil {
+		t.Fatalf("loading deliveries: %v", err)
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("%d deliveries queued for one event, want 1", len(deliveries))
+	}
+	id := deliveries[0].ID
+
+	for attempt, status := range []int{http.StatusServiceUnavailable, http.StatusInternalServerError} {
+		if _, err := f.attempt(t, id); err == nil {
+			t.Fatalf("attempt %d succeeded against a %d", attempt+1, status)
+		}
+		d := f.delivery(t, id)
+		if d.Status != models.WebhookDeliveryRetrying || d.Attempts != attempt+1 || d.ResponseStatus != status {
+			t.Errorf("after attempt %d: status %s, %d attempts, response %d; want retrying, %d, %d",
+				attempt+1, d.Status, d.Attempts, d.ResponseStatus, attempt+1, status)
+		}
+		if d.LastError == "" {
+			t.Errorf("after attempt %d: no error recorded", attempt+1)
+		}
+	}
+
+	if _, err := f.attempt(t, id); err != nil {
+		t.Fatalf("third attempt: %v", err)
+	}
+	d := f.delivery(t, id)
+	if d.Status != models.WebhookDeliverySucceeded || d.Attempts != 3 || d.DeliveredAt == nil || d.LastError != "" {
+		t.Errorf("after success: %+v", d)
+	}
+
+	received := f.receiver.deliveries()
+	if len(received) != 3 {
+		t.Fatalf("receiver got %d requests, want 3", len(received))
+	}
+	last := received[2]
+	if err := payments.VerifySignature(f.subscription.Secret, last.body, last.header.Get(webhooks.SignatureHeader), time.Now()); err != nil {
+		t.Errorf("signature: %v", err)
+	}
+	if got := last.header.Get(webhooks.EventIDHeader); got != msg.ID.String() {
+		t.Errorf("%s = %q, want %s", webhooks.EventIDHeader, got, msg.ID)
+	}
+	var envelope events.Message
+	if err := json.Unmarshal(last.body, &envelope); err != nil || envelope.ID != msg.ID || envelope.Type != msg.Type {
+		t.Errorf("body = %s, want the event envelope", last.body)
+	}
+}
+
+func TestWebhookDeliveryFailsAfterLastAttempt(t *testing.T) {
+	f := newWebhookFixture(t, http.StatusBadGateway)
+	msg := f.publish(t)
+
+	var d models.WebhookDelivery
+	if err := f.db.Where("event_id = ?", msg.ID).First(&d).Error; err != nil {
+		t.Fatalf("loading delivery: %v", err)
+	}
+	var job models.Job
+	if err := f.db.Where("payload->>'delivery_id' = ?", d.ID.String()).First(&job).Error; err != nil {
+		t.Fatalf("loading job: %v", err)
+	}
+	if job.MaxAttempts != webhookMaxAttempts {
+		t.Errorf("job allows %d attempts, want %d", job.MaxAttempts, webhookMaxAttempts)
+	}
+
+	// The job queue's last go.
+	job.Attempts = job.MaxAttempts
+	if err := f.svc.DeliverWebhook(context.Background(), &job); err == nil {
+		t.Fatal("DeliverWebhook succeeded against a 502")
+	}
+	if d = f.delivery(t, d.ID); d.Status != models.WebhookDeliveryFailed || d.ResponseStatus != http.StatusBadGateway {
+		t.Errorf("after the last attempt: status %s, response %d; want failed, 502", d.Status, d.ResponseStatus)
+	}
+}
+
+func TestReplayWebhookDelivery(t *testing.T) {
+	f := newWebhookFixture(t)
+	msg := f.publish(t)
+
+	var original models.WebhookDelivery
+	if err := f.db.Where("event_id = ?", msg.ID).First(&original).Error; err != nil {
+		t.Fatalf("loading delivery: %v", err)
+	}
+	if _, err := f.attempt(t, original.ID); err != nil {
+		t.Fatalf("delivering: %v", err)
+	}
+
+	// Only the owning API key, or an admin, may replay.
+	if status, _, errResp := f.svc.ReplayWebhookDelivery(context.Background(), f.subscription.ID.String(),
+		original.ID.String(), uuid.NewString()); errResp == nil || status != http.StatusNotFound {
+		t.Errorf("replay with another API key: status %d, want 404", status)
+	}
+
+	for i := 0; i < 2; i++ {
+		status, resp, errResp := f.svc.ReplayWebhookDelivery(context.Background(), f.subscription.ID.String(),
+			original.ID.String(), f.subscription.APIKeyID.String())
+		if errResp != nil || status != http.StatusAccepted {
+			t.Fatalf("replay %d: %d %+v", i+1, status, errResp)
+		}
+		if resp.ID == original.ID || resp.EventID != msg.ID || resp.ReplayOfID == nil || *resp.ReplayOfID != original.ID {
+			t.Errorf("replay %d = %+v, want a new delivery of the same event", i+1, resp)
+		}
+		if _, err := f.attempt(t, resp.ID); err != nil {
+			t.Fatalf("delivering replay %d: %v", i+1, err)
+		}
+		if d := f.delivery(t, resp.ID); d.Status != models.WebhookDeliverySucceeded {
+			t.Errorf("replay %d: status %s, want succeeded", i+1, d.Status)
+		}
+	}
+
+	received := f.receiver.deliveries()
+	if len(received) != 3 {
+		t.Fatalf("receiver got %d requests, want the original and two replays", len(received))
+	}
+	seen := map[string]bool{}
+	for _, r := range received {
+		if got := r.header.Get(webhooks.EventIDHeader); got != msg.ID.String() {
+			t.Errorf("replayed %s = %q, want the original event ID", webhooks.EventIDHeader, got)
+		}
+		if string(r.body) != string(received[0].body) {
+			t.Errorf("replayed body %s differs from the original %s", r.body, received[0].body)
+		}
+		seen[r.header.Get(webhooks.DeliveryIDHeader)] = true
+	}
+	if len(seen) != 3 {
+		t.Errorf("delivery IDs %v, want one per delivery", seen)
+	}
+}
+
+func TestPruneWebhookDeliveries(t *testing.T) {
+	f := newWebhookFixture(t)
+	old := time.Now().Add(-webhookDeliveryRetention - time.Hour)
+
+	tests := []struct {
+		name    string
+		status  string
+		created time.Time
+		pruned  bool
+	}{
+		{"old succeeded", models.WebhookDeliverySucceeded, old, true},
+		{"old failed", models.WebhookDeliveryFailed, old, true},
+		{"old still retrying", models.WebhookDeliveryRetrying, old, false},
+		{"recent succeeded", models.WebhookDeliverySucceeded, time.Now(), false},
+	}
+	ids := make([]uuid.UUID, len(tests))
+	for i, tt := range tests {
+		delivery := models.WebhookDelivery{
+			SubscriptionID: f.subscription.ID,
+			EventID:        uuid.New(),
+			EventType:      events.RentalCreated,
+			Status:         tt.status,
+			CreatedAt:      tt.created,
+		}
+		if err := f.db.Create(&delivery).Error; err != nil {
+			t.Fatalf("creating %s delivery: %v", tt.name, err)
+		}
+		ids[i] = delivery.ID
+	}
+
+	pruned, err := f.svc.PruneWebhookDeliveries(context.Background())
+	if err != nil {
+		t.Fatalf("PruneWebhookDeliveries: %v", err)
+	}
+	if pruned != 2 {
+		t.Errorf("pruned %d deliveries, want 2", pruned)
+	}
+	for i, tt := range tests {
+		var n int64
+		if err := f.db.Model(&models.WebhookDelivery{}).Where("id = ?", ids[i]).Count(&n).Error; err != nil {
+			t.Fatalf("counting deliveries: %v", err)
+		}
+		if (n == 0) != tt.pruned {
+			t.Errorf("%s delivery: pruned %v, want %v", tt.name, n == 0, tt.pruned)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalStoreRoundTrip(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewLocalStore(root)
+	if err != nil {
+		t.Fatalf("NewLocalStore: %v", err)
+	}
+	ctx := context.Background()
+
+	if err := store.Put(ctx, "vehicles/abc/photo.jpg", []byte("jpeg"), "image/jpeg"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "vehicles", "abc", "photo.jpg")); err != nil {
+		t.Errorf("blob not stored below root: %v", err)
+	}
+
+	rc, err := store.Get(ctx, "vehicles/abc/photo.jpg")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	data, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(data) != "jpeg" {
+		t.Errorf("Get = %q, want %q", data, "jpeg")
+	}
+
+	if err := store.Delete(ctx, "vehicles/abc/photo.jpg"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Get(ctx, "vehicles/abc/photo.jpg"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete: err = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete(ctx, "vehicles/abc/photo.jpg"); err != nil {
+		t.Errorf("Delete of a missing blob: %v", err)
+	}
+}
+
+func TestLocalStoreRejectsTraversal(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "blobs")
+	store, err := NewLocalStore(root)
+	if err != nil {
+		t.Fatalf("NewLocalStore: %v", err)
+	}
+	secret := filepath.Join(parent, "secret.txt")
+	if err := os.WriteFile(secret, []byte("secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	for _, key := range []string{
+		"../secret.txt",
+		"vehicles/../../secret.txt",
+		"vehicles/..",
+		"..",
+		"",
+		"/",
+	} {
+		if err := store.Put(ctx, key, []byte("x"), ""); err == nil {
+			t.Errorf("Put(%q) succeeded, want an error", key)
+		}
+		if rc, err := store.Get(ctx, key); err == nil {
+			rc.Close()
+			t.Errorf("Get(%q) succeeded, want an error", key)
+		}
+		if err := store.Delete(ctx, key); err == nil {
+			t.Errorf("Delete(%q) succeeded, want an error", key)
+		}
+	}
+
+	if data, err := os.ReadFile(secret); err != nil || string(data) != "secret" {
+		t.Errorf("file outside root was touched: %q, %v", data, err)
+	}
+}
+
+func TestLocalStoreAbsoluteKeyStaysInRoot(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewLocalStore(root)
+	if err != nil {
+		t.Fatalf("NewLocalStore: %v", err)
+	}
+
+	path, err := store.path("/etc/passwd")
+	if err != nil {
+		t.Fatalf("path: %v", err)
+	}
+	if !strings.HasPrefix(path, root+string(filepath.Separator)) {
+		t.Errorf("path(%q) = %q, want a path below %q", "/etc/passwd", path, root)
+	}
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+)
+
+// ErrNotFound is returned by Get when no object exists under the key.
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore keeps uploaded files outside the database. Keys are slash
+// separated paths chosen by the caller.
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Connect builds the store selected by BLOB_STORE ("local", the default, or
+// "s3") from the environment.
+func Connect() BlobStore {
+	switch os.Getenv("BLOB_STORE") {
+	case "", "local":
+		root := os.Getenv("BLOB_LOCAL_ROOT")
+		if root == "" {
+			root = "./data/blobs"
+		}
+		store, err := NewLocalStore(root)
+		if err != nil {
+			log.Fatal("failed to initialise local blob store:", err)
+		}
+		return store
+	case "s3":
+		store, err := NewS3Store(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+		})
+		if err != nil {
+			log.Fatal("failed to initialise S3 blob store:", err)
+		}
+		return store
+	default:
+		log.Fatalf("unknown BLOB_STORE %q", os.Getenv("BLOB_STORE"))
+		return nil
+	}
+}
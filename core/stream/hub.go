@@ -0,0 +1,71 @@
+package stream
+
+import (
+	"sync"
+	"vehix/core/events"
+)
+
+// subscriberBuffer is how many messages a subscriber may fall behind by
+// before it is dropped.
+const subscriberBuffer = 256
+
+type subscriber struct {
+	ch    chan events.Message
+	match func(events.Message) bool
+}
+
+// Hub fans events out to the streams open on this process. A subscriber that
+// falls too far behind is dropped, its channel closed, rather than holding up
+// everyone else; clients reconnect and catch up from where they left off.
+type Hub struct {
+	mu   sync.Mutex
+	subs map[*subscriber]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{subs: map[*subscriber]struct{}{}}
+}
+
+// Subscribe returns a channel of the events match accepts, and a function
+// that ends the subscription. The channel is closed when the subscription
+// ends, by either side.
+func (h *Hub) Subscribe(match func(events.Message) bool) (<-chan events.Message, func()) {
+	sub := &subscriber{ch: make(chan events.Message, subscriberBuffer), match: match}
+	h.mu.Lock()
+	h.subs[sub] = struct{}{}
+	h.mu.Unlock()
+	return sub.ch, func() { h.remove(sub) }
+}
+
+// Broadcast hands msg to every subscriber that wants it, without blocking.
+func (h *Hub) Broadcast(msg events.Message) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subs {
+		if !sub.match(msg) {
+			continue
+		}
+		select {
+		case sub.ch <- msg:
+		default:
+			delete(h.subs, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// Subscribers reports how many streams are open.
+func (h *Hub) Subscribers() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs)
+}
+
+func (h *Hub) remove(sub *subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subs[sub]; ok {
+		delete(h.subs, sub)
+		close(sub.ch)
+	}
+}
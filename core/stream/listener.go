@@ -0,0 +1,70 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+	"vehix/core/events"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	"vehix/models"
+
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+// Channel is the Postgres notification channel a trigger on outbox_events
+// signals each new row's ID on. Notifications are delivered when the
+// inserting transaction commits, and never for one that rolls back.
+const Channel = "outbox_events"
+
+// Listen feeds hub with every event written to the outbox, whichever replica
+// wrote it, until ctx is done. It holds a connection of its own for LISTEN
+// and reconnects after errors; events written while it was disconnected are
+// not replayed here, clients catch up from the outbox when they reconnect.
+func Listen(ctx context.Context, db *gorm.DB, dsn string, hub *Hub) {
+	backoff := time.Second
+	for ctx.Err() == nil {
+		err := listen(ctx, db, dsn, hub)
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Error(fmt.Sprintf("[%s] %s: %v", messages.ERR_STREAM_LISTEN.Code, messages.ERR_STREAM_LISTEN.Text, err))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, time.Minute)
+	}
+}
+
+func listen(ctx context.Context, db *gorm.DB, dsn string, hub *Hub) error {
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{Channel}.Sanitize()); err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		id, err := strconv.ParseUint(notification.Payload, 10, 64)
+		if err != nil {
+			continue
+		}
+		var event models.OutboxEvent
+		if err := db.WithContext(ctx).Where("id = ?", id).Limit(1).Find(&event).Error; err != nil {
+			return err
+		}
+		if event.ID != 0 {
+			hub.Broadcast(events.FromOutbox(&event))
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package webhooks
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+	"vehix/core/logger"
+	"vehix/core/messages"
+	"vehix/core/payments"
+)
+
+// Receiver is a stand-in subscriber endpoint for trying webhooks locally. It
+// checks each delivery's signature against secret, logs it and answers 204,
+// or 401 if the signature doesn't match.
+func Receiver(secret string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if err := payments.VerifySignature(secret, body, r.Header.Get(SignatureHeader), time.Now()); err != nil {
+			logger.Error(fmt.Sprintf("[%s] %s: delivery %s: %s", messages.ERR_WEBHOOK_SIGNATURE.Code,
+				messages.ERR_WEBHOOK_SIGNATURE.Text, r.Header.Get(DeliveryIDHeader), err.Error()))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		logger.Info(fmt.Sprintf("[%s] %s: %s %s (delivery %s): %s", messages.INFO_WEBHOOK_RECEIVED.Code,
+			messages.INFO_WEBHOOK_RECEIVED.Text, r.Header.Get(EventTypeHeader), r.Header.Get(EventIDHeader),
+			r.Header.Get(DeliveryIDHeader), body))
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,196 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"syscall"
+	"time"
+	"vehix/core/payments"
+)
+
+// Deliveries are signed like the payment gateway's callbacks to us: the
+// SignatureHeader is "t=<unix seconds>,v1=<hex>", where v1 is the HMAC-SHA256
+// of "<t>.<body>" under the subscription's secret.
+const (
+	SignatureHeader  = payments.SignatureHeader
+	EventTypeHeader  = "Vehix-Event-Type"
+	EventIDHeader    = "Vehix-Event-Id"
+	DeliveryIDHeader = "Vehix-Delivery-Id"
+)
+
+const (
+	maxURLLength      = 2048
+	responseBodyLimit = 2048
+)
+
+var ErrBlockedAddress = errors.New("webhook address is not publicly routable")
+
+// blockedPrefixes are the special-purpose ranges (RFC 6890 and its updates)
+// deliveries may not reach: anything private, local, shared with a provider's
+// own network or embedding an IPv4 address that could be one of these.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "this network"
+	netip.MustParsePrefix("10.0.0.0/8"),      // private
+	netip.MustParsePrefix("100.64.0.0/10"),   // carrier-grade NAT, cloud metadata on some providers
+	netip.MustParsePrefix("127.0.0.0/8"),     // loopback
+	netip.MustParsePrefix("169.254.0.0/16"),  // link-local, cloud metadata
+	netip.MustParsePrefix("172.16.0.0/12"),   // private
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // documentation
+	netip.MustParsePrefix("192.88.99.0/24"),  // 6to4 relay anycast
+	netip.MustParsePrefix("192.168.0.0/16"),  // private
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // documentation
+	netip.MustParsePrefix("224.0.0.0/4"),     // multicast
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved, broadcast
+
+	netip.MustParsePrefix("::/96"),           // unspecified, loopback, IPv4-compatible
+	netip.MustParsePrefix("::ffff:0:0:0/96"), // IPv4-translated
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // local-use NAT64
+	netip.MustParsePrefix("100::/64"),        // discard-only
+	netip.MustParsePrefix("2001::/32"),       // Teredo
+	netip.MustParsePrefix("2001:db8::/32"),   // documentation
+	netip.MustParsePrefix("2002::/16"),       // 6to4
+	netip.MustParsePrefix("fc00::/7"),        // unique local
+	netip.MustParsePrefix("fe80::/10"),       // link-local
+	netip.MustParsePrefix("fec0::/10"),       // site-local
+	netip.MustParsePrefix("ff00::/8"),        // multicast
+}
+
+// blockedAddress reports whether ip falls in one of blockedPrefixes. An
+// IPv4-mapped IPv6 address is checked as the IPv4 address it stands for.
+func blockedAddress(ip netip.Addr) bool {
+	ip = ip.Unmap().WithZone("")
+	if !ip.IsValid() {
+		return true
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+type Request struct {
+	URL        string
+	Secret     string
+	EventType  string
+	EventID    string
+	DeliveryID string
+	Body       []byte
+}
+
+type Response struct {
+	StatusCode int
+	Body       string // the first responseBodyLimit bytes
+	Duration   time.Duration
+}
+
+// Sender posts deliveries to subscriber endpoints. Unless allowLocal is set
+// it only connects to public addresses over HTTPS, checked when dialling so a
+// DNS answer that changes after validation can't point it inward.
+type Sender struct {
+	client     *http.Client
+	allowLocal bool
+}
+
+// Connect builds the sender from the environment. WEBHOOK_ALLOW_LOCAL=true
+// lets webhooks target plain HTTP and private addresses, such as a receiver
+// on localhost.
+func Connect() *Sender {
+	return NewSender(os.Getenv("WEBHOOK_ALLOW_LOCAL") == "true")
+}
+
+func NewSender(allowLocal bool) *Sender {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			if allowLocal {
+				return nil
+			}
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip, err := netip.ParseAddr(host)
+			if err != nil || blockedAddress(ip) {
+				return fmt.Errorf("%w: %s", ErrBlockedAddress, host)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &Sender{
+		allowLocal: allowLocal,
+		client: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: transport,
+			// A redirect is answered like any other non-2xx: as a failure.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// ValidateURL reports why raw can't be used as a webhook endpoint, if it
+// can't.
+func (s *Sender) ValidateURL(raw string) error {
+	if len(raw) > maxURLLength {
+		return fmt.Errorf("url must be at most %d characters", maxURLLength)
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return errors.New("url must be an absolute URL")
+	}
+	if u.User != nil {
+		return errors.New("url must not contain credentials")
+	}
+	if u.Scheme != "https" && !(s.allowLocal && u.Scheme == "http") {
+		return errors.New("url must use https")
+	}
+	return nil
+}
+
+// Send makes one delivery attempt. A response outside 2xx is returned along
+// with an error so its status and body can be logged.
+func (s *Sender) Send(ctx context.Context, r Request) (*Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.URL, bytes.NewReader(r.Body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "vehix-webhooks/1")
+	req.Header.Set(SignatureHeader, payments.SignPayload(r.Secret, r.Body, time.Now()))
+	req.Header.Set(EventTypeHeader, r.EventType)
+	req.Header.Set(EventIDHeader, r.EventID)
+	req.Header.Set(DeliveryIDHeader, r.DeliveryID)
+
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return &Response{Duration: time.Since(start)}, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, responseBodyLimit))
+
+	result := &Response{StatusCode: resp.StatusCode, Body: string(body), Duration: time.Since(start)}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return result, fmt.Errorf("receiver answered %s", resp.Status)
+	}
+	return result, nil
+}
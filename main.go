@@ -1,14 +1,45 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	_ "time/tzdata" // branch timezones must resolve even without a system zoneinfo
+	apiKeyApi "vehix/apis/apikeys"
+	auditApi "vehix/apis/audit"
 	authApis "vehix/apis/auth"
+	branchApi "vehix/apis/branches"
+	driverApi "vehix/apis/drivers"
+	extraApi "vehix/apis/extras"
+	inspectionApi "vehix/apis/inspections"
+	invoiceApi "vehix/apis/invoices"
+	jobApi "vehix/apis/jobs"
+	maintenanceApi "vehix/apis/maintenance"
+	notificationApi "vehix/apis/notifications"
+	paymentApi "vehix/apis/payments"
+	promoApi "vehix/apis/promos"
 	rentalApi "vehix/apis/rentals"
+	streamApi "vehix/apis/stream"
+	telematicsApi "vehix/apis/telematics"
 	userApi "vehix/apis/user"
 	vehicleApi "vehix/apis/vehicles"
+	webhookApi "vehix/apis/webhooks"
 	"vehix/core/database"
+	"vehix/core/events"
+	"vehix/core/jobs"
+	"vehix/core/logger"
+	"vehix/core/messages"
 	"vehix/core/middleware"
+	"vehix/core/notify"
+	"vehix/core/payments"
 	"vehix/core/service"
+	"vehix/core/storage"
+	"vehix/core/stream"
+	"vehix/core/webhooks"
 	"vehix/models"
 
 	"github.com/gofiber/fiber/v2"
@@ -16,20 +47,159 @@ import (
 
 func main() {
 
+	// `vehix webhook-receiver` listens for webhook deliveries and logs the
+	// ones signed with WEBHOOK_RECEIVER_SECRET, for trying webhooks locally.
+	if len(os.Args) > 1 && os.Args[1] == "webhook-receiver" {
+		addr := os.Getenv("WEBHOOK_RECEIVER_ADDR")
+		if addr == "" {
+			addr = ":9090"
+		}
+		log.Fatal(http.ListenAndServe(addr, webhooks.Receiver(os.Getenv("WEBHOOK_RECEIVER_SECRET"))))
+	}
+
 	db := database.Connect()
-	err := db.AutoMigrate(
-		&models.User{},
-		&models.Vehicle{},
-		&models.Rental{},
-	)
-	if err != nil {
+	if err := database.Migrate(db); err != nil {
 		log.Fatalf("Failed to auto-migrate database: %v", err)
 	}
 
 	authService := service.NewAuthService(db)
-	userService := service.NewUserService(db)
+	blobStore := storage.Connect()
+	userService := service.NewUserService(db, blobStore)
+	apiKeyService := service.NewAPIKeyService(db)
+	auditService := service.NewAuditService(db)
+	branchService := service.NewBranchService(db)
+	vehicleService := service.NewVehicleService(db)
+	paymentGateway := payments.Connect()
+	paymentService := service.NewPaymentService(db, paymentGateway)
+	rentalService := service.NewRentalService(db, paymentGateway)
+	mediaService := service.NewMediaService(db, blobStore)
+	maintenanceService := service.NewMaintenanceService(db)
+	inspectionService := service.NewInspectionService(db)
+	invoiceService := service.NewInvoiceService(db, paymentGateway)
+	extraService := service.NewExtraService(db)
+	promoService := service.NewPromoService(db)
+	driverService := service.NewDriverService(db, blobStore)
+	jobService := service.NewJobService(db)
+	webhookService := service.NewWebhookService(db, webhooks.Connect())
+	notificationService := service.NewNotificationService(db, notify.Connect())
+	streamHub := stream.NewHub()
+	streamService := service.NewStreamService(db, streamHub)
+	telematicsService := service.NewTelematicsService(db)
 
-	app := fiber.New()
+	// Background jobs. Recurring work is scheduled in the database so that
+	// only one replica runs each occurrence.
+	jobHandlers := map[string]jobs.Handler{
+		service.WebhookDeliveryJob:  webhookService.DeliverWebhook,
+		service.NotificationSendJob: notificationService.SendNotification,
+		service.PaymentVoidJob:      rentalService.VoidExpiredPayment,
+		"notifications.pickup_reminders": func(ctx context.Context, _ *models.Job) error {
+			count, err := notificationService.QueuePickupReminders(ctx)
+			if err == nil && count > 0 {
+				logger.Info(fmt.Sprintf("[%s] %s: %d", messages.INFO_PICKUP_REMINDERS_QUEUED.Code,
+					messages.INFO_PICKUP_REMINDERS_QUEUED.Text, count))
+			}
+			return err
+		},
+		"users.anonymize_deleted": func(ctx context.Context, _ *models.Job) error {
+			count, err := userService.AnonymizeDeletedUsers(ctx)
+			if err == nil && count > 0 {
+				logger.Info(fmt.Sprintf("[%s] %s: %d", messages.INFO_USER_ANONYMIZE_SUCCESS.Code,
+					messages.INFO_USER_ANONYMIZE_SUCCESS.Text, count))
+			}
+			return err
+		},
+		"maintenance.raise_service_tasks": func(ctx context.Context, _ *models.Job) error {
+			count, err := maintenanceService.RaiseDueServiceTasks(ctx)
+			if err == nil && count > 0 {
+				logger.Info(fmt.Sprintf("[%s] %s: %d", messages.INFO_SERVICE_TASKS_CREATED.Code,
+					messages.INFO_SERVICE_TASKS_CREATED.Text, count))
+			}
+			return err
+		},
+		"rentals.process_overdue": func(ctx context.Context, _ *models.Job) error {
+			count, err := rentalService.ProcessOverdueRentals(ctx)
+			if err == nil && count > 0 {
+				logger.Info(fmt.Sprintf("[%s] %s: %d", messages.INFO_OVERDUE_RENTALS_PROCESSED.Code,
+					messages.INFO_OVERDUE_RENTALS_PROCESSED.Text, count))
+			}
+			return err
+		},
+		"rentals.expire_holds": func(ctx context.Context, _ *models.Job) error {
+			count, err := rentalService.ExpireRentalHolds(ctx)
+			if err == nil && count > 0 {
+				logger.Info(fmt.Sprintf("[%s] %s: %d", messages.INFO_RENTAL_HOLDS_EXPIRED.Code,
+					messages.INFO_RENTAL_HOLDS_EXPIRED.Text, count))
+			}
+			return err
+		},
+		"telematics.prune_readings": func(ctx context.Context, _ *models.Job) error {
+			count, err := telematicsService.PruneReadings(ctx)
+			if err == nil && count > 0 {
+				logger.Info(fmt.Sprintf("[%s] %s: %d", messages.INFO_TELEMATICS_READINGS_PRUNED.Code,
+					messages.INFO_TELEMATICS_READINGS_PRUNED.Text, count))
+			}
+			return err
+		},
+		"webhooks.prune_deliveries": func(ctx context.Context, _ *models.Job) error {
+			count, err := webhookService.PruneWebhookDeliveries(ctx)
+			if err == nil && count > 0 {
+				logger.Info(fmt.Sprintf("[%s] %s: %d", messages.INFO_WEBHOOK_DELIVERIES_PRUNED.Code,
+					messages.INFO_WEBHOOK_DELIVERIES_PRUNED.Text, count))
+			}
+			return err
+		},
+		"rentals.expire_unpaid": func(ctx context.Context, _ *models.Job) error {
+			count, err := rentalService.ExpireUnpaidRentals(ctx)
+			if err == nil && count > 0 {
+				logger.Info(fmt.Sprintf("[%s] %s: %d", messages.INFO_UNPAID_RENTALS_EXPIRED.Code,
+					messages.INFO_UNPAID_RENTALS_EXPIRED.Text, count))
+			}
+			return err
+		},
+	}
+	for _, r := range []struct{ name, schedule, kind string }{
+		{"anonymize-deleted-users", "0 * * * *", "users.anonymize_deleted"},
+		{"raise-service-tasks", "30 * * * *", "maintenance.raise_service_tasks"},
+		{"process-overdue-rentals", "*/5 * * * *", "rentals.process_overdue"},
+		{"expire-unpaid-rentals", "*/5 * * * *", "rentals.expire_unpaid"},
+		{"expire-rental-holds", "* * * * *", "rentals.expire_holds"},
+		{"pickup-reminders", "*/15 * * * *", "notifications.pickup_reminders"},
+		{"prune-telematics-readings", "15 3 * * *", "telematics.prune_readings"},
+		{"prune-webhook-deliveries", "45 3 * * *", "webhooks.prune_deliveries"},
+	} {
+		if err := jobs.Recurring(db, r.name, r.schedule, r.kind, nil); err != nil {
+			log.Fatalf("Failed to schedule %s: %v", r.name, err)
+		}
+	}
+
+	// `vehix worker` runs jobs and relays outbox events only. The API process
+	// does both too unless JOBS_IN_API=false, for deployments with dedicated
+	// workers.
+	if len(os.Args) > 1 && os.Args[1] == "worker" {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		publisher := events.Fanout{events.Connect(), webhookService, notificationService}
+		defer publisher.Close()
+		relayDone := make(chan struct{})
+		go func() {
+			defer close(relayDone)
+			events.NewRelay(db, publisher).Run(ctx)
+		}()
+		jobs.NewWorker(db, jobHandlers).Run(ctx)
+		<-relayDone
+		return
+	}
+	if os.Getenv("JOBS_IN_API") != "false" {
+		go jobs.NewWorker(db, jobHandlers).Run(context.Background())
+		go events.NewRelay(db, events.Fanout{events.Connect(), webhookService, notificationService}).Run(context.Background())
+	}
+	go stream.Listen(context.Background(), db, os.Getenv("DATABASE_URL"), streamHub)
+
+	app := fiber.New(fiber.Config{
+		// Large enough for the biggest media upload plus multipart overhead.
+		BodyLimit: service.MaxDocumentBytes + 1<<20,
+	})
+	app.Use(middleware.RequestContext())
 
 	// API v1 group with middleware
 	v1 := app.Group("/v1")
@@ -40,43 +210,218 @@ func main() {
 	auth.Post("/login", authApis.LoginHandler(authService))                             // POST /v1/auth/login - Login
 	auth.Post("/refresh", authApis.RefreshAccessTokenHandler(authService, userService)) // POST /v1/auth/refresh - Refresh Token
 
+	// Gateway callbacks, authenticated by their signature
+	v1.Post("/payments/webhook", paymentApi.PaymentWebhookHandler(paymentService)) // POST /v1/payments/webhook - Payment gateway events
+
+	// Telematics units, authenticated by their device token
+	v1.Post("/telematics/readings", telematicsApi.PostTelematicsReadingsHandler(telematicsService)) // POST /v1/telematics/readings - Batched odometer, fuel and GPS readings
+
 	// Protected routes
-	v1.Use(middleware.Middleware(authService))
+	v1.Use(middleware.Middleware(authService, apiKeyService))
 	/*
 		=================================================================
 		USER HANDLERS
 		=================================================================
 	*/
-	v1.Get("/me", userApi.GetUserHandler(userService))       // GET 		/v1/me - Get user details
-	v1.Patch("/me", userApi.UpdateUserHandler(userService))  // PATCH 	/v1/me - Update user details
-	v1.Delete("/me", userApi.DeleteUserHandler(userService)) // DELETE	/v1/me - Delete user details
-	v1.Get("/me/rentals", rentalApi.GetAllRentalsHandler)    // GET 		/v1/me/rentals - Get rentals by user
+	v1.Get("/me", userApi.GetUserHandler(userService))                    // GET 		/v1/me - Get user details
+	v1.Patch("/me", userApi.UpdateUserHandler(userService))               // PATCH 	/v1/me - Update user details
+	v1.Delete("/me", userApi.DeleteUserHandler(userService))              // DELETE	/v1/me - Delete user details
+	v1.Get("/me/rentals", rentalApi.GetUserRentalsHandler(rentalService)) // GET 		/v1/me/rentals - Get rentals by user
+	v1.Get("/me/export", userApi.ExportUserDataHandler(userService))      // GET 		/v1/me/export - Export all user data
 
 	// Admin only routes
-	v1.Get("/users", userApi.ListUsersHandler(userService)) // GET	/v1/users - Get all users
+	v1.Get("/users", userApi.ListUsersHandler(userService))                                                 // GET	/v1/users - Get all users
+	v1.Get("/users/:id", userApi.GetUserByIDHandler(userService))                                           // GET	/v1/users/:userID - Get user details
+	v1.Patch("/users/:id", userApi.AdminUpdateUserHandler(userService))                                     // PATCH	/v1/users/:userID - Update user details
+	v1.Delete("/users/:id", userApi.AdminDeleteUserHandler(userService))                                    // DELETE	/v1/users/:userID - Delete user
+	v1.Put("/users/:id/role", userApi.AssignRoleHandler(userService))                                       // PUT	/v1/users/:userID/role - Assign role
+	v1.Post("/users/:id/suspend", userApi.UpdateUserStatusHandler(userService, models.UserStatusSuspended)) // POST	/v1/users/:userID/suspend - Suspend account
+	v1.Post("/users/:id/reactivate", userApi.UpdateUserStatusHandler(userService, models.UserStatusActive)) // POST	/v1/users/:userID/reactivate - Reactivate account
+	v1.Post("/users/:id/password-reset", userApi.ForcePasswordResetHandler(userService))                    // POST	/v1/users/:userID/password-reset - Force password reset
+
+	/*
+		=================================================================
+		API KEY HANDLERS (admin only)
+		=================================================================
+	*/
+	v1.Get("/api-keys", apiKeyApi.ListAPIKeysHandler(apiKeyService))         // GET 		/v1/api-keys - List API keys
+	v1.Post("/api-keys", apiKeyApi.CreateAPIKeyHandler(apiKeyService))       // POST 		/v1/api-keys - Create a new API key
+	v1.Delete("/api-keys/:id", apiKeyApi.RevokeAPIKeyHandler(apiKeyService)) // DELETE	/v1/api-keys/:keyID - Revoke an API key
+
+	/*
+		=================================================================
+		AUDIT HANDLERS (admin only)
+		=================================================================
+	*/
+	v1.Get("/audit", auditApi.ListAuditLogsHandler(auditService))           // GET 		/v1/audit - Query the audit trail
+	v1.Get("/audit/verify", auditApi.VerifyAuditChainHandler(auditService)) // GET 		/v1/audit/verify - Verify the audit hash chain
+
+	/*
+		=================================================================
+		NOTIFICATION HANDLERS
+		=================================================================
+	*/
+	v1.Get("/me/notification-preferences", notificationApi.GetNotificationPreferencesHandler(notificationService)) // GET 		/v1/me/notification-preferences - Get channels and language for notifications
+	v1.Put("/me/notification-preferences", notificationApi.PutNotificationPreferencesHandler(notificationService)) // PUT 		/v1/me/notification-preferences - Choose what is sent by email and SMS
+	v1.Get("/me/notifications", notificationApi.GetNotificationsHandler(notificationService))                      // GET 		/v1/me/notifications - List notifications sent to me
+
+	/*
+		=================================================================
+		DRIVER HANDLERS
+		=================================================================
+	*/
+	v1.Get("/me/driver-profile", driverApi.GetMyDriverProfileHandler(driverService))                        // GET 		/v1/me/driver-profile - Get own driver profile
+	v1.Put("/me/driver-profile", driverApi.PutMyDriverProfileHandler(driverService))                        // PUT 		/v1/me/driver-profile - Save own licence details
+	v1.Post("/me/driver-profile/license-photo", driverApi.UploadLicensePhotoHandler(driverService))         // POST 		/v1/me/driver-profile/license-photo - Upload a licence scan
+	v1.Get("/me/driver-invitations", driverApi.GetDriverInvitationsHandler(driverService))                  // GET 		/v1/me/driver-invitations - List rentals I'm named on as a driver
+	v1.Post("/rentals/:id/drivers/respond", driverApi.RespondDriverInvitationHandler(driverService))        // POST 		/v1/rentals/:rentalID/drivers/respond - Accept or decline being a driver
+	v1.Get("/driver-profiles", driverApi.ListDriverProfilesHandler(driverService))                          // GET 		/v1/driver-profiles - List driver profiles for verification
+	v1.Get("/users/:id/driver-profile", driverApi.GetDriverProfileHandler(driverService))                   // GET 		/v1/users/:userID/driver-profile - Get a user's driver profile
+	v1.Get("/users/:id/driver-profile/license-photo", driverApi.DownloadLicensePhotoHandler(driverService)) // GET 		/v1/users/:userID/driver-profile/license-photo - Download the licence scan
+	v1.Post("/users/:id/driver-profile/review", driverApi.ReviewDriverProfileHandler(driverService))        // POST 		/v1/users/:userID/driver-profile/review - Verify or reject a driver
+	v1.Get("/vehicle-classes", driverApi.GetVehicleClassesHandler(driverService))                           // GET 		/v1/vehicle-classes - List driver and cancellation rules per vehicle category
+	v1.Put("/vehicle-classes/:category", driverApi.SetVehicleClassHandler(driverService))                   // PUT 		/v1/vehicle-classes/:category - Set driver and cancellation rules for a category
+
+	/*
+		=================================================================
+		BRANCH HANDLERS
+		=================================================================
+	*/
+	v1.Get("/branches", branchApi.GetAllBranchesHandler(branchService))                 // GET 		/v1/branches - List branches
+	v1.Post("/branches", branchApi.PostBranchHandler(branchService))                    // POST 		/v1/branches - Create a new branch
+	v1.Get("/branches/:id", branchApi.GetBranchByIDHandler(branchService))              // GET 		/v1/branches/:branchID - Get branch details
+	v1.Patch("/branches/:id", branchApi.UpdateBranchHandler(branchService))             // PATCH 	/v1/branches/:branchID - Update branch details
+	v1.Get("/branches/:id/one-way-fees", branchApi.GetOneWayFeesHandler(branchService)) // GET 		/v1/branches/:branchID/one-way-fees - List one-way fee overrides
+	v1.Put("/branches/:id/one-way-fees", branchApi.SetOneWayFeeHandler(branchService))  // PUT 		/v1/branches/:branchID/one-way-fees - Set a one-way fee override
 
 	/*
 		=================================================================
 		VEHICLE HANDLERS
 		=================================================================
 	*/
-	v1.Get("/vehicles", vehicleApi.GetAllVehiclesHandler)       // GET 		/v1/vehicles/ - Get vehicles
-	v1.Post("/vehicles", vehicleApi.PostVehiclesHandler)        // POST 		/v1/vehicles/ - Create a new vehicle entry
-	v1.Get("/vehicles/:id", vehicleApi.GetVehicleByIDHandler)   // GET 		/v1/vehicles/:vehicleID - Get vehicle details
-	v1.Patch("/vehicles/:id", vehicleApi.UpdateVehicleHandler)  // PATCH 	/v1/vehicles/:vehicleID- Update vehicle details
-	v1.Delete("/vehicles/:id", vehicleApi.DeleteVehicleHandler) // DELETE	/v1/vehicles/:vehicleID - Delete vehicle details
-	v1.Get("/users/:id", rentalApi.GetAllRentalsHandler)        // GET 		/v1/vehicles/:vehicleID/rentals - Get rentals by vehicle
+	v1.Get("/vehicles", vehicleApi.GetAllVehiclesHandler(vehicleService))              // GET 		/v1/vehicles/ - Get vehicles
+	v1.Post("/vehicles", vehicleApi.PostVehiclesHandler(vehicleService))               // POST 		/v1/vehicles/ - Create a new vehicle entry
+	v1.Get("/vehicles/:id", vehicleApi.GetVehicleByIDHandler(vehicleService))          // GET 		/v1/vehicles/:vehicleID - Get vehicle details
+	v1.Patch("/vehicles/:id", vehicleApi.UpdateVehicleHandler(vehicleService))         // PATCH 	/v1/vehicles/:vehicleID- Update vehicle details
+	v1.Delete("/vehicles/:id", vehicleApi.DeleteVehicleHandler(vehicleService))        // DELETE	/v1/vehicles/:vehicleID - Delete vehicle details
+	v1.Get("/vehicles/:id/rentals", rentalApi.GetVehicleRentalsHandler(rentalService)) // GET 		/v1/vehicles/:vehicleID/rentals - Get rentals by vehicle
+
+	v1.Get("/vehicles/:id/media", vehicleApi.GetVehicleMediaHandler(mediaService))                // GET 		/v1/vehicles/:vehicleID/media - List photos (and documents for admins)
+	v1.Post("/vehicles/:id/media", vehicleApi.UploadVehicleMediaHandler(mediaService))            // POST 		/v1/vehicles/:vehicleID/media - Upload a photo or document
+	v1.Get("/vehicles/:id/media/:mediaID", vehicleApi.DownloadVehicleMediaHandler(mediaService))  // GET 		/v1/vehicles/:vehicleID/media/:mediaID - Download a file or its thumbnail
+	v1.Delete("/vehicles/:id/media/:mediaID", vehicleApi.DeleteVehicleMediaHandler(mediaService)) // DELETE	/v1/vehicles/:vehicleID/media/:mediaID - Delete a file
+
+	/*
+		=================================================================
+		MAINTENANCE HANDLERS (admin only)
+		=================================================================
+	*/
+	v1.Get("/vehicles/:id/maintenance", maintenanceApi.GetVehicleMaintenanceHandler(maintenanceService))      // GET 		/v1/vehicles/:vehicleID/maintenance - List a vehicle's maintenance windows
+	v1.Post("/vehicles/:id/maintenance", maintenanceApi.PostMaintenanceHandler(maintenanceService))           // POST 		/v1/vehicles/:vehicleID/maintenance - Block a vehicle for maintenance
+	v1.Get("/vehicles/:id/service-intervals", maintenanceApi.GetServiceIntervalsHandler(maintenanceService))  // GET 		/v1/vehicles/:vehicleID/service-intervals - List service intervals
+	v1.Post("/vehicles/:id/service-intervals", maintenanceApi.PostServiceIntervalHandler(maintenanceService)) // POST 		/v1/vehicles/:vehicleID/service-intervals - Add a service interval
+	v1.Delete("/service-intervals/:id", maintenanceApi.DeleteServiceIntervalHandler(maintenanceService))      // DELETE	/v1/service-intervals/:intervalID - Remove a service interval
+	v1.Get("/maintenance", maintenanceApi.ListMaintenanceHandler(maintenanceService))                         // GET 		/v1/maintenance - List maintenance across the fleet
+	v1.Patch("/maintenance/:id", maintenanceApi.UpdateMaintenanceHandler(maintenanceService))                 // PATCH 	/v1/maintenance/:maintenanceID - Reschedule, start or cancel
+	v1.Post("/maintenance/:id/complete", maintenanceApi.CompleteMaintenanceHandler(maintenanceService))       // POST 		/v1/maintenance/:maintenanceID/complete - Complete maintenance
 
 	/*
 		=================================================================
 		RENTALS HANDLERS
 		=================================================================
 	*/
-	v1.Get("/rentals", rentalApi.GetAllRentalsHandler)        // GET 	/api/v1/rentals/ - Get rentals
-	v1.Post("/rentals", rentalApi.PostRentalHandler)          // POST 	/api/v1/rentals/ - Create a new rental
-	v1.Get("/rentals/:id", rentalApi.GetRentalByIDHandler)    // GET 	/api/v1/rentals/:rentalID - Get rental details
-	v1.Patch("/rentals/:id", vehicleApi.UpdateVehicleHandler) // PATCH 	/api/v1/rentals/:rentalID - Update rental details
-	v1.Delete("/rentals/:id", rentalApi.DeleteRentalHandler)  // DELETE 	/api/v1/rentals/:rentalID - Delete vehicle details
+	v1.Get("/rentals", rentalApi.GetAllRentalsHandler(rentalService))                            // GET 	/api/v1/rentals/ - Get rentals
+	v1.Post("/rentals", rentalApi.PostRentalHandler(rentalService))                              // POST 	/api/v1/rentals/ - Create a new rental
+	v1.Post("/rentals/holds", rentalApi.PostRentalHoldHandler(rentalService))                    // POST 	/api/v1/rentals/holds - Hold a vehicle for a few minutes before paying
+	v1.Get("/rentals/:id", rentalApi.GetRentalByIDHandler(rentalService))                        // GET 	/api/v1/rentals/:rentalID - Get rental details
+	v1.Patch("/rentals/:id", rentalApi.UpdateRentalHandler(rentalService))                       // PATCH 	/api/v1/rentals/:rentalID - Change dates or vehicle
+	v1.Delete("/rentals/:id", rentalApi.DeleteRentalHandler(rentalService))                      // DELETE 	/api/v1/rentals/:rentalID - Delete vehicle details
+	v1.Post("/rentals/:id/return", rentalApi.ReturnRentalHandler(rentalService))                 // POST 	/api/v1/rentals/:rentalID/return - Check a rental back in
+	v1.Post("/rentals/:id/confirm", rentalApi.ConfirmRentalHandler(rentalService))               // POST 	/api/v1/rentals/:rentalID/confirm - Pay for a held rental and confirm it
+	v1.Post("/rentals/:id/no-show", rentalApi.PostNoShowHandler(rentalService))                  // POST 	/api/v1/rentals/:rentalID/no-show - Cancel an uncollected rental and charge the no-show fee
+	v1.Get("/rentals/:id/modifications", rentalApi.GetRentalModificationsHandler(rentalService)) // GET 	/api/v1/rentals/:rentalID/modifications - List changes made to the booking
+	v1.Post("/quotes", rentalApi.PostQuoteHandler(rentalService))                                // POST 	/api/v1/quotes - Price a rental without booking it
+
+	/*
+		=================================================================
+		EXTRA HANDLERS
+		=================================================================
+	*/
+	v1.Get("/extras", extraApi.GetAllExtrasHandler(extraService))            // GET 	/api/v1/extras - List extras, with availability for a branch and window
+	v1.Post("/extras", extraApi.PostExtraHandler(extraService))              // POST 	/api/v1/extras - Create an extra
+	v1.Get("/extras/:id", extraApi.GetExtraHandler(extraService))            // GET 	/api/v1/extras/:extraID - Get extra details
+	v1.Patch("/extras/:id", extraApi.UpdateExtraHandler(extraService))       // PATCH 	/api/v1/extras/:extraID - Update an extra
+	v1.Get("/extras/:id/stock", extraApi.GetExtraStockHandler(extraService)) // GET 	/api/v1/extras/:extraID/stock - List stock per branch
+	v1.Put("/extras/:id/stock", extraApi.SetExtraStockHandler(extraService)) // PUT 	/api/v1/extras/:extraID/stock - Set stock at a branch
+
+	/*
+		=================================================================
+		PROMO CODE HANDLERS
+		=================================================================
+	*/
+	v1.Get("/promo-codes", promoApi.GetAllPromoCodesHandler(promoService))      // GET 	/api/v1/promo-codes - List promo codes with redemption counts
+	v1.Post("/promo-codes", promoApi.PostPromoCodeHandler(promoService))        // POST 	/api/v1/promo-codes - Create a promo code
+	v1.Get("/promo-codes/:id", promoApi.GetPromoCodeHandler(promoService))      // GET 	/api/v1/promo-codes/:promoCodeID - Get promo code details
+	v1.Patch("/promo-codes/:id", promoApi.UpdatePromoCodeHandler(promoService)) // PATCH 	/api/v1/promo-codes/:promoCodeID - Update or deactivate a promo code
+
+	/*
+		=================================================================
+		INSPECTION HANDLERS
+		=================================================================
+	*/
+	v1.Post("/rentals/:id/inspections", inspectionApi.PostInspectionHandler(inspectionService))                 // POST 	/api/v1/rentals/:rentalID/inspections - Record a checkout or checkin inspection
+	v1.Get("/rentals/:id/condition", inspectionApi.GetConditionReportHandler(rentalService, inspectionService)) // GET 	/api/v1/rentals/:rentalID/condition - Compare checkout and checkin
+	v1.Get("/rentals/:id/charges", inspectionApi.GetRentalChargesHandler(rentalService, inspectionService))     // GET 	/api/v1/rentals/:rentalID/charges - List mileage, fuel and damage charges
+	v1.Post("/damage-items/:id/photos", inspectionApi.PostDamagePhotoHandler(mediaService))                     // POST 	/api/v1/damage-items/:damageItemID/photos - Attach a damage photo
+	v1.Post("/rental-charges/:id/review", inspectionApi.ReviewChargeHandler(inspectionService))                 // POST 	/api/v1/rental-charges/:chargeID/review - Approve, adjust or waive a charge
+
+	/*
+		=================================================================
+		INVOICE HANDLERS
+		=================================================================
+	*/
+	v1.Post("/rentals/:id/invoice", invoiceApi.PostInvoiceHandler(invoiceService))          // POST 	/api/v1/rentals/:rentalID/invoice - Issue the invoice for a returned rental
+	v1.Get("/rentals/:id/invoice", invoiceApi.GetRentalInvoiceHandler(invoiceService))      // GET 	/api/v1/rentals/:rentalID/invoice - Get the invoice as JSON or PDF
+	v1.Get("/invoices/:id", invoiceApi.GetInvoiceHandler(invoiceService))                   // GET 	/api/v1/invoices/:invoiceID - Get an invoice or credit note as JSON or PDF
+	v1.Post("/invoices/:id/credit-notes", invoiceApi.PostCreditNoteHandler(invoiceService)) // POST 	/api/v1/invoices/:invoiceID/credit-notes - Credit and refund part of an invoice
+
+	/*
+		=================================================================
+		JOB HANDLERS
+		=================================================================
+	*/
+	v1.Get("/jobs", jobApi.ListJobsHandler(jobService))                    // GET 	/api/v1/jobs - List background jobs by status and kind
+	v1.Get("/jobs/:id", jobApi.GetJobHandler(jobService))                  // GET 	/api/v1/jobs/:jobID - Get a job with its last error
+	v1.Post("/jobs/:id/retry", jobApi.RetryJobHandler(jobService))         // POST 	/api/v1/jobs/:jobID/retry - Requeue a dead job
+	v1.Get("/recurring-jobs", jobApi.ListRecurringJobsHandler(jobService)) // GET 	/api/v1/recurring-jobs - List cron schedules and their next run
+
+	/*
+		=================================================================
+		TELEMATICS HANDLERS (admin only)
+		=================================================================
+	*/
+	v1.Get("/vehicles/:id/telematics-devices", telematicsApi.GetTelematicsDevicesHandler(telematicsService))  // GET 	/api/v1/vehicles/:vehicleID/telematics-devices - List a vehicle's telematics units
+	v1.Post("/vehicles/:id/telematics-devices", telematicsApi.PostTelematicsDeviceHandler(telematicsService)) // POST 	/api/v1/vehicles/:vehicleID/telematics-devices - Register a unit, returns its token
+	v1.Delete("/telematics-devices/:id", telematicsApi.RevokeTelematicsDeviceHandler(telematicsService))      // DELETE	/api/v1/telematics-devices/:deviceID - Revoke a unit's token
+
+	/*
+		=================================================================
+		STREAM HANDLERS
+		=================================================================
+	*/
+	v1.Get("/stream", streamApi.GetStreamHandler(streamService)) // GET 	/api/v1/stream - Live rental and vehicle changes as server-sent events
+
+	/*
+		=================================================================
+		WEBHOOK HANDLERS
+		=================================================================
+	*/
+	v1.Get("/webhooks", webhookApi.ListWebhooksHandler(webhookService))                                             // GET 	/api/v1/webhooks - List the API client's webhooks
+	v1.Post("/webhooks", webhookApi.CreateWebhookHandler(webhookService))                                           // POST 	/api/v1/webhooks - Subscribe to events, returns the signing secret
+	v1.Delete("/webhooks/:id", webhookApi.DeleteWebhookHandler(webhookService))                                     // DELETE	/api/v1/webhooks/:webhookID - Unsubscribe
+	v1.Post("/webhooks/:id/ping", webhookApi.PingWebhookHandler(webhookService))                                    // POST 	/api/v1/webhooks/:webhookID/ping - Send a test delivery
+	v1.Get("/webhooks/:id/deliveries", webhookApi.GetWebhookDeliveriesHandler(webhookService))                      // GET 	/api/v1/webhooks/:webhookID/deliveries - Delivery log
+	v1.Post("/webhooks/:id/deliveries/:deliveryID/replay", webhookApi.ReplayWebhookDeliveryHandler(webhookService)) // POST 	/api/v1/webhooks/:webhookID/deliveries/:deliveryID/replay - Send a delivery again
 
 	// Start the server
 	log.Fatal(app.Listen(":3000"))
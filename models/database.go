@@ -4,29 +4,829 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+const (
+	UserStatusActive    = "active"
+	UserStatusSuspended = "suspended"
 )
 
 type User struct {
-	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
-	Name      string    `gorm:"type:varchar(255);not null"`
-	Email     string    `gorm:"type:varchar(255);uniqueIndex;not null"`
-	Password  string    `gorm:"type:varchar(255);not null"`
-	Role      string    `gorm:"type:varchar(50);not null;default:'user'"`
+	ID       uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	Name     string    `gorm:"type:varchar(255);not null"`
+	Email    string    `gorm:"type:varchar(255);uniqueIndex:idx_users_email_active,where:deleted_at IS NULL;not null"`
+	Password string    `gorm:"type:varchar(255);not null" json:"-"`
+	Role     string    `gorm:"type:varchar(50);not null;default:'user'"`
+	Status   string    `gorm:"type:varchar(50);not null;default:'active'"`
+
+	SuspendedAt           *time.Time
+	SuspensionReason      string `gorm:"type:text"`
+	PasswordResetRequired bool   `gorm:"not null;default:false"`
+
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	DeletedAt    gorm.DeletedAt `gorm:"index"`
+	AnonymizedAt *time.Time
+}
+
+const (
+	DriverStatusPending  = "pending"
+	DriverStatusVerified = "verified"
+	DriverStatusRejected = "rejected"
+)
+
+// DriverProfile holds the licence details a customer must have verified by
+// staff before they can book. Changing any licence detail sends the profile
+// back to pending.
+type DriverProfile struct {
+	UserID           uuid.UUID `gorm:"type:uuid;primaryKey"`
+	DateOfBirth      time.Time `gorm:"type:date;not null"`
+	LicenseNumber    string    `gorm:"type:varchar(64);not null"`
+	LicenseCountry   string    `gorm:"type:varchar(2);not null"`
+	LicenseExpiresOn time.Time `gorm:"type:date;not null"`
+
+	LicensePhotoKey  string `gorm:"type:varchar(255);not null;default:''"`
+	LicensePhotoType string `gorm:"type:varchar(100);not null;default:''"`
+
+	Status          string     `gorm:"type:varchar(20);not null;default:'pending';index"`
+	ReviewedBy      *uuid.UUID `gorm:"type:uuid"`
+	ReviewedAt      *time.Time
+	RejectionReason string `gorm:"type:text"`
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
 
+// Branch is a physical location vehicles are picked up from and returned to.
+// Opening hours are interpreted in the branch's own Timezone.
+type Branch struct {
+	ID             uuid.UUID    `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	Code           string       `gorm:"type:varchar(32);uniqueIndex;not null"`
+	Name           string       `gorm:"type:varchar(255);not null"`
+	AddressLine1   string       `gorm:"type:varchar(255);not null"`
+	AddressLine2   string       `gorm:"type:varchar(255)"`
+	City           string       `gorm:"type:varchar(255);not null"`
+	Region         string       `gorm:"type:varchar(255)"`
+	PostalCode     string       `gorm:"type:varchar(32)"`
+	Country        string       `gorm:"type:varchar(2);not null"`
+	Timezone       string       `gorm:"type:varchar(64);not null"`
+	OpeningHours   OpeningHours `gorm:"type:jsonb;not null;default:'{}'"`
+	OneWayFeeCents int64        `gorm:"not null;default:0"`
+	TaxRateBps     int          `gorm:"not null;default:0"` // basis points, 1900 = 19%
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// OneWayFee overrides the drop-off branch's default one-way fee for a
+// specific pickup/drop-off pair.
+type OneWayFee struct {
+	FromBranchID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	ToBranchID   uuid.UUID `gorm:"type:uuid;primaryKey"`
+	FeeCents     int64     `gorm:"not null"`
+	UpdatedAt    time.Time
+}
+
+const (
+	VehicleCategoryEconomy  = "economy"
+	VehicleCategoryCompact  = "compact"
+	VehicleCategoryMidsize  = "midsize"
+	VehicleCategoryFullsize = "fullsize"
+	VehicleCategorySUV      = "suv"
+	VehicleCategoryVan      = "van"
+	VehicleCategoryLuxury   = "luxury"
+)
+
+// VehicleClass holds the rules that apply to every vehicle in a category.
+// Drivers younger than MinDriverAge can't book the class; those younger than
+// YoungDriverAge pay YoungDriverFeeCents per rental day. CancellationPolicy
+// is what new bookings of the class agree to. Categories without a row use
+// the defaults from the environment.
+type VehicleClass struct {
+	Category            string             `gorm:"type:varchar(50);primaryKey"`
+	MinDriverAge        int                `gorm:"not null"`
+	YoungDriverAge      int                `gorm:"not null;default:0"`
+	YoungDriverFeeCents int64              `gorm:"not null;default:0"`
+	CancellationPolicy  CancellationPolicy `gorm:"embedded;embeddedPrefix:cancellation_"`
+	UpdatedAt           time.Time
+}
+
+const (
+	CancellationFeeNone    = "none"
+	CancellationFeePercent = "percent"
+	CancellationFeeOneDay  = "one_day"
+)
+
+// CancellationPolicy decides what calling off a booking costs. Cancelling at
+// least FreeHours before the start is free, later than that LateFee applies,
+// and a booking nobody turns up for is charged NoShowFee. Percent fees are
+// taken in basis points of the booked total, a one-day fee is the booked
+// total divided by the rental days.
+type CancellationPolicy struct {
+	FreeHours    int    `gorm:"not null;default:0"`
+	LateFee      string `gorm:"type:varchar(20);not null;default:'none'"`
+	LateFeeBps   int    `gorm:"not null;default:0"`
+	NoShowFee    string `gorm:"type:varchar(20);not null;default:'none'"`
+	NoShowFeeBps int    `gorm:"not null;default:0"`
+}
+
+const (
+	TransmissionManual    = "manual"
+	TransmissionAutomatic = "automatic"
+)
+
+const (
+	FuelTypePetrol   = "petrol"
+	FuelTypeDiesel   = "diesel"
+	FuelTypeHybrid   = "hybrid"
+	FuelTypeElectric = "electric"
+)
+
 type Vehicle struct {
 	ID    uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
 	Make  string    `gorm:"type:varchar(255);not null"`
 	Model string    `gorm:"type:varchar(255);not null"`
 	Year  int       `gorm:"type:integer;not null"`
+
+	// VIN is unique among vehicles still in the fleet. Rows created before
+	// the catalog was extended have an empty VIN until staff fill it in.
+	VIN             string     `gorm:"column:vin;type:varchar(17);not null;default:'';uniqueIndex:idx_vehicles_vin_active,where:deleted_at IS NULL AND vin <> ''"`
+	LicensePlate    string     `gorm:"type:varchar(20);not null;default:'';index"`
+	Category        string     `gorm:"type:varchar(50);not null;default:'economy';index"`
+	Transmission    string     `gorm:"type:varchar(20);not null;default:'manual'"`
+	FuelType        string     `gorm:"type:varchar(20);not null;default:'petrol'"`
+	Seats           int        `gorm:"not null;default:5"`
+	Doors           int        `gorm:"not null;default:4"`
+	LuggageCapacity int        `gorm:"not null;default:0"`
+	MileageKm       int        `gorm:"not null;default:0"`
+	Color           string     `gorm:"type:varchar(50);not null;default:''"`
+	Features        StringList `gorm:"type:jsonb;not null;default:'[]'"`
+	DailyRateCents  int64      `gorm:"not null;default:0"`
+
+	HomeBranchID    *uuid.UUID `gorm:"type:uuid;index"`
+	CurrentBranchID *uuid.UUID `gorm:"type:uuid;index"`
+
+	// The latest state reported by the vehicle's telematics unit, as of
+	// TelemetryAt. The odometer keeps MileageKm up to date as well.
+	Latitude    *float64
+	Longitude   *float64
+	FuelPercent *float64
+	TelemetryAt *time.Time
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+const (
+	MediaKindPhoto    = "photo"
+	MediaKindDocument = "document"
+	MediaKindDamage   = "damage"
+)
+
+// VehicleMedia is a photo or document attached to a vehicle. The file itself
+// lives in the blob store under StorageKey.
+type VehicleMedia struct {
+	ID           uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	VehicleID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	Kind         string    `gorm:"type:varchar(20);not null"`
+	FileName     string    `gorm:"type:varchar(255);not null"`
+	ContentType  string    `gorm:"type:varchar(100);not null"`
+	SizeBytes    int64     `gorm:"not null"`
+	Width        int
+	Height       int
+	StorageKey   string     `gorm:"type:varchar(512);not null"`
+	ThumbnailKey string     `gorm:"type:varchar(512)"`
+	UploadedBy   uuid.UUID  `gorm:"type:uuid;not null"`
+	DamageItemID *uuid.UUID `gorm:"type:uuid;index"`
+	CreatedAt    time.Time
+}
+
+const (
+	MaintenanceKindPlanned = "planned"
+	MaintenanceKindAdHoc   = "adhoc"
+)
+
+// Only scheduled and in-progress windows take a vehicle out of service.
+// Pending tasks are raised by service intervals and wait for staff to pick a
+// time slot.
+const (
+	MaintenanceStatusPending    = "pending"
+	MaintenanceStatusScheduled  = "scheduled"
+	MaintenanceStatusInProgress = "in_progress"
+	MaintenanceStatusCompleted  = "completed"
+	MaintenanceStatusCancelled  = "cancelled"
+)
+
+type MaintenanceWindow struct {
+	ID                uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	VehicleID         uuid.UUID  `gorm:"type:uuid;not null;index"`
+	ServiceIntervalID *uuid.UUID `gorm:"type:uuid;index"`
+	Kind              string     `gorm:"type:varchar(20);not null"`
+	Status            string     `gorm:"type:varchar(20);not null;index"`
+	Reason            string     `gorm:"type:text;not null"`
+	StartsAt          *time.Time
+	EndsAt            *time.Time
+	DueAt             *time.Time
+	DueMileageKm      *int
+	CompletedAt       *time.Time
+	CompletedMileage  *int
+	CreatedBy         *uuid.UUID `gorm:"type:uuid"`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// ServiceInterval is a recurring service requirement for a vehicle, due every
+// EveryKm kilometres and/or EveryDays days since it was last done.
+type ServiceInterval struct {
+	ID            uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	VehicleID     uuid.UUID `gorm:"type:uuid;not null;index"`
+	Name          string    `gorm:"type:varchar(255);not null"`
+	EveryKm       int       `gorm:"not null;default:0"`
+	EveryDays     int       `gorm:"not null;default:0"`
+	DurationHours int       `gorm:"not null;default:24"`
+	LastServiceAt time.Time `gorm:"not null"`
+	LastServiceKm int       `gorm:"not null;default:0"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+const (
+	InspectionTypeCheckout = "checkout"
+	InspectionTypeCheckin  = "checkin"
+)
+
+// Inspection is the condition report taken when a vehicle is handed over
+// (checkout) and when it comes back (checkin). A rental has at most one of
+// each.
+type Inspection struct {
+	ID               uuid.UUID    `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	RentalID         uuid.UUID    `gorm:"type:uuid;not null;uniqueIndex:idx_inspections_rental_type"`
+	Type             string       `gorm:"type:varchar(20);not null;uniqueIndex:idx_inspections_rental_type"`
+	VehicleID        uuid.UUID    `gorm:"type:uuid;not null;index"`
+	OdometerKm       int          `gorm:"not null"`
+	FuelLevelPercent int          `gorm:"not null"`
+	Notes            string       `gorm:"type:text"`
+	InspectedBy      uuid.UUID    `gorm:"type:uuid;not null"`
+	Damages          []DamageItem `gorm:"foreignKey:InspectionID"`
+	CreatedAt        time.Time
+}
+
+const (
+	DamageSeverityMinor    = "minor"
+	DamageSeverityModerate = "moderate"
+	DamageSeverityMajor    = "major"
+)
+
+type DamageItem struct {
+	ID           uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	InspectionID uuid.UUID `gorm:"type:uuid;not null;index"`
+	Zone         string    `gorm:"type:varchar(50);not null"`
+	Severity     string    `gorm:"type:varchar(20);not null"`
+	Description  string    `gorm:"type:text"`
+	// IsNew is set on check-in items that weren't recorded at checkout.
+	IsNew     bool `gorm:"not null;default:false"`
+	CreatedAt time.Time
+}
+
+const (
+	ChargeKindMileage = "mileage"
+	ChargeKindFuel    = "fuel"
+	ChargeKindDamage  = "damage"
+	ChargeKindLate    = "late_return"
+)
+
+// RentalCharge is an extra amount owed on a rental beyond the booking itself.
+// Damage charges are estimates and need review before they are billed.
+type RentalCharge struct {
+	ID             uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	RentalID       uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Kind           string     `gorm:"type:varchar(50);not null"`
+	Description    string     `gorm:"type:text;not null"`
+	Quantity       int        `gorm:"not null;default:1"`
+	UnitCents      int64      `gorm:"not null"`
+	AmountCents    int64      `gorm:"not null"`
+	SourceID       *uuid.UUID `gorm:"type:uuid"`
+	RequiresReview bool       `gorm:"not null;default:false"`
+	CreatedAt      time.Time
+}
+
+const (
+	ExtraPricingPerDay    = "per_day"
+	ExtraPricingPerRental = "per_rental"
+)
+
+// Extra is an add-on sold with a rental (child seat, GPS, insurance cover).
+// Extras with TrackInventory are physical items stocked per branch in
+// ExtraStock; the rest can be sold without limit.
+type Extra struct {
+	ID             uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	Code           string    `gorm:"type:varchar(32);uniqueIndex;not null"`
+	Name           string    `gorm:"type:varchar(255);not null"`
+	Description    string    `gorm:"type:text"`
+	Pricing        string    `gorm:"type:varchar(20);not null"`
+	PriceCents     int64     `gorm:"not null"`
+	MaxPerRental   int       `gorm:"not null;default:1"`
+	TrackInventory bool      `gorm:"not null;default:false"`
+	Active         bool      `gorm:"not null;default:true"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type ExtraStock struct {
+	ExtraID   uuid.UUID `gorm:"type:uuid;primaryKey"`
+	BranchID  uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Quantity  int       `gorm:"not null"`
+	UpdatedAt time.Time
+}
+
+// RentalExtra is an extra booked on a rental, priced when it was booked.
+// Stocked extras are drawn from the pickup branch for the rental window.
+type RentalExtra struct {
+	ID          uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	RentalID    uuid.UUID  `gorm:"type:uuid;not null;index"`
+	ExtraID     uuid.UUID  `gorm:"type:uuid;not null;index"`
+	BranchID    *uuid.UUID `gorm:"type:uuid;index"`
+	Name        string     `gorm:"type:varchar(255);not null"`
+	Pricing     string     `gorm:"type:varchar(20);not null"`
+	Quantity    int        `gorm:"not null"`
+	UnitCents   int64      `gorm:"not null"`
+	AmountCents int64      `gorm:"not null"`
+	CreatedAt   time.Time
+}
+
+const (
+	RentalDriverPending  = "pending"
+	RentalDriverAccepted = "accepted"
+	RentalDriverDeclined = "declined"
+	RentalDriverExpired  = "expired"
+)
+
+// RentalDriver is an additional driver named on a rental. The named user must
+// accept before they are covered. FeeCents (the per-driver fee plus any young
+// driver surcharge, for the whole rental) stops being charged if they decline
+// or haven't answered by the time the rental is returned.
+type RentalDriver struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	RentalID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_rental_drivers_rental_user"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_rental_drivers_rental_user;index"`
+	Name        string    `gorm:"type:varchar(255);not null"`
+	Status      string    `gorm:"type:varchar(20);not null;default:'pending'"`
+	FeeCents    int64     `gorm:"not null"`
+	RespondedAt *time.Time
+	CreatedAt   time.Time
 }
 
+const (
+	PromoDiscountPercent = "percent"
+	PromoDiscountFixed   = "fixed"
+)
+
+// PromoCode is a marketing discount entered at booking. It takes
+// DiscountBps off, or DiscountCents off, the base rate and extras. Codes are
+// valid for bookings made between ValidFrom and ValidUntil; a nil bound is
+// open. Redemptions are the non-cancelled rentals that used the code, so a
+// cancellation frees its use again.
+type PromoCode struct {
+	ID             uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	Code           string    `gorm:"type:varchar(32);uniqueIndex;not null"`
+	Description    string    `gorm:"type:text"`
+	DiscountType   string    `gorm:"type:varchar(20);not null"`
+	DiscountBps    int       `gorm:"not null;default:0"`
+	DiscountCents  int64     `gorm:"not null;default:0"`
+	ValidFrom      *time.Time
+	ValidUntil     *time.Time
+	MaxRedemptions *int
+	MaxPerUser     *int
+	MinRentalDays  int        `gorm:"not null;default:0"`
+	Categories     StringList `gorm:"type:jsonb;not null;default:'[]'"` // empty means every category
+	Active         bool       `gorm:"not null;default:true"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+const (
+	InvoiceKindInvoice    = "invoice"
+	InvoiceKindCreditNote = "credit_note"
+)
+
+const (
+	InvoiceLineBaseRate         = "base_rate"
+	InvoiceLineOneWayFee        = "one_way_fee"
+	InvoiceLineExtra            = "extra"
+	InvoiceLineDiscount         = "discount"
+	InvoiceLineYoungDriver      = "young_driver"
+	InvoiceLineAdditionalDriver = "additional_driver"
+	InvoiceLineCredit           = "credit"
+)
+
+// Invoice is an issued billing document, either an invoice for a finished
+// rental or a credit note against one. Issued documents are never changed;
+// corrections are made with credit notes, whose amounts are negative.
+// Numbers are gap-free within a Series (branch code and document kind).
+type Invoice struct {
+	ID                uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	Number            string     `gorm:"type:varchar(64);uniqueIndex;not null"`
+	Series            string     `gorm:"type:varchar(48);not null;uniqueIndex:idx_invoices_series_sequence"`
+	Sequence          int64      `gorm:"not null;uniqueIndex:idx_invoices_series_sequence"`
+	Kind              string     `gorm:"type:varchar(20);not null"`
+	RentalID          uuid.UUID  `gorm:"type:uuid;not null;index;uniqueIndex:idx_invoices_rental,where:kind = 'invoice'"`
+	UserID            uuid.UUID  `gorm:"type:uuid;not null;index"`
+	BranchID          *uuid.UUID `gorm:"type:uuid;index"`
+	CreditedInvoiceID *uuid.UUID `gorm:"type:uuid;index"`
+	BillToName        string     `gorm:"type:varchar(255);not null"`
+	BillToEmail       string     `gorm:"type:varchar(255);not null"`
+	IssuerName        string     `gorm:"type:varchar(255);not null"`
+	IssuerAddress     string     `gorm:"type:text;not null"`
+	Currency          string     `gorm:"type:varchar(3);not null"`
+	TaxRateBps        int        `gorm:"not null"`
+	SubtotalCents     int64      `gorm:"not null"`
+	TaxCents          int64      `gorm:"not null"`
+	TotalCents        int64      `gorm:"not null"`
+	Reason            string     `gorm:"type:text"`
+	IssuedAt          time.Time  `gorm:"not null"`
+
+	Lines []InvoiceLine `gorm:"foreignKey:InvoiceID"`
+}
+
+type InvoiceLine struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	InvoiceID   uuid.UUID `gorm:"type:uuid;not null;index"`
+	Position    int       `gorm:"not null"`
+	Kind        string    `gorm:"type:varchar(50);not null"`
+	Description string    `gorm:"type:text;not null"`
+	Quantity    int       `gorm:"not null"`
+	UnitCents   int64     `gorm:"not null"`
+	AmountCents int64     `gorm:"not null"`
+}
+
+// InvoiceSequence is the last number handed out in a series. It is bumped in
+// the same transaction that inserts the invoice, so a rollback gives the
+// number back and no gaps appear.
+type InvoiceSequence struct {
+	Series     string `gorm:"type:varchar(48);primaryKey"`
+	LastNumber int64  `gorm:"not null"`
+}
+
+const (
+	RentalStatusConfirmed = "confirmed"
+	RentalStatusCancelled = "cancelled"
+	RentalStatusReturned  = "returned"
+	// A held rental keeps the vehicle for the customer while they pay and
+	// lapses at HoldExpiresAt unless confirmed.
+	RentalStatusHeld = "held"
+)
+
+const (
+	PaymentStatusNone       = "none"
+	PaymentStatusPending    = "pending"
+	PaymentStatusAuthorized = "authorized"
+	PaymentStatusFailed     = "failed"
+	PaymentStatusCaptured   = "captured"
+	PaymentStatusVoided     = "voided"
+	PaymentStatusRefunded   = "refunded"
+)
+
 type Rental struct {
 	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
-	UserID    uuid.UUID `gorm:"type:uuid;not null"`
-	VehicleID uuid.UUID `gorm:"type:uuid;not null"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	VehicleID uuid.UUID `gorm:"type:uuid;not null;index"`
 	StartDate time.Time
 	EndDate   time.Time
+	Status    string `gorm:"type:varchar(50);not null;default:'confirmed'"`
+	// HoldExpiresAt is set while the rental is held.
+	HoldExpiresAt *time.Time `gorm:"index"`
+
+	PickupBranchID  *uuid.UUID `gorm:"type:uuid;index"`
+	DropoffBranchID *uuid.UUID `gorm:"type:uuid;index"`
+	OneWayFeeCents  int64      `gorm:"not null;default:0"`
+	ReturnedAt      *time.Time
+
+	// RentalCents is the vehicle's daily rate times the booked days, and
+	// YoungDriverCents the class surcharge for drivers under its age.
+	// AdditionalDriversCents sums the fees of named drivers still charged. A promo
+	// code's DiscountCents comes off before tax, which is added at the pickup
+	// branch's rate as it stood at booking. The deposit is
+	// held on top and released when the rental is captured.
+	RentalCents            int64  `gorm:"not null;default:0"`
+	ExtrasCents            int64  `gorm:"not null;default:0"`
+	DiscountCents          int64  `gorm:"not null;default:0"`
+	YoungDriverCents       int64  `gorm:"not null;default:0"`
+	AdditionalDriversCents int64  `gorm:"not null;default:0"`
+	TaxRateBps             int    `gorm:"not null;default:0"`
+	DepositCents           int64  `gorm:"not null;default:0"`
+	PaymentStatus          string `gorm:"type:varchar(20);not null;default:'none'"`
+	PaymentAuthorizationID string `gorm:"type:varchar(100);index"`
+	AuthorizedCents        int64  `gorm:"not null;default:0"`
+	CapturedCents          int64  `gorm:"not null;default:0"`
+	RefundedCents          int64  `gorm:"not null;default:0"`
+
+	PromoCodeID *uuid.UUID `gorm:"type:uuid;index"`
+	PromoCode   string     `gorm:"type:varchar(32);not null;default:''"`
+
+	// The class's cancellation policy is copied at booking, like the tax
+	// rate, so the terms the customer accepted are the ones applied.
+	CancellationPolicy   CancellationPolicy `gorm:"embedded;embeddedPrefix:cancellation_"`
+	CancellationFeeCents int64              `gorm:"not null;default:0"`
+	NoShow               bool               `gorm:"not null;default:false"`
+
+	// OverdueAt is when the rental was found not returned past its end date
+	// and grace period. A booking whose vehicle is still out with an overdue
+	// rental is blocked by it until the vehicle comes back or the booking is
+	// moved.
+	OverdueAt         *time.Time
+	BlockedByRentalID *uuid.UUID `gorm:"type:uuid;index"`
+	BlockedAt         *time.Time
+
+	Extras  []RentalExtra  `gorm:"foreignKey:RentalID"`
+	Drivers []RentalDriver `gorm:"foreignKey:RentalID"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// RentalModification is one change to a booked rental, kept so the customer
+// and staff can see how the price and payment hold moved. When the change
+// needed a bigger hold the old authorization is replaced and recorded here,
+// so late webhooks for it can still be recognised.
+type RentalModification struct {
+	ID         uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	RentalID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_rental_modifications_seq"`
+	Sequence   int       `gorm:"not null;uniqueIndex:idx_rental_modifications_seq"`
+	ModifiedBy uuid.UUID `gorm:"type:uuid;not null"`
+
+	PreviousVehicleID uuid.UUID `gorm:"type:uuid;not null"`
+	PreviousStartDate time.Time `gorm:"not null"`
+	PreviousEndDate   time.Time `gorm:"not null"`
+	VehicleID         uuid.UUID `gorm:"type:uuid;not null"`
+	StartDate         time.Time `gorm:"not null"`
+	EndDate           time.Time `gorm:"not null"`
+
+	PreviousTotalCents      int64  `gorm:"not null"`
+	TotalCents              int64  `gorm:"not null"`
+	PreviousAuthorizationID string `gorm:"type:varchar(100);index"`
+	AuthorizationID         string `gorm:"type:varchar(100)"`
+
+	CreatedAt time.Time
+}
+
+// PaymentEvent records each gateway webhook that has been applied, so a
+// redelivered event is recognised and skipped.
+type PaymentEvent struct {
+	ID              uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	GatewayEventID  string     `gorm:"type:varchar(100);uniqueIndex;not null"`
+	Type            string     `gorm:"type:varchar(50);not null"`
+	AuthorizationID string     `gorm:"type:varchar(100);not null;index"`
+	RentalID        *uuid.UUID `gorm:"type:uuid;index"`
+	AmountCents     int64      `gorm:"not null;default:0"`
+	CreatedAt       time.Time
+}
+
+type APIKey struct {
+	ID           uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	Name         string     `gorm:"type:varchar(255);not null"`
+	Prefix       string     `gorm:"type:varchar(32);uniqueIndex;not null"`
+	KeyHash      string     `gorm:"type:varchar(64);not null" json:"-"`
+	Organization string     `gorm:"type:varchar(255);not null;index"`
+	UserID       uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Permissions  StringList `gorm:"type:jsonb;not null;default:'[]'"`
+	CreatedBy    uuid.UUID  `gorm:"type:uuid;not null"`
+	ExpiresAt    *time.Time
+	LastUsedAt   *time.Time
+	RevokedAt    *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+const (
+	AuditActorUser      = "user"
+	AuditActorAPIKey    = "api_key"
+	AuditActorSystem    = "system"
+	AuditActorAnonymous = "anonymous"
+)
+
+// AuditLog is an append-only, hash-chained record of a mutating action. Each
+// entry's Hash covers its own content and the previous entry's Hash, so any
+// edit or deletion breaks the chain from that point on.
+type AuditLog struct {
+	ID           uint64    `gorm:"primaryKey;autoIncrement"`
+	ActorType    string    `gorm:"type:varchar(50);not null"`
+	ActorID      string    `gorm:"type:varchar(64);index"`
+	APIKeyID     string    `gorm:"type:varchar(64)"`
+	Action       string    `gorm:"type:varchar(100);not null;index"`
+	ResourceType string    `gorm:"type:varchar(50);not null;index:idx_audit_logs_resource"`
+	ResourceID   string    `gorm:"type:varchar(64);not null;index:idx_audit_logs_resource"`
+	Before       JSONMap   `gorm:"type:jsonb"`
+	After        JSONMap   `gorm:"type:jsonb"`
+	Changes      JSONMap   `gorm:"type:jsonb"`
+	IP           string    `gorm:"type:varchar(64)"`
+	RequestID    string    `gorm:"type:varchar(64)"`
+	PrevHash     string    `gorm:"type:varchar(64);not null"`
+	Hash         string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+	CreatedAt    time.Time `gorm:"index"`
+}
+
+const (
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusDead      = "dead"
+)
+
+// Job is a unit of background work. Workers claim ready jobs with SKIP LOCKED
+// and hold them until LockedUntil; a job whose worker died is picked up again
+// once the lease runs out. Failures are retried with backoff until
+// MaxAttempts, after which the job is dead and waits for an admin to retry it.
+type Job struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	Kind        string    `gorm:"type:varchar(100);not null;index"`
+	Payload     JSONMap   `gorm:"type:jsonb;not null;default:'{}'"`
+	Status      string    `gorm:"type:varchar(20);not null;default:'queued';index:idx_jobs_ready,priority:1"`
+	RunAt       time.Time `gorm:"not null;index:idx_jobs_ready,priority:2"`
+	Attempts    int       `gorm:"not null;default:0"`
+	MaxAttempts int       `gorm:"not null"`
+	LastError   string    `gorm:"type:text;not null;default:''"`
+	LockedBy    string    `gorm:"type:varchar(100);not null;default:''"`
+	LockedUntil *time.Time
+	// RecurringJob names the schedule that enqueued the job, if any.
+	RecurringJob string `gorm:"type:varchar(100);not null;default:'';index"`
+	FinishedAt   *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// RecurringJob enqueues a job of Kind each time its cron Schedule comes due.
+// The row is locked while it is advanced, so only one replica enqueues each
+// run.
+type RecurringJob struct {
+	Name      string    `gorm:"type:varchar(100);primaryKey"`
+	Schedule  string    `gorm:"type:varchar(100);not null"`
+	Kind      string    `gorm:"type:varchar(100);not null"`
+	Payload   JSONMap   `gorm:"type:jsonb;not null;default:'{}'"`
+	NextRunAt time.Time `gorm:"not null;index"`
+	LastRunAt *time.Time
+	UpdatedAt time.Time
+}
+
+// OutboxEvent is a domain event written in the same transaction as the change
+// it describes, so an event exists if and only if the change committed. The
+// relay publishes pending rows and stamps PublishedAt; a crash in between
+// publishes the row again, so consumers must dedupe on EventID.
+type OutboxEvent struct {
+	ID            uint64     `gorm:"primaryKey;autoIncrement"`
+	EventID       uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();uniqueIndex"`
+	Type          string     `gorm:"type:varchar(100);not null;index"`
+	AggregateType string     `gorm:"type:varchar(50);not null;index:idx_outbox_events_aggregate"`
+	AggregateID   string     `gorm:"type:varchar(64);not null;index:idx_outbox_events_aggregate"`
+	Payload       JSONMap    `gorm:"type:jsonb;not null;default:'{}'"`
+	Attempts      int        `gorm:"not null;default:0"`
+	LastError     string     `gorm:"type:text;not null;default:''"`
+	NextAttemptAt time.Time  `gorm:"not null;index"`
+	PublishedAt   *time.Time `gorm:"index"`
+	CreatedAt     time.Time
+}
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryRetrying  = "retrying"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookSubscription sends events to an API client's endpoint. Clients owned
+// by an admin receive every event; others only those about their owner's own
+// account and bookings. An empty EventTypes means all types.
+type WebhookSubscription struct {
+	ID         uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	APIKeyID   uuid.UUID  `gorm:"type:uuid;not null;index"`
+	URL        string     `gorm:"type:varchar(2048);not null"`
+	EventTypes StringList `gorm:"type:jsonb;not null;default:'[]'"`
+	Secret     string     `gorm:"type:varchar(100);not null" json:"-"`
+	CreatedBy  uuid.UUID  `gorm:"type:uuid;not null"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	DeletedAt  gorm.DeletedAt `gorm:"index"`
+}
+
+// WebhookDelivery is one event sent to one subscription, and its log. A
+// replay is a new delivery pointing at the one it repeats; otherwise each
+// event is delivered to a subscription once, however often it is relayed.
+type WebhookDelivery struct {
+	ID             uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	SubscriptionID uuid.UUID  `gorm:"type:uuid;not null;index;uniqueIndex:idx_webhook_deliveries_event,where:replay_of_id IS NULL"`
+	EventID        uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_webhook_deliveries_event,where:replay_of_id IS NULL"`
+	EventType      string     `gorm:"type:varchar(100);not null"`
+	Payload        JSONMap    `gorm:"type:jsonb;not null;default:'{}'"`
+	Status         string     `gorm:"type:varchar(20);not null;default:'pending';index"`
+	Attempts       int        `gorm:"not null;default:0"`
+	ResponseStatus int        `gorm:"not null;default:0"`
+	ResponseBody   string     `gorm:"type:text;not null;default:''"`
+	LastError      string     `gorm:"type:text;not null;default:''"`
+	DurationMillis int64      `gorm:"not null;default:0"`
+	ReplayOfID     *uuid.UUID `gorm:"type:uuid"`
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time `gorm:"index"`
+	UpdatedAt      time.Time
+}
+
+const (
+	NotificationBookingConfirmation = "booking_confirmation"
+	NotificationPickupReminder      = "pickup_reminder"
+	NotificationOverdueWarning      = "overdue_warning"
+	NotificationReceipt             = "receipt"
+	NotificationStaffOverdue        = "staff_overdue"
+	NotificationStaffBlocked        = "staff_booking_blocked"
+)
+
+// NotificationKinds lists every kind a user can opt in to. The staff kinds
+// only ever go to admins.
+var NotificationKinds = []string{
+	NotificationBookingConfirmation,
+	NotificationPickupReminder,
+	NotificationOverdueWarning,
+	NotificationReceipt,
+	NotificationStaffOverdue,
+	NotificationStaffBlocked,
+}
+
+const (
+	NotificationChannelEmail = "email"
+	NotificationChannelSMS   = "sms"
+)
+
+const (
+	NotificationQueued = "queued"
+	NotificationSent   = "sent"
+	NotificationFailed = "failed"
+)
+
+// NotificationPreference says which kinds of notification a user gets on each
+// channel, and in which language. A user without a row gets every kind by
+// email in English and nothing by SMS.
+type NotificationPreference struct {
+	UserID     uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	Locale     string     `gorm:"type:varchar(16);not null;default:'en'"`
+	Phone      string     `gorm:"type:varchar(32);not null;default:''"`
+	EmailKinds StringList `gorm:"type:jsonb;not null;default:'[]'"`
+	SMSKinds   StringList `gorm:"type:jsonb;not null;default:'[]'"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// Notification is one message to a user on one channel, and its log. DedupKey
+// is unique, so however often the event behind it is relayed or the reminder
+// scan comes round, each notification is queued once.
+type Notification struct {
+	ID        uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index"`
+	RentalID  *uuid.UUID `gorm:"type:uuid;index"`
+	Kind      string     `gorm:"type:varchar(50);not null"`
+	Channel   string     `gorm:"type:varchar(10);not null"`
+	Recipient string     `gorm:"type:varchar(255);not null"`
+	Subject   string     `gorm:"type:varchar(255);not null;default:''"`
+	Body      string     `gorm:"type:text;not null"`
+	DedupKey  string     `gorm:"type:varchar(200);not null;uniqueIndex"`
+	Status    string     `gorm:"type:varchar(20);not null;default:'queued';index"`
+	Attempts  int        `gorm:"not null;default:0"`
+	LastError string     `gorm:"type:text;not null;default:''"`
+	SentAt    *time.Time
+	CreatedAt time.Time `gorm:"index"`
+	UpdatedAt time.Time
+}
+
+// TelematicsDevice is a connected car's unit reporting for one vehicle. It
+// signs in with a token of the form vxd_<prefix>_<secret>, stored hashed like
+// an API key.
+type TelematicsDevice struct {
+	ID         uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	VehicleID  uuid.UUID `gorm:"type:uuid;not null;index"`
+	Name       string    `gorm:"type:varchar(255);not null"`
+	Prefix     string    `gorm:"type:varchar(32);uniqueIndex;not null"`
+	TokenHash  string    `gorm:"type:varchar(64);not null" json:"-"`
+	CreatedBy  uuid.UUID `gorm:"type:uuid;not null"`
+	LastSeenAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// TelematicsReading is one state report from a device. The table is only
+// appended to and read by vehicle and time range; old rows are pruned after
+// the retention period. A reading sent twice is stored once, since a device
+// reports at most one state per instant.
+type TelematicsReading struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement"`
+	VehicleID   uuid.UUID `gorm:"type:uuid;not null;index:idx_telematics_readings_vehicle_time,priority:1"`
+	DeviceID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_telematics_readings_device_time,priority:1"`
+	RecordedAt  time.Time `gorm:"not null;index:idx_telematics_readings_vehicle_time,priority:2;uniqueIndex:idx_telematics_readings_device_time,priority:2"`
+	OdometerKm  *float64
+	FuelPercent *float64
+	Latitude    *float64
+	Longitude   *float64
+	ReceivedAt  time.Time `gorm:"not null"`
 }
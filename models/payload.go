@@ -1,6 +1,10 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 // Common Responses
 
@@ -47,11 +51,979 @@ type UpdateUserPayload struct {
 	Password *string `json:"password,omitempty"`
 }
 
+type AssignRolePayload struct {
+	Role string `json:"role"`
+}
+
+type UpdateUserStatusPayload struct {
+	Reason string `json:"reason,omitempty"`
+}
+
 type UserResponse struct {
-	ID        uuid.UUID `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	Role      string    `json:"role"`
-	CreatedAt string    `json:"created_at"`
-	UpdatedAt string    `json:"updated_at"`
+	ID                    uuid.UUID `json:"id"`
+	Name                  string    `json:"name"`
+	Email                 string    `json:"email"`
+	Role                  string    `json:"role"`
+	Status                string    `json:"status"`
+	SuspendedAt           string    `json:"suspended_at,omitempty"`
+	SuspensionReason      string    `json:"suspension_reason,omitempty"`
+	PasswordResetRequired bool      `json:"password_reset_required"`
+	CreatedAt             string    `json:"created_at"`
+	UpdatedAt             string    `json:"updated_at"`
+}
+
+// API Key Payload
+
+type CreateAPIKeyPayload struct {
+	Name         string     `json:"name"`
+	Organization string     `json:"organization"`
+	UserID       string     `json:"user_id"`
+	Permissions  []string   `json:"permissions"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+}
+
+type APIKeyResponse struct {
+	ID           uuid.UUID `json:"id"`
+	Name         string    `json:"name"`
+	Prefix       string    `json:"prefix"`
+	Organization string    `json:"organization"`
+	UserID       uuid.UUID `json:"user_id"`
+	Permissions  []string  `json:"permissions"`
+	ExpiresAt    string    `json:"expires_at,omitempty"`
+	LastUsedAt   string    `json:"last_used_at,omitempty"`
+	RevokedAt    string    `json:"revoked_at,omitempty"`
+	CreatedAt    string    `json:"created_at"`
+}
+
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+// Webhook Payload
+
+type CreateWebhookPayload struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	// APIKeyID is only read from admins signed in as themselves; API clients
+	// always subscribe for their own key.
+	APIKeyID string `json:"api_key_id,omitempty"`
+}
+
+type WebhookResponse struct {
+	ID         uuid.UUID `json:"id"`
+	APIKeyID   uuid.UUID `json:"api_key_id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	CreatedAt  string    `json:"created_at"`
+}
+
+type CreateWebhookResponse struct {
+	WebhookResponse
+	Secret string `json:"secret"`
+}
+
+type WebhookDeliveryFilter struct {
+	Status string `query:"status"`
+	Limit  int    `query:"limit"`
+	Offset int    `query:"offset"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             uuid.UUID      `json:"id"`
+	SubscriptionID uuid.UUID      `json:"subscription_id"`
+	EventID        uuid.UUID      `json:"event_id"`
+	EventType      string         `json:"event_type"`
+	Payload        map[string]any `json:"payload"`
+	Status         string         `json:"status"`
+	Attempts       int            `json:"attempts"`
+	ResponseStatus int            `json:"response_status,omitempty"`
+	ResponseBody   string         `json:"response_body,omitempty"`
+	LastError      string         `json:"last_error,omitempty"`
+	DurationMillis int64          `json:"duration_ms"`
+	ReplayOfID     *uuid.UUID     `json:"replay_of_id,omitempty"`
+	DeliveredAt    string         `json:"delivered_at,omitempty"`
+	CreatedAt      string         `json:"created_at"`
+}
+
+// Notification Payload
+
+// UpdateNotificationPreferencesPayload changes only the fields it sets. Email
+// and SMS list the notification kinds wanted on each channel.
+type UpdateNotificationPreferencesPayload struct {
+	Locale *string   `json:"locale,omitempty"`
+	Phone  *string   `json:"phone,omitempty"`
+	Email  *[]string `json:"email,omitempty"`
+	SMS    *[]string `json:"sms,omitempty"`
+}
+
+type NotificationPreferencesResponse struct {
+	Locale string   `json:"locale"`
+	Phone  string   `json:"phone,omitempty"`
+	Email  []string `json:"email"`
+	SMS    []string `json:"sms"`
+}
+
+type NotificationFilter struct {
+	Limit  int `query:"limit"`
+	Offset int `query:"offset"`
+}
+
+type NotificationResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	RentalID  *uuid.UUID `json:"rental_id,omitempty"`
+	Kind      string     `json:"kind"`
+	Channel   string     `json:"channel"`
+	Recipient string     `json:"recipient"`
+	Subject   string     `json:"subject,omitempty"`
+	Body      string     `json:"body"`
+	Status    string     `json:"status"`
+	Attempts  int        `json:"attempts"`
+	LastError string     `json:"last_error,omitempty"`
+	SentAt    string     `json:"sent_at,omitempty"`
+	CreatedAt string     `json:"created_at"`
+}
+
+// Stream Payload
+
+// StreamFilter narrows an event stream. Types is a comma-separated list of
+// event types; LastEventID resumes after the event with that ID, as sent in
+// the SSE Last-Event-ID header.
+type StreamFilter struct {
+	BranchID    string `query:"branch_id"`
+	Types       string `query:"types"`
+	LastEventID string `query:"last_event_id"`
+}
+
+// Vehicle Payload
+
+type CreateVehiclePayload struct {
+	Make            string   `json:"make"`
+	Model           string   `json:"model"`
+	Year            int      `json:"year"`
+	VIN             string   `json:"vin"`
+	LicensePlate    string   `json:"license_plate"`
+	Category        string   `json:"category"`
+	Transmission    string   `json:"transmission"`
+	FuelType        string   `json:"fuel_type"`
+	Seats           int      `json:"seats"`
+	Doors           int      `json:"doors"`
+	LuggageCapacity int      `json:"luggage_capacity"`
+	MileageKm       int      `json:"mileage_km"`
+	Color           string   `json:"color"`
+	Features        []string `json:"features,omitempty"`
+	DailyRateCents  int64    `json:"daily_rate_cents"`
+	HomeBranchID    string   `json:"home_branch_id,omitempty"`
+}
+
+type UpdateVehiclePayload struct {
+	Make            *string   `json:"make,omitempty"`
+	Model           *string   `json:"model,omitempty"`
+	Year            *int      `json:"year,omitempty"`
+	VIN             *string   `json:"vin,omitempty"`
+	LicensePlate    *string   `json:"license_plate,omitempty"`
+	Category        *string   `json:"category,omitempty"`
+	Transmission    *string   `json:"transmission,omitempty"`
+	FuelType        *string   `json:"fuel_type,omitempty"`
+	Seats           *int      `json:"seats,omitempty"`
+	Doors           *int      `json:"doors,omitempty"`
+	LuggageCapacity *int      `json:"luggage_capacity,omitempty"`
+	MileageKm       *int      `json:"mileage_km,omitempty"`
+	Color           *string   `json:"color,omitempty"`
+	Features        *[]string `json:"features,omitempty"`
+	DailyRateCents  *int64    `json:"daily_rate_cents,omitempty"`
+	HomeBranchID    *string   `json:"home_branch_id,omitempty"`
+	CurrentBranchID *string   `json:"current_branch_id,omitempty"`
+}
+
+// VehicleFilter narrows GET /v1/vehicles. With start_date and end_date only
+// vehicles free for the whole window (and, with branch_id, at that branch
+// when the window starts) are returned.
+// The remaining fields are catalog filters; features is a comma separated
+// list and matches vehicles that have all of them.
+type VehicleFilter struct {
+	BranchID  string `query:"branch_id"`
+	StartDate string `query:"start_date"`
+	EndDate   string `query:"end_date"`
+
+	Make         string `query:"make"`
+	Model        string `query:"model"`
+	Category     string `query:"category"`
+	Transmission string `query:"transmission"`
+	FuelType     string `query:"fuel_type"`
+	Color        string `query:"color"`
+	Features     string `query:"features"`
+	MinYear      int    `query:"min_year"`
+	MaxYear      int    `query:"max_year"`
+	MinSeats     int    `query:"min_seats"`
+	MinDoors     int    `query:"min_doors"`
+	MinLuggage   int    `query:"min_luggage"`
+	MaxMileageKm int    `query:"max_mileage_km"`
+}
+
+type VehicleResponse struct {
+	ID              uuid.UUID  `json:"id"`
+	Make            string     `json:"make"`
+	Model           string     `json:"model"`
+	Year            int        `json:"year"`
+	VIN             string     `json:"vin"`
+	LicensePlate    string     `json:"license_plate"`
+	Category        string     `json:"category"`
+	Transmission    string     `json:"transmission"`
+	FuelType        string     `json:"fuel_type"`
+	Seats           int        `json:"seats"`
+	Doors           int        `json:"doors"`
+	LuggageCapacity int        `json:"luggage_capacity"`
+	MileageKm       int        `json:"mileage_km"`
+	Color           string     `json:"color"`
+	Features        []string   `json:"features"`
+	DailyRateCents  int64      `json:"daily_rate_cents"`
+	HomeBranchID    *uuid.UUID `json:"home_branch_id,omitempty"`
+	CurrentBranchID *uuid.UUID `json:"current_branch_id,omitempty"`
+	// Position is only shown to admins: while a vehicle is rented it is
+	// where the customer is.
+	Position    *VehiclePosition `json:"position,omitempty"`
+	FuelPercent *float64         `json:"fuel_percent,omitempty"`
+	TelemetryAt string           `json:"telemetry_at,omitempty"`
+	CreatedAt   string           `json:"created_at"`
+	UpdatedAt   string           `json:"updated_at"`
+}
+
+type VehiclePosition struct {
+	Latitude  float64 `json:"lat"`
+	Longitude float64 `json:"lon"`
+}
+
+type VehicleMediaResponse struct {
+	ID           uuid.UUID `json:"id"`
+	VehicleID    uuid.UUID `json:"vehicle_id"`
+	Kind         string    `json:"kind"`
+	FileName     string    `json:"file_name"`
+	ContentType  string    `json:"content_type"`
+	SizeBytes    int64     `json:"size_bytes"`
+	Width        int       `json:"width,omitempty"`
+	Height       int       `json:"height,omitempty"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url,omitempty"`
+	CreatedAt    string    `json:"created_at"`
+}
+
+// Telematics Payload
+
+type CreateTelematicsDevicePayload struct {
+	Name string `json:"name"`
+}
+
+type TelematicsDeviceResponse struct {
+	ID         uuid.UUID `json:"id"`
+	VehicleID  uuid.UUID `json:"vehicle_id"`
+	Name       string    `json:"name"`
+	Prefix     string    `json:"prefix"`
+	LastSeenAt string    `json:"last_seen_at,omitempty"`
+	RevokedAt  string    `json:"revoked_at,omitempty"`
+	CreatedAt  string    `json:"created_at"`
+}
+
+type CreateTelematicsDeviceResponse struct {
+	TelematicsDeviceResponse
+	Token string `json:"token"`
+}
+
+// TelematicsReadingPayload is one reading in a batch. Every field but the
+// timestamp is optional, as units report what they can.
+type TelematicsReadingPayload struct {
+	Timestamp   time.Time `json:"timestamp"`
+	OdometerKm  *float64  `json:"odometer_km,omitempty"`
+	FuelPercent *float64  `json:"fuel_percent,omitempty"`
+	Latitude    *float64  `json:"lat,omitempty"`
+	Longitude   *float64  `json:"lon,omitempty"`
+}
+
+type TelematicsBatchPayload struct {
+	Readings []TelematicsReadingPayload `json:"readings"`
+}
+
+// TelematicsBatchResponse counts the readings stored and those already
+// stored before; rejected ones are listed by their index in the batch.
+type TelematicsBatchResponse struct {
+	Accepted   int                   `json:"accepted"`
+	Duplicates int                   `json:"duplicates"`
+	Rejected   []TelematicsRejection `json:"rejected"`
+}
+
+type TelematicsRejection struct {
+	Index  int    `json:"index"`
+	Reason string `json:"reason"`
+}
+
+// Maintenance Payload
+
+type CreateMaintenancePayload struct {
+	Kind     string    `json:"kind"`
+	Reason   string    `json:"reason"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+}
+
+type UpdateMaintenancePayload struct {
+	Reason   *string    `json:"reason,omitempty"`
+	StartsAt *time.Time `json:"starts_at,omitempty"`
+	EndsAt   *time.Time `json:"ends_at,omitempty"`
+	Status   *string    `json:"status,omitempty"`
+}
+
+type CompleteMaintenancePayload struct {
+	MileageKm int `json:"mileage_km"`
+}
+
+type MaintenanceFilter struct {
+	Status    string `query:"status"`
+	VehicleID string `query:"vehicle_id"`
+}
+
+type MaintenanceResponse struct {
+	ID                uuid.UUID  `json:"id"`
+	VehicleID         uuid.UUID  `json:"vehicle_id"`
+	ServiceIntervalID *uuid.UUID `json:"service_interval_id,omitempty"`
+	Kind              string     `json:"kind"`
+	Status            string     `json:"status"`
+	Reason            string     `json:"reason"`
+	StartsAt          string     `json:"starts_at,omitempty"`
+	EndsAt            string     `json:"ends_at,omitempty"`
+	DueAt             string     `json:"due_at,omitempty"`
+	DueMileageKm      *int       `json:"due_mileage_km,omitempty"`
+	CompletedAt       string     `json:"completed_at,omitempty"`
+	CompletedMileage  *int       `json:"completed_mileage_km,omitempty"`
+	// Rentals overlapping an ad-hoc window; these need to be moved to
+	// another vehicle.
+	ImpactedRentalIDs []uuid.UUID `json:"impacted_rental_ids,omitempty"`
+	CreatedAt         string      `json:"created_at"`
+}
+
+type CreateServiceIntervalPayload struct {
+	Name          string     `json:"name"`
+	EveryKm       int        `json:"every_km"`
+	EveryDays     int        `json:"every_days"`
+	DurationHours int        `json:"duration_hours"`
+	LastServiceAt *time.Time `json:"last_service_at,omitempty"`
+	LastServiceKm *int       `json:"last_service_km,omitempty"`
+}
+
+type ServiceIntervalResponse struct {
+	ID            uuid.UUID `json:"id"`
+	VehicleID     uuid.UUID `json:"vehicle_id"`
+	Name          string    `json:"name"`
+	EveryKm       int       `json:"every_km,omitempty"`
+	EveryDays     int       `json:"every_days,omitempty"`
+	DurationHours int       `json:"duration_hours"`
+	LastServiceAt string    `json:"last_service_at"`
+	LastServiceKm int       `json:"last_service_km"`
+}
+
+// Inspection Payload
+
+type DamageItemPayload struct {
+	Zone        string `json:"zone"`
+	Severity    string `json:"severity"`
+	Description string `json:"description,omitempty"`
+}
+
+type CreateInspectionPayload struct {
+	Type             string              `json:"type"`
+	OdometerKm       int                 `json:"odometer_km"`
+	FuelLevelPercent int                 `json:"fuel_level_percent"`
+	Notes            string              `json:"notes,omitempty"`
+	Damages          []DamageItemPayload `json:"damages,omitempty"`
+}
+
+type DamageItemResponse struct {
+	ID          uuid.UUID              `json:"id"`
+	Zone        string                 `json:"zone"`
+	Severity    string                 `json:"severity"`
+	Description string                 `json:"description,omitempty"`
+	IsNew       bool                   `json:"is_new"`
+	Photos      []VehicleMediaResponse `json:"photos"`
+}
+
+type InspectionResponse struct {
+	ID               uuid.UUID            `json:"id"`
+	RentalID         uuid.UUID            `json:"rental_id"`
+	VehicleID        uuid.UUID            `json:"vehicle_id"`
+	Type             string               `json:"type"`
+	OdometerKm       int                  `json:"odometer_km"`
+	FuelLevelPercent int                  `json:"fuel_level_percent"`
+	Notes            string               `json:"notes,omitempty"`
+	InspectedBy      uuid.UUID            `json:"inspected_by"`
+	Damages          []DamageItemResponse `json:"damages"`
+	CreatedAt        string               `json:"created_at"`
+}
+
+type RentalChargeResponse struct {
+	ID             uuid.UUID  `json:"id"`
+	RentalID       uuid.UUID  `json:"rental_id"`
+	Kind           string     `json:"kind"`
+	Description    string     `json:"description"`
+	Quantity       int        `json:"quantity"`
+	UnitCents      int64      `json:"unit_cents"`
+	AmountCents    int64      `json:"amount_cents"`
+	SourceID       *uuid.UUID `json:"source_id,omitempty"`
+	RequiresReview bool       `json:"requires_review"`
+	CreatedAt      string     `json:"created_at"`
+}
+
+// ConditionReport compares the checkout and checkin inspections of a rental.
+// Delta fields are only set once both inspections exist.
+type ConditionReport struct {
+	RentalID         uuid.UUID              `json:"rental_id"`
+	Checkout         *InspectionResponse    `json:"checkout,omitempty"`
+	Checkin          *InspectionResponse    `json:"checkin,omitempty"`
+	DistanceKm       *int                   `json:"distance_km,omitempty"`
+	IncludedKm       *int                   `json:"included_km,omitempty"`
+	FuelDeltaPercent *int                   `json:"fuel_delta_percent,omitempty"`
+	NewDamages       []DamageItemResponse   `json:"new_damages"`
+	Charges          []RentalChargeResponse `json:"charges"`
+}
+
+type ReviewChargePayload struct {
+	Approved    bool   `json:"approved"`
+	AmountCents *int64 `json:"amount_cents,omitempty"`
+}
+
+// Invoice Payload
+
+// CreateCreditNotePayload credits part or all of an invoice. AmountCents is
+// gross (tax included); zero credits whatever has not been credited yet.
+type CreateCreditNotePayload struct {
+	Reason      string `json:"reason"`
+	AmountCents int64  `json:"amount_cents,omitempty"`
+}
+
+type InvoiceLineResponse struct {
+	Kind        string `json:"kind"`
+	Description string `json:"description"`
+	Quantity    int    `json:"quantity"`
+	UnitCents   int64  `json:"unit_cents"`
+	AmountCents int64  `json:"amount_cents"`
+}
+
+type InvoiceResponse struct {
+	ID                uuid.UUID             `json:"id"`
+	Number            string                `json:"number"`
+	Kind              string                `json:"kind"`
+	RentalID          uuid.UUID             `json:"rental_id"`
+	UserID            uuid.UUID             `json:"user_id"`
+	BranchID          *uuid.UUID            `json:"branch_id,omitempty"`
+	CreditedInvoiceID *uuid.UUID            `json:"credited_invoice_id,omitempty"`
+	IssuerName        string                `json:"issuer_name"`
+	IssuerAddress     string                `json:"issuer_address"`
+	BillToName        string                `json:"bill_to_name"`
+	BillToEmail       string                `json:"bill_to_email"`
+	Currency          string                `json:"currency"`
+	Lines             []InvoiceLineResponse `json:"lines"`
+	SubtotalCents     int64                 `json:"subtotal_cents"`
+	TaxRateBps        int                   `json:"tax_rate_bps"`
+	TaxCents          int64                 `json:"tax_cents"`
+	TotalCents        int64                 `json:"total_cents"`
+	Reason            string                `json:"reason,omitempty"`
+	IssuedAt          string                `json:"issued_at"`
+	CreditNotes       []InvoiceResponse     `json:"credit_notes,omitempty"`
+}
+
+// Branch Payload
+
+type CreateBranchPayload struct {
+	Code           string       `json:"code"`
+	Name           string       `json:"name"`
+	AddressLine1   string       `json:"address_line1"`
+	AddressLine2   string       `json:"address_line2,omitempty"`
+	City           string       `json:"city"`
+	Region         string       `json:"region,omitempty"`
+	PostalCode     string       `json:"postal_code,omitempty"`
+	Country        string       `json:"country"`
+	Timezone       string       `json:"timezone"`
+	OpeningHours   OpeningHours `json:"opening_hours,omitempty"`
+	OneWayFeeCents int64        `json:"one_way_fee_cents"`
+	TaxRateBps     int          `json:"tax_rate_bps"`
+}
+
+type UpdateBranchPayload struct {
+	Name           *string       `json:"name,omitempty"`
+	AddressLine1   *string       `json:"address_line1,omitempty"`
+	AddressLine2   *string       `json:"address_line2,omitempty"`
+	City           *string       `json:"city,omitempty"`
+	Region         *string       `json:"region,omitempty"`
+	PostalCode     *string       `json:"postal_code,omitempty"`
+	Country        *string       `json:"country,omitempty"`
+	Timezone       *string       `json:"timezone,omitempty"`
+	OpeningHours   *OpeningHours `json:"opening_hours,omitempty"`
+	OneWayFeeCents *int64        `json:"one_way_fee_cents,omitempty"`
+	TaxRateBps     *int          `json:"tax_rate_bps,omitempty"`
+}
+
+type BranchResponse struct {
+	ID             uuid.UUID    `json:"id"`
+	Code           string       `json:"code"`
+	Name           string       `json:"name"`
+	AddressLine1   string       `json:"address_line1"`
+	AddressLine2   string       `json:"address_line2,omitempty"`
+	City           string       `json:"city"`
+	Region         string       `json:"region,omitempty"`
+	PostalCode     string       `json:"postal_code,omitempty"`
+	Country        string       `json:"country"`
+	Timezone       string       `json:"timezone"`
+	OpeningHours   OpeningHours `json:"opening_hours"`
+	OneWayFeeCents int64        `json:"one_way_fee_cents"`
+	TaxRateBps     int          `json:"tax_rate_bps"`
+	CreatedAt      string       `json:"created_at"`
+	UpdatedAt      string       `json:"updated_at"`
+}
+
+type SetOneWayFeePayload struct {
+	ToBranchID string `json:"to_branch_id"`
+	FeeCents   int64  `json:"fee_cents"`
+}
+
+type OneWayFeeResponse struct {
+	FromBranchID uuid.UUID `json:"from_branch_id"`
+	ToBranchID   uuid.UUID `json:"to_branch_id"`
+	FeeCents     int64     `json:"fee_cents"`
+}
+
+// Rental Payload
+
+type CreateRentalPayload struct {
+	VehicleID       string    `json:"vehicle_id"`
+	StartDate       time.Time `json:"start_date"`
+	EndDate         time.Time `json:"end_date"`
+	PickupBranchID  string    `json:"pickup_branch_id,omitempty"`
+	DropoffBranchID string    `json:"dropoff_branch_id,omitempty"`
+	PaymentToken    string    `json:"payment_token"`
+	PromoCode       string    `json:"promo_code,omitempty"`
+
+	Extras []RentalExtraPayload `json:"extras,omitempty"`
+	// AdditionalDrivers are the emails of registered users who will also
+	// drive. Each must accept before they are covered.
+	AdditionalDrivers []string `json:"additional_drivers,omitempty"`
+}
+
+type RentalExtraPayload struct {
+	ExtraID  string `json:"extra_id"`
+	Quantity int    `json:"quantity"`
+}
+
+type RentalDriverResponse struct {
+	UserID      uuid.UUID `json:"user_id"`
+	Name        string    `json:"name"`
+	Status      string    `json:"status"`
+	FeeCents    int64     `json:"fee_cents"`
+	RespondedAt string    `json:"responded_at,omitempty"`
+}
+
+// DriverInvitationResponse is a rental the caller has been named on as an
+// additional driver.
+type DriverInvitationResponse struct {
+	RentalID  uuid.UUID `json:"rental_id"`
+	VehicleID uuid.UUID `json:"vehicle_id"`
+	StartDate string    `json:"start_date"`
+	EndDate   string    `json:"end_date"`
+	Status    string    `json:"status"`
+	FeeCents  int64     `json:"fee_cents"`
+}
+
+type RespondDriverInvitationPayload struct {
+	Accept bool `json:"accept"`
+}
+
+type RentalExtraResponse struct {
+	ExtraID     uuid.UUID `json:"extra_id"`
+	Name        string    `json:"name"`
+	Pricing     string    `json:"pricing"`
+	Quantity    int       `json:"quantity"`
+	UnitCents   int64     `json:"unit_cents"`
+	AmountCents int64     `json:"amount_cents"`
+}
+
+// QuoteLine is one priced element of a quote; the same lines later appear on
+// the invoice.
+type QuoteLine struct {
+	Kind        string `json:"kind"`
+	Description string `json:"description"`
+	Quantity    int    `json:"quantity"`
+	UnitCents   int64  `json:"unit_cents"`
+	AmountCents int64  `json:"amount_cents"`
+}
+
+// QuoteResponse prices a prospective booking exactly as POST /v1/rentals
+// would, without reserving anything.
+type QuoteResponse struct {
+	VehicleID       uuid.UUID   `json:"vehicle_id"`
+	StartDate       string      `json:"start_date"`
+	EndDate         string      `json:"end_date"`
+	Days            int         `json:"days"`
+	PickupBranchID  *uuid.UUID  `json:"pickup_branch_id,omitempty"`
+	DropoffBranchID *uuid.UUID  `json:"dropoff_branch_id,omitempty"`
+	Currency        string      `json:"currency"`
+	Lines           []QuoteLine `json:"lines"`
+	PromoCode       string      `json:"promo_code,omitempty"`
+	DiscountCents   int64       `json:"discount_cents"`
+	SubtotalCents   int64       `json:"subtotal_cents"`
+	TaxRateBps      int         `json:"tax_rate_bps"`
+	TaxCents        int64       `json:"tax_cents"`
+	TotalCents      int64       `json:"total_cents"`
+	DepositCents    int64       `json:"deposit_cents"`
+
+	CancellationPolicy CancellationPolicyResponse `json:"cancellation_policy"`
+}
+
+// ConfirmRentalPayload turns a hold into a booking by paying for it.
+type ConfirmRentalPayload struct {
+	PaymentToken string `json:"payment_token"`
+}
+
+// UpdateRentalPayload moves a booking. Once the rental has started only the
+// end date can change. PaymentToken is needed when the new price is more than
+// the current hold covers.
+type UpdateRentalPayload struct {
+	VehicleID    *string    `json:"vehicle_id,omitempty"`
+	StartDate    *time.Time `json:"start_date,omitempty"`
+	EndDate      *time.Time `json:"end_date,omitempty"`
+	PaymentToken string     `json:"payment_token,omitempty"`
+}
+
+type RentalModificationResponse struct {
+	Sequence           int       `json:"sequence"`
+	ModifiedBy         uuid.UUID `json:"modified_by"`
+	PreviousVehicleID  uuid.UUID `json:"previous_vehicle_id"`
+	PreviousStartDate  string    `json:"previous_start_date"`
+	PreviousEndDate    string    `json:"previous_end_date"`
+	VehicleID          uuid.UUID `json:"vehicle_id"`
+	StartDate          string    `json:"start_date"`
+	EndDate            string    `json:"end_date"`
+	PreviousTotalCents int64     `json:"previous_total_cents"`
+	TotalCents         int64     `json:"total_cents"`
+	DifferenceCents    int64     `json:"difference_cents"`
+	HoldReplaced       bool      `json:"hold_replaced"`
+	CreatedAt          string    `json:"created_at"`
+}
+
+type ReturnRentalPayload struct {
+	DropoffBranchID *string `json:"dropoff_branch_id,omitempty"`
+}
+
+type RentalFilter struct {
+	UserID    string
+	VehicleID string
+	Overdue   bool
+	Blocked   bool
+}
+
+type RentalResponse struct {
+	ID                     uuid.UUID  `json:"id"`
+	UserID                 uuid.UUID  `json:"user_id"`
+	VehicleID              uuid.UUID  `json:"vehicle_id"`
+	StartDate              string     `json:"start_date"`
+	EndDate                string     `json:"end_date"`
+	Status                 string     `json:"status"`
+	HoldExpiresAt          string     `json:"hold_expires_at,omitempty"`
+	PickupBranchID         *uuid.UUID `json:"pickup_branch_id,omitempty"`
+	DropoffBranchID        *uuid.UUID `json:"dropoff_branch_id,omitempty"`
+	OneWayFeeCents         int64      `json:"one_way_fee_cents"`
+	ReturnedAt             string     `json:"returned_at,omitempty"`
+	RentalCents            int64      `json:"rental_cents"`
+	ExtrasCents            int64      `json:"extras_cents"`
+	YoungDriverCents       int64      `json:"young_driver_cents"`
+	AdditionalDriversCents int64      `json:"additional_drivers_cents"`
+	PromoCode              string     `json:"promo_code,omitempty"`
+	DiscountCents          int64      `json:"discount_cents"`
+	TaxRateBps             int        `json:"tax_rate_bps"`
+	DepositCents           int64      `json:"deposit_cents"`
+	PaymentStatus          string     `json:"payment_status"`
+	AuthorizedCents        int64      `json:"authorized_cents"`
+	CapturedCents          int64      `json:"captured_cents"`
+	RefundedCents          int64      `json:"refunded_cents"`
+	CancellationFeeCents   int64      `json:"cancellation_fee_cents"`
+	NoShow                 bool       `json:"no_show,omitempty"`
+	OverdueAt              string     `json:"overdue_at,omitempty"`
+	BlockedByRentalID      *uuid.UUID `json:"blocked_by_rental_id,omitempty"`
+	BlockedAt              string     `json:"blocked_at,omitempty"`
+	CreatedAt              string     `json:"created_at"`
+
+	CancellationPolicy CancellationPolicyResponse `json:"cancellation_policy"`
+
+	Extras  []RentalExtraResponse  `json:"extras,omitempty"`
+	Drivers []RentalDriverResponse `json:"additional_drivers,omitempty"`
+}
+
+// Extra Payload
+
+type CreateExtraPayload struct {
+	Code           string `json:"code"`
+	Name           string `json:"name"`
+	Description    string `json:"description,omitempty"`
+	Pricing        string `json:"pricing"`
+	PriceCents     int64  `json:"price_cents"`
+	MaxPerRental   int    `json:"max_per_rental,omitempty"`
+	TrackInventory bool   `json:"track_inventory"`
+}
+
+type UpdateExtraPayload struct {
+	Name           *string `json:"name,omitempty"`
+	Description    *string `json:"description,omitempty"`
+	Pricing        *string `json:"pricing,omitempty"`
+	PriceCents     *int64  `json:"price_cents,omitempty"`
+	MaxPerRental   *int    `json:"max_per_rental,omitempty"`
+	TrackInventory *bool   `json:"track_inventory,omitempty"`
+	Active         *bool   `json:"active,omitempty"`
+}
+
+// ExtraFilter narrows GET /v1/extras. With branch_id, start_date and
+// end_date each stocked extra reports how many are free for that window.
+type ExtraFilter struct {
+	BranchID        string `query:"branch_id"`
+	StartDate       string `query:"start_date"`
+	EndDate         string `query:"end_date"`
+	IncludeInactive bool   `query:"include_inactive"`
+}
+
+type ExtraResponse struct {
+	ID             uuid.UUID `json:"id"`
+	Code           string    `json:"code"`
+	Name           string    `json:"name"`
+	Description    string    `json:"description,omitempty"`
+	Pricing        string    `json:"pricing"`
+	PriceCents     int64     `json:"price_cents"`
+	MaxPerRental   int       `json:"max_per_rental"`
+	TrackInventory bool      `json:"track_inventory"`
+	Active         bool      `json:"active"`
+	Available      *int      `json:"available,omitempty"`
+	CreatedAt      string    `json:"created_at"`
+	UpdatedAt      string    `json:"updated_at"`
+}
+
+type SetExtraStockPayload struct {
+	BranchID string `json:"branch_id"`
+	Quantity int    `json:"quantity"`
+}
+
+type ExtraStockResponse struct {
+	ExtraID  uuid.UUID `json:"extra_id"`
+	BranchID uuid.UUID `json:"branch_id"`
+	Quantity int       `json:"quantity"`
+}
+
+// Promo Code Payload
+
+type CreatePromoCodePayload struct {
+	Code           string     `json:"code"`
+	Description    string     `json:"description,omitempty"`
+	DiscountType   string     `json:"discount_type"`
+	DiscountBps    int        `json:"discount_bps,omitempty"`
+	DiscountCents  int64      `json:"discount_cents,omitempty"`
+	ValidFrom      *time.Time `json:"valid_from,omitempty"`
+	ValidUntil     *time.Time `json:"valid_until,omitempty"`
+	MaxRedemptions *int       `json:"max_redemptions,omitempty"`
+	MaxPerUser     *int       `json:"max_per_user,omitempty"`
+	MinRentalDays  int        `json:"min_rental_days,omitempty"`
+	Categories     []string   `json:"categories,omitempty"`
+}
+
+// UpdatePromoCodePayload changes a campaign in place. Caps and validity
+// bounds can't be cleared once set; deactivate the code and issue a new one.
+type UpdatePromoCodePayload struct {
+	Description    *string    `json:"description,omitempty"`
+	DiscountType   *string    `json:"discount_type,omitempty"`
+	DiscountBps    *int       `json:"discount_bps,omitempty"`
+	DiscountCents  *int64     `json:"discount_cents,omitempty"`
+	ValidFrom      *time.Time `json:"valid_from,omitempty"`
+	ValidUntil     *time.Time `json:"valid_until,omitempty"`
+	MaxRedemptions *int       `json:"max_redemptions,omitempty"`
+	MaxPerUser     *int       `json:"max_per_user,omitempty"`
+	MinRentalDays  *int       `json:"min_rental_days,omitempty"`
+	Categories     *[]string  `json:"categories,omitempty"`
+	Active         *bool      `json:"active,omitempty"`
+}
+
+type PromoCodeResponse struct {
+	ID             uuid.UUID `json:"id"`
+	Code           string    `json:"code"`
+	Description    string    `json:"description,omitempty"`
+	DiscountType   string    `json:"discount_type"`
+	DiscountBps    int       `json:"discount_bps,omitempty"`
+	DiscountCents  int64     `json:"discount_cents,omitempty"`
+	ValidFrom      string    `json:"valid_from,omitempty"`
+	ValidUntil     string    `json:"valid_until,omitempty"`
+	MaxRedemptions *int      `json:"max_redemptions,omitempty"`
+	MaxPerUser     *int      `json:"max_per_user,omitempty"`
+	MinRentalDays  int       `json:"min_rental_days"`
+	Categories     []string  `json:"categories"`
+	Active         bool      `json:"active"`
+	Redemptions    int       `json:"redemptions"`
+	CreatedAt      string    `json:"created_at"`
+	UpdatedAt      string    `json:"updated_at"`
+}
+
+// Driver Payload
+
+// DriverProfilePayload dates are plain YYYY-MM-DD.
+type DriverProfilePayload struct {
+	DateOfBirth      string `json:"date_of_birth"`
+	LicenseNumber    string `json:"license_number"`
+	LicenseCountry   string `json:"license_country"`
+	LicenseExpiresOn string `json:"license_expires_on"`
+}
+
+type ReviewDriverProfilePayload struct {
+	Approved bool   `json:"approved"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+type DriverProfileFilter struct {
+	Status string `query:"status"`
+}
+
+type DriverProfileResponse struct {
+	UserID           uuid.UUID  `json:"user_id"`
+	DateOfBirth      string     `json:"date_of_birth"`
+	LicenseNumber    string     `json:"license_number"`
+	LicenseCountry   string     `json:"license_country"`
+	LicenseExpiresOn string     `json:"license_expires_on"`
+	HasLicensePhoto  bool       `json:"has_license_photo"`
+	Status           string     `json:"status"`
+	ReviewedBy       *uuid.UUID `json:"reviewed_by,omitempty"`
+	ReviewedAt       string     `json:"reviewed_at,omitempty"`
+	RejectionReason  string     `json:"rejection_reason,omitempty"`
+	UpdatedAt        string     `json:"updated_at"`
+}
+
+type VehicleClassPayload struct {
+	MinDriverAge        int                       `json:"min_driver_age"`
+	YoungDriverAge      int                       `json:"young_driver_age"`
+	YoungDriverFeeCents int64                     `json:"young_driver_fee_cents"`
+	CancellationPolicy  CancellationPolicyPayload `json:"cancellation_policy"`
+}
+
+type VehicleClassResponse struct {
+	Category            string                     `json:"category"`
+	MinDriverAge        int                        `json:"min_driver_age"`
+	YoungDriverAge      int                        `json:"young_driver_age"`
+	YoungDriverFeeCents int64                      `json:"young_driver_fee_cents"`
+	CancellationPolicy  CancellationPolicyResponse `json:"cancellation_policy"`
+}
+
+// CancellationPolicyPayload sets a class's cancellation terms. Fees are
+// "none", "percent" (with the matching _bps field) or "one_day".
+type CancellationPolicyPayload struct {
+	FreeHours    int    `json:"free_hours"`
+	LateFee      string `json:"late_fee"`
+	LateFeeBps   int    `json:"late_fee_bps,omitempty"`
+	NoShowFee    string `json:"no_show_fee"`
+	NoShowFeeBps int    `json:"no_show_fee_bps,omitempty"`
+}
+
+// CancellationPolicyResponse describes the terms. On a quote or rental it
+// also gives the deadline for free cancellation and what each fee comes to.
+type CancellationPolicyResponse struct {
+	FreeHours      int    `json:"free_hours"`
+	LateFee        string `json:"late_fee"`
+	LateFeeBps     int    `json:"late_fee_bps,omitempty"`
+	NoShowFee      string `json:"no_show_fee"`
+	NoShowFeeBps   int    `json:"no_show_fee_bps,omitempty"`
+	FreeUntil      string `json:"free_until,omitempty"`
+	LateFeeCents   *int64 `json:"late_fee_cents,omitempty"`
+	NoShowFeeCents *int64 `json:"no_show_fee_cents,omitempty"`
+}
+
+// Data Export Payload
+
+// UserDataExport is everything held about a user: every record keyed on their
+// user ID, plus the audit entries about them or made by them. Records that
+// belong to a rental are part of that rental. Records staff keep about
+// vehicles only carry the ID of whoever made them and are covered by that
+// person's audit entries instead.
+type UserDataExport struct {
+	ExportedAt    string                 `json:"exported_at"`
+	User          UserResponse           `json:"user"`
+	DriverProfile *DriverProfileResponse `json:"driver_profile,omitempty"`
+	Rentals       []RentalResponse       `json:"rentals"`
+
+	DriverInvitations []DriverInvitationResponse `json:"driver_invitations"`
+	Invoices          []InvoiceResponse          `json:"invoices"`
+	APIKeys           []APIKeyResponse           `json:"api_keys"`
+
+	NotificationPreferences *NotificationPreferencesResponse `json:"notification_preferences,omitempty"`
+	Notifications           []NotificationResponse           `json:"notifications"`
+
+	AuditTrail []AuditLogResponse `json:"audit_trail"`
+}
+
+// Audit Payload
+
+type AuditLogFilter struct {
+	ActorID      string `query:"actor_id"`
+	Action       string `query:"action"`
+	ResourceType string `query:"resource_type"`
+	ResourceID   string `query:"resource_id"`
+	From         string `query:"from"`
+	To           string `query:"to"`
+	Limit        int    `query:"limit"`
+	Offset       int    `query:"offset"`
+}
+
+type AuditLogResponse struct {
+	ID           uint64         `json:"id"`
+	ActorType    string         `json:"actor_type"`
+	ActorID      string         `json:"actor_id,omitempty"`
+	APIKeyID     string         `json:"api_key_id,omitempty"`
+	Action       string         `json:"action"`
+	ResourceType string         `json:"resource_type"`
+	ResourceID   string         `json:"resource_id"`
+	Before       map[string]any `json:"before,omitempty"`
+	After        map[string]any `json:"after,omitempty"`
+	Changes      map[string]any `json:"changes,omitempty"`
+	IP           string         `json:"ip,omitempty"`
+	RequestID    string         `json:"request_id,omitempty"`
+	PrevHash     string         `json:"prev_hash"`
+	Hash         string         `json:"hash"`
+	CreatedAt    string         `json:"created_at"`
+}
+
+type AuditChainVerification struct {
+	Valid      bool    `json:"valid"`
+	Checked    int     `json:"checked"`
+	BrokenAtID *uint64 `json:"broken_at_id,omitempty"`
+}
+
+// Job Payload
+
+type JobFilter struct {
+	Status string `query:"status"`
+	Kind   string `query:"kind"`
+	Limit  int    `query:"limit"`
+	Offset int    `query:"offset"`
+}
+
+type JobResponse struct {
+	ID           uuid.UUID `json:"id"`
+	Kind         string    `json:"kind"`
+	Payload      JSONMap   `json:"payload"`
+	Status       string    `json:"status"`
+	RunAt        string    `json:"run_at"`
+	Attempts     int       `json:"attempts"`
+	MaxAttempts  int       `json:"max_attempts"`
+	LastError    string    `json:"last_error,omitempty"`
+	LockedBy     string    `json:"locked_by,omitempty"`
+	LockedUntil  string    `json:"locked_until,omitempty"`
+	RecurringJob string    `json:"recurring_job,omitempty"`
+	FinishedAt   string    `json:"finished_at,omitempty"`
+	CreatedAt    string    `json:"created_at"`
+	UpdatedAt    string    `json:"updated_at"`
+}
+
+type RecurringJobResponse struct {
+	Name      string  `json:"name"`
+	Schedule  string  `json:"schedule"`
+	Kind      string  `json:"kind"`
+	Payload   JSONMap `json:"payload"`
+	NextRunAt string  `json:"next_run_at"`
+	LastRunAt string  `json:"last_run_at,omitempty"`
 }
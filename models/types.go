@@ -0,0 +1,160 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// StringList is a list of strings persisted as a jsonb array.
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal([]string(l))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (l *StringList) Scan(value any) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*l = StringList{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for StringList: %T", value)
+	}
+	return json.Unmarshal(data, (*[]string)(l))
+}
+
+func (l StringList) Contains(s string) bool {
+	for _, v := range l {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// JSONMap is a free-form JSON object persisted as jsonb.
+type JSONMap map[string]any
+
+func (m JSONMap) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(map[string]any(m))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (m *JSONMap) Scan(value any) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*m = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for JSONMap: %T", value)
+	}
+	return json.Unmarshal(data, (*map[string]any)(m))
+}
+
+// TimeRange is a same-day interval in "HH:MM" 24h local time.
+type TimeRange struct {
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
+// OpeningHours maps a lowercase weekday ("monday") to the ranges a branch is
+// open on that day. A day with no entry is closed; empty hours mean the
+// branch is always open.
+type OpeningHours map[string][]TimeRange
+
+func (h OpeningHours) Value() (driver.Value, error) {
+	if h == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(map[string][]TimeRange(h))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (h *OpeningHours) Scan(value any) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*h = OpeningHours{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for OpeningHours: %T", value)
+	}
+	return json.Unmarshal(data, (*map[string][]TimeRange)(h))
+}
+
+// Validate checks weekday names and that every range is a well-formed
+// HH:MM pair with open before close.
+func (h OpeningHours) Validate() error {
+	for day, ranges := range h {
+		if _, ok := weekdays[day]; !ok {
+			return fmt.Errorf("unknown weekday %q", day)
+		}
+		for _, r := range ranges {
+			open, err := time.Parse("15:04", r.Open)
+			if err != nil {
+				return fmt.Errorf("%s: invalid open time %q", day, r.Open)
+			}
+			closing, err := time.Parse("15:04", r.Close)
+			if err != nil {
+				return fmt.Errorf("%s: invalid close time %q", day, r.Close)
+			}
+			if !open.Before(closing) {
+				return fmt.Errorf("%s: open time must be before close time", day)
+			}
+		}
+	}
+	return nil
+}
+
+// IsOpen reports whether t, converted to loc, falls within the opening hours.
+func (h OpeningHours) IsOpen(t time.Time, loc *time.Location) bool {
+	if len(h) == 0 {
+		return true
+	}
+	local := t.In(loc)
+	clock := local.Format("15:04")
+	for _, r := range h[strings.ToLower(local.Weekday().String())] {
+		if clock >= r.Open && clock <= r.Close {
+			return true
+		}
+	}
+	return false
+}
+
+var weekdays = map[string]struct{}{
+	"monday": {}, "tuesday": {}, "wednesday": {}, "thursday": {},
+	"friday": {}, "saturday": {}, "sunday": {},
+}